@@ -0,0 +1,164 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+)
+
+// TryBuildAll builds the values of all types provided to the Container that
+// no constructor depends on, and returns those that were built.
+//
+//	values, err := c.TryBuildAll()
+//	for t, v := range values {
+//	  log.Printf("started %v: %v", t, v)
+//	}
+//	if err != nil {
+//	  log.Printf("some services failed to start: %v", err)
+//	}
+//
+// Unlike Invoke, TryBuildAll does not stop at the first failure: every type
+// is built independently, so a constructor that fails only prevents the
+// values that depend on it from being built. The errors of all types that
+// failed to build are returned together.
+//
+// Only unnamed values are built; named values and value groups are built
+// only as dependencies of these. Values provided to child Scopes of the
+// Container are not built unless they were exported.
+func (c *Container) TryBuildAll() (map[reflect.Type]reflect.Value, error) {
+	s := c.scope
+	if err := s.verifyAcyclic(); err != nil {
+		return nil, err
+	}
+
+	types := s.unconsumedTypes()
+	values := make(map[reflect.Type]reflect.Value, len(types))
+	var errs []error
+	for _, t := range types {
+		v, err := paramSingle{Type: t}.Build(s)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		values[t] = v
+	}
+	return values, newErrBuildAllFailed(len(types), errs)
+}
+
+// unconsumedTypes returns the types of the unnamed values provided to this
+// Scope that no constructor of this Scope or its descendants depends on,
+// sorted by name.
+func (s *Scope) unconsumedTypes() []reflect.Type {
+	consumed := make(map[key]struct{})
+	for _, scope := range s.appendSubscopes(nil) {
+		for _, n := range scope.nodes {
+			addConsumedKeys(consumed, n.paramList.Params...)
+		}
+	}
+
+	var types []reflect.Type
+	for k := range s.providers {
+		if k.name != "" || k.group != "" {
+			continue
+		}
+		if _, ok := consumed[k]; !ok {
+			types = append(types, k.t)
+		}
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i].String() < types[j].String()
+	})
+	return types
+}
+
+// addConsumedKeys adds the keys of the values and value groups that the
+// given params depend on to keys.
+func addConsumedKeys(keys map[key]struct{}, params ...param) {
+	for _, p := range params {
+		switch p := p.(type) {
+		case paramSingle:
+			keys[key{t: p.Type, name: p.Name}] = struct{}{}
+		case paramDynamic:
+			addConsumedKeys(keys, p.Value)
+		case paramGroupedSlice:
+			keys[key{t: p.Type.Elem(), group: p.Group}] = struct{}{}
+		case paramObject:
+			for _, f := range p.Fields {
+				addConsumedKeys(keys, f.Param)
+			}
+		}
+	}
+}
+
+// errBuildAllFailed is returned by TryBuildAll when more than one type
+// failed to build.
+type errBuildAllFailed struct {
+	Total  int
+	Errors []error // inv: len > 1
+}
+
+// newErrBuildAllFailed returns the errors of the types that failed to build
+// out of total. A single error is returned as-is.
+func newErrBuildAllFailed(total int, errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	return errBuildAllFailed{Total: total, Errors: errs}
+}
+
+var _ digError = errBuildAllFailed{}
+
+func (e errBuildAllFailed) Error() string { return fmt.Sprint(e) }
+
+// Unwrap returns the errors of all types that failed to build, in order.
+func (e errBuildAllFailed) Unwrap() []error { return e.Errors }
+
+func (e errBuildAllFailed) writeMessage(w io.Writer, _ string) {
+	fmt.Fprintf(w, "%d of %d types failed to build", len(e.Errors), e.Total)
+}
+
+func (e errBuildAllFailed) Format(w fmt.State, c rune) {
+	e.writeMessage(w, "%v")
+
+	// As with errInvokeAllFailed, each failure is listed on its own line
+	// with %+v, and separated by semicolons otherwise.
+	if w.Flag('+') && c == 'v' {
+		io.WriteString(w, ":")
+		for i, err := range e.Errors {
+			fmt.Fprintf(w, "\n  - [%d] %+v", i+1, err)
+		}
+		return
+	}
+
+	io.WriteString(w, ": ")
+	for i, err := range e.Errors {
+		if i > 0 {
+			io.WriteString(w, "; ")
+		}
+		fmt.Fprintf(w, "[%d] %v", i+1, err)
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestTryBuildAll(t *testing.T) {
+	t.Parallel()
+
+	type Config struct{}
+	type Users struct{ *Config }
+	type Orders struct{ *Config }
+	type Payments struct{}
+	type Named struct{}
+
+	t.Run("builds unconsumed types", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{} })
+		c.RequireProvide(func(cfg *Config) *Users { return &Users{cfg} })
+		c.RequireProvide(func(cfg *Config) *Orders { return &Orders{cfg} })
+		c.RequireProvide(func() *Named {
+			t.Fatal("named values must not be built")
+			return nil
+		}, dig.Name("named"))
+
+		values, err := c.TryBuildAll()
+		require.NoError(t, err)
+		require.Len(t, values, 2)
+
+		users := values[reflect.TypeOf(&Users{})].Interface().(*Users)
+		orders := values[reflect.TypeOf(&Orders{})].Interface().(*Orders)
+		assert.Same(t, users.Config, orders.Config)
+	})
+
+	t.Run("independent failures", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (*Config, error) {
+			return nil, errors.New("great sadness")
+		})
+		c.RequireProvide(func(cfg *Config) *Users { return &Users{cfg} })
+		c.RequireProvide(func(cfg *Config) *Orders { return &Orders{cfg} })
+		c.RequireProvide(func() *Payments { return &Payments{} })
+
+		values, err := c.TryBuildAll()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "2 of 3 types failed to build")
+		assert.Contains(t, err.Error(), "great sadness")
+		assert.Contains(t, fmt.Sprintf("%+v", err), "\n  - [2] ")
+
+		require.Len(t, values, 1)
+		assert.Contains(t, values, reflect.TypeOf(&Payments{}))
+	})
+
+	t.Run("single failure", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (*Payments, error) {
+			return nil, errors.New("great sadness")
+		})
+
+		values, err := c.TryBuildAll()
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "types failed to build")
+		assert.Contains(t, err.Error(), "great sadness")
+		assert.Empty(t, values)
+	})
+
+	t.Run("scopes", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		child := c.Scope("child")
+		child.RequireProvide(func() *Users { return &Users{} })
+		child.RequireProvide(func() *Orders { return &Orders{} }, dig.Export(true))
+
+		values, err := c.TryBuildAll()
+		require.NoError(t, err)
+		require.Len(t, values, 1)
+		assert.Contains(t, values, reflect.TypeOf(&Orders{}))
+	})
+
+	t.Run("cycles", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.DeferAcyclicVerification())
+		c.RequireProvide(func(*Users) *Config { return &Config{} })
+		c.RequireProvide(func(cfg *Config) *Users { return &Users{cfg} })
+
+		_, err := c.TryBuildAll()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cycle detected in dependency graph")
+	})
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+// ProvideCtor starts providing the given constructor to the Container with
+// a builder. See Scope.ProvideCtor for details.
+func (c *Container) ProvideCtor(constructor interface{}) ProvideBuilder {
+	return c.scope.ProvideCtor(constructor)
+}
+
+// ProvideCtor starts providing the given constructor to the Scope with a
+// builder. Options are added by calling methods on the builder, and the
+// constructor is provided when Err is called, with the same behavior and
+// errors as Provide.
+//
+//	err := s.ProvideCtor(newFile).
+//	  Named("ro").
+//	  As(new(io.Reader)).
+//	  Err()
+//
+// Options that conflict with each other are not available together: a
+// builder for a named value does not offer ToGroup, and a builder for a
+// value group does not offer Named. Use With to pass any other
+// ProvideOption.
+func (s *Scope) ProvideCtor(constructor interface{}) ProvideBuilder {
+	return ProvideBuilder{provideBuilder{s: s, ctor: constructor}}
+}
+
+// provideBuilder holds the state shared by all provide builders. Builders
+// are values for a single constructor: every method returns a modified
+// copy and leaves the builder it was called on unchanged.
+type provideBuilder struct {
+	s       *Scope
+	ctor    interface{}
+	opts    []ProvideOption
+	group   string
+	flatten bool
+}
+
+func (b provideBuilder) with(opts ...ProvideOption) provideBuilder {
+	// Copy the options so that builders derived from the same base don't
+	// share them.
+	b.opts = append(b.opts[:len(b.opts):len(b.opts)], opts...)
+	return b
+}
+
+func (b provideBuilder) provide() error {
+	opts := b.opts
+	if len(b.group) > 0 {
+		group := b.group
+		if b.flatten {
+			group += ",flatten"
+		}
+		opts = append(opts[:len(opts):len(opts)], Group(group))
+	}
+	return b.s.Provide(b.ctor, opts...)
+}
+
+// ProvideBuilder provides a constructor with options. It is returned by
+// ProvideCtor.
+type ProvideBuilder struct{ b provideBuilder }
+
+// Named provides the results of the constructor with the given name. See
+// the Name option.
+func (pb ProvideBuilder) Named(name string) NamedProvideBuilder {
+	return NamedProvideBuilder{pb.b.with(Name(name))}
+}
+
+// ToGroup adds the results of the constructor to the given value group.
+// See the Group option.
+func (pb ProvideBuilder) ToGroup(group string) GroupProvideBuilder {
+	b := pb.b
+	b.group = group
+	return GroupProvideBuilder{b}
+}
+
+// As provides the results of the constructor as the given interfaces. See
+// the As option.
+func (pb ProvideBuilder) As(i ...interface{}) ProvideBuilder {
+	return ProvideBuilder{pb.b.with(As(i...))}
+}
+
+// Export makes the constructor available to all Scopes of the Container.
+// See the Export option.
+func (pb ProvideBuilder) Export() ProvideBuilder {
+	return ProvideBuilder{pb.b.with(Export(true))}
+}
+
+// With adds the given options.
+func (pb ProvideBuilder) With(opts ...ProvideOption) ProvideBuilder {
+	return ProvideBuilder{pb.b.with(opts...)}
+}
+
+// Err provides the constructor and returns the error from Provide, if any.
+func (pb ProvideBuilder) Err() error {
+	return pb.b.provide()
+}
+
+// NamedProvideBuilder provides a constructor whose results are named. It
+// is returned by ProvideBuilder.Named.
+type NamedProvideBuilder struct{ b provideBuilder }
+
+// As provides the results of the constructor as the given interfaces, with
+// the same name. See the As option.
+func (nb NamedProvideBuilder) As(i ...interface{}) NamedProvideBuilder {
+	return NamedProvideBuilder{nb.b.with(As(i...))}
+}
+
+// Export makes the constructor available to all Scopes of the Container.
+// See the Export option.
+func (nb NamedProvideBuilder) Export() NamedProvideBuilder {
+	return NamedProvideBuilder{nb.b.with(Export(true))}
+}
+
+// With adds the given options.
+func (nb NamedProvideBuilder) With(opts ...ProvideOption) NamedProvideBuilder {
+	return NamedProvideBuilder{nb.b.with(opts...)}
+}
+
+// Err provides the constructor and returns the error from Provide, if any.
+func (nb NamedProvideBuilder) Err() error {
+	return nb.b.provide()
+}
+
+// GroupProvideBuilder provides a constructor whose results are added to a
+// value group. It is returned by ProvideBuilder.ToGroup.
+type GroupProvideBuilder struct{ b provideBuilder }
+
+// As adds the results of the constructor to the value group as the given
+// interfaces. See the As option.
+func (gb GroupProvideBuilder) As(i ...interface{}) GroupProvideBuilder {
+	return GroupProvideBuilder{gb.b.with(As(i...))}
+}
+
+// Flatten adds each element of the slices returned by the constructor to
+// the value group, rather than the slices themselves. See Value Groups in
+// the package documentation.
+func (gb GroupProvideBuilder) Flatten() GroupProvideBuilder {
+	b := gb.b
+	b.flatten = true
+	return GroupProvideBuilder{b}
+}
+
+// Export makes the constructor available to all Scopes of the Container.
+// See the Export option.
+func (gb GroupProvideBuilder) Export() GroupProvideBuilder {
+	return GroupProvideBuilder{gb.b.with(Export(true))}
+}
+
+// With adds the given options.
+func (gb GroupProvideBuilder) With(opts ...ProvideOption) GroupProvideBuilder {
+	return GroupProvideBuilder{gb.b.with(opts...)}
+}
+
+// Err provides the constructor and returns the error from Provide, if any.
+func (gb GroupProvideBuilder) Err() error {
+	return gb.b.provide()
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestProvideCtor(t *testing.T) {
+	t.Parallel()
+
+	type A struct{ name string }
+
+	t.Run("matches Provide", func(t *testing.T) {
+		t.Parallel()
+
+		var info dig.ProvideInfo
+		c := digtest.New(t)
+		require.NoError(t, c.ProvideCtor(func() *A { return &A{} }).
+			Named("a").
+			With(dig.FillProvideInfo(&info)).
+			Err())
+		require.Len(t, info.Outputs, 1)
+		assert.Equal(t, `*dig_test.A[name = "a"]`, info.Outputs[0].String())
+	})
+
+	t.Run("derived builders do not share options", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		base := c.ProvideCtor(func() *A { return &A{name: "a"} })
+		require.NoError(t, base.Named("first").Err())
+		require.NoError(t, base.Named("second").Err())
+
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			First  *A `name:"first"`
+			Second *A `name:"second"`
+		}) {
+			assert.NotNil(t, p.First)
+			assert.NotNil(t, p.Second)
+		})
+	})
+
+	t.Run("scope", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		child := c.Scope("child")
+		require.NoError(t, child.ProvideCtor(func() *A { return &A{} }).Export().Err())
+		c.RequireInvoke(func(*A) {})
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.ProvideCtor(func() *A { return &A{} }).ToGroup("as").As(new(io.Reader)).Err()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid dig.As: *dig_test.A does not implement io.Reader")
+
+		err = c.ProvideCtor(func() *A { return &A{} }).ToGroup("as").Flatten().Err()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "flatten can be applied to slices only")
+	})
+}
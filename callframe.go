@@ -0,0 +1,130 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"reflect"
+
+	"go.uber.org/dig/internal/digreflect"
+)
+
+// invocation is the state of a single call of Invoke that is shared by the
+// constructors it calls, directly or not.
+type invocation struct {
+	// Function passed to Invoke, and the number of the Invoke reported by
+	// CallInfo. Unset for constructors called outside of Invoke.
+	fn  *digreflect.Func
+	seq uint64
+
+	// Records the types of the values read, if the RecordConsumed option
+	// was given.
+	consumed *consumedRecorder
+}
+
+// callFrame is a function call in progress on behalf of an Invoke: the
+// function passed to Invoke, or a constructor that it depends on. Frames
+// are immutable, so that an Invoke does not share them with others.
+type callFrame struct {
+	inv    *invocation
+	parent *callFrame
+
+	// Constructor being called, or nil for the function passed to Invoke.
+	ctor *constructorNode
+
+	// Number of constructor calls that this frame is nested in, including
+	// its own.
+	depth int
+}
+
+// newInvocationFrame returns the frame of the function passed to an Invoke
+// that was called through the Resolver of the given frame, if it is not
+// nil. Such an Invoke is nested in the call that depends on the Resolver.
+func newInvocationFrame(inv *invocation, caller *callFrame) *callFrame {
+	f := &callFrame{inv: inv, parent: caller}
+	if caller != nil {
+		f.depth = caller.depth
+	}
+	return f
+}
+
+// enter returns the frame of a call of the given constructor from this
+// frame. Constructors called outside an Invoke, such as those built by
+// Container.BuildAll, start a new frame from a nil receiver.
+func (f *callFrame) enter(n *constructorNode) *callFrame {
+	if f == nil {
+		return &callFrame{inv: &invocation{}, ctor: n, depth: 1}
+	}
+	return &callFrame{inv: f.inv, parent: f, ctor: n, depth: f.depth + 1}
+}
+
+// cycleTo returns the constructors called from the most recent call of the
+// given constructor up to this frame, followed by the constructor, if it is
+// being called by this frame or a frame that it is nested in. Calling it
+// again would wait for a call that cannot complete.
+func (f *callFrame) cycleTo(n *constructorNode) []cycleErrPathEntry {
+	var path []cycleErrPathEntry
+	for ; f != nil; f = f.parent {
+		if f.ctor == nil {
+			continue
+		}
+		path = append(path, cycleErrPathEntry{
+			Key:  key{t: f.ctor.CType()},
+			Func: f.ctor.Location(),
+		})
+		if f.ctor != n {
+			continue
+		}
+		for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+			path[i], path[j] = path[j], path[i]
+		}
+		return append(path, path[0])
+	}
+	return nil
+}
+
+// frameStore is a containerStore that additionally carries the frame of
+// the call whose parameters are being built.
+type frameStore struct {
+	containerStore
+
+	frame *callFrame
+}
+
+func (fs frameStore) callFrame() *callFrame {
+	return fs.frame
+}
+
+// withCallFrame returns a containerStore that resolves values from s on
+// behalf of the call in progress in c, if any.
+func withCallFrame(s, c containerStore) containerStore {
+	if f := c.callFrame(); f != nil {
+		return frameStore{containerStore: s, frame: f}
+	}
+	return s
+}
+
+// recordConsumed notes that a value of the given type was read from c, for
+// an Invoke with the RecordConsumed option in progress.
+func recordConsumed(c containerStore, t reflect.Type) {
+	if f := c.callFrame(); f != nil && f.inv.consumed != nil {
+		f.inv.consumed.record(t)
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+
+	"go.uber.org/dig/internal/digerror"
+	"go.uber.org/dig/internal/digreflect"
+	"go.uber.org/dig/internal/dot"
+)
+
+// CallInfo describes a call of a constructor. Constructors may depend on a
+// CallInfo, which the container provides itself, to learn where and why they
+// are being called, for example to annotate their logs.
+//
+//	c.Provide(func(info dig.CallInfo) *Logger {
+//	  return NewLogger(info.ScopeName, info.CallSequence)
+//	})
+//
+// A new CallInfo is built for every call, so it is neither cached nor part
+// of the dependency graph. Only constructors may depend on a CallInfo;
+// functions passed to Invoke or Decorate may not. See ForbidCallInfo to
+// disallow it altogether.
+type CallInfo struct {
+	// ConsumerLocation identifies the constructor that is being called.
+	ConsumerLocation string
+
+	// ScopeName is the name of the Scope that the constructor was provided
+	// to. It is empty for the Container.
+	ScopeName string
+
+	// InvokeSequence identifies the call of Invoke that caused the
+	// constructor to be called. Calls of Invoke on a Container and its
+	// Scopes are numbered from 1 in the order they start. It is zero for
+	// constructors called outside of Invoke, such as by BuildAll.
+	InvokeSequence uint64
+
+	// CallSequence numbers the calls of constructors that depend on a
+	// CallInfo, starting from 1, in the order they start.
+	CallSequence uint64
+}
+
+// _callInfoType is the type of the CallInfo that constructors may depend on.
+var _callInfoType = reflect.TypeOf(CallInfo{})
+
+// ForbidCallInfo is an [Option] that rejects functions that depend on a
+// [CallInfo], for containers whose constructors must not depend on how they
+// are called.
+func ForbidCallInfo() Option {
+	return forbidCallInfoOption{}
+}
+
+type forbidCallInfoOption struct{}
+
+func (forbidCallInfoOption) String() string {
+	return "ForbidCallInfo()"
+}
+
+func (forbidCallInfoOption) applyOption(c *Container) {
+	c.scope.forbidCallInfo = true
+}
+
+// newParamCallInfo builds the dependency of a function on a CallInfo,
+// unless the container was created with ForbidCallInfo.
+func newParamCallInfo(c containerStore) (param, error) {
+	stores := c.storesToRoot()
+	if stores[len(stores)-1].(*Scope).forbidCallInfo {
+		return nil, newErrInvalidInput(fmt.Sprintf(
+			"cannot depend on %v: the container does not allow it", _callInfoType), nil)
+	}
+	return paramCallInfo{}, nil
+}
+
+// usesCallInfo reports whether any of the given params, including those
+// nested inside parameter objects, is a CallInfo.
+func usesCallInfo(params ...param) bool {
+	for _, p := range params {
+		switch p := p.(type) {
+		case paramCallInfo:
+			return true
+		case paramObject:
+			for _, f := range p.Fields {
+				if usesCallInfo(f.Param) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// checkNoCallInfo reports an error if the provided parameters depend on a
+// CallInfo. Used for functions that are not constructors.
+func checkNoCallInfo(pl paramList) error {
+	if usesCallInfo(pl.Params...) {
+		return newErrInvalidInput(fmt.Sprintf(
+			"cannot depend on %v: only constructors may depend on it", _callInfoType), nil)
+	}
+	return nil
+}
+
+// callInfoStore is a containerStore that additionally carries the CallInfo
+// of the constructor whose parameters are being built.
+type callInfoStore struct {
+	containerStore
+
+	info CallInfo
+}
+
+// callInfoFor returns the CallInfo for a call of the given constructor,
+// which is about to start in the given frame.
+func (s *Scope) callInfoFor(n *constructorNode, f *callFrame) CallInfo {
+	defer s.lock()()
+	root := s.rootScope()
+	root.callSeq++
+	return CallInfo{
+		ConsumerLocation: n.location.String(),
+		ScopeName:        n.OrigScope().name,
+		InvokeSequence:   f.inv.seq,
+		CallSequence:     root.callSeq,
+	}
+}
+
+// beginInvoke returns the invocation of a call of Invoke of the given
+// function that is about to start, numbered in the order of the calls.
+func (s *Scope) beginInvoke(f *digreflect.Func) *invocation {
+	defer s.lock()()
+	root := s.rootScope()
+	root.invokeSeq++
+	return &invocation{fn: f, seq: root.invokeSeq}
+}
+
+// paramCallInfo is a dependency on the CallInfo of a constructor call,
+// which the container provides itself.
+type paramCallInfo struct{}
+
+var _ param = paramCallInfo{}
+
+func (paramCallInfo) String() string {
+	return _callInfoType.String()
+}
+
+// DotParam returns nothing: the CallInfo is not part of the graph.
+func (paramCallInfo) DotParam() []*dot.Param {
+	return nil
+}
+
+func (paramCallInfo) Build(c containerStore) (reflect.Value, error) {
+	cs, ok := c.(callInfoStore)
+	if !ok {
+		digerror.BugPanicf("paramCallInfo.Build() called outside a constructor call")
+	}
+	return reflect.ValueOf(cs.info), nil
+}
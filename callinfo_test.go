@@ -0,0 +1,164 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestCallInfo(t *testing.T) {
+	t.Parallel()
+
+	type A struct{ info dig.CallInfo }
+	type B struct{ info dig.CallInfo }
+
+	t.Run("describes the call", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		child := c.Scope("child")
+		require.NoError(t, child.Provide(func(info dig.CallInfo) *A {
+			return &A{info: info}
+		}))
+
+		require.NoError(t, child.Invoke(func(a *A) {
+			assert.Contains(t, a.info.ConsumerLocation, "TestCallInfo")
+			assert.Equal(t, "child", a.info.ScopeName)
+			assert.Equal(t, uint64(1), a.info.InvokeSequence)
+			assert.Equal(t, uint64(1), a.info.CallSequence)
+		}))
+	})
+
+	t.Run("sequences", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func(info dig.CallInfo) *A { return &A{info: info} })
+		c.RequireProvide(func(p struct {
+			dig.In
+
+			Info dig.CallInfo
+			A    *A
+		}) *B {
+			return &B{info: p.Info}
+		})
+
+		c.RequireInvoke(func() {})
+		c.RequireInvoke(func(b *B) {})
+		c.RequireInvoke(func(a *A, b *B) {
+			assert.Empty(t, a.info.ScopeName)
+			assert.Equal(t, uint64(2), a.info.InvokeSequence)
+			assert.Equal(t, uint64(2), b.info.InvokeSequence)
+
+			// B starts before A, which it depends on.
+			assert.Equal(t, uint64(1), b.info.CallSequence)
+			assert.Equal(t, uint64(2), a.info.CallSequence)
+		})
+	})
+
+	t.Run("concurrent invokes", func(t *testing.T) {
+		t.Parallel()
+
+		type X struct{}
+		type Y struct{}
+
+		started, release := make(chan struct{}), make(chan struct{})
+		done := make(chan error)
+		c := digtest.New(t)
+		c.RequireProvide(func(info dig.CallInfo) *A { return &A{info: info} })
+		c.RequireProvide(func() *X {
+			close(started)
+			<-release
+			return &X{}
+		})
+		// The second Invoke is in progress while the first one calls the
+		// constructor of A.
+		c.RequireProvide(func() (*Y, error) {
+			close(release)
+			return &Y{}, <-done
+		})
+
+		var a *A
+		go func() {
+			done <- c.Invoke(func(_ *X, got *A) { a = got })
+		}()
+		<-started
+		c.RequireInvoke(func(*Y) {})
+		assert.Equal(t, uint64(1), a.info.InvokeSequence)
+	})
+
+	t.Run("not cached", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		child1 := c.Scope("child1")
+		child2 := c.Scope("child2")
+		c.RequireProvide(func(info dig.CallInfo) *A { return &A{info: info} })
+
+		var got []string
+		for _, s := range []*digtest.Scope{child1, child2} {
+			require.NoError(t, s.Provide(func(info dig.CallInfo) *B { return &B{info: info} }))
+			require.NoError(t, s.Invoke(func(b *B) {
+				got = append(got, b.info.ScopeName)
+			}))
+		}
+		assert.Equal(t, []string{"child1", "child2"}, got)
+	})
+
+	t.Run("invoke", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Invoke(func(dig.CallInfo) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot depend on dig.CallInfo: only constructors may depend on it")
+	})
+
+	t.Run("decorate", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{} })
+		err := c.Decorate(func(a *A, info dig.CallInfo) *A { return a })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only constructors may depend on it")
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.ForbidCallInfo())
+		err := c.Provide(func(p struct {
+			dig.In
+
+			Info dig.CallInfo
+		}) *A {
+			return &A{info: p.Info}
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot depend on dig.CallInfo: the container does not allow it")
+	})
+}
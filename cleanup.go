@@ -0,0 +1,255 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+
+	"go.uber.org/dig/internal/dot"
+)
+
+// _cleanupFuncType is the type of the function that constructors and
+// invoked functions may depend on to register cleanup functions.
+var _cleanupFuncType = reflect.TypeOf((func(func()))(nil))
+
+// Types of the results that constructors may return to register a cleanup
+// function with the AutoCleanup option, or io.Closer with AutoClose.
+var (
+	_cleanupResultType    = reflect.TypeOf((func())(nil))
+	_ctxCleanupResultType = reflect.TypeOf((func(context.Context) error)(nil))
+	_closerType           = reflect.TypeOf((*io.Closer)(nil)).Elem()
+)
+
+// isCleanupResult reports whether a result of type t is a cleanup function
+// rather than a value to provide.
+func isCleanupResult(t reflect.Type, opts resultOptions) bool {
+	if !opts.Cleanups {
+		return false
+	}
+	return (opts.AutoCleanup && (t == _cleanupResultType || t == _ctxCleanupResultType)) ||
+		(opts.AutoClose && t == _closerType)
+}
+
+// cleanup returns the cleanup function among the values returned by the
+// constructor, or nil if it did not return one.
+func (rl resultList) cleanup(values []reflect.Value) func(context.Context) error {
+	if rl.cleanupIndex < 0 {
+		return nil
+	}
+	v := values[rl.cleanupIndex]
+	if v.IsNil() {
+		return nil
+	}
+	switch f := v.Interface().(type) {
+	case func():
+		return func(context.Context) error {
+			f()
+			return nil
+		}
+	case func(context.Context) error:
+		return f
+	case io.Closer:
+		return func(context.Context) error {
+			return f.Close()
+		}
+	}
+	return nil
+}
+
+// AutoCleanup is a ProvideOption that registers a func() or a
+// func(context.Context) error returned by the constructor, in any position,
+// as a cleanup function to run when RunCleanups is called, instead of
+// providing it. This accepts providers written for wire unchanged.
+//
+//	c.Provide(func() (*sql.DB, func(), error) {
+//	  // ...
+//	}, dig.AutoCleanup())
+//
+// Without this option, such results are provided like other values.
+func AutoCleanup() ProvideOption {
+	return provideAutoCleanupOption{}
+}
+
+type provideAutoCleanupOption struct{}
+
+func (provideAutoCleanupOption) String() string {
+	return "AutoCleanup()"
+}
+
+func (provideAutoCleanupOption) applyProvideOption(opts *provideOptions) {
+	opts.AutoCleanup = true
+}
+
+// AutoClose is a ProvideOption that closes an io.Closer returned by the
+// constructor when RunCleanups is called, instead of providing it.
+//
+//	c.Provide(func() (*Server, io.Closer, error) {
+//	  // ...
+//	}, dig.AutoClose())
+//
+// It may be combined with AutoCleanup. See RunCleanups.
+func AutoClose() ProvideOption {
+	return provideAutoCloseOption{}
+}
+
+type provideAutoCloseOption struct{}
+
+func (provideAutoCloseOption) String() string {
+	return "AutoClose()"
+}
+
+func (provideAutoCloseOption) applyProvideOption(opts *provideOptions) {
+	opts.AutoClose = true
+}
+
+// addCleanup registers a cleanup function to run with RunCleanups.
+func (s *Scope) addCleanup(f func(context.Context) error) {
+	defer s.lock()()
+	root := s.rootScope()
+	root.cleanups = append(root.cleanups, f)
+}
+
+// paramCleanup is a dependency on the cleanup registration function,
+// func(func()), which the container provides itself.
+//
+// Functions registered through it are run by RunCleanups.
+type paramCleanup struct{}
+
+var _ param = paramCleanup{}
+
+func (paramCleanup) String() string {
+	return fmt.Sprint(_cleanupFuncType)
+}
+
+// DotParam returns nothing: the cleanup function is not part of the graph.
+func (paramCleanup) DotParam() []*dot.Param {
+	return nil
+}
+
+func (paramCleanup) Build(c containerStore) (reflect.Value, error) {
+	root := c.storesToRoot()
+	s := root[len(root)-1].(*Scope)
+	return reflect.ValueOf(func(f func()) {
+		s.addCleanup(func(context.Context) error {
+			f()
+			return nil
+		})
+	}), nil
+}
+
+// RunCleanups runs all cleanup functions registered by constructors and
+// invoked functions, in the reverse order of their registration. Cleanup
+// functions run at most once. Errors returned by cleanup functions are
+// ignored; use RunCleanupsContext to receive them.
+//
+// Functions register cleanups by accepting a func(func()) parameter, which
+// the Container provides.
+//
+//	c.Provide(func(cleanup func(func())) (*sql.DB, error) {
+//	  db, err := sql.Open(...)
+//	  if err != nil {
+//	    return nil, err
+//	  }
+//	  cleanup(func() { db.Close() })
+//	  return db, nil
+//	})
+//	defer c.RunCleanups()
+//
+// Constructors provided with the AutoCleanup option may also return a
+// func() or a func(context.Context) error along with their values, in any
+// position, as wire providers do. Such a result is registered as a cleanup
+// function when the constructor succeeds, and is not provided to the
+// Container. A constructor may return at most one cleanup function. See
+// AutoClose to register io.Closers this way.
+func (c *Container) RunCleanups() {
+	_ = c.RunCleanupsContext(context.Background())
+}
+
+// RunCleanupsContext runs all cleanup functions like RunCleanups, passing
+// ctx to those that accept a context.Context. All cleanup functions run
+// even if some of them fail, and their errors are returned together.
+func (c *Container) RunCleanupsContext(ctx context.Context) error {
+	var errs []error
+	s := c.scope
+	for {
+		unlock := s.lock()
+		if len(s.cleanups) == 0 {
+			unlock()
+			break
+		}
+		last := len(s.cleanups) - 1
+		f := s.cleanups[last]
+		s.cleanups = s.cleanups[:last]
+		unlock()
+		if err := f(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	return errCleanupsFailed(errs)
+}
+
+// errCleanupsFailed is returned by RunCleanupsContext when more than one
+// cleanup function failed.
+type errCleanupsFailed []error // inv: len > 1
+
+var _ digError = errCleanupsFailed{}
+
+func (e errCleanupsFailed) Error() string { return fmt.Sprint(e) }
+
+// Unwrap returns the errors of all failed cleanup functions, in the order
+// that they ran.
+func (e errCleanupsFailed) Unwrap() []error { return e }
+
+func (e errCleanupsFailed) writeMessage(w io.Writer, _ string) {
+	fmt.Fprintf(w, "%d cleanup functions failed", len(e))
+}
+
+func (e errCleanupsFailed) Format(w fmt.State, c rune) {
+	e.writeMessage(w, "%v")
+
+	// As with errGroupMembersFailed, each failure is listed on its own
+	// line with %+v, and separated by semicolons otherwise.
+	if w.Flag('+') && c == 'v' {
+		io.WriteString(w, ":")
+		for i, err := range e {
+			fmt.Fprintf(w, "\n  - [%d] %+v", i+1, err)
+		}
+		return
+	}
+
+	io.WriteString(w, ": ")
+	for i, err := range e {
+		if i > 0 {
+			io.WriteString(w, "; ")
+		}
+		fmt.Fprintf(w, "[%d] %v", i+1, err)
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestRunCleanups(t *testing.T) {
+	t.Parallel()
+
+	t.Run("runs in reverse order", func(t *testing.T) {
+		type A struct{}
+		type B struct{}
+
+		var calls []string
+		c := digtest.New(t)
+		c.RequireProvide(func(cleanup func(func())) *A {
+			cleanup(func() { calls = append(calls, "A") })
+			return &A{}
+		})
+		c.RequireProvide(func(_ *A, cleanup func(func())) *B {
+			cleanup(func() { calls = append(calls, "B") })
+			return &B{}
+		})
+		c.RequireInvoke(func(_ *B, cleanup func(func())) {
+			cleanup(func() { calls = append(calls, "invoke") })
+		})
+		assert.Empty(t, calls, "cleanups must not run until requested")
+
+		c.RunCleanups()
+		assert.Equal(t, []string{"invoke", "B", "A"}, calls)
+
+		c.RunCleanups()
+		assert.Len(t, calls, 3, "cleanups must run at most once")
+	})
+
+	t.Run("dig.In field", func(t *testing.T) {
+		var ran bool
+		c := digtest.New(t)
+		c.Scope("child").RequireInvoke(func(p struct {
+			dig.In
+
+			Cleanup func(func())
+		}) {
+			p.Cleanup(func() { ran = true })
+		})
+
+		c.RunCleanups()
+		assert.True(t, ran, "cleanups registered in scopes must be run by the container")
+	})
+
+	t.Run("cannot be provided", func(t *testing.T) {
+		c := digtest.New(t)
+		err := c.Provide(func() func(func()) { return nil })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot provide func(func()): it is provided by the container to register cleanup functions")
+	})
+}
+
+func TestCleanupResults(t *testing.T) {
+	t.Parallel()
+
+	type A struct{}
+	type B struct{}
+
+	t.Run("any position", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		c := digtest.New(t)
+		c.RequireProvide(func() (*A, func(), error) {
+			return &A{}, func() { calls = append(calls, "A") }, nil
+		}, dig.AutoCleanup())
+		c.RequireProvide(func(*A) (func(context.Context) error, *B) {
+			return func(context.Context) error {
+				calls = append(calls, "B")
+				return nil
+			}, &B{}
+		}, dig.AutoCleanup())
+		c.RequireInvoke(func(*B) {})
+
+		require.NoError(t, c.RunCleanupsContext(context.Background()))
+		assert.Equal(t, []string{"B", "A"}, calls)
+	})
+
+	t.Run("cleanups are not provided", func(t *testing.T) {
+		t.Parallel()
+
+		var info dig.ProvideInfo
+		c := digtest.New(t)
+		c.RequireProvide(func() (func(), *A) {
+			return func() {}, &A{}
+		}, dig.AutoCleanup(), dig.FillProvideInfo(&info))
+		require.Len(t, info.Outputs, 1)
+		assert.Equal(t, "*dig_test.A", info.Outputs[0].String())
+
+		err := c.Invoke(func(func()) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: func()")
+	})
+
+	t.Run("not registered on failure", func(t *testing.T) {
+		t.Parallel()
+
+		ran := false
+		c := digtest.New(t)
+		c.RequireProvide(func() (*A, func(), error) {
+			return nil, func() { ran = true }, errors.New("great sadness")
+		}, dig.AutoCleanup())
+		require.Error(t, c.Invoke(func(*A) {}))
+
+		c.RunCleanups()
+		assert.False(t, ran, "cleanup of failed constructor must not run")
+	})
+
+	t.Run("nil cleanup", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (*A, func()) { return &A{}, nil }, dig.AutoCleanup())
+		c.RequireInvoke(func(*A) {})
+		assert.NoError(t, c.RunCleanupsContext(context.Background()))
+	})
+
+	t.Run("context and errors", func(t *testing.T) {
+		t.Parallel()
+
+		type key struct{}
+		ctx := context.WithValue(context.Background(), key{}, "value")
+
+		errA := errors.New("A failed")
+		errB := errors.New("B failed")
+		c := digtest.New(t)
+		c.RequireProvide(func() (*A, func(context.Context) error) {
+			return &A{}, func(ctx context.Context) error {
+				assert.Equal(t, "value", ctx.Value(key{}))
+				return errA
+			}
+		}, dig.AutoCleanup())
+		c.RequireProvide(func(*A) (*B, func(context.Context) error) {
+			return &B{}, func(context.Context) error { return errB }
+		}, dig.AutoCleanup())
+		c.RequireInvoke(func(*B) {})
+
+		err := c.RunCleanupsContext(ctx)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, errA)
+		assert.ErrorIs(t, err, errB)
+		assert.Equal(t, "2 cleanup functions failed: [1] B failed; [2] A failed", err.Error())
+		assert.Equal(t, "2 cleanup functions failed:\n  - [1] B failed\n  - [2] A failed", fmt.Sprintf("%+v", err))
+	})
+
+	t.Run("AutoClose", func(t *testing.T) {
+		t.Parallel()
+
+		var closed bool
+		c := digtest.New(t)
+		c.RequireProvide(func() (*A, io.Closer, error) {
+			return &A{}, closerFunc(func() error {
+				closed = true
+				return nil
+			}), nil
+		}, dig.AutoClose())
+		c.RequireInvoke(func(*A) {})
+		assert.False(t, closed)
+
+		require.NoError(t, c.RunCleanupsContext(context.Background()))
+		assert.True(t, closed)
+		assert.Equal(t, "AutoClose()", fmt.Sprint(dig.AutoClose()))
+	})
+
+	t.Run("io.Closer is provided without AutoClose", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (*A, io.Closer) {
+			return &A{}, closerFunc(func() error { return nil })
+		})
+		c.RequireInvoke(func(io.Closer) {})
+	})
+
+	t.Run("functions are provided without AutoCleanup", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			Stop  func()                        `name:"stop"`
+			Hooks []func(context.Context) error `group:"hooks"`
+		}
+
+		var stopped bool
+		c := digtest.New(t)
+		c.RequireProvide(func() (*A, func()) {
+			return &A{}, func() { stopped = true }
+		})
+		c.RequireProvide(func() func() { return func() {} }, dig.Name("stop"))
+		c.RequireProvide(func() func(context.Context) error {
+			return func(context.Context) error { return nil }
+		}, dig.Group("hooks"))
+		c.RequireInvoke(func(_ *A, stop func(), p params) {
+			stop()
+			assert.NotNil(t, p.Stop)
+			assert.Len(t, p.Hooks, 1)
+		})
+		assert.True(t, stopped)
+		assert.Equal(t, "AutoCleanup()", fmt.Sprint(dig.AutoCleanup()))
+	})
+
+	t.Run("more than one cleanup", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Provide(func() (func(), *A, func()) { return nil, nil, nil }, dig.AutoCleanup())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			"cannot return more than one cleanup function: results 1 (func()) and 3 (func()) of func() (func(), *dig_test.A, func()) are both cleanup functions")
+
+		err = c.Provide(func() (*A, func(), io.Closer) { return nil, nil, nil }, dig.AutoCleanup(), dig.AutoClose())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot return more than one cleanup function")
+	})
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }
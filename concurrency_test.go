@@ -0,0 +1,227 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestConcurrentInvoke(t *testing.T) {
+	t.Parallel()
+
+	type DB struct{}
+	type Cache struct{}
+
+	// waitFor fails the test instead of hanging if ch is not closed in
+	// time.
+	waitFor := func(t *testing.T, ch <-chan struct{}, msg string) {
+		t.Helper()
+		select {
+		case <-ch:
+		case <-time.After(5 * time.Second):
+			t.Error(msg)
+		}
+	}
+
+	t.Run("disjoint subgraphs overlap", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			dbStarted = make(chan struct{})
+			cacheDone = make(chan struct{})
+		)
+		c := digtest.New(t)
+		c.RequireProvide(func() *DB {
+			close(dbStarted)
+			// The slow constructor only completes once the cache was built
+			// by another goroutine, which proves that they overlap.
+			waitFor(t, cacheDone, "cache was not built while the DB was being built")
+			return &DB{}
+		})
+		c.RequireProvide(func() *Cache { return &Cache{} })
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, c.Invoke(func(*DB) {}))
+		}()
+		go func() {
+			defer wg.Done()
+			waitFor(t, dbStarted, "DB was not built")
+			assert.NoError(t, c.Invoke(func(*Cache) {}))
+			close(cacheDone)
+		}()
+		wg.Wait()
+	})
+
+	t.Run("same constructor is called once", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			calls   int32
+			started = make(chan struct{})
+			release = make(chan struct{})
+		)
+		c := digtest.New(t)
+		c.RequireProvide(func() *DB {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				close(started)
+			}
+			waitFor(t, release, "DB was not released")
+			return &DB{}
+		})
+
+		const n = 8
+		dbs := make([]*DB, n)
+		var wg sync.WaitGroup
+		wg.Add(n)
+		for i := 0; i < n; i++ {
+			go func(i int) {
+				defer wg.Done()
+				assert.NoError(t, c.Invoke(func(db *DB) { dbs[i] = db }))
+			}(i)
+		}
+
+		waitFor(t, started, "DB was not built")
+		// Give the other goroutines a chance to wait for the call in
+		// progress.
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		for _, db := range dbs {
+			assert.Same(t, dbs[0], db)
+		}
+	})
+
+	t.Run("errors are delivered to all waiters", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			calls   int32
+			started = make(chan struct{})
+			release = make(chan struct{})
+		)
+		c := digtest.New(t)
+		c.RequireProvide(func() (*DB, error) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				close(started)
+			}
+			waitFor(t, release, "DB was not released")
+			return nil, errors.New("great sadness")
+		})
+
+		errs := make([]error, 2)
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			errs[0] = c.Invoke(func(*DB) {})
+		}()
+		go func() {
+			defer wg.Done()
+			waitFor(t, started, "DB was not built")
+			errs[1] = c.Invoke(func(*DB) {})
+		}()
+
+		waitFor(t, started, "DB was not built")
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		for _, err := range errs {
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "great sadness")
+		}
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("group and named map parameters", func(t *testing.T) {
+		t.Parallel()
+
+		type Handler string
+		type params struct {
+			dig.In
+
+			Grouped []Handler          `group:"handlers"`
+			Named   map[string]Handler `names:"*"`
+		}
+		type missing struct {
+			dig.In
+
+			Grouped []Handler `group:"handlers"`
+			Cache   *Cache
+		}
+
+		for _, opts := range [][]dig.Option{nil, {dig.DeferAcyclicVerification()}} {
+			c := digtest.New(t, opts...)
+			c.RequireProvide(func() Handler { return "users" }, dig.Group("handlers"))
+			c.RequireProvide(func() Handler { return "orders" }, dig.Name("orders"))
+			child := c.Scope("child")
+
+			// Each Invoke adds graph nodes for its parameters while the
+			// others walk the graph.
+			var (
+				wg    sync.WaitGroup
+				start = make(chan struct{})
+			)
+			for i := 0; i < 32; i++ {
+				wg.Add(4)
+				go func() {
+					defer wg.Done()
+					<-start
+					assert.NoError(t, c.Invoke(func(p params) {
+						assert.Equal(t, []Handler{"users"}, p.Grouped)
+						assert.Equal(t, map[string]Handler{"orders": "orders"}, p.Named)
+					}))
+				}()
+				go func() {
+					defer wg.Done()
+					<-start
+					assert.NoError(t, child.Invoke(func(params) {}))
+				}()
+				go func() {
+					defer wg.Done()
+					<-start
+					assert.Error(t, c.Invoke(func(missing) {}))
+				}()
+				go func() {
+					defer wg.Done()
+					<-start
+					assert.Error(t, child.Invoke(func(missing) {}))
+				}()
+			}
+			close(start)
+			wg.Wait()
+		}
+	})
+}
@@ -35,6 +35,13 @@ import (
 // constructorNodes can produce zero or more values that they store into the container.
 // For the Provide path, we verify that constructorNodes produce at least one value,
 // otherwise the function will never be called.
+//
+// A constructorNode does not change once it is created, except for its
+// order in the graphs of Scopes, which is kept per Scope. The state of
+// calling it, such as whether it was called, calls in progress, and the
+// values it produced, lives in the Scope that owns it, keyed by the node.
+// Providing the same function to several Containers or Scopes creates a
+// separate node for each of them, with independent lifecycles.
 type constructorNode struct {
 	ctor  interface{}
 	ctype reflect.Type
@@ -42,11 +49,18 @@ type constructorNode struct {
 	// Location where this function was defined.
 	location *digreflect.Func
 
-	// id uniquely identifies the constructor that produces a node.
+	// id uniquely identifies the node among the constructors of its root
+	// Scope. It is the sequence number of the node rather than the address
+	// of the function, which all functions built with reflect.MakeFunc
+	// share.
 	id dot.CtorID
 
-	// Whether the constructor owned by this node was already called.
-	called bool
+	// fn is the address of the function.
+	fn uintptr
+
+	// seq numbers the constructors of a root Scope in the order they were
+	// provided, starting from 1.
+	seq uint64
 
 	// Type information about constructor parameters.
 	paramList paramList
@@ -63,6 +77,51 @@ type constructorNode struct {
 	// scope this node was originally provided to.
 	// This is different from s if and only if the constructor was Provided with ExportOption.
 	origS *Scope
+
+	// Value group that failures of this constructor are submitted to, if
+	// any.
+	errorGroup string
+
+	// Metadata attached to this constructor with the Tags option.
+	tags map[string]string
+
+	// Tag of the values this constructor submits to value groups, set with
+	// the GroupTag option.
+	groupTag string
+
+	// Labels of the values this constructor submits to value groups, set
+	// with the GroupLabels option.
+	groupLabels []string
+
+	// Weight of the values this constructor submits to value groups, set
+	// with the Weight option.
+	groupWeight int
+
+	// Whether this constructor is called by Invoke even if nothing depends
+	// on it.
+	eager bool
+
+	// Whether this constructor depends on a CallInfo.
+	callInfo bool
+
+	// Predicate set with the GroupUnless option, and the key of the value
+	// group it applies to. The constructor is not called if it reports
+	// true for the values of the group.
+	groupUnless    func([]reflect.Value) bool
+	groupUnlessKey key
+
+	// Options that this constructor was provided with, used to provide it
+	// to other Containers with Import.
+	provideOpts provideOptions
+}
+
+// inflightCall is a call of a constructor that is in progress.
+type inflightCall struct {
+	// Closed when the call completes.
+	done chan struct{}
+
+	// Result of the call, set before done is closed.
+	err error
 }
 
 type constructorOptions struct {
@@ -72,6 +131,58 @@ type constructorOptions struct {
 	ResultGroup string
 	ResultAs    []interface{}
 	Location    *digreflect.Func
+
+	// Whether values are also provided as the interfaces registered with
+	// RegisterInterface that they implement.
+	AsImplemented bool
+
+	// If specified, names the values produced by this constructor that
+	// were not named otherwise.
+	ResultNameFunc func(reflect.Type) string
+
+	// If specified, struct tags to apply to the positional parameters and
+	// non-error results of this constructor.
+	ParamTags  []string
+	ResultTags []string
+
+	// If specified, failures of this constructor are submitted to this
+	// value group instead of being returned.
+	ErrorGroup string
+
+	// Metadata attached to the constructor with the Tags option.
+	Tags map[string]string
+
+	// If specified, namespace of all value groups this constructor
+	// provides.
+	GroupNamespace string
+
+	// If specified, tag of all values this constructor provides to value
+	// groups.
+	GroupTag string
+
+	// If specified, labels of all values this constructor provides to value
+	// groups.
+	GroupLabels []string
+
+	// If specified, weight of all values this constructor provides to value
+	// groups.
+	GroupWeight int
+
+	// If true, the constructor is called by Invoke even if nothing depends
+	// on it.
+	Eager bool
+
+	// If true, a func() or func(context.Context) error returned by the
+	// constructor is run by RunCleanups instead of being provided.
+	AutoCleanup bool
+
+	// If true, an io.Closer returned by the constructor is closed by
+	// RunCleanups instead of being provided.
+	AutoClose bool
+
+	// If true, nil values are left out of the value groups that the
+	// constructor provides to.
+	OmitNilFromGroup bool
 }
 
 func newConstructorNode(ctor interface{}, s *Scope, origS *Scope, opts constructorOptions) (*constructorNode, error) {
@@ -83,13 +194,33 @@ func newConstructorNode(ctor interface{}, s *Scope, origS *Scope, opts construct
 	if err != nil {
 		return nil, err
 	}
+	if len(opts.ParamTags) > 0 {
+		params, err = params.withTags(opts.ParamTags, s)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := checkNoContextParams(params); err != nil {
+		return nil, err
+	}
 
 	results, err := newResultList(
 		ctype,
 		resultOptions{
-			Name:  opts.ResultName,
-			Group: opts.ResultGroup,
-			As:    opts.ResultAs,
+			Name:           opts.ResultName,
+			NameFunc:       opts.ResultNameFunc,
+			Cleanups:       true,
+			AutoCleanup:    opts.AutoCleanup,
+			AutoClose:      opts.AutoClose,
+			NamedResults:   true,
+			Group:          opts.ResultGroup,
+			As:             opts.ResultAs,
+			AsImplemented:  opts.AsImplemented,
+			Tags:           s.tagKeys(),
+			ResultTags:     opts.ResultTags,
+			GroupNamespace: opts.GroupNamespace,
+
+			OmitNilFromGroup: opts.OmitNilFromGroup,
 		},
 	)
 	if err != nil {
@@ -101,28 +232,64 @@ func newConstructorNode(ctor interface{}, s *Scope, origS *Scope, opts construct
 		location = digreflect.InspectFunc(ctor)
 	}
 
+	root := s.rootScope()
+	root.provideSeq++
 	n := &constructorNode{
-		ctor:       ctor,
-		ctype:      ctype,
-		location:   location,
-		id:         dot.CtorID(cptr),
-		paramList:  params,
-		resultList: results,
-		orders:     make(map[*Scope]int),
-		s:          s,
-		origS:      origS,
+		ctor:        ctor,
+		ctype:       ctype,
+		location:    location,
+		id:          dot.CtorID(root.provideSeq),
+		fn:          cptr,
+		seq:         root.provideSeq,
+		paramList:   params,
+		resultList:  results,
+		orders:      make(map[*Scope]int),
+		s:           s,
+		origS:       origS,
+		errorGroup:  opts.ErrorGroup,
+		tags:        opts.Tags,
+		groupTag:    opts.GroupTag,
+		groupLabels: opts.GroupLabels,
+		groupWeight: opts.GroupWeight,
+		eager:       opts.Eager,
+		callInfo:    usesCallInfo(params.Params...),
 	}
 	s.newGraphNode(n, n.orders)
 	return n, nil
 }
 
 func (n *constructorNode) Location() *digreflect.Func { return n.location }
-func (n *constructorNode) ParamList() paramList       { return n.paramList }
-func (n *constructorNode) ResultList() resultList     { return n.resultList }
-func (n *constructorNode) ID() dot.CtorID             { return n.id }
-func (n *constructorNode) CType() reflect.Type        { return n.ctype }
-func (n *constructorNode) Order(s *Scope) int         { return n.orders[s] }
-func (n *constructorNode) OrigScope() *Scope          { return n.origS }
+
+// groupMember returns information about this constructor for the values it
+// submits to value groups.
+func (n *constructorNode) groupMember() groupMember {
+	return groupMember{
+		Source: n.location,
+		Tag:    n.groupTag,
+		Labels: n.groupLabels,
+		Weight: n.groupWeight,
+		Seq:    n.seq,
+	}
+}
+func (n *constructorNode) ParamList() paramList   { return n.paramList }
+func (n *constructorNode) ResultList() resultList { return n.resultList }
+func (n *constructorNode) ID() dot.CtorID         { return n.id }
+func (n *constructorNode) CType() reflect.Type    { return n.ctype }
+func (n *constructorNode) Order(s *Scope) int     { return n.orders[s] }
+func (n *constructorNode) OrigScope() *Scope      { return n.origS }
+
+// Tags returns a copy of the metadata attached to this constructor, or nil
+// if there is none.
+func (n *constructorNode) Tags() map[string]string {
+	if len(n.tags) == 0 {
+		return nil
+	}
+	tags := make(map[string]string, len(n.tags))
+	for k, v := range n.tags {
+		tags[k] = v
+	}
+	return tags
+}
 
 func (n *constructorNode) String() string {
 	return fmt.Sprintf("deps: %v, ctor: %v", n.paramList, n.ctype)
@@ -130,8 +297,60 @@ func (n *constructorNode) String() string {
 
 // Call calls this constructor if it hasn't already been called and
 // injects any values produced by it into the provided container.
-func (n *constructorNode) Call(c containerStore) (err error) {
-	if n.called {
+//
+// If another goroutine is already calling this constructor, Call waits for
+// that call to complete and returns its result instead of calling the
+// constructor again. Calls of different constructors run concurrently.
+//
+// Under CacheFailures, Call returns the error that an earlier call failed
+// with instead of calling the constructor again.
+func (n *constructorNode) Call(c containerStore) error {
+	if err := n.s.cachedFailure(n); err != nil {
+		return err
+	}
+
+	fl, start := n.s.beginCall(n)
+	if !start {
+		if fl == nil {
+			return nil
+		}
+		// A frame of this call, which may have been kept by a Resolver
+		// after an earlier call returned, only closes a cycle while the
+		// constructor is being called.
+		if path := c.callFrame().cycleTo(n); path != nil {
+			return errCycleDetected{Path: path, scope: n.s}
+		}
+		<-fl.done
+		return fl.err
+	}
+	defer n.s.endCall(n, fl)
+
+	fl.err = n.call(c)
+	if fl.err != nil {
+		if len(n.errorGroup) > 0 {
+			n.report(fl.err)
+			fl.err = nil
+		} else {
+			n.s.cacheFailure(n, fl.err)
+		}
+	}
+	return fl.err
+}
+
+// closesCycle reports whether the constructor is on a cycle of the graph,
+// through optional dependencies, that has a constructor being called.
+func (n *constructorNode) closesCycle() bool {
+	for _, cn := range n.s.gh.cycleWith(n.Order(n.s)) {
+		if cn.s.isCalling(cn) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *constructorNode) call(c containerStore) (err error) {
+	if n.groupUnless != nil && n.skipsGroup() {
+		n.s.markCalled(n)
 		return nil
 	}
 
@@ -153,7 +372,22 @@ func (n *constructorNode) Call(c containerStore) (err error) {
 		}()
 	}
 
-	args, err := n.paramList.BuildList(c)
+	root := n.s.rootScope()
+	frame, err := root.enterResolution(c, n)
+	if err != nil {
+		return err
+	}
+	defer root.trackCall(frame.inv)()
+
+	var store containerStore = frameStore{containerStore: c, frame: frame}
+	if n.callInfo {
+		store = callInfoStore{containerStore: store, info: n.s.callInfoFor(n, frame)}
+	}
+
+	args, err := n.paramList.BuildList(store)
+	if err == nil {
+		err = root.checkSharedMutations(n.location, n.paramList, args)
+	}
 	if err != nil {
 		return errArgumentsFailed{
 			Func:   n.location,
@@ -167,29 +401,65 @@ func (n *constructorNode) Call(c containerStore) (err error) {
 		return errConstructorFailed{Func: n.location, Reason: err}
 	}
 
+	if root.checkNilInterfaces {
+		if k, t, ok := receiver.findTypedNil(); ok {
+			return errTypedNilInterface{Func: n.location, Key: k, Type: t}
+		}
+	}
+
+	if n.resultList.returnsNamedResults() {
+		if err := n.s.commitNamedResults(n, receiver.values); err != nil {
+			return errConstructorFailed{Func: n.location, Reason: err}
+		}
+	}
+
 	// Commit the result to the original container that this constructor
 	// was supplied to. The provided constructor is only used for a view of
 	// the rest of the graph to instantiate the dependencies of this
 	// container.
-	receiver.Commit(n.s)
-	n.called = true
+	receiver.Commit(n.s, n.groupMember())
+	n.s.markCalled(n)
+	if f := n.resultList.cleanup(results); f != nil {
+		root.addCleanup(f)
+	}
 
 	return nil
 }
 
+// report submits the failure of this constructor to its error group in
+// place of its results.
+func (n *constructorNode) report(err error) {
+	var rerr error = ReportedError{
+		Package:  n.location.Package,
+		Function: n.location.Name,
+		File:     n.location.File,
+		Line:     n.location.Line,
+		Err:      err,
+	}
+	n.s.submitGroupedValueFrom(n.errorGroup, _errType, reflect.ValueOf(&rerr).Elem(), n.groupMember())
+	n.s.markFailed(n, err)
+}
+
 // stagingContainerWriter is a containerWriter that records the changes that
 // would be made to a containerWriter and defers them until Commit is called.
 type stagingContainerWriter struct {
 	values map[key]reflect.Value
 	groups map[key][]reflect.Value
+
+	// Labels of each value in groups, at the same index.
+	groupLabels map[key][][]string
+
+	// Value groups that nil values were left out of.
+	omitted []key
 }
 
 var _ containerWriter = (*stagingContainerWriter)(nil)
 
 func newStagingContainerWriter() *stagingContainerWriter {
 	return &stagingContainerWriter{
-		values: make(map[key]reflect.Value),
-		groups: make(map[key][]reflect.Value),
+		values:      make(map[key]reflect.Value),
+		groups:      make(map[key][]reflect.Value),
+		groupLabels: make(map[key][][]string),
 	}
 }
 
@@ -201,24 +471,97 @@ func (sr *stagingContainerWriter) setDecoratedValue(_ string, _ reflect.Type, _
 	digerror.BugPanicf("stagingContainerWriter.setDecoratedValue must never be called")
 }
 
-func (sr *stagingContainerWriter) submitGroupedValue(group string, t reflect.Type, v reflect.Value) {
+func (sr *stagingContainerWriter) submitGroupedValue(group string, t reflect.Type, v reflect.Value, labels []string) {
 	k := key{t: t, group: group}
 	sr.groups[k] = append(sr.groups[k], v)
+	sr.groupLabels[k] = append(sr.groupLabels[k], labels)
+}
+
+func (sr *stagingContainerWriter) submitGroupedValueFrom(_ string, _ reflect.Type, _ reflect.Value, _ groupMember) {
+	digerror.BugPanicf("stagingContainerWriter.submitGroupedValueFrom must never be called")
 }
 
 func (sr *stagingContainerWriter) submitDecoratedGroupedValue(_ string, _ reflect.Type, _ reflect.Value) {
 	digerror.BugPanicf("stagingContainerWriter.submitDecoratedGroupedValue must never be called")
 }
 
-// Commit commits the received results to the provided containerWriter.
-func (sr *stagingContainerWriter) Commit(cw containerWriter) {
+func (sr *stagingContainerWriter) omitGroupedValue(group string, t reflect.Type) {
+	sr.omitted = append(sr.omitted, key{t: t, group: group})
+}
+
+func (sr *stagingContainerWriter) omitGroupedValueFrom(_ string, _ reflect.Type, _ groupMember) {
+	digerror.BugPanicf("stagingContainerWriter.omitGroupedValueFrom must never be called")
+}
+
+// findTypedNil searches the received results for a value of an interface
+// type that wraps a nil value, and returns its key and the type of the
+// wrapped value.
+func (sr *stagingContainerWriter) findTypedNil() (key, reflect.Type, bool) {
+	var (
+		found  bool
+		foundK key
+		foundT reflect.Type
+	)
+	// Report the same value every time if there are several.
+	check := func(k key, v reflect.Value) {
+		if k.t.Kind() != reflect.Interface {
+			return
+		}
+		t, ok := typedNil(v)
+		if !ok || (found && foundK.String() <= k.String()) {
+			return
+		}
+		found, foundK, foundT = true, k, t
+	}
+
+	for k, v := range sr.values {
+		check(k, v)
+	}
+	for k, vs := range sr.groups {
+		for _, v := range vs {
+			check(k, v)
+		}
+	}
+	return foundK, foundT, found
+}
+
+// typedNil reports whether v is, or is an interface that wraps, a nil
+// pointer, map, slice, channel, or function, and returns the type of that
+// nil value.
+func typedNil(v reflect.Value) (reflect.Type, bool) {
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func:
+		return v.Type(), v.IsNil()
+	default:
+		return nil, false
+	}
+}
+
+// Commit commits the received results to the provided containerWriter,
+// recording m as information about the constructor that produced their
+// grouped values.
+func (sr *stagingContainerWriter) Commit(cw containerWriter, m groupMember) {
 	for k, v := range sr.values {
 		cw.setValue(k.name, k.t, v)
 	}
 
 	for k, vs := range sr.groups {
-		for _, v := range vs {
-			cw.submitGroupedValue(k.group, k.t, v)
+		for i, v := range vs {
+			m := m
+			m.Labels = mergeGroupLabels(m.Labels, sr.groupLabels[k][i])
+			m.Index = i
+			cw.submitGroupedValueFrom(k.group, k.t, v, m)
 		}
 	}
+
+	for _, k := range sr.omitted {
+		cw.omitGroupedValueFrom(k.group, k.t, m)
+	}
 }
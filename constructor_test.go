@@ -58,10 +58,77 @@ func TestNodeAlreadyCalled(t *testing.T) {
 	s := newScope()
 	n, err := newConstructorNode(f, s, s, constructorOptions{})
 	require.NoError(t, err, "failed to build node")
-	require.False(t, n.called, "node must not have been called")
+	require.False(t, s.wasCalled(n), "node must not have been called")
 
 	c := New()
 	require.NoError(t, n.Call(c.scope), "invoke failed")
-	require.True(t, n.called, "node must be called")
+	require.True(t, s.wasCalled(n), "node must be called")
 	require.NoError(t, n.Call(c.scope), "calling again should be okay")
 }
+
+func TestConstructorProvidedToSeveralContainers(t *testing.T) {
+	type config struct{ name string }
+	type service struct{ name string }
+
+	calls := make(map[string]int)
+	newService := func(cfg *config) *service {
+		calls[cfg.name]++
+		return &service{name: cfg.name}
+	}
+
+	containers := make([]*Container, 3)
+	for i, name := range []string{"a", "b", "c"} {
+		name := name
+		c := New()
+		require.NoError(t, c.Provide(func() *config { return &config{name: name} }))
+		require.NoError(t, c.Provide(newService))
+		containers[i] = c
+	}
+
+	for i, name := range []string{"a", "b", "c"} {
+		for j := 0; j < 2; j++ {
+			require.NoError(t, containers[i].Invoke(func(s *service) {
+				assert.Equal(t, name, s.name)
+			}))
+		}
+	}
+	assert.Equal(t, map[string]int{"a": 1, "b": 1, "c": 1}, calls,
+		"each container must call the constructor once")
+
+	nodes := make(map[*constructorNode]struct{})
+	for _, c := range containers {
+		require.Len(t, c.scope.nodes, 2)
+		n := c.scope.nodes[1]
+		nodes[n] = struct{}{}
+
+		assert.True(t, c.scope.wasCalled(n))
+		assert.Len(t, c.scope.calledCtors, 2, "only the container's own nodes must be called")
+		assert.Empty(t, c.scope.inflightCtors)
+	}
+	assert.Len(t, nodes, 3, "each container must have its own node")
+}
+
+func TestConstructorProvidedToSiblingScopes(t *testing.T) {
+	type service struct{ id int }
+
+	calls := 0
+	newService := func() *service {
+		calls++
+		return &service{id: calls}
+	}
+
+	c := New()
+	first, second := c.Scope("first"), c.Scope("second")
+	require.NoError(t, first.Provide(newService))
+	require.NoError(t, second.Provide(newService))
+
+	var fromFirst, fromSecond *service
+	require.NoError(t, first.Invoke(func(s *service) { fromFirst = s }))
+	assert.Equal(t, 1, calls)
+	assert.False(t, second.wasCalled(second.nodes[0]), "calling one scope's node must not affect the other")
+
+	require.NoError(t, second.Invoke(func(s *service) { fromSecond = s }))
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, 1, fromFirst.id)
+	assert.Equal(t, 2, fromSecond.id)
+}
@@ -53,6 +53,31 @@ func (k key) String() string {
 	return k.t.String()
 }
 
+// tagKeys are the struct tag keys that dig reads from the fields of dig.In
+// and dig.Out structs. Empty keys use the defaults.
+type tagKeys struct {
+	Name  string
+	Group string
+
+	// If set, keys that look like misspellings of the keys dig reads are
+	// allowed. Set by the LenientTags option.
+	Lenient bool
+}
+
+func (tk tagKeys) name() string {
+	if tk.Name == "" {
+		return _nameTag
+	}
+	return tk.Name
+}
+
+func (tk tagKeys) group() string {
+	if tk.Group == "" {
+		return _groupTag
+	}
+	return tk.Group
+}
+
 // Option configures a Container.
 type Option interface {
 	applyOption(*Container)
@@ -81,12 +106,26 @@ type containerWriter interface {
 	setDecoratedValue(name string, t reflect.Type, v reflect.Value)
 
 	// submitGroupedValue submits a value to the value group with the provided
-	// name.
-	submitGroupedValue(name string, t reflect.Type, v reflect.Value)
+	// name and labels.
+	submitGroupedValue(name string, t reflect.Type, v reflect.Value, labels []string)
+
+	// submitGroupedValueFrom submits a value to the value group with the
+	// provided name, recording information about the constructor that
+	// produced it.
+	submitGroupedValueFrom(name string, t reflect.Type, v reflect.Value, m groupMember)
 
 	// submitDecoratedGroupedValue submits a decorated value to the value group
 	// with the provided name.
 	submitDecoratedGroupedValue(name string, t reflect.Type, v reflect.Value)
+
+	// omitGroupedValue records that a nil value was left out of the value
+	// group with the provided name.
+	omitGroupedValue(name string, t reflect.Type)
+
+	// omitGroupedValueFrom records that a nil value was left out of the
+	// value group with the provided name, along with information about the
+	// constructor that produced it.
+	omitGroupedValueFrom(name string, t reflect.Type, m groupMember)
 }
 
 // containerStore provides access to the Container's underlying data store.
@@ -110,6 +149,12 @@ type containerStore interface {
 	// The order in which the values are returned is undefined.
 	getValueGroup(name string, t reflect.Type) []reflect.Value
 
+	// Retrieves all values for the provided group and type along with the
+	// functions that produced them.
+	//
+	// The order in which the values are returned is undefined.
+	getValueGroupEntries(name string, t reflect.Type) []groupEntry
+
 	// Retrieves all decorated values for the provided group and type, if any.
 	getDecoratedValueGroup(name string, t reflect.Type) (reflect.Value, bool)
 
@@ -117,6 +162,18 @@ type containerStore interface {
 	// type.
 	getValueProviders(name string, t reflect.Type) []provider
 
+	// Returns the names of all named values of the given type that have
+	// providers in this containerStore.
+	getValueNames(t reflect.Type) []string
+
+	// Returns the constructors that return NamedResults in this
+	// containerStore.
+	getNamedResultsProviders() []provider
+
+	// Returns the names of all named values of the given type that were
+	// provided through NamedResults in this containerStore.
+	getNamedResultNames(t reflect.Type) []string
+
 	// Returns the providers that can produce values for the given group and
 	// type.
 	getGroupProviders(name string, t reflect.Type) []provider
@@ -137,10 +194,41 @@ type containerStore interface {
 	// store.
 	storesToRoot() []containerStore
 
+	// Returns the frame of the call whose parameters are being built from
+	// this store, or nil if none.
+	callFrame() *callFrame
+
+	// Notes the error encountered while building the value with the given
+	// key, or forgets it if err is nil.
+	recordBuildError(k key, err error)
+
+	// Reports whether the LazyOptionals option is in effect.
+	buildsOptionalsLazily() bool
+
+	// Returns the middleware set with the WithParamResolver option.
+	paramResolverMiddleware() []func(ResolveFunc) ResolveFunc
+
+	// Reports whether the PreferMostDerived option is in effect.
+	prefersMostDerived() bool
+
+	// Reports whether the CopySharedValues option is in effect.
+	copiesSharedValues() bool
+
+	// Notes that the value with the given key was satisfied with a value of
+	// type t under PreferMostDerived.
+	recordDerived(k key, t reflect.Type)
+
 	createGraph() *dot.Graph
 
 	// Returns invokerFn function to use when calling arguments.
 	invoker() invokerFn
+
+	// Returns a random number in [0, n) from the source of randomness of
+	// the container.
+	randIntn(n int) int
+
+	// Returns the struct tag keys to read from dig.In and dig.Out fields.
+	tagKeys() tagKeys
 }
 
 // New constructs a Container.
@@ -159,6 +247,11 @@ func New(opts ...Option) *Container {
 // run after each call to container.Provide. The container will instead verify
 // the graph on first `Invoke`.
 //
+// Invoke only verifies the part of the graph that the invoked function
+// depends on, so a cycle among constructors that it does not need is not
+// reported. Later calls of Invoke only verify the constructors that were
+// not verified by earlier calls since the last call to Provide.
+//
 // Applications adding providers to a container in a tight loop may experience
 // performance improvements by initializing the container with this option.
 func DeferAcyclicVerification() Option {
@@ -194,6 +287,89 @@ func (recoverFromPanicsOption) applyOption(c *Container) {
 	c.scope.recoverFromPanics = true
 }
 
+// StrictInvoke is an [Option] that makes Invoke fail if the function given
+// to it returns any values other than an error. By default, such values are
+// silently discarded.
+//
+// This catches a common mistake where Invoke is used in place of Provide:
+//
+//	c.Invoke(NewServer) // the *Server returned by NewServer is lost
+func StrictInvoke() Option {
+	return strictInvokeOption{}
+}
+
+type strictInvokeOption struct{}
+
+func (strictInvokeOption) String() string {
+	return "StrictInvoke()"
+}
+
+func (strictInvokeOption) applyOption(c *Container) {
+	c.scope.strictInvoke = true
+}
+
+// CheckNilInterfaces is an [Option] that makes constructors fail if they
+// produce a nil pointer, map, slice, channel, or function as a value of an
+// interface type, either by returning an interface or through the As
+// option. Such a value is a non-nil interface that wraps a nil value, which
+// consumers cannot detect with a nil check. By default, it is not checked.
+func CheckNilInterfaces() Option {
+	return checkNilInterfacesOption{}
+}
+
+type checkNilInterfacesOption struct{}
+
+func (checkNilInterfacesOption) String() string {
+	return "CheckNilInterfaces()"
+}
+
+func (checkNilInterfacesOption) applyOption(c *Container) {
+	c.scope.checkNilInterfaces = true
+}
+
+// WithNameTag is an Option that changes the struct tag key from which dig
+// reads the names of values in dig.In and dig.Out structs. It defaults to
+// "name".
+//
+// Use this if the "name" tag is already used for other purposes.
+//
+//	c := dig.New(dig.WithNameTag("dig-name"))
+//	c.Provide(func() (struct {
+//	  dig.Out
+//
+//	  Conn *sql.DB `dig-name:"ro" name:"unrelated"`
+//	}) { ... })
+func WithNameTag(tag string) Option {
+	return nameTagOption(tag)
+}
+
+type nameTagOption string
+
+func (o nameTagOption) String() string {
+	return fmt.Sprintf("WithNameTag(%q)", string(o))
+}
+
+func (o nameTagOption) applyOption(c *Container) {
+	c.scope.tags.Name = string(o)
+}
+
+// WithGroupTag is an Option that changes the struct tag key from which dig
+// reads the names of value groups in dig.In and dig.Out structs. It defaults
+// to "group".
+func WithGroupTag(tag string) Option {
+	return groupTagOption(tag)
+}
+
+type groupTagOption string
+
+func (o groupTagOption) String() string {
+	return fmt.Sprintf("WithGroupTag(%q)", string(o))
+}
+
+func (o groupTagOption) applyOption(c *Container) {
+	c.scope.tags.Group = string(o)
+}
+
 // Changes the source of randomness for the container.
 //
 // This will help provide determinism during tests.
@@ -213,6 +389,18 @@ func (o setRandOption) applyOption(c *Container) {
 
 // DryRun is an Option which, when set to true, disables invocation of functions supplied to
 // Provide and Invoke. Use this to build no-op containers.
+//
+// Instead of calling a function, a dry run container produces placeholder
+// values of the types that the function declares it returns, and checks
+// them the same way it checks real results, for example against the
+// interfaces given to the As option. Placeholders of pointers, maps,
+// slices, channels, and functions, including the fields of dig.Out
+// structs, are not nil, so that a dry run does not report them as missing
+// optional results or as nil values with the CheckNilInterfaces option.
+// Placeholders of other types, including interfaces, are zero values.
+//
+// Functions passed to Invoke that depend on a RunMode are still called, so
+// that they can pretend to run. See RunMode for details.
 func DryRun(dry bool) Option {
 	return dryRunOption(dry)
 }
@@ -224,6 +412,7 @@ func (o dryRunOption) String() string {
 }
 
 func (o dryRunOption) applyOption(c *Container) {
+	c.scope.dryRun = bool(o)
 	if o {
 		c.scope.invokerFn = dryInvoker
 	} else {
@@ -238,17 +427,54 @@ func defaultInvoker(fn reflect.Value, args []reflect.Value) []reflect.Value {
 	return fn.Call(args)
 }
 
-// Generates zero values for results without calling the supplied function.
+// Generates placeholder values for results without calling the supplied
+// function.
 func dryInvoker(fn reflect.Value, _ []reflect.Value) []reflect.Value {
 	ft := fn.Type()
 	results := make([]reflect.Value, ft.NumOut())
 	for i := 0; i < ft.NumOut(); i++ {
-		results[i] = reflect.Zero(fn.Type().Out(i))
+		results[i] = dryValue(ft.Out(i))
 	}
 
 	return results
 }
 
+// dryValue returns a placeholder value of type t for DryRun. Values of
+// types that may be nil are not nil, and the fields of dig.Out structs are
+// placeholders themselves.
+func dryValue(t reflect.Type) reflect.Value {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return reflect.New(t.Elem())
+	case reflect.Map:
+		return reflect.MakeMap(t)
+	case reflect.Slice:
+		return reflect.MakeSlice(t, 0, 0)
+	case reflect.Chan:
+		return reflect.MakeChan(t, 0)
+	case reflect.Func:
+		return reflect.MakeFunc(t, func([]reflect.Value) []reflect.Value {
+			results := make([]reflect.Value, t.NumOut())
+			for i := range results {
+				results[i] = reflect.Zero(t.Out(i))
+			}
+			return results
+		})
+	case reflect.Struct:
+		if !IsOut(t) {
+			break
+		}
+		v := reflect.New(t).Elem()
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.PkgPath == "" && f.Type != _outType {
+				v.Field(i).Set(dryValue(f.Type))
+			}
+		}
+		return v
+	}
+	return reflect.Zero(t)
+}
+
 // String representation of the entire Container
 func (c *Container) String() string {
 	return c.scope.String()
@@ -259,6 +485,9 @@ func (c *Container) Scope(name string, opts ...ScopeOption) *Scope {
 	return c.scope.Scope(name, opts...)
 }
 
+// byTypeName sorts types by their names. Types with the same name, like
+// types with the same short name from different packages, are sorted by
+// their fully qualified names.
 type byTypeName []reflect.Type
 
 func (bs byTypeName) Len() int {
@@ -266,13 +495,43 @@ func (bs byTypeName) Len() int {
 }
 
 func (bs byTypeName) Less(i int, j int) bool {
-	return fmt.Sprint(bs[i]) < fmt.Sprint(bs[j])
+	ni, nj := bs[i].String(), bs[j].String()
+	if ni != nj {
+		return ni < nj
+	}
+	return qualifiedTypeName(bs[i]) < qualifiedTypeName(bs[j])
 }
 
 func (bs byTypeName) Swap(i int, j int) {
 	bs[i], bs[j] = bs[j], bs[i]
 }
 
+// qualifiedTypeName returns the name of the given type with the full import
+// paths of the named types it refers to.
+func qualifiedTypeName(t reflect.Type) string {
+	if t.Name() != "" {
+		if t.PkgPath() == "" {
+			return t.Name()
+		}
+		return t.PkgPath() + "." + t.Name()
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + qualifiedTypeName(t.Elem())
+	case reflect.Slice:
+		return "[]" + qualifiedTypeName(t.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%v", t.Len(), qualifiedTypeName(t.Elem()))
+	case reflect.Map:
+		return fmt.Sprintf("map[%v]%v", qualifiedTypeName(t.Key()), qualifiedTypeName(t.Elem()))
+	case reflect.Chan:
+		return fmt.Sprintf("%v %v", t.ChanDir(), qualifiedTypeName(t.Elem()))
+	default:
+		return t.String()
+	}
+}
+
 func shuffledCopy(rand *rand.Rand, items []reflect.Value) []reflect.Value {
 	newItems := make([]reflect.Value, len(items))
 	for i, j := range rand.Perm(len(items)) {
@@ -22,8 +22,12 @@ package dig
 
 import (
 	"fmt"
+	htmltemplate "html/template"
 	"math/rand"
+	"reflect"
+	"sort"
 	"testing"
+	texttemplate "text/template"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -55,6 +59,24 @@ func TestOptionStrings(t *testing.T) {
 		})
 	})
 
+	t.Run("LenientTags", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "LenientTags()", fmt.Sprint(LenientTags()))
+	})
+
+	t.Run("CopySharedValues", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "CopySharedValues()", fmt.Sprint(CopySharedValues()))
+	})
+
+	t.Run("DetectSharedMutations", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "DetectSharedMutations()", fmt.Sprint(DetectSharedMutations()))
+	})
+
 	t.Run("DryRun", func(t *testing.T) {
 		t.Parallel()
 
@@ -67,4 +89,137 @@ func TestOptionStrings(t *testing.T) {
 
 		assert.Equal(t, "RecoverFromPanics()", fmt.Sprint(RecoverFromPanics()))
 	})
+
+	t.Run("StrictInvoke()", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "StrictInvoke()", fmt.Sprint(StrictInvoke()))
+	})
+
+	t.Run("CheckNilInterfaces()", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "CheckNilInterfaces()", fmt.Sprint(CheckNilInterfaces()))
+	})
+
+	t.Run("OnGroupSubmit()", func(t *testing.T) {
+		t.Parallel()
+
+		opt := OnGroupSubmit(func(string, reflect.Type, reflect.Value) {})
+		assert.Contains(t, fmt.Sprint(opt), "OnGroupSubmit(0x")
+	})
+
+	t.Run("ForbidCallInfo()", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "ForbidCallInfo()", fmt.Sprint(ForbidCallInfo()))
+	})
+
+	t.Run("LazyOptionals()", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "LazyOptionals()", fmt.Sprint(LazyOptionals()))
+	})
+
+	t.Run("EagerDependencyCheck()", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "EagerDependencyCheck()", fmt.Sprint(EagerDependencyCheck()))
+	})
+
+	t.Run("DeterministicGroupOrder()", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "DeterministicGroupOrder()", fmt.Sprint(DeterministicGroupOrder()))
+	})
+
+	t.Run("StrictSliceGroups()", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "StrictSliceGroups()", fmt.Sprint(StrictSliceGroups()))
+	})
+
+	t.Run("PreferMostDerived()", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "PreferMostDerived()", fmt.Sprint(PreferMostDerived()))
+	})
+
+	t.Run("AllowRebuild()", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "AllowRebuild()", fmt.Sprint(AllowRebuild()))
+	})
+
+	t.Run("ContextKey", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, `ContextKey("auth", key)`, fmt.Sprint(ContextKey("auth", "key")))
+	})
+
+	t.Run("MaxProviders", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "MaxProviders(10)", fmt.Sprint(MaxProviders(10)))
+	})
+
+	t.Run("MaxResolutionDepth", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "MaxResolutionDepth(5)", fmt.Sprint(MaxResolutionDepth(5)))
+	})
+
+	t.Run("WithNameTag", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, `WithNameTag("dig-name")`, fmt.Sprint(WithNameTag("dig-name")))
+	})
+
+	t.Run("WithGroupTag", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, `WithGroupTag("dig-group")`, fmt.Sprint(WithGroupTag("dig-group")))
+	})
+
+	t.Run("WithParamResolver()", func(t *testing.T) {
+		t.Parallel()
+
+		opt := WithParamResolver(func(next ResolveFunc) ResolveFunc { return next })
+		assert.Contains(t, fmt.Sprint(opt), "WithParamResolver(0x")
+	})
+}
+
+func TestKnownTypesOrder(t *testing.T) {
+	t.Parallel()
+
+	var (
+		html = reflect.TypeOf(&htmltemplate.Template{})
+		text = reflect.TypeOf(&texttemplate.Template{})
+	)
+	c1 := New()
+	assert.NoError(t, c1.Provide(func() *texttemplate.Template { return nil }))
+	assert.NoError(t, c1.Provide(func() *htmltemplate.Template { return nil }))
+
+	c2 := New()
+	assert.NoError(t, c2.Provide(func() *htmltemplate.Template { return nil }))
+	assert.NoError(t, c2.Provide(func() *texttemplate.Template { return nil }))
+
+	// Both types are named *template.Template, so they are sorted by
+	// their import paths.
+	assert.Equal(t, []reflect.Type{html, text}, c1.scope.knownTypes())
+	assert.Equal(t, []reflect.Type{html, text}, c2.scope.knownTypes())
+
+	types := []reflect.Type{
+		reflect.TypeOf(map[string]*texttemplate.Template{}),
+		reflect.TypeOf(map[string]*htmltemplate.Template{}),
+		reflect.TypeOf([]chan *texttemplate.Template{}),
+		reflect.TypeOf([]chan *htmltemplate.Template{}),
+	}
+	sort.Sort(byTypeName(types))
+	assert.Equal(t, []reflect.Type{
+		reflect.TypeOf([]chan *htmltemplate.Template{}),
+		reflect.TypeOf([]chan *texttemplate.Template{}),
+		reflect.TypeOf(map[string]*htmltemplate.Template{}),
+		reflect.TypeOf(map[string]*texttemplate.Template{}),
+	}, types)
 }
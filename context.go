@@ -0,0 +1,156 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+const _fromCtxTag = "fromctx"
+
+// ContextKey is an Option that registers a context key under the given name.
+// Fields of dig.In structs tagged with `fromctx:"name"` will be filled with
+// the value stored under key in the context passed to InvokeContext.
+//
+//	type authKey struct{}
+//
+//	c := dig.New(dig.ContextKey("auth", authKey{}))
+//	err := c.Invoke(func(p struct {
+//	  dig.In
+//
+//	  User *User `fromctx:"auth"`
+//	}) {
+//	  // ...
+//	}, dig.InvokeContext(ctx))
+func ContextKey(name string, key interface{}) Option {
+	return contextKeyOption{name: name, key: key}
+}
+
+type contextKeyOption struct {
+	name string
+	key  interface{}
+}
+
+func (o contextKeyOption) String() string {
+	return fmt.Sprintf("ContextKey(%q, %v)", o.name, o.key)
+}
+
+func (o contextKeyOption) applyOption(c *Container) {
+	if c.scope.contextKeys == nil {
+		c.scope.contextKeys = make(map[string]interface{})
+	}
+	c.scope.contextKeys[o.name] = o.key
+}
+
+// InvokeContext is an InvokeOption that makes the given context available
+// to the invoked function. Fields of its dig.In parameters tagged with
+// `fromctx:".."` will be read from this context.
+//
+// Only the function passed to Invoke may request context values;
+// constructors and decorators are shared across invocations and may not use
+// the fromctx tag.
+func InvokeContext(ctx context.Context) InvokeOption {
+	return invokeContextOption{ctx: ctx}
+}
+
+type invokeContextOption struct{ ctx context.Context }
+
+func (o invokeContextOption) String() string {
+	return fmt.Sprintf("InvokeContext(%v)", o.ctx)
+}
+
+func (o invokeContextOption) applyInvokeOption(opts *invokeOptions) {
+	opts.Context = o.ctx
+}
+
+// contextStore is a containerStore that additionally carries the context
+// of a context-aware Invoke.
+type contextStore struct {
+	containerStore
+
+	ctx  context.Context
+	keys map[string]interface{}
+}
+
+// findContextParams returns all paramContextValues in the given params,
+// including those nested inside parameter objects.
+func findContextParams(params ...param) []paramContextValue {
+	var found []paramContextValue
+	for _, p := range params {
+		switch p := p.(type) {
+		case paramContextValue:
+			found = append(found, p)
+		case paramObject:
+			for _, f := range p.Fields {
+				found = append(found, findContextParams(f.Param)...)
+			}
+		}
+	}
+	return found
+}
+
+// Verifies that the context values requested by the provided parameters
+// can be satisfied by an Invoke with the given context.
+func (s *Scope) checkContextParams(pl paramList, ctx context.Context) error {
+	keys := s.rootScope().contextKeys
+	for _, p := range findContextParams(pl.Params...) {
+		if ctx == nil {
+			return newErrInvalidInput(fmt.Sprintf(
+				"field %v requests context value %q but Invoke was not given a context: use dig.InvokeContext", p.Field, p.Name), nil)
+		}
+		if _, ok := keys[p.Name]; !ok {
+			return newErrInvalidInput(fmt.Sprintf(
+				"field %v requests unknown context value %q: register it with dig.ContextKey", p.Field, p.Name), nil)
+		}
+	}
+	return nil
+}
+
+// Reports an error if the provided parameters request any context values.
+// Used for constructors and decorators, which may outlive a single Invoke.
+func checkNoContextParams(pl paramList) error {
+	if ps := findContextParams(pl.Params...); len(ps) > 0 {
+		return newErrInvalidInput(fmt.Sprintf(
+			"field %v cannot use the %q tag: context values may only be requested by functions passed to Invoke", ps[0].Field, _fromCtxTag), nil)
+	}
+	return nil
+}
+
+// errMissingContextValue is returned when a non-optional context value was
+// not present in the context given to Invoke.
+type errMissingContextValue struct {
+	Field string
+	Name  string
+}
+
+var _ digError = errMissingContextValue{}
+
+func (e errMissingContextValue) Error() string { return fmt.Sprint(e) }
+
+func (e errMissingContextValue) writeMessage(w io.Writer, _ string) {
+	fmt.Fprintf(w, "missing context value %q for field %v", e.Name, e.Field)
+}
+
+func (e errMissingContextValue) Format(w fmt.State, c rune) {
+	formatError(e, w, c)
+}
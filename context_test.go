@@ -0,0 +1,163 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+type authKey struct{}
+
+type principal struct{ name string }
+
+func TestInvokeContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("value is read from context", func(t *testing.T) {
+		c := digtest.New(t, dig.ContextKey("auth", authKey{}))
+		c.RequireProvide(func() string { return "hello" })
+
+		ctx := context.WithValue(context.Background(), authKey{}, &principal{name: "alice"})
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Greeting string
+			User     *principal `fromctx:"auth"`
+		}) {
+			assert.Equal(t, "hello", p.Greeting)
+			assert.Equal(t, "alice", p.User.name)
+		}, dig.InvokeContext(ctx))
+	})
+
+	t.Run("value not assignable to field", func(t *testing.T) {
+		c := digtest.New(t, dig.ContextKey("auth", authKey{}))
+
+		ctx := context.WithValue(context.Background(), authKey{}, &principal{name: "bob"})
+		err := c.Invoke(func(p struct {
+			dig.In
+
+			User fmt.Stringer `fromctx:"auth"`
+		}) {
+		}, dig.InvokeContext(ctx))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not assignable to fmt.Stringer")
+	})
+
+	t.Run("missing optional value", func(t *testing.T) {
+		c := digtest.New(t, dig.ContextKey("auth", authKey{}))
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			User *principal `fromctx:"auth" optional:"true"`
+		}) {
+			assert.Nil(t, p.User)
+		}, dig.InvokeContext(context.Background()))
+	})
+
+	t.Run("missing value", func(t *testing.T) {
+		c := digtest.New(t, dig.ContextKey("auth", authKey{}))
+		err := c.Invoke(func(p struct {
+			dig.In
+
+			User *principal `fromctx:"auth"`
+		}) {
+			t.Fatal("function must not be called")
+		}, dig.InvokeContext(context.Background()))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `missing context value "auth" for field struct { dig.In; User *dig_test.principal "fromctx:\"auth\"" }.User`)
+	})
+
+	t.Run("scopes use root context keys", func(t *testing.T) {
+		c := digtest.New(t, dig.ContextKey("auth", authKey{}))
+		s := c.Scope("child")
+
+		ctx := context.WithValue(context.Background(), authKey{}, &principal{name: "carol"})
+		s.RequireInvoke(func(p struct {
+			dig.In
+
+			User *principal `fromctx:"auth"`
+		}) {
+			assert.Equal(t, "carol", p.User.name)
+		}, dig.InvokeContext(ctx))
+	})
+}
+
+func TestInvokeContextFailures(t *testing.T) {
+	t.Parallel()
+
+	type params struct {
+		dig.In
+
+		User *principal `fromctx:"auth"`
+	}
+
+	t.Run("invoke without context", func(t *testing.T) {
+		c := digtest.New(t, dig.ContextKey("auth", authKey{}))
+		err := c.Invoke(func(params) {
+			t.Fatal("function must not be called")
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `requests context value "auth" but Invoke was not given a context`)
+	})
+
+	t.Run("unregistered key", func(t *testing.T) {
+		c := digtest.New(t)
+		err := c.Invoke(func(params) {
+			t.Fatal("function must not be called")
+		}, dig.InvokeContext(context.Background()))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `requests unknown context value "auth"`)
+	})
+
+	t.Run("provide", func(t *testing.T) {
+		c := digtest.New(t, dig.ContextKey("auth", authKey{}))
+		err := c.Provide(func(params) string { return "" })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "context values may only be requested by functions passed to Invoke")
+	})
+
+	t.Run("decorate", func(t *testing.T) {
+		c := digtest.New(t, dig.ContextKey("auth", authKey{}))
+		c.RequireProvide(func() string { return "" })
+		err := c.Decorate(func(params, string) string { return "" })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "context values may only be requested by functions passed to Invoke")
+	})
+
+	t.Run("fromctx with name", func(t *testing.T) {
+		c := digtest.New(t, dig.ContextKey("auth", authKey{}))
+		err := c.Invoke(func(struct {
+			dig.In
+
+			User *principal `fromctx:"auth" name:"user"`
+		}) {
+		}, dig.InvokeContext(context.Background()))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot use named values with context values")
+	})
+}
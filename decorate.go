@@ -69,15 +69,28 @@ type decoratorNode struct {
 
 func newDecoratorNode(dcor interface{}, s *Scope) (*decoratorNode, error) {
 	dval := reflect.ValueOf(dcor)
+	if !dval.IsValid() {
+		return nil, newErrInvalidInput("can't decorate with an untyped nil", nil)
+	}
 	dtype := dval.Type()
+	if dtype.Kind() != reflect.Func {
+		return nil, newErrInvalidInput(
+			fmt.Sprintf("must decorate with a function, got %v (type %v)", dcor, dtype), nil)
+	}
 	dptr := dval.Pointer()
 
 	pl, err := newParamList(dtype, s)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkNoContextParams(pl); err != nil {
+		return nil, err
+	}
+	if err := checkNoCallInfo(pl); err != nil {
+		return nil, err
+	}
 
-	rl, err := newResultList(dtype, resultOptions{})
+	rl, err := newResultList(dtype, resultOptions{Tags: s.tagKeys()})
 	if err != nil {
 		return nil, err
 	}
@@ -96,11 +109,11 @@ func newDecoratorNode(dcor interface{}, s *Scope) (*decoratorNode, error) {
 }
 
 func (n *decoratorNode) Call(s containerStore) (err error) {
-	if n.state == decoratorCalled {
+	if n.State() == decoratorCalled {
 		return nil
 	}
 
-	n.state = decoratorOnStack
+	n.setState(decoratorOnStack)
 
 	if err := shallowCheckDependencies(s, n.params); err != nil {
 		return errMissingDependencies{
@@ -120,7 +133,10 @@ func (n *decoratorNode) Call(s containerStore) (err error) {
 		}()
 	}
 
-	args, err := n.params.BuildList(n.s)
+	args, err := n.params.BuildList(withCallFrame(n.s, s))
+	if err == nil {
+		err = n.s.checkSharedMutations(n.location, n.params, args)
+	}
 	if err != nil {
 		return errArgumentsFailed{
 			Func:   n.location,
@@ -132,13 +148,21 @@ func (n *decoratorNode) Call(s containerStore) (err error) {
 	if err := n.results.ExtractList(n.s, true /* decorated */, results); err != nil {
 		return err
 	}
-	n.state = decoratorCalled
+	n.setState(decoratorCalled)
 	return nil
 }
 
 func (n *decoratorNode) ID() dot.CtorID { return n.id }
 
-func (n *decoratorNode) State() decoratorState { return n.state }
+func (n *decoratorNode) State() decoratorState {
+	defer n.s.lock()()
+	return n.state
+}
+
+func (n *decoratorNode) setState(state decoratorState) {
+	defer n.s.lock()()
+	n.state = state
+}
 
 // DecorateOption modifies the default behavior of Decorate.
 type DecorateOption interface {
@@ -296,3 +320,43 @@ func findResultKeys(r resultList) ([]key, error) {
 	}
 	return keys, nil
 }
+
+// WithDecorator is an InvokeOption that applies the given decorator to the
+// values that the invoked function depends on, for that Invoke only. The
+// decorator has the same form as one passed to Decorate. It may be given
+// more than once to apply several decorators, as long as they decorate
+// different values.
+//
+//	err := c.Invoke(handle, dig.WithDecorator(func(l *zap.Logger) *zap.Logger {
+//	  return l.With(zap.String("request", id))
+//	}))
+//
+// The decorated values are discarded after the Invoke. Constructors called
+// to build the arguments of the function see the values as they were
+// before decoration, as they would if the decorator were applied to a
+// child Scope.
+func WithDecorator(decorator interface{}) InvokeOption {
+	return withDecoratorOption{decorator: decorator}
+}
+
+type withDecoratorOption struct{ decorator interface{} }
+
+func (o withDecoratorOption) String() string {
+	return fmt.Sprintf("WithDecorator(%v)", reflect.TypeOf(o.decorator))
+}
+
+func (o withDecoratorOption) applyInvokeOption(opts *invokeOptions) {
+	opts.Decorators = append(opts.Decorators, o.decorator)
+}
+
+// decorateInvoke returns a short-lived child of this Scope with the given
+// decorators applied, to build the arguments of a single Invoke from.
+func (s *Scope) decorateInvoke(decorators []interface{}) (*Scope, error) {
+	ds := s.newChildScope(s.name)
+	for _, d := range decorators {
+		if err := ds.Decorate(d); err != nil {
+			return nil, err
+		}
+	}
+	return ds, nil
+}
@@ -584,6 +584,19 @@ func TestDecorateSuccess(t *testing.T) {
 }
 
 func TestDecorateFailure(t *testing.T) {
+	t.Run("decorate with a non-function", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Decorate(42)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must decorate with a function, got 42 (type int)")
+
+		err = c.Decorate(nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "can't decorate with an untyped nil")
+	})
+
 	t.Run("decorate a type that wasn't provided", func(t *testing.T) {
 		t.Parallel()
 
@@ -937,3 +950,117 @@ func TestFillDecorateInfoString(t *testing.T) {
 		assert.Contains(t, fmt.Sprint(opt), "FillDecorateInfo(0x")
 	})
 }
+
+func TestWithDecorator(t *testing.T) {
+	t.Parallel()
+
+	type Logger struct{ fields []string }
+	type Handler struct{ log *Logger }
+
+	withField := func(f string) func(*Logger) *Logger {
+		return func(l *Logger) *Logger {
+			return &Logger{fields: append(append([]string(nil), l.fields...), f)}
+		}
+	}
+
+	newContainer := func(t *testing.T) *digtest.Container {
+		c := digtest.New(t)
+		c.RequireProvide(func() *Logger { return &Logger{fields: []string{"app"}} })
+		c.RequireProvide(func(l *Logger) *Handler { return &Handler{log: l} })
+		return c
+	}
+
+	t.Run("applies to a single invoke", func(t *testing.T) {
+		t.Parallel()
+
+		c := newContainer(t)
+		require.NoError(t, c.Invoke(func(l *Logger) {
+			assert.Equal(t, []string{"app", "request=1"}, l.fields)
+		}, dig.WithDecorator(withField("request=1"))))
+		require.NoError(t, c.Invoke(func(l *Logger) {
+			assert.Equal(t, []string{"app", "request=2"}, l.fields)
+		}, dig.WithDecorator(withField("request=2"))))
+
+		c.RequireInvoke(func(l *Logger) {
+			assert.Equal(t, []string{"app"}, l.fields, "decoration must be discarded")
+		})
+	})
+
+	t.Run("layered over scope decorators", func(t *testing.T) {
+		t.Parallel()
+
+		c := newContainer(t)
+		c.RequireDecorate(withField("decorated"))
+		require.NoError(t, c.Invoke(func(l *Logger) {
+			assert.Equal(t, []string{"app", "decorated", "request"}, l.fields)
+		}, dig.WithDecorator(withField("request"))))
+	})
+
+	t.Run("constructors see undecorated values", func(t *testing.T) {
+		t.Parallel()
+
+		c := newContainer(t)
+		require.NoError(t, c.Invoke(func(h *Handler, l *Logger) {
+			assert.Equal(t, []string{"app"}, h.log.fields)
+			assert.Equal(t, []string{"app", "request"}, l.fields)
+		}, dig.WithDecorator(withField("request"))))
+	})
+
+	t.Run("several decorators", func(t *testing.T) {
+		t.Parallel()
+
+		c := newContainer(t)
+		require.NoError(t, c.Invoke(func(l *Logger, h *Handler) {
+			assert.Equal(t, []string{"app", "request"}, l.fields)
+			assert.Equal(t, []string{"app", "handler"}, h.log.fields)
+		},
+			dig.WithDecorator(withField("request")),
+			dig.WithDecorator(func(h *Handler) *Handler {
+				return &Handler{log: withField("handler")(h.log)}
+			}),
+		))
+	})
+
+	t.Run("scope", func(t *testing.T) {
+		t.Parallel()
+
+		c := newContainer(t)
+		child := c.Scope("child")
+		child.RequireDecorate(withField("child"))
+		require.NoError(t, child.Invoke(func(l *Logger) {
+			assert.Equal(t, []string{"app", "child", "request"}, l.fields)
+		}, dig.WithDecorator(withField("request"))))
+
+		assert.Empty(t, dig.DebugSnapshot(c.Container).Root.Children[0].Children,
+			"invoke must not leave scopes behind")
+	})
+
+	t.Run("same value decorated twice", func(t *testing.T) {
+		t.Parallel()
+
+		c := newContainer(t)
+		err := c.Invoke(func(*Logger) {
+			t.Fatal("must not be called")
+		}, dig.WithDecorator(withField("a")), dig.WithDecorator(withField("b")))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already decorated")
+	})
+
+	t.Run("invalid decorator", func(t *testing.T) {
+		t.Parallel()
+
+		c := newContainer(t)
+		err := c.Invoke(func(*Logger) {
+			t.Fatal("must not be called")
+		}, dig.WithDecorator("not a function"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `must decorate with a function, got not a function (type string)`)
+	})
+
+	t.Run("String", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "WithDecorator(func(*dig_test.Logger) *dig_test.Logger)",
+			fmt.Sprint(dig.WithDecorator(withField("a"))))
+	})
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// DependsOn reports whether building a value of type from requires a value
+// of type to, directly or through the dependencies of its constructors.
+// This is useful to enforce layering rules in tests:
+//
+//	ok, path, err := c.DependsOn(
+//	  reflect.TypeOf((*http.Handler)(nil)).Elem(),
+//	  reflect.TypeOf(&sql.DB{}),
+//	)
+//	if ok {
+//	  t.Errorf("HTTP layer depends on the database through %v", path)
+//	}
+//
+// If it does, DependsOn returns the IDs of the constructors along one of
+// the shortest paths between them, starting with the provider of from and
+// ending with the provider of to. Values of both types may be named or
+// belong to value groups, and constructors provided to any Scope are
+// considered. DependsOn fails if no constructor provides from.
+func (c *Container) DependsOn(from, to reflect.Type) (bool, []ID, error) {
+	if from == nil || to == nil {
+		return false, nil, newErrInvalidInput("cannot query dependencies of an untyped nil", nil)
+	}
+
+	found := false
+	for _, s := range c.scope.appendSubscopes(nil) {
+		starts := s.nodesProviding(from)
+		if len(starts) == 0 {
+			continue
+		}
+		found = true
+		if path := s.dependencyPath(starts, to); path != nil {
+			return true, path, nil
+		}
+	}
+
+	if !found {
+		return false, nil, newErrInvalidInput(
+			fmt.Sprintf("cannot query dependencies of %v: no constructor provides it", from), nil)
+	}
+	return false, nil, nil
+}
+
+// nodesProviding returns the constructors provided to this Scope that
+// produce values of type t under any name or group.
+func (s *Scope) nodesProviding(t reflect.Type) []*constructorNode {
+	var nodes []*constructorNode
+	for _, n := range s.nodes {
+		if providesType(n, t) {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// dependencyPath searches the graph of this Scope breadth-first from the
+// given constructors, in a clone of the graph, for a dependency that produces values of type to. It
+// returns the IDs of the constructors on the path to it, or nil if there
+// is none.
+func (s *Scope) dependencyPath(starts []*constructorNode, to reflect.Type) []ID {
+	// parents maps the order of each visited node to the order of the node
+	// it was reached from, or -1 for the starting nodes.
+	parents := make(map[int]int)
+	var queue []int
+	for _, n := range starts {
+		parents[n.Order(s)] = -1
+		queue = append(queue, n.Order(s))
+	}
+
+	g := s.gh.Clone()
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		for _, v := range g.EdgesFrom(u) {
+			if _, ok := parents[v]; ok {
+				continue
+			}
+			parents[v] = u
+			if n, ok := g.Lookup(v).(*constructorNode); ok && providesType(n, to) {
+				return pathTo(g, parents, v)
+			}
+			queue = append(queue, v)
+		}
+	}
+	return nil
+}
+
+// pathTo returns the IDs of the constructors on the path that leads to the
+// node with order v. Value group and named map nodes on the path are not
+// included.
+func pathTo(g *graphHolder, parents map[int]int, v int) []ID {
+	var path []ID
+	for ; v >= 0; v = parents[v] {
+		if n, ok := g.Lookup(v).(*constructorNode); ok {
+			path = append(path, ID(n.ID()))
+		}
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+func providesType(n *constructorNode, t reflect.Type) bool {
+	for k := range resultKeys(n.resultList) {
+		if k.t == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestDependsOn(t *testing.T) {
+	t.Parallel()
+
+	type DB struct{}
+	type Repo struct{}
+	type Service struct{}
+	type Handler struct{}
+	type Metrics struct{}
+
+	var (
+		dbType      = reflect.TypeOf(&DB{})
+		repoType    = reflect.TypeOf(&Repo{})
+		serviceType = reflect.TypeOf(&Service{})
+		handlerType = reflect.TypeOf(&Handler{})
+		metricsType = reflect.TypeOf(&Metrics{})
+	)
+
+	provide := func(t *testing.T, c interface {
+		Provide(interface{}, ...dig.ProvideOption) error
+	}, ctor interface{}) dig.ID {
+		var info dig.ProvideInfo
+		require.NoError(t, c.Provide(ctor, dig.FillProvideInfo(&info)))
+		return info.ID
+	}
+
+	t.Run("transitive", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		dbID := provide(t, c, func() *DB { return &DB{} })
+		repoID := provide(t, c, func(*DB) *Repo { return &Repo{} })
+		serviceID := provide(t, c, func(*Repo) *Service { return &Service{} })
+		handlerID := provide(t, c, func(*Service, *Metrics) *Handler { return &Handler{} })
+		provide(t, c, func() *Metrics { return &Metrics{} })
+
+		ok, path, err := c.DependsOn(handlerType, dbType)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, []dig.ID{handlerID, serviceID, repoID, dbID}, path)
+
+		ok, path, err = c.DependsOn(repoType, handlerType)
+		require.NoError(t, err)
+		assert.False(t, ok, "dependencies must not be followed backwards")
+		assert.Nil(t, path)
+
+		ok, _, err = c.DependsOn(metricsType, dbType)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("shortest path", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		dbID := provide(t, c, func() *DB { return &DB{} })
+		provide(t, c, func(*DB) *Repo { return &Repo{} })
+		serviceID := provide(t, c, func(*Repo, *DB) *Service { return &Service{} })
+
+		ok, path, err := c.DependsOn(serviceType, dbType)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, []dig.ID{serviceID, dbID}, path)
+	})
+
+	t.Run("through value groups and parameter objects", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			Repos []*Repo `group:"repos"`
+		}
+
+		c := digtest.New(t)
+		dbID := provide(t, c, func() *DB { return &DB{} })
+		var info dig.ProvideInfo
+		c.RequireProvide(func(*DB) *Repo { return &Repo{} }, dig.Group("repos"), dig.FillProvideInfo(&info))
+		serviceID := provide(t, c, func(params) *Service { return &Service{} })
+
+		ok, path, err := c.DependsOn(serviceType, dbType)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, []dig.ID{serviceID, info.ID, dbID}, path)
+	})
+
+	t.Run("child scope", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		dbID := provide(t, c, func() *DB { return &DB{} })
+		child := c.Scope("child")
+		var info dig.ProvideInfo
+		child.RequireProvide(func(*DB) *Handler { return &Handler{} }, dig.FillProvideInfo(&info))
+
+		ok, path, err := c.DependsOn(handlerType, dbType)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, []dig.ID{info.ID, dbID}, path)
+	})
+
+	t.Run("no provider", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		_, _, err := c.DependsOn(handlerType, dbType)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot query dependencies of *dig_test.Handler: no constructor provides it")
+
+		_, _, err = c.DependsOn(nil, dbType)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "untyped nil")
+	})
+}
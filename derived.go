@@ -0,0 +1,171 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// PreferMostDerived is an [Option] that lets dig satisfy a dependency on an
+// interface that has no provider with a value of another type that
+// implements it. That type may be a wider interface that embeds the
+// requested one, or a concrete type.
+//
+//	c := dig.New(dig.PreferMostDerived())
+//	c.Provide(func() StoreWithTx { ... })
+//	c.Invoke(func(s Store) {
+//	  tx := s.(StoreWithTx) // succeeds
+//	})
+//
+// This removes the need for the As option when a single provided type
+// implements an interface, such as a *bytes.Buffer used as an io.Writer.
+//
+// If several provided types implement the interface, the most derived of
+// them is used: a type is skipped if another candidate implements it. If
+// that still leaves several candidates, the dependency fails with an error
+// listing them. Values must have the same name as the dependency.
+//
+// Dependencies satisfied this way are listed by the String method of the
+// Scope that requested them.
+func PreferMostDerived() Option {
+	return preferMostDerivedOption{}
+}
+
+type preferMostDerivedOption struct{}
+
+func (preferMostDerivedOption) String() string {
+	return "PreferMostDerived()"
+}
+
+func (preferMostDerivedOption) applyOption(c *Container) {
+	c.scope.preferMostDerived = true
+}
+
+func (s *Scope) prefersMostDerived() bool {
+	return s.rootScope().preferMostDerived
+}
+
+func (s *Scope) recordDerived(k key, t reflect.Type) {
+	defer s.lock()()
+	if s.derived == nil {
+		s.derived = make(map[key]reflect.Type)
+	}
+	s.derived[k] = t
+}
+
+// derivedType returns the type of the value that satisfies this param
+// under PreferMostDerived, or nil if there is none. It returns an error if
+// there are several candidates.
+func (ps paramSingle) derivedType(c containerStore) (reflect.Type, error) {
+	if ps.Type.Kind() != reflect.Interface || !c.prefersMostDerived() {
+		return nil, nil
+	}
+
+	seen := make(map[reflect.Type]struct{})
+	var candidates []reflect.Type
+	for _, s := range c.storesToRoot() {
+		for _, t := range s.knownTypes() {
+			if _, ok := seen[t]; ok || t == ps.Type || !t.Implements(ps.Type) {
+				continue
+			}
+			if len(s.getValueProviders(ps.Name, t)) == 0 {
+				continue
+			}
+			seen[t] = struct{}{}
+			candidates = append(candidates, t)
+		}
+	}
+
+	candidates = mostDerived(candidates)
+	switch len(candidates) {
+	case 0:
+		return nil, nil
+	case 1:
+		return candidates[0], nil
+	default:
+		sort.Sort(byTypeName(candidates))
+		return nil, errAmbiguousDerived{
+			Key:        key{name: ps.Name, t: ps.Type},
+			Candidates: candidates,
+		}
+	}
+}
+
+// buildDerived builds the value of type t that satisfies this param.
+func (ps paramSingle) buildDerived(c containerStore, t reflect.Type) (reflect.Value, error) {
+	v, err := paramSingle{Name: ps.Name, Type: t, Optional: ps.Optional}.Build(c)
+	if err != nil {
+		return v, err
+	}
+	c.recordDerived(key{name: ps.Name, t: ps.Type}, t)
+
+	iv := reflect.New(ps.Type).Elem()
+	iv.Set(v)
+	return ps.found(c, iv)
+}
+
+// mostDerived drops the interfaces that another of the given types
+// implements. Interfaces that implement each other are both kept.
+func mostDerived(types []reflect.Type) []reflect.Type {
+	var kept []reflect.Type
+	for _, t := range types {
+		derived := false
+		for _, other := range types {
+			if t.Kind() == reflect.Interface && other != t && other.Implements(t) &&
+				(other.Kind() != reflect.Interface || !t.Implements(other)) {
+				derived = true
+				break
+			}
+		}
+		if !derived {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
+
+// errAmbiguousDerived is returned when several provided types could
+// satisfy an interface under PreferMostDerived.
+type errAmbiguousDerived struct {
+	Key        key
+	Candidates []reflect.Type
+}
+
+var _ digError = errAmbiguousDerived{}
+
+func (e errAmbiguousDerived) Error() string { return fmt.Sprint(e) }
+
+func (e errAmbiguousDerived) writeMessage(w io.Writer, v string) {
+	candidates := make([]string, len(e.Candidates))
+	for i, t := range e.Candidates {
+		candidates[i] = t.String()
+	}
+	fmt.Fprintf(w, "cannot choose a provider of %v with dig.PreferMostDerived: "+
+		"multiple provided types implement it: %v", e.Key, strings.Join(candidates, ", "))
+}
+
+func (e errAmbiguousDerived) Format(w fmt.State, c rune) {
+	formatError(e, w, c)
+}
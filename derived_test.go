@@ -0,0 +1,182 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+type derivedStore interface {
+	Get(string) string
+}
+
+type derivedStoreWithTx interface {
+	derivedStore
+
+	Begin()
+}
+
+type derivedStoreWithBatch interface {
+	derivedStore
+
+	Batch()
+}
+
+type memStore struct{ name string }
+
+func (memStore) Get(string) string { return "" }
+func (memStore) Begin()            {}
+
+type batchStore struct{}
+
+func (batchStore) Get(string) string { return "" }
+func (batchStore) Batch()            {}
+
+func TestPreferMostDerived(t *testing.T) {
+	t.Parallel()
+
+	t.Run("disabled by default", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() derivedStoreWithTx { return memStore{} })
+		err := c.Invoke(func(derivedStore) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: dig_test.derivedStore")
+	})
+
+	t.Run("embedding interface", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.PreferMostDerived())
+		c.RequireProvide(func() derivedStoreWithTx { return memStore{name: "tx"} })
+		c.RequireInvoke(func(s derivedStore) {
+			tx, ok := s.(derivedStoreWithTx)
+			require.True(t, ok)
+			assert.Equal(t, memStore{name: "tx"}, tx)
+		})
+		assert.Contains(t, c.String(), "derived: {")
+		assert.Contains(t, c.String(), "dig_test.derivedStore <- dig_test.derivedStoreWithTx")
+	})
+
+	t.Run("concrete type", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.PreferMostDerived())
+		c.RequireProvide(func() *memStore { return &memStore{name: "ptr"} })
+		c.RequireInvoke(func(s derivedStore) {
+			assert.Equal(t, &memStore{name: "ptr"}, s)
+		})
+	})
+
+	t.Run("direct provider wins", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.PreferMostDerived())
+		c.RequireProvide(func() derivedStoreWithTx { return memStore{name: "tx"} })
+		c.RequireProvide(func() derivedStore { return memStore{name: "plain"} })
+		c.RequireInvoke(func(s derivedStore) {
+			assert.Equal(t, memStore{name: "plain"}, s)
+		})
+	})
+
+	t.Run("most derived candidate", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.PreferMostDerived())
+		c.RequireProvide(func() derivedStoreWithTx { return memStore{name: "tx"} })
+		c.RequireProvide(func() memStore { return memStore{name: "concrete"} })
+		c.RequireInvoke(func(s derivedStore) {
+			assert.Equal(t, memStore{name: "concrete"}, s)
+		})
+	})
+
+	t.Run("names must match", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.PreferMostDerived())
+		c.RequireProvide(func() derivedStoreWithTx { return memStore{name: "ro"} }, dig.Name("ro"))
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			RO      derivedStore `name:"ro"`
+			Unnamed derivedStore `optional:"true"`
+		}) {
+			assert.Equal(t, memStore{name: "ro"}, p.RO)
+			assert.Nil(t, p.Unnamed)
+		})
+	})
+
+	t.Run("ambiguous", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.PreferMostDerived())
+		c.RequireProvide(func() derivedStoreWithTx { return memStore{} })
+		c.RequireProvide(func() derivedStoreWithBatch { return batchStore{} })
+		err := c.Invoke(func(derivedStore) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			"cannot choose a provider of dig_test.derivedStore with dig.PreferMostDerived: "+
+				"multiple provided types implement it: dig_test.derivedStoreWithBatch, dig_test.derivedStoreWithTx")
+	})
+
+	t.Run("concrete types without As", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.PreferMostDerived())
+		buf := new(bytes.Buffer)
+		c.RequireProvide(func() *bytes.Buffer { return buf })
+		c.RequireInvoke(func(w io.Writer) {
+			assert.Same(t, buf, w)
+		})
+
+		c.RequireProvide(func() *strings.Builder { return new(strings.Builder) })
+		err := c.Invoke(func(io.Reader) {})
+		require.NoError(t, err, "only one provided type implements io.Reader")
+
+		err = c.Invoke(func(fmt.Stringer) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			"cannot choose a provider of fmt.Stringer with dig.PreferMostDerived: "+
+				"multiple provided types implement it: *bytes.Buffer, *strings.Builder")
+	})
+
+	t.Run("child scopes", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.PreferMostDerived())
+		child := c.Scope("child")
+		child.RequireProvide(func() derivedStoreWithTx { return memStore{name: "child"} })
+		child.RequireInvoke(func(s derivedStore) {
+			assert.Equal(t, memStore{name: "child"}, s)
+		})
+		assert.Error(t, c.Invoke(func(derivedStore) {}))
+	})
+}
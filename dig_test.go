@@ -22,6 +22,7 @@ package dig_test
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -376,6 +377,26 @@ func TestEndToEndSuccess(t *testing.T) {
 		})
 	})
 
+	t.Run("out type ignores unexported fields", func(t *testing.T) {
+		type type1 struct{}
+		type out struct {
+			dig.Out `ignore-unexported:"true"`
+
+			T1    *type1
+			calls int // bookkeeping only, not provided
+		}
+
+		var info dig.ProvideInfo
+		c := digtest.New(t)
+		c.RequireProvide(func() out {
+			return out{T1: &type1{}, calls: 1}
+		}, dig.FillProvideInfo(&info))
+		assert.Len(t, info.Outputs, 1, "unexported field must not be provided")
+		c.RequireInvoke(func(t1 *type1) {
+			assert.NotNil(t, t1, "exported field should be provided")
+		})
+	})
+
 	t.Run("out type inserts multiple objects into the graph", func(t *testing.T) {
 		type A struct{ name string }
 		type B struct{ name string }
@@ -424,6 +445,39 @@ func TestEndToEndSuccess(t *testing.T) {
 		})
 	})
 
+	t.Run("optional result field", func(t *testing.T) {
+		type type1 struct{}
+		type type2 struct{}
+
+		type result struct {
+			dig.Out
+
+			T1 *type1 `optional:"true"`
+			T2 *type2 `optional:"true"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() result {
+			return result{T2: &type2{}}
+		})
+
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			T1 *type1 `optional:"true"`
+			T2 *type2
+		}) {
+			assert.Nil(t, p.T1, "omitted result must be treated as absent")
+			assert.NotNil(t, p.T2, "non-zero optional result must be provided")
+		})
+
+		err := c.Invoke(func(*type1) {
+			t.Fatal("function must not be called")
+		})
+		require.Error(t, err, "omitted result must not satisfy a required dependency")
+		assert.Contains(t, err.Error(), "optional result was omitted by function")
+	})
+
 	t.Run("nested dependencies", func(t *testing.T) {
 		c := digtest.New(t)
 
@@ -1400,6 +1454,57 @@ func TestGroups(t *testing.T) {
 		assert.Equal(t, gaveErr, dig.RootCause(err))
 	})
 
+	t.Run("all provider failures are reported", func(t *testing.T) {
+		c := digtest.New(t)
+
+		type out struct {
+			dig.Out
+
+			Result string `group:"x"`
+		}
+
+		c.RequireProvide(func() (out, error) {
+			return out{}, errors.New("first failure")
+		})
+
+		c.RequireProvide(func() out {
+			return out{Result: "foo"}
+		})
+
+		c.RequireProvide(func() (out, error) {
+			return out{}, errors.New("second failure")
+		})
+
+		type in struct {
+			dig.In
+
+			Strings []string `group:"x"`
+		}
+
+		var calls int
+		c.RequireProvide(func() (out, error) {
+			calls++
+			return out{Result: "bar"}, nil
+		})
+
+		err := c.Invoke(func(i in) {
+			require.FailNow(t, "this function must not be called")
+		})
+		require.Error(t, err, "expected failure")
+		assert.Equal(t, 1, calls, "all providers must be called")
+		dig.AssertErrorMatches(t, err,
+			`could not build arguments for function "go.uber.org/dig_test".TestGroups`,
+			`could not build value group string\[group="x"\]: 2 providers failed:`,
+			`\[1\] received non-nil error from function "go.uber.org/dig_test".TestGroups\S+`,
+			`dig_test.go:\d+`, // file:line
+			"first failure",
+			`\[2\] received non-nil error from function "go.uber.org/dig_test".TestGroups\S+`,
+			`dig_test.go:\d+`, // file:line
+			"second failure",
+		)
+		assert.Contains(t, err.Error(), "first failure; [2] received non-nil error")
+	})
+
 	t.Run("flatten collects slices", func(t *testing.T) {
 		c := digtest.New(t, dig.SetRand(rand.New(rand.NewSource(0))))
 
@@ -1563,6 +1668,82 @@ func TestGroups(t *testing.T) {
 			assert.ElementsMatch(t, []string{"a"}, param.Value)
 		})
 	})
+
+	t.Run("consume group values with their providers", func(t *testing.T) {
+		c := digtest.New(t)
+
+		newFoo := func() string { return "foo" }
+		newBars := func() []string { return []string{"bar", "baz"} }
+		c.RequireProvide(newFoo, dig.Group("names"))
+		c.RequireProvide(newBars, dig.Group("names,flatten"))
+
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Names   []string                 `group:"names"`
+			Entries []dig.GroupValue[string] `group:"names"`
+		}) {
+			assert.ElementsMatch(t, []string{"foo", "bar", "baz"}, p.Names,
+				"plain slice consumption must be unaffected")
+			require.Len(t, p.Entries, 3)
+
+			funcs := make(map[string]string)
+			for _, e := range p.Entries {
+				assert.Equal(t, "go.uber.org/dig_test", e.Package)
+				assert.Contains(t, e.File, "dig_test.go")
+				assert.NotZero(t, e.Line)
+				funcs[e.Value] = e.Function
+			}
+			assert.Equal(t, funcs["bar"], funcs["baz"], "flattened values share a provider")
+			assert.NotEqual(t, funcs["foo"], funcs["bar"])
+		})
+	})
+
+	t.Run("group values in dry run carry providers", func(t *testing.T) {
+		c := digtest.New(t, dig.DryRun(true))
+		c.RequireProvide(func() string { return "foo" }, dig.Group("names"))
+
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Entries []dig.GroupValue[string] `group:"names"`
+		}) {
+			require.Len(t, p.Entries, 1)
+			assert.Zero(t, p.Entries[0].Value)
+			assert.Equal(t, "go.uber.org/dig_test", p.Entries[0].Package)
+		})
+	})
+
+	t.Run("decorated group values have no provider", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "foo" }, dig.Group("names"))
+		c.RequireDecorate(func(p struct {
+			dig.In
+
+			Names []string `group:"names"`
+		}) struct {
+			dig.Out
+
+			Names []string `group:"names"`
+		} {
+			return struct {
+				dig.Out
+
+				Names []string `group:"names"`
+			}{Names: append(p.Names, "bar")}
+		})
+
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Entries []dig.GroupValue[string] `group:"names"`
+		}) {
+			require.Len(t, p.Entries, 2)
+			for _, e := range p.Entries {
+				assert.Empty(t, e.Function)
+			}
+		})
+	})
 }
 
 // --- END OF END TO END TESTS
@@ -1848,160 +2029,784 @@ func TestProvideWithWeirdNames(t *testing.T) {
 	})
 }
 
-func TestProvideInvalidName(t *testing.T) {
+func TestCustomTagKeys(t *testing.T) {
 	t.Parallel()
 
-	c := digtest.New(t)
-	err := c.Provide(func() io.Reader {
-		t.Fatal("this function must not be called")
-		return nil
-	}, dig.Name("foo`bar"))
-	require.Error(t, err, "Provide must fail")
-	assert.Contains(t, err.Error(), "invalid dig.Name(\"foo`bar\"): names cannot contain backquotes")
-}
-
-func TestProvideInvalidGroup(t *testing.T) {
-	t.Parallel()
+	type result struct {
+		dig.Out
 
-	c := digtest.New(t)
-	err := c.Provide(func() io.Reader {
-		t.Fatal("this function must not be called")
-		return nil
-	}, dig.Group("foo`bar"))
-	require.Error(t, err, "Provide must fail")
-	assert.Contains(t, err.Error(), "invalid dig.Group(\"foo`bar\"): group names cannot contain backquotes")
+		Writer io.Writer `name:"ignored" dig-name:"out"`
+		Reader io.Reader `group:"ignored" dig-group:"in"`
+	}
 
-	err = c.Provide(func() io.Reader {
-		t.Fatal("this function must not be called")
-		return nil
-	}, dig.Group("foo,bar"))
-	require.Error(t, err, "Provide must fail")
-	assert.Contains(t, err.Error(), `cannot parse group "foo,bar": invalid option "bar"`)
-}
+	type params struct {
+		dig.In
 
-func TestProvideInvalidAs(t *testing.T) {
-	ptrToStruct := &struct {
-		name string
-	}{
-		name: "example",
+		Writer  io.Writer   `name:"ignored" dig-name:"out"`
+		Readers []io.Reader `dig-group:"in"`
 	}
-	type out struct {
-		dig.Out
 
-		name string
-	}
-	var nilInterface io.Reader
+	c := digtest.New(t, dig.WithNameTag("dig-name"), dig.WithGroupTag("dig-group"))
+	c.RequireProvide(func() result {
+		return result{Writer: new(bytes.Buffer), Reader: new(bytes.Buffer)}
+	})
+	c.RequireProvide(func() io.Reader { return new(bytes.Buffer) }, dig.Group("in"))
 
-	tests := []struct {
-		name        string
-		param       interface{}
-		expectedErr string
-		addlOption  dig.ProvideOption
-	}{
-		{
-			name:        "as param is not an type interface",
-			param:       123,
-			expectedErr: "invalid dig.As(int): argument must be a pointer to an interface",
-		},
-		{
-			name:        "as param is a pointer to struct",
-			param:       ptrToStruct,
-			expectedErr: "invalid dig.As(*struct { name string }): argument must be a pointer to an interface",
-		},
-		{
-			name:        "as param is a nil interface",
-			param:       nilInterface,
-			expectedErr: "invalid dig.As(nil): argument must be a pointer to an interface",
-		},
-		{
-			name:        "as param is a nil",
-			param:       nil,
-			expectedErr: "invalid dig.As(nil): argument must be a pointer to an interface",
-		},
-		{
-			name:        "as param is a func",
-			param:       func() {},
-			expectedErr: "invalid dig.As(func()): argument must be a pointer to an interface",
-		},
-		{
-			name:        "as param is a func returning dig_test.out",
-			param:       func() *out { return &out{name: "example"} },
-			expectedErr: "invalid dig.As(func() *dig_test.out): argument must be a pointer to an interface",
-		},
-		{
-			name:        "as param is not implemented by provided type",
-			param:       new(io.ReadCloser),
-			expectedErr: "invalid dig.As: *bytes.Buffer does not implement io.ReadCloser",
-		},
-		{
-			name:        "as param is not implemented by provided type",
-			param:       new(io.ReadCloser),
-			expectedErr: "invalid dig.As: *bytes.Buffer does not implement io.ReadCloser",
-			addlOption:  dig.Group("readclosers"),
-		},
-	}
-	for _, tt := range tests {
-		tt := tt
-		t.Run(tt.name, func(t *testing.T) {
-			t.Parallel()
+	c.RequireInvoke(func(p params) {
+		assert.NotNil(t, p.Writer)
+		assert.Len(t, p.Readers, 2)
+	})
 
-			c := digtest.New(t)
-			var err error
-			if tt.addlOption == nil {
-				err = c.Provide(
-					func() *bytes.Buffer {
-						var buf bytes.Buffer
-						return &buf
-					},
-					dig.As(tt.param),
-				)
-			} else {
-				err = c.Provide(
-					func() *bytes.Buffer {
-						var buf bytes.Buffer
-						return &buf
-					},
-					dig.As(tt.param),
-					tt.addlOption,
-				)
-			}
+	t.Run("scopes use the same keys", func(t *testing.T) {
+		c.Scope("child").RequireInvoke(func(p params) {
+			assert.NotNil(t, p.Writer)
+		})
+	})
 
-			require.Error(t, err, "provide must fail")
-			assert.Contains(t, err.Error(), tt.expectedErr)
+	t.Run("default keys are no longer read", func(t *testing.T) {
+		err := c.Invoke(func(struct {
+			dig.In
+
+			Writer io.Writer `name:"out"`
+		}) {
 		})
-	}
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: io.Writer")
+	})
 }
 
-func TestAsExpectingOriginalType(t *testing.T) {
+func TestParamTags(t *testing.T) {
 	t.Parallel()
 
-	t.Run("fail on expecting original type", func(t *testing.T) {
-		c := digtest.New(t)
+	type ServerOptions struct{ port int }
+	type Server struct {
+		opts *ServerOptions
+		name string
+	}
 
-		c.RequireProvide(
-			func() *bytes.Buffer {
-				return bytes.NewBufferString("foo")
-			},
-			dig.As(new(io.Reader)),
-			dig.Name("buff"))
+	newServer := func(opts *ServerOptions, name string) *Server {
+		return &Server{opts: opts, name: name}
+	}
 
-		type in struct {
-			dig.In
+	t.Run("optional and named", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "ro" }, dig.Name("ro"))
 
-			Buffer *bytes.Buffer `name:"buff"`
-			Reader io.Reader     `name:"buff"`
-		}
+		var info dig.ProvideInfo
+		c.RequireProvide(newServer,
+			dig.ParamTags(`optional:"true"`, `name:"ro"`),
+			dig.FillProvideInfo(&info))
 
-		require.Error(t, c.Invoke(func(got in) {
-			t.Fatal("*bytes.Buffer with name buff shouldn't be provided")
-		}))
+		c.RequireInvoke(func(s *Server) {
+			assert.Nil(t, s.opts)
+			assert.Equal(t, "ro", s.name)
+		})
+
+		require.Len(t, info.Inputs, 2)
+		assert.Equal(t, "*dig_test.ServerOptions[optional]", info.Inputs[0].String())
+		assert.Equal(t, `string[name = "ro"]`, info.Inputs[1].String())
 	})
-}
 
-func TestProvideIncompatibleOptions(t *testing.T) {
-	t.Parallel()
+	t.Run("fewer tags than parameters", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(func() *ServerOptions { return &ServerOptions{port: 8080} })
+		c.RequireProvide(func() string { return "default" })
+		c.RequireProvide(newServer, dig.ParamTags(""))
 
-	t.Run("group and name", func(t *testing.T) {
+		c.RequireInvoke(func(s *Server) {
+			assert.Equal(t, 8080, s.opts.port)
+			assert.Equal(t, "default", s.name)
+		})
+	})
+
+	t.Run("value group", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "a" }, dig.Group("names"))
+		c.RequireProvide(func() string { return "b" }, dig.Group("names"))
+		c.RequireProvide(func(names []string) int { return len(names) }, dig.ParamTags(`group:"names"`))
+
+		c.RequireInvoke(func(n int) {
+			assert.Equal(t, 2, n)
+		})
+	})
+
+	t.Run("too many tags", func(t *testing.T) {
+		c := digtest.New(t)
+		err := c.Provide(newServer, dig.ParamTags("", "", `optional:"true"`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot apply 3 parameter tags to func(*dig_test.ServerOptions, string) *dig_test.Server: it has 2 parameters")
+	})
+
+	t.Run("invalid tag", func(t *testing.T) {
+		c := digtest.New(t)
+		err := c.Provide(newServer, dig.ParamTags(`optional:"maybe"`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `bad tags for argument 1: invalid value "maybe" for "optional" tag on field Arg1`)
+	})
+
+	t.Run("parameter object", func(t *testing.T) {
+		type params struct {
+			dig.In
+
+			Name string
+		}
+
+		c := digtest.New(t)
+		err := c.Provide(func(params) int { return 0 }, dig.ParamTags(`optional:"true"`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot apply tags to argument 1: dig_test.params is a parameter object")
+	})
+
+	t.Run("resolver", func(t *testing.T) {
+		c := digtest.New(t)
+		err := c.Provide(func(dig.Resolver) int { return 0 }, dig.ParamTags(`name:"r"`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			"cannot apply tags to argument 1: dig.Resolver is a Resolver, tags can only be applied to plain values")
+	})
+}
+
+func TestMixedPositionalAndParamObjects(t *testing.T) {
+	t.Parallel()
+
+	type DB struct{ name string }
+	type Cache struct{}
+
+	type params struct {
+		dig.In
+
+		Cache *Cache
+		RO    *DB           `name:"ro"`
+		Ints  []int         `group:"ints"`
+		Buf   *bytes.Buffer `optional:"true"`
+	}
+
+	newContainer := func(t *testing.T) *digtest.Container {
+		c := digtest.New(t)
+		c.RequireProvide(func() *DB { return &DB{name: "main"} })
+		c.RequireProvide(func() *DB { return &DB{name: "ro"} }, dig.Name("ro"))
+		c.RequireProvide(func() *Cache { return &Cache{} })
+		c.RequireProvide(func() int { return 1 }, dig.Group("ints"))
+		c.RequireProvide(func() int { return 2 }, dig.Group("ints"))
+		return c
+	}
+
+	check := func(t *testing.T, db *DB, p params) {
+		assert.Equal(t, "main", db.name, "positional argument")
+		assert.NotNil(t, p.Cache)
+		assert.Equal(t, "ro", p.RO.name, "named field")
+		assert.ElementsMatch(t, []int{1, 2}, p.Ints, "group field")
+		assert.Nil(t, p.Buf, "optional field")
+	}
+
+	t.Run("parameter object last", func(t *testing.T) {
+		t.Parallel()
+
+		c := newContainer(t)
+		type A struct{}
+		c.RequireProvide(func(db *DB, p params) *A {
+			check(t, db, p)
+			return &A{}
+		})
+		c.RequireInvoke(func(*A) {})
+	})
+
+	t.Run("parameter object in the middle", func(t *testing.T) {
+		t.Parallel()
+
+		c := newContainer(t)
+		c.RequireInvoke(func(db *DB, p params, cache *Cache) {
+			check(t, db, p)
+			assert.Same(t, p.Cache, cache)
+		})
+	})
+
+	t.Run("several parameter objects", func(t *testing.T) {
+		t.Parallel()
+
+		c := newContainer(t)
+		c.RequireInvoke(func(p1 params, db *DB, p2 params) {
+			check(t, db, p1)
+			check(t, db, p2)
+		})
+	})
+
+	t.Run("param tags skip parameter objects", func(t *testing.T) {
+		t.Parallel()
+
+		c := newContainer(t)
+		type A struct{}
+		c.RequireProvide(func(p params, db *DB) *A {
+			assert.Equal(t, "ro", db.name)
+			return &A{}
+		}, dig.ParamTags("", `name:"ro"`))
+		c.RequireInvoke(func(*A) {})
+
+		err := c.Provide(func(db *DB, p params) *Cache { return nil }, dig.ParamTags("", `name:"x"`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot apply tags to argument 2: dig_test.params is a parameter object")
+	})
+
+	t.Run("decorator", func(t *testing.T) {
+		t.Parallel()
+
+		type decorateParams struct {
+			dig.In
+
+			Cache *Cache
+		}
+
+		c := newContainer(t)
+		c.RequireDecorate(func(db *DB, p decorateParams) *DB {
+			assert.NotNil(t, p.Cache)
+			return &DB{name: db.name + "-decorated"}
+		})
+		c.RequireInvoke(func(db *DB, p params) {
+			assert.Equal(t, "main-decorated", db.name)
+		})
+	})
+
+	t.Run("scope", func(t *testing.T) {
+		t.Parallel()
+
+		c := newContainer(t)
+		c.Scope("child").RequireInvoke(func(db *DB, p params) {
+			check(t, db, p)
+		})
+	})
+
+	t.Run("errors name the argument", func(t *testing.T) {
+		t.Parallel()
+
+		type bad struct {
+			dig.In
+
+			name string
+		}
+
+		c := newContainer(t)
+		err := c.Invoke(func(db *DB, cache *Cache, b bad) { _ = b.name })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `bad argument 3: bad field "name" of dig_test.bad`)
+
+		type missing struct {
+			dig.In
+
+			Buf *bytes.Buffer
+		}
+		err = c.Invoke(func(db *DB, m missing) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: *bytes.Buffer")
+	})
+}
+
+func TestResultTags(t *testing.T) {
+	t.Parallel()
+
+	type Conn struct{ mode string }
+
+	newConns := func() (*Conn, *Conn, error) {
+		return &Conn{mode: "ro"}, &Conn{mode: "rw"}, nil
+	}
+
+	t.Run("named results", func(t *testing.T) {
+		type params struct {
+			dig.In
+
+			RO *Conn `name:"ro"`
+			RW *Conn `name:"rw"`
+		}
+
+		c := digtest.New(t)
+		var info dig.ProvideInfo
+		c.RequireProvide(newConns,
+			dig.ResultTags(`name:"ro"`, `name:"rw"`),
+			dig.FillProvideInfo(&info))
+
+		c.RequireInvoke(func(p params) {
+			assert.Equal(t, "ro", p.RO.mode)
+			assert.Equal(t, "rw", p.RW.mode)
+		})
+
+		require.Len(t, info.Outputs, 2)
+		assert.Equal(t, `*dig_test.Conn[name = "ro"]`, info.Outputs[0].String())
+		assert.Equal(t, `*dig_test.Conn[name = "rw"]`, info.Outputs[1].String())
+	})
+
+	t.Run("fewer tags than results", func(t *testing.T) {
+		type params struct {
+			dig.In
+
+			Name string `name:"primary"`
+			Port int
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (string, int) { return "db", 5432 }, dig.ResultTags(`name:"primary"`))
+
+		c.RequireInvoke(func(p params) {
+			assert.Equal(t, "db", p.Name)
+			assert.Equal(t, 5432, p.Port)
+		})
+	})
+
+	t.Run("flattened value group", func(t *testing.T) {
+		type params struct {
+			dig.In
+
+			Names []string `group:"names"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() ([]string, int) { return []string{"a", "b"}, 1 },
+			dig.ResultTags(`group:"names,flatten"`))
+		c.RequireProvide(func() string { return "c" }, dig.Group("names"))
+
+		c.RequireInvoke(func(p params, n int) {
+			assert.ElementsMatch(t, []string{"a", "b", "c"}, p.Names)
+			assert.Equal(t, 1, n)
+		})
+	})
+
+	t.Run("too many tags", func(t *testing.T) {
+		c := digtest.New(t)
+		err := c.Provide(newConns, dig.ResultTags("", "", `name:"extra"`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			"cannot apply 3 result tags to func() (*dig_test.Conn, *dig_test.Conn, error): it has 2 non-error results")
+	})
+
+	t.Run("invalid tag", func(t *testing.T) {
+		c := digtest.New(t)
+		err := c.Provide(newConns, dig.ResultTags(`name:"ro" group:"conns"`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "bad result 1")
+		assert.Contains(t, err.Error(), "cannot use named values with value groups")
+	})
+
+	t.Run("result object", func(t *testing.T) {
+		type out struct {
+			dig.Out
+
+			Conn *Conn
+		}
+
+		c := digtest.New(t)
+		err := c.Provide(func() out { return out{} }, dig.ResultTags(`name:"ro"`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot apply tags to a result object: dig_test.out embeds dig.Out")
+	})
+
+	t.Run("conflicts with Name", func(t *testing.T) {
+		c := digtest.New(t)
+		err := c.Provide(newConns, dig.ResultTags(`name:"ro"`), dig.Name("conn"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot use dig.ResultTags with dig.Name or dig.Group")
+	})
+}
+
+func TestProvideValue(t *testing.T) {
+	t.Parallel()
+
+	t.Run("concrete value", func(t *testing.T) {
+		c := digtest.New(t)
+		require.NoError(t, c.ProvideValue(reflect.ValueOf(42)))
+
+		c.RequireInvoke(func(i int) {
+			assert.Equal(t, 42, i)
+		})
+	})
+
+	t.Run("interface type is preserved", func(t *testing.T) {
+		var r io.Reader = new(bytes.Buffer)
+
+		c := digtest.New(t)
+		require.NoError(t, c.ProvideValue(reflect.ValueOf(&r).Elem()))
+
+		c.RequireInvoke(func(got io.Reader) {
+			assert.True(t, got == r, "must provide the same reader")
+		})
+		err := c.Invoke(func(*bytes.Buffer) {})
+		require.Error(t, err, "must not provide the concrete type")
+	})
+
+	t.Run("name and group", func(t *testing.T) {
+		type params struct {
+			dig.In
+
+			Named  string   `name:"foo"`
+			Values []string `group:"bar"`
+		}
+
+		c := digtest.New(t)
+		require.NoError(t, c.ProvideValue(reflect.ValueOf("a"), dig.Name("foo")))
+		require.NoError(t, c.ProvideValue(reflect.ValueOf("b"), dig.Group("bar")))
+		require.NoError(t, c.ProvideValue(reflect.ValueOf("c"), dig.Group("bar")))
+
+		c.RequireInvoke(func(p params) {
+			assert.Equal(t, "a", p.Named)
+			assert.ElementsMatch(t, []string{"b", "c"}, p.Values)
+		})
+	})
+
+	t.Run("scope", func(t *testing.T) {
+		c := dig.New()
+		s := c.Scope("child")
+		require.NoError(t, s.ProvideValue(reflect.ValueOf(42)))
+		assert.NoError(t, s.Invoke(func(i int) {
+			assert.Equal(t, 42, i)
+		}))
+		assert.Error(t, c.Invoke(func(int) {}), "value must not be visible to the parent")
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		c := digtest.New(t)
+		err := c.ProvideValue(reflect.Value{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "can't provide an invalid reflect.Value")
+	})
+
+	t.Run("errors refer to the caller", func(t *testing.T) {
+		c := digtest.New(t)
+		require.NoError(t, c.ProvideValue(reflect.ValueOf(42)))
+		err := c.ProvideValue(reflect.ValueOf(43))
+		require.Error(t, err)
+		assert.Regexp(t, `TestProvideValue\S+ \(\S+dig_test.go:\d+\)`, err.Error())
+		assert.Contains(t, err.Error(), "already provided")
+	})
+}
+
+func TestIfNotProvided(t *testing.T) {
+	t.Parallel()
+
+	type Logger struct{ name string }
+	type Metrics struct{}
+
+	newDefaultLogger := func() *Logger { return &Logger{name: "default"} }
+
+	t.Run("provided when absent", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(newDefaultLogger, dig.IfNotProvided())
+		c.RequireInvoke(func(l *Logger) {
+			assert.Equal(t, "default", l.name)
+		})
+	})
+
+	t.Run("skipped when present", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Logger { return &Logger{name: "app"} })
+		c.RequireProvide(func() *Logger {
+			t.Fatal("must not be called")
+			return nil
+		}, dig.IfNotProvided())
+		c.RequireProvide(newDefaultLogger, dig.IfNotProvided())
+
+		c.RequireInvoke(func(l *Logger) {
+			assert.Equal(t, "app", l.name)
+		})
+	})
+
+	t.Run("named", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Logger { return &Logger{name: "app"} }, dig.Name("audit"))
+		c.RequireProvide(newDefaultLogger, dig.Name("audit"), dig.IfNotProvided())
+		c.RequireProvide(newDefaultLogger, dig.IfNotProvided())
+
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Default *Logger
+			Audit   *Logger `name:"audit"`
+		}) {
+			assert.Equal(t, "default", p.Default.name)
+			assert.Equal(t, "app", p.Audit.name)
+		})
+	})
+
+	t.Run("provided by parent scope", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Logger { return &Logger{name: "app"} })
+		child := c.Scope("child")
+		child.RequireProvide(newDefaultLogger, dig.IfNotProvided())
+
+		child.RequireInvoke(func(l *Logger) {
+			assert.Equal(t, "app", l.name)
+		})
+	})
+
+	t.Run("skipped constructor is not in the graph", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Logger { return &Logger{name: "app"} })
+		c.RequireProvide(func(*Metrics) *Logger { return nil }, dig.IfNotProvided())
+
+		c.RequireInvoke(func(*Logger) {})
+		assert.Empty(t, c.MissingDependencies(), "skipped constructor must not be checked")
+	})
+
+	t.Run("some results provided", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Logger { return &Logger{} })
+		err := c.Provide(func() (*Logger, *Metrics) { return nil, nil }, dig.IfNotProvided())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			"cannot use dig.IfNotProvided: [*dig_test.Logger] already provided, but [*dig_test.Metrics] not provided")
+	})
+
+	t.Run("value groups", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Provide(newDefaultLogger, dig.Group("loggers"), dig.IfNotProvided())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `cannot use dig.IfNotProvided with value groups: group:"loggers" provided`)
+	})
+}
+
+func TestCheckNilInterfaces(t *testing.T) {
+	t.Parallel()
+
+	t.Run("As with nil pointer", func(t *testing.T) {
+		c := digtest.New(t, dig.CheckNilInterfaces())
+		c.RequireProvide(func() *bytes.Buffer { return nil }, dig.As(new(io.Reader)))
+
+		err := c.Invoke(func(io.Reader) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to build io.Reader")
+		assert.Regexp(t, `function "go.uber.org/dig_test".TestCheckNilInterfaces\S+ \(\S+\) `+
+			`provided a nil \*bytes.Buffer as io.Reader, which is not a nil interface`, err.Error())
+	})
+
+	t.Run("interface result wrapping nil pointer", func(t *testing.T) {
+		c := digtest.New(t, dig.CheckNilInterfaces())
+		c.RequireProvide(func() io.Reader {
+			var buf *bytes.Buffer
+			return buf
+		}, dig.Name("in"))
+
+		type params struct {
+			dig.In
+
+			Reader io.Reader `name:"in"`
+		}
+		err := c.Invoke(func(params) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `provided a nil *bytes.Buffer as io.Reader[name="in"]`)
+	})
+
+	t.Run("value group", func(t *testing.T) {
+		c := digtest.New(t, dig.CheckNilInterfaces())
+		c.RequireProvide(func() io.Reader {
+			var buf *bytes.Buffer
+			return buf
+		}, dig.Group("readers"))
+
+		type params struct {
+			dig.In
+
+			Readers []io.Reader `group:"readers"`
+		}
+		err := c.Invoke(func(params) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `provided a nil *bytes.Buffer as io.Reader[group="readers"]`)
+	})
+
+	t.Run("nil interface and concrete nil are allowed", func(t *testing.T) {
+		c := digtest.New(t, dig.CheckNilInterfaces())
+		c.RequireProvide(func() io.Reader { return nil })
+		c.RequireProvide(func() *bytes.Buffer { return nil })
+
+		c.RequireInvoke(func(r io.Reader, buf *bytes.Buffer) {
+			assert.Nil(t, r)
+			assert.Nil(t, buf)
+		})
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(func() *bytes.Buffer { return nil }, dig.As(new(io.Reader)))
+
+		c.RequireInvoke(func(r io.Reader) {
+			assert.True(t, r != nil, "interface must wrap the nil pointer")
+		})
+	})
+}
+
+func TestProvideInvalidName(t *testing.T) {
+	t.Parallel()
+
+	c := digtest.New(t)
+	err := c.Provide(func() io.Reader {
+		t.Fatal("this function must not be called")
+		return nil
+	}, dig.Name("foo`bar"))
+	require.Error(t, err, "Provide must fail")
+	assert.Contains(t, err.Error(), "invalid dig.Name(\"foo`bar\"): names cannot contain backquotes")
+}
+
+func TestProvideInvalidGroup(t *testing.T) {
+	t.Parallel()
+
+	c := digtest.New(t)
+	err := c.Provide(func() io.Reader {
+		t.Fatal("this function must not be called")
+		return nil
+	}, dig.Group("foo`bar"))
+	require.Error(t, err, "Provide must fail")
+	assert.Contains(t, err.Error(), "invalid dig.Group(\"foo`bar\"): group names cannot contain backquotes")
+
+	err = c.Provide(func() io.Reader {
+		t.Fatal("this function must not be called")
+		return nil
+	}, dig.Group("foo,bar"))
+	require.Error(t, err, "Provide must fail")
+	assert.Contains(t, err.Error(), `cannot parse group "foo,bar": invalid option "bar"`)
+
+	err = c.Invoke(func(struct {
+		dig.In
+
+		Readers []io.Reader `group:"foo,,flatten"`
+	}) {
+		t.Fatal("this function must not be called")
+	})
+	require.Error(t, err, "Invoke must fail")
+	assert.Contains(t, err.Error(), `cannot parse group "foo,,flatten" of field "Readers": invalid option "": options cannot be empty`)
+}
+
+func TestProvideInvalidAs(t *testing.T) {
+	ptrToStruct := &struct {
+		name string
+	}{
+		name: "example",
+	}
+	type out struct {
+		dig.Out
+
+		name string
+	}
+	var nilInterface io.Reader
+
+	tests := []struct {
+		name        string
+		param       interface{}
+		expectedErr string
+		addlOption  dig.ProvideOption
+	}{
+		{
+			name:        "as param is not an type interface",
+			param:       123,
+			expectedErr: "invalid dig.As(int): argument must be a pointer to an interface",
+		},
+		{
+			name:        "as param is a pointer to struct",
+			param:       ptrToStruct,
+			expectedErr: "invalid dig.As(*struct { name string }): argument must be a pointer to an interface",
+		},
+		{
+			name:        "as param is a nil interface",
+			param:       nilInterface,
+			expectedErr: "invalid dig.As(nil): argument must be a pointer to an interface",
+		},
+		{
+			name:        "as param is a nil",
+			param:       nil,
+			expectedErr: "invalid dig.As(nil): argument must be a pointer to an interface",
+		},
+		{
+			name:        "as param is a func",
+			param:       func() {},
+			expectedErr: "invalid dig.As(func()): argument must be a pointer to an interface",
+		},
+		{
+			name:        "as param is a func returning dig_test.out",
+			param:       func() *out { return &out{name: "example"} },
+			expectedErr: "invalid dig.As(func() *dig_test.out): argument must be a pointer to an interface",
+		},
+		{
+			name:        "as param is not implemented by provided type",
+			param:       new(io.ReadCloser),
+			expectedErr: "invalid dig.As: *bytes.Buffer does not implement io.ReadCloser",
+		},
+		{
+			name:        "as param is not implemented by provided type",
+			param:       new(io.ReadCloser),
+			expectedErr: "invalid dig.As: *bytes.Buffer does not implement io.ReadCloser",
+			addlOption:  dig.Group("readclosers"),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := digtest.New(t)
+			var err error
+			if tt.addlOption == nil {
+				err = c.Provide(
+					func() *bytes.Buffer {
+						var buf bytes.Buffer
+						return &buf
+					},
+					dig.As(tt.param),
+				)
+			} else {
+				err = c.Provide(
+					func() *bytes.Buffer {
+						var buf bytes.Buffer
+						return &buf
+					},
+					dig.As(tt.param),
+					tt.addlOption,
+				)
+			}
+
+			require.Error(t, err, "provide must fail")
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+func TestAsExpectingOriginalType(t *testing.T) {
+	t.Parallel()
+
+	t.Run("fail on expecting original type", func(t *testing.T) {
+		c := digtest.New(t)
+
+		c.RequireProvide(
+			func() *bytes.Buffer {
+				return bytes.NewBufferString("foo")
+			},
+			dig.As(new(io.Reader)),
+			dig.Name("buff"))
+
+		type in struct {
+			dig.In
+
+			Buffer *bytes.Buffer `name:"buff"`
+			Reader io.Reader     `name:"buff"`
+		}
+
+		require.Error(t, c.Invoke(func(got in) {
+			t.Fatal("*bytes.Buffer with name buff shouldn't be provided")
+		}))
+	})
+}
+
+func TestProvideIncompatibleOptions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("group and name", func(t *testing.T) {
 		c := digtest.New(t)
 		err := c.Provide(func() io.Reader {
 			t.Fatal("this function must not be called")
@@ -2090,6 +2895,53 @@ func TestCantProvideParameterObjects(t *testing.T) {
 	})
 }
 
+func TestCantProvideWrappedResultObjects(t *testing.T) {
+	t.Parallel()
+
+	type Result struct {
+		dig.Out
+
+		Reader io.Reader
+	}
+
+	t.Run("slice", func(t *testing.T) {
+		c := digtest.New(t)
+		err := c.Provide(func() (*bytes.Buffer, []Result) {
+			panic("great sadness")
+		})
+		require.Error(t, err, "provide should fail")
+		dig.AssertErrorMatches(t, err,
+			`cannot provide function "go.uber.org/dig_test".TestCantProvideWrappedResultObjects\S+`,
+			`dig_test.go:\d+`, // file:line
+			"bad result 2:",
+			`cannot return a slice of result objects, .+: result 2 of type \[\]dig_test.Result contains a struct that embeds dig.Out`,
+		)
+	})
+
+	t.Run("pointer to pointer", func(t *testing.T) {
+		c := digtest.New(t)
+		err := c.Provide(func() **Result {
+			panic("great sadness")
+		})
+		require.Error(t, err, "provide should fail")
+		dig.AssertErrorMatches(t, err,
+			`cannot provide function "go.uber.org/dig_test".TestCantProvideWrappedResultObjects\S+`,
+			`dig_test.go:\d+`, // file:line
+			"bad result 1:",
+			`cannot return a pointer to a pointer to a result object, return the result object by value instead: result 1 of type \*\*dig_test.Result contains a struct that embeds dig.Out`,
+		)
+	})
+
+	t.Run("value group", func(t *testing.T) {
+		c := digtest.New(t)
+		err := c.Provide(func() []Result {
+			panic("great sadness")
+		}, dig.Group("results,flatten"))
+		require.Error(t, err, "provide should fail")
+		assert.Contains(t, err.Error(), "cannot return a slice of result objects")
+	})
+}
+
 func TestProvideKnownTypesFails(t *testing.T) {
 	t.Parallel()
 
@@ -2156,6 +3008,52 @@ func TestDryModeSuccess(t *testing.T) {
 		c.RequireDecorate(decorates)
 		c.RequireInvoke(invokes)
 	})
+	t.Run("placeholders are not nil interfaces", func(t *testing.T) {
+		c := digtest.New(t, dig.DryRun(true), dig.CheckNilInterfaces())
+		c.RequireProvide(func() *bytes.Buffer {
+			t.Fatal("must not be called")
+			return nil
+		}, dig.As(new(io.Reader)))
+		c.RequireProvide(func() map[string]int {
+			t.Fatal("must not be called")
+			return nil
+		}, dig.Group("maps"))
+		c.RequireInvoke(func(io.Reader, struct {
+			dig.In
+
+			Maps []map[string]int `group:"maps"`
+		}) {
+		})
+	})
+	t.Run("optional results are not omitted", func(t *testing.T) {
+		type type1 struct{}
+		type out struct {
+			dig.Out
+
+			T1     *type1    `optional:"true"`
+			Reader io.Reader `optional:"true"`
+		}
+		c := digtest.New(t, dig.DryRun(true))
+		c.RequireProvide(func() out {
+			t.Fatal("must not be called")
+			return out{}
+		})
+		c.RequireInvoke(func(*type1) {})
+
+		err := c.Invoke(func(io.Reader) {})
+		require.Error(t, err, "interfaces have no placeholders")
+		assert.Contains(t, err.Error(), "optional result was omitted")
+	})
+	t.Run("cleanup results", func(t *testing.T) {
+		type type1 struct{}
+		c := digtest.New(t, dig.DryRun(true))
+		c.RequireProvide(func() (*type1, func(context.Context) error) {
+			t.Fatal("must not be called")
+			return nil, nil
+		})
+		c.RequireInvoke(func(*type1) {})
+		assert.NoError(t, c.RunCleanupsContext(context.Background()))
+	})
 }
 
 func TestProvideCycleFails(t *testing.T) {
@@ -2375,6 +3273,134 @@ func testProvideCycleFails(t *testing.T, dryRun bool) {
 			`depends on func\(\*dig_test.C\) \*dig_test.C provided by "go.uber.org/dig_test".testProvideCycleFails.\S+ \(\S+\)`,
 		)
 	})
+
+	t.Run("DeferAcyclicVerification only verifies the invoked subgraph", func(t *testing.T) {
+		// A <-> B    X <- Y
+		type A struct{}
+		type B struct{}
+		type X struct{}
+		type Y struct{}
+
+		c := digtest.New(t, dig.DeferAcyclicVerification())
+		c.RequireProvide(func(*B) *A { return &A{} })
+		c.RequireProvide(func(*A) *B { return &B{} })
+		c.RequireProvide(func(*Y) *X { return &X{} })
+		c.RequireProvide(func() *Y { return &Y{} })
+
+		c.RequireInvoke(func(*X) {})
+
+		err := c.Invoke(func(*A) {})
+		require.Error(t, err, "expected error when depending on cycle")
+		assert.True(t, dig.IsCycleDetected(err))
+		dig.AssertErrorMatches(t, err,
+			`cycle detected in dependency graph:`,
+			`func\(\*dig_test.B\) \*dig_test.A provided by "go.uber.org/dig_test".testProvideCycleFails.\S+ \(\S+\)`,
+			`depends on func\(\*dig_test.A\) \*dig_test.B provided by "go.uber.org/dig_test".testProvideCycleFails.\S+ \(\S+\)`,
+			`depends on func\(\*dig_test.B\) \*dig_test.A provided by "go.uber.org/dig_test".testProvideCycleFails.\S+ \(\S+\)`,
+		)
+
+		c.RequireInvoke(func(*X, *Y) {})
+	})
+
+	t.Run("DeferAcyclicVerification verifies again after Provide", func(t *testing.T) {
+		type A struct{}
+		type B struct{}
+		type C struct{}
+		type params struct {
+			dig.In
+
+			B *B
+			C *C
+		}
+
+		c := digtest.New(t, dig.DeferAcyclicVerification())
+		c.RequireProvide(func(params) *A { return &A{} })
+		c.RequireProvide(func() (*B, error) { return nil, errors.New("great sadness") })
+		// Verifies A, but fails before C is consumed.
+		require.Error(t, c.Invoke(func(*A) {}))
+
+		// A -> C -> A
+		c.RequireProvide(func(*A) *C { return &C{} })
+
+		err := c.Invoke(func(*C) {})
+		require.Error(t, err, "expected error when depending on cycle")
+		assert.True(t, dig.IsCycleDetected(err))
+	})
+}
+
+// optionalCycleA and optionalCycleB depend on each other. They are declared
+// at package level because local types cannot refer to types declared
+// after them.
+type (
+	optionalCycleA struct{ B *optionalCycleB }
+	optionalCycleB struct{ A *optionalCycleA }
+)
+
+func TestProvideOptionalCycle(t *testing.T) {
+	t.Parallel()
+
+	type A = optionalCycleA
+	type B = optionalCycleB
+	type params struct {
+		dig.In
+
+		A *A `optional:"true"`
+	}
+
+	t.Run("broken with the zero value", func(t *testing.T) {
+		t.Parallel()
+
+		// A -> B ~> A, where ~> is optional.
+		c := digtest.New(t)
+		c.RequireProvide(func(b *B) *A { return &A{B: b} })
+		c.RequireProvide(func(p params) *B { return &B{A: p.A} })
+
+		c.RequireInvoke(func(a *A) {
+			require.NotNil(t, a.B)
+			assert.Nil(t, a.B.A, "optional dependency on the cycle must be zero")
+		})
+	})
+
+	t.Run("entered through the optional edge", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func(b *B) *A { return &A{B: b} })
+		c.RequireProvide(func(p params) *B { return &B{A: p.A} })
+
+		// A cannot be built while B, which it depends on, is being built.
+		c.RequireInvoke(func(b *B) {
+			assert.Nil(t, b.A, "optional dependency on the cycle must be zero")
+		})
+	})
+
+	t.Run("DeferAcyclicVerification", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.DeferAcyclicVerification())
+		c.RequireProvide(func(b *B) *A { return &A{B: b} })
+		c.RequireProvide(func(p params) *B { return &B{A: p.A} })
+		c.RequireInvoke(func(*A) {})
+	})
+
+	t.Run("required edges still form cycles", func(t *testing.T) {
+		t.Parallel()
+
+		// A -> B -> A, beside B ~> A.
+		type required struct {
+			dig.In
+
+			A    *A
+			Also *A `name:"also" optional:"true"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func(b *B) *A { return &A{B: b} })
+		c.RequireProvide(func(b *B) *A { return &A{B: b} }, dig.Name("also"))
+		err := c.Provide(func(p required) *B { return &B{A: p.A} })
+		require.Error(t, err, "expected error when introducing cycle")
+		assert.True(t, dig.IsCycleDetected(err))
+	})
 }
 
 func TestProvideErrNonCycle(t *testing.T) {
@@ -2678,6 +3704,24 @@ func testProvideFailures(t *testing.T, dryRun bool) {
 			`cannot provide function "go.uber.org/dig_test".testProvideFailures.func\d+.1`,
 		)
 	})
+
+	t.Run("invalid options should refer to location given by LocationForPC ProvideOption", func(t *testing.T) {
+		c := digtest.New(t)
+		type A struct{}
+
+		locationFn := func() {}
+
+		err := c.Provide(func() A { return A{} },
+			dig.Name("foo"),
+			dig.Group("bar"),
+			dig.LocationForPC(reflect.ValueOf(locationFn).Pointer()),
+		)
+		require.Error(t, err, "provide must return error")
+		dig.AssertErrorMatches(t, err,
+			`cannot provide function "go.uber.org/dig_test".testProvideFailures.func\d+.1`,
+			"cannot use named values with value groups",
+		)
+	})
 }
 
 func TestInvokeFailures(t *testing.T) {
@@ -3560,6 +4604,46 @@ func BenchmarkProvideCycleDetection(b *testing.B) {
 	}
 }
 
+func BenchmarkProvideManyProviders(b *testing.B) {
+	type Config struct{}
+
+	const numProviders = 10000
+	names := make([]string, numProviders)
+	for i := range names {
+		names[i] = fmt.Sprintf("svc%d", i)
+	}
+
+	newConfig := func() *Config { return &Config{} }
+	newService := func(*Config) string { return "" }
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		c := digtest.New(b)
+		c.RequireProvide(newConfig)
+		for _, name := range names {
+			c.RequireProvide(newService, dig.Name(name))
+		}
+	}
+}
+
+func BenchmarkProvideLeafProviders(b *testing.B) {
+	const numProviders = 10000
+	names := make([]string, numProviders)
+	for i := range names {
+		names[i] = fmt.Sprintf("leaf%d", i)
+	}
+
+	newLeaf := func() string { return "" }
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		c := digtest.New(b)
+		for _, name := range names {
+			c.RequireProvide(newLeaf, dig.Name(name))
+		}
+	}
+}
+
 func TestUnexportedFieldsFailures(t *testing.T) {
 	t.Run("empty tag value", func(t *testing.T) {
 		type type1 struct{}
@@ -3709,35 +4793,113 @@ func TestProvideInfoOption(t *testing.T) {
 		assert.Equal(t, `*dig_test.type4[group = "g"]`, info.Outputs[1].String())
 	})
 
-	t.Run("two ctors", func(t *testing.T) {
-		type type1 struct{}
-		type type2 struct{}
-		type type3 struct{}
-		type type4 struct{}
-		ctor1 := func(*type1) *type2 {
-			return &type2{}
-		}
-		ctor2 := func(*type3) *type4 {
-			return &type4{}
-		}
-		c := digtest.New(t)
-		info1 := dig.ProvideInfo{}
-		info2 := dig.ProvideInfo{}
-		c.RequireProvide(ctor1, dig.FillProvideInfo(&info1))
-		c.RequireProvide(ctor2, dig.FillProvideInfo(&info2))
+	t.Run("two ctors", func(t *testing.T) {
+		type type1 struct{}
+		type type2 struct{}
+		type type3 struct{}
+		type type4 struct{}
+		ctor1 := func(*type1) *type2 {
+			return &type2{}
+		}
+		ctor2 := func(*type3) *type4 {
+			return &type4{}
+		}
+		c := digtest.New(t)
+		info1 := dig.ProvideInfo{}
+		info2 := dig.ProvideInfo{}
+		c.RequireProvide(ctor1, dig.FillProvideInfo(&info1))
+		c.RequireProvide(ctor2, dig.FillProvideInfo(&info2))
+
+		assert.NotEqual(t, info1.ID, info2.ID)
+
+		assert.Equal(t, 1, len(info1.Inputs))
+		assert.Equal(t, 1, len(info1.Outputs))
+		assert.Equal(t, 1, len(info2.Inputs))
+		assert.Equal(t, 1, len(info2.Outputs))
+
+		assert.Equal(t, "*dig_test.type1", info1.Inputs[0].String())
+		assert.Equal(t, "*dig_test.type2", info1.Outputs[0].String())
+
+		assert.Equal(t, "*dig_test.type3", info2.Inputs[0].String())
+		assert.Equal(t, "*dig_test.type4", info2.Outputs[0].String())
+	})
+
+	t.Run("tags", func(t *testing.T) {
+		type type1 struct{}
+
+		c := digtest.New(t)
+		var info dig.ProvideInfo
+		c.RequireProvide(func() *type1 { return &type1{} },
+			dig.Tags(map[string]string{"team": "payments", "tier": "2"}),
+			dig.Tags(map[string]string{"tier": "1"}),
+			dig.FillProvideInfo(&info))
+		assert.Equal(t, map[string]string{"team": "payments", "tier": "1"}, info.Tags)
+
+		var again dig.ProvideInfo
+		c.RequireProvide(func() *type1 { return &type1{} }, dig.Name("again"), dig.FillProvideInfo(&again))
+		assert.Nil(t, again.Tags, "constructors without tags report none")
+	})
+
+	t.Run("empty tag key", func(t *testing.T) {
+		c := digtest.New(t)
+		err := c.Provide(func() int { return 0 }, dig.Tags(map[string]string{"": "x"}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid dig.Tags: keys cannot be empty")
+	})
+}
+
+func TestProviderSignature(t *testing.T) {
+	t.Parallel()
+
+	type type1 struct{}
+	type type2 struct{}
+
+	t.Run("matches FillProvideInfo", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		var info dig.ProvideInfo
+		c.RequireProvide(func(*type1) *type2 { return &type2{} },
+			dig.Name("n"), dig.Tags(map[string]string{"team": "payments"}), dig.FillProvideInfo(&info))
+
+		got, err := c.ProviderSignature(info.ID)
+		require.NoError(t, err)
+		assert.Equal(t, &info, got)
+		assert.Equal(t, "*dig_test.type1", got.Inputs[0].String())
+		assert.Equal(t, `*dig_test.type2[name = "n"]`, got.Outputs[0].String())
+	})
+
+	t.Run("child scope", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		var info dig.ProvideInfo
+		c.Scope("child").RequireProvide(func() *type1 { return &type1{} }, dig.FillProvideInfo(&info))
+
+		got, err := c.ProviderSignature(info.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "*dig_test.type1", got.Outputs[0].String())
+	})
+
+	t.Run("provided values", func(t *testing.T) {
+		t.Parallel()
 
-		assert.NotEqual(t, info1.ID, info2.ID)
+		c := digtest.New(t)
+		var name, count dig.ProvideInfo
+		require.NoError(t, c.ProvideValue(reflect.ValueOf("n"), dig.FillProvideInfo(&name)))
+		require.NoError(t, c.ProvideValue(reflect.ValueOf(1), dig.FillProvideInfo(&count)))
 
-		assert.Equal(t, 1, len(info1.Inputs))
-		assert.Equal(t, 1, len(info1.Outputs))
-		assert.Equal(t, 1, len(info2.Inputs))
-		assert.Equal(t, 1, len(info2.Outputs))
+		got, err := c.ProviderSignature(count.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "int", got.Outputs[0].String())
+	})
 
-		assert.Equal(t, "*dig_test.type1", info1.Inputs[0].String())
-		assert.Equal(t, "*dig_test.type2", info1.Outputs[0].String())
+	t.Run("unknown ID", func(t *testing.T) {
+		t.Parallel()
 
-		assert.Equal(t, "*dig_test.type3", info2.Inputs[0].String())
-		assert.Equal(t, "*dig_test.type4", info2.Outputs[0].String())
+		_, err := digtest.New(t).ProviderSignature(42)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no constructor with ID 42 was provided")
 	})
 }
 
@@ -3840,3 +5002,499 @@ func TestEndToEndSuccessWithAliases(t *testing.T) {
 		})
 	})
 }
+
+func TestGroupNamespace(t *testing.T) {
+	t.Parallel()
+
+	type Handler string
+
+	type paymentsParams struct {
+		dig.In
+
+		Handlers []Handler `group:"payments/handlers"`
+		Errors   []error   `group:"payments/errors"`
+	}
+
+	t.Run("provide and consume", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		ns := dig.GroupNamespace("payments")
+		c.RequireProvide(func() Handler { return "refund" }, dig.Group("handlers"), ns)
+		c.RequireProvide(func() Handler { return "charge" }, dig.ResultTags(`group:"handlers"`), ns)
+		c.RequireProvide(func() struct {
+			dig.Out
+
+			Handlers []Handler `group:"handlers,flatten"`
+		} {
+			return struct {
+				dig.Out
+
+				Handlers []Handler `group:"handlers,flatten"`
+			}{Handlers: []Handler{"dispute"}}
+		}, ns)
+		c.RequireProvide(func() (int, error) {
+			return 0, errors.New("great sadness")
+		}, dig.ReportErrorsToGroup("errors"), ns)
+		c.RequireProvide(func() Handler { return "login" }, dig.Group("handlers"), dig.GroupNamespace("auth"))
+
+		c.RequireInvoke(func(p paymentsParams) {
+			assert.ElementsMatch(t, []Handler{"refund", "charge", "dispute"}, p.Handlers)
+			assert.Len(t, p.Errors, 1)
+		})
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Handlers []Handler `group:"handlers"`
+		}) {
+			assert.Empty(t, p.Handlers, "namespaced values must not be in the bare group")
+		})
+	})
+
+	t.Run("nested namespace", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() Handler { return "refund" },
+			dig.Group("v2/handlers"), dig.GroupNamespace("payments"))
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Handlers []Handler `group:"payments/v2/handlers"`
+		}) {
+			assert.Equal(t, []Handler{"refund"}, p.Handlers)
+		})
+	})
+
+	t.Run("errors name the namespaced group", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (Handler, error) {
+			return "", errors.New("great sadness")
+		}, dig.Group("handlers"), dig.GroupNamespace("payments"))
+
+		err := c.Invoke(func(paymentsParams) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `could not build value group dig_test.Handler[group="payments/handlers"]`)
+	})
+
+	t.Run("invalid namespaces", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			give    string
+			wantErr string
+		}{
+			{give: "pay,ments", wantErr: `invalid dig.GroupNamespace("pay,ments"): namespaces cannot contain commas, quotes, or backquotes`},
+			{give: `pay"ments`, wantErr: "namespaces cannot contain commas, quotes, or backquotes"},
+			{give: "pay`ments", wantErr: "namespaces cannot contain commas, quotes, or backquotes"},
+			{give: "payments/", wantErr: `invalid dig.GroupNamespace("payments/"): invalid group name "payments/"`},
+		}
+
+		for _, tt := range tests {
+			err := digtest.New(t).Provide(func() Handler { return "" },
+				dig.Group("handlers"), dig.GroupNamespace(tt.give))
+			require.Error(t, err, tt.give)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		}
+	})
+
+	t.Run("missing dependencies report namespaced groups", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() Handler { return "refund" }, dig.Group("handlers"), dig.GroupNamespace("payments"))
+		c.RequireProvide(func() Handler { return "login" }, dig.Group("handlers"), dig.GroupNamespace("auth"))
+		c.RequireProvide(func() string { return "unrelated" }, dig.Group("handlers"), dig.GroupNamespace("other"))
+		c.RequireProvide(func(struct {
+			dig.In
+
+			Handlers []Handler `group:"handlers"`
+		}) int {
+			return 0
+		})
+
+		deps := c.MissingDependencies()
+		require.Len(t, deps, 1)
+		assert.Equal(t, []string{"auth/handlers", "payments/handlers"}, deps[0].NamespacedGroups)
+		assert.Contains(t, deps[0].String(),
+			`(provided in namespaced groups ["auth/handlers" "payments/handlers"])`)
+	})
+}
+
+func TestGroupTag(t *testing.T) {
+	t.Parallel()
+
+	type Route string
+
+	type adminParams struct {
+		dig.In
+
+		Routes []Route `group:"routes,tag=admin"`
+	}
+
+	provideRoutes := func(c *digtest.Container) {
+		c.RequireProvide(func() Route { return "users" }, dig.Group("routes"), dig.GroupTag("admin"))
+		c.RequireProvide(func() []Route { return []Route{"audit", "config"} },
+			dig.Group("routes,flatten"), dig.GroupTag("admin"))
+		c.RequireProvide(func() Route { return "home" }, dig.Group("routes"), dig.GroupTag("public"))
+		c.RequireProvide(func() Route { return "health" }, dig.Group("routes"))
+	}
+
+	t.Run("consumers filter by tag", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		provideRoutes(c)
+		c.RequireInvoke(func(p adminParams) {
+			assert.ElementsMatch(t, []Route{"users", "audit", "config"}, p.Routes)
+		})
+	})
+
+	t.Run("consumers without a tag receive all values", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		provideRoutes(c)
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Routes []Route `group:"routes"`
+		}) {
+			assert.ElementsMatch(t, []Route{"users", "audit", "config", "home", "health"}, p.Routes)
+		})
+	})
+
+	t.Run("no matching values", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() Route { return "home" }, dig.Group("routes"), dig.GroupTag("public"))
+		c.RequireInvoke(func(p adminParams) {
+			assert.Empty(t, p.Routes)
+		})
+	})
+
+	t.Run("GroupValue entries", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		provideRoutes(c)
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Routes []dig.GroupValue[Route] `group:"routes,tag=public"`
+		}) {
+			require.Len(t, p.Routes, 1)
+			assert.Equal(t, Route("home"), p.Routes[0].Value)
+		})
+	})
+
+	t.Run("child scopes", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		provideRoutes(c)
+		child := c.Scope("child")
+		child.RequireProvide(func() Route { return "billing" }, dig.Group("routes"), dig.GroupTag("admin"))
+		child.RequireInvoke(func(p adminParams) {
+			assert.ElementsMatch(t, []Route{"users", "audit", "config", "billing"}, p.Routes)
+		})
+	})
+
+	t.Run("param tags", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		provideRoutes(c)
+		c.RequireProvide(func(routes []Route) int { return len(routes) },
+			dig.ParamTags(`group:"routes,tag=admin"`))
+		c.RequireInvoke(func(n int) {
+			assert.Equal(t, 3, n)
+		})
+	})
+
+	t.Run("results cannot be tagged in struct tags", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Provide(func() struct {
+			dig.Out
+
+			Route Route `group:"routes,tag=admin"`
+		} {
+			panic("must not be called")
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			`cannot use tag with result value groups: tag "admin" was used with group "routes", use dig.GroupTag instead`)
+
+		err = c.Provide(func() Route { panic("must not be called") }, dig.Group("routes,tag=admin"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot use tag with result value groups")
+	})
+
+	t.Run("decorated groups cannot be filtered", func(t *testing.T) {
+		t.Parallel()
+
+		type decorated struct {
+			dig.Out
+
+			Routes []Route `group:"routes"`
+		}
+
+		c := digtest.New(t)
+		provideRoutes(c)
+		c.RequireDecorate(func(p struct {
+			dig.In
+
+			Routes []Route `group:"routes"`
+		}) decorated {
+			return decorated{Routes: p.Routes}
+		})
+
+		err := c.Invoke(func(adminParams) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `cannot consume dig_test.Route[group="routes", tag="admin"]: decorated values have no tag`)
+	})
+}
+
+func TestGroupLabels(t *testing.T) {
+	t.Parallel()
+
+	type Route string
+
+	type adminParams struct {
+		dig.In
+
+		Routes []Route `group:"routes" labels:"admin"`
+	}
+
+	provideRoutes := func(c *digtest.Container) {
+		c.RequireProvide(func() Route { return "users" }, dig.Group("routes"), dig.GroupLabels("admin", "v2"))
+		c.RequireProvide(func() []Route { return []Route{"audit", "config"} },
+			dig.Group("routes,flatten"), dig.GroupLabels("admin"))
+		c.RequireProvide(func() Route { return "home" }, dig.Group("routes"), dig.GroupLabels("public", "v2"))
+		c.RequireProvide(func() Route { return "health" }, dig.Group("routes"))
+	}
+
+	t.Run("consumers filter by label", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		provideRoutes(c)
+		c.RequireInvoke(func(p adminParams) {
+			assert.ElementsMatch(t, []Route{"users", "audit", "config"}, p.Routes)
+		})
+	})
+
+	t.Run("multiple labels intersect", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		provideRoutes(c)
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Routes []Route `group:"routes" labels:"admin,v2"`
+		}) {
+			assert.Equal(t, []Route{"users"}, p.Routes)
+		})
+	})
+
+	t.Run("consumers without labels receive unlabeled values", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		provideRoutes(c)
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Routes []Route `group:"routes"`
+		}) {
+			assert.Equal(t, []Route{"health"}, p.Routes)
+		})
+	})
+
+	t.Run("wildcard consumers receive all values", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		provideRoutes(c)
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Routes []Route `group:"routes" labels:"*"`
+		}) {
+			assert.ElementsMatch(t, []Route{"users", "audit", "config", "home", "health"}, p.Routes)
+		})
+	})
+
+	t.Run("result object labels", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() struct {
+			dig.Out
+
+			Admin  Route   `group:"routes" labels:"admin"`
+			Public []Route `group:"routes,flatten" labels:"public"`
+		} {
+			return struct {
+				dig.Out
+
+				Admin  Route   `group:"routes" labels:"admin"`
+				Public []Route `group:"routes,flatten" labels:"public"`
+			}{Admin: "users", Public: []Route{"home", "about"}}
+		}, dig.GroupLabels("v2"))
+
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Admin  []Route `group:"routes" labels:"admin,v2"`
+			Public []Route `group:"routes" labels:"public"`
+		}) {
+			assert.Equal(t, []Route{"users"}, p.Admin)
+			assert.ElementsMatch(t, []Route{"home", "about"}, p.Public)
+		})
+	})
+
+	t.Run("combined with tag", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		provideRoutes(c)
+		c.RequireProvide(func() Route { return "billing" },
+			dig.Group("routes"), dig.GroupTag("internal"), dig.GroupLabels("admin"))
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Routes []dig.GroupValue[Route] `group:"routes,tag=internal" labels:"admin"`
+		}) {
+			require.Len(t, p.Routes, 1)
+			assert.Equal(t, Route("billing"), p.Routes[0].Value)
+		})
+	})
+
+	t.Run("param tags", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		provideRoutes(c)
+		c.RequireProvide(func(routes []Route) int { return len(routes) },
+			dig.ParamTags(`group:"routes" labels:"v2"`))
+		c.RequireInvoke(func(n int) {
+			assert.Equal(t, 2, n)
+		})
+	})
+
+	t.Run("invalid labels", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			desc    string
+			give    interface{}
+			opts    []dig.ProvideOption
+			wantErr string
+		}{
+			{
+				desc:    "empty label option",
+				give:    func() Route { panic("must not be called") },
+				opts:    []dig.ProvideOption{dig.Group("routes"), dig.GroupLabels("admin", "")},
+				wantErr: `invalid dig.GroupLabels(["admin" ""]): labels cannot be empty`,
+			},
+			{
+				desc:    "label option with comma",
+				give:    func() Route { panic("must not be called") },
+				opts:    []dig.ProvideOption{dig.Group("routes"), dig.GroupLabels("admin,v2")},
+				wantErr: "labels cannot contain commas",
+			},
+			{
+				desc:    "wildcard label option",
+				give:    func() Route { panic("must not be called") },
+				opts:    []dig.ProvideOption{dig.Group("routes"), dig.GroupLabels("*")},
+				wantErr: `"*" matches all labels and cannot be used as a label`,
+			},
+			{
+				desc: "empty label in consumer",
+				give: func(adminParams, struct {
+					dig.In
+
+					Routes []Route `group:"routes" labels:"admin,"`
+				}) int {
+					panic("must not be called")
+				},
+				wantErr: `invalid labels "admin," of field "Routes": labels cannot be empty`,
+			},
+			{
+				desc: "consumer without group",
+				give: func(struct {
+					dig.In
+
+					Route Route `labels:"admin"`
+				}) int {
+					panic("must not be called")
+				},
+				wantErr: `labels can be applied to value groups only: field "Route" (dig_test.Route) is not part of a value group`,
+			},
+			{
+				desc: "result without group",
+				give: func() struct {
+					dig.Out
+
+					Route Route `labels:"admin"`
+				} {
+					panic("must not be called")
+				},
+				wantErr: `labels can be applied to value groups only: field "Route" (dig_test.Route) is not part of a value group`,
+			},
+			{
+				desc: "wildcard result",
+				give: func() struct {
+					dig.Out
+
+					Route Route `group:"routes" labels:"*"`
+				} {
+					panic("must not be called")
+				},
+				wantErr: `invalid labels "*" of field "Route"`,
+			},
+		}
+
+		for _, tt := range tests {
+			tt := tt
+			t.Run(tt.desc, func(t *testing.T) {
+				t.Parallel()
+
+				err := digtest.New(t).Provide(tt.give, tt.opts...)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("decorated groups cannot be filtered", func(t *testing.T) {
+		t.Parallel()
+
+		type decorated struct {
+			dig.Out
+
+			Routes []Route `group:"routes"`
+		}
+
+		c := digtest.New(t)
+		provideRoutes(c)
+		c.RequireDecorate(func(p struct {
+			dig.In
+
+			Routes []Route `group:"routes"`
+		}) decorated {
+			return decorated{Routes: p.Routes}
+		})
+
+		err := c.Invoke(func(adminParams) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `cannot consume dig_test.Route[group="routes", labels="admin"]: decorated values have no labels`)
+	})
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"go.uber.org/dig/internal/dot"
+)
+
+const _dynamicTag = "dynamic"
+
+// paramDynamic is a dig.In field tagged with `dynamic:"true"`. It is a
+// function of type func() T or func() (T, error) that resolves T from the
+// container every time it is called, rather than once when the function
+// that depends on it is called.
+//
+//	type Params struct {
+//	  dig.In
+//
+//	  Config func() *Config `dynamic:"true"`
+//	}
+//
+// Values are resolved from the Scope that built the function that depends
+// on it, exactly as if T had been requested directly at the time of the
+// call, so later calls may observe values that were provided or decorated
+// in the meantime.
+type paramDynamic struct {
+	// Function type of the field.
+	Type reflect.Type
+
+	// Value that is resolved on every call.
+	Value paramSingle
+
+	// Whether the function returns an error rather than panicking if the
+	// value cannot be resolved.
+	ReturnsError bool
+}
+
+var _ param = paramDynamic{}
+
+// newParamDynamic builds a param for a field with a dynamic tag. If the tag
+// is false, the field is treated as a regular dependency.
+func newParamDynamic(f reflect.StructField, c containerStore) (param, error) {
+	tag := f.Tag.Get(_dynamicTag)
+	dynamic, err := strconv.ParseBool(tag)
+	if err != nil {
+		return nil, newErrInvalidInput(
+			fmt.Sprintf("invalid value %q for %q tag on field %v", tag, _dynamicTag, f.Name), err)
+	}
+	tags := c.tagKeys()
+	if !dynamic {
+		if f.Tag.Get(tags.group()) != "" {
+			return newParamGroupedSlice(f, c)
+		}
+		return newParam(f.Type, c)
+	}
+	if g := f.Tag.Get(tags.group()); g != "" {
+		return nil, newErrInvalidInput(fmt.Sprintf(
+			"cannot use value groups with dynamic fields: group:%q requested on %v", g, f.Name), nil)
+	}
+
+	t := f.Type
+	if t.Kind() != reflect.Func || t.NumIn() != 0 || t.IsVariadic() ||
+		t.NumOut() == 0 || t.NumOut() > 2 || (t.NumOut() == 2 && t.Out(1) != _errType) {
+		return nil, newErrInvalidInput(fmt.Sprintf(
+			"dynamic field %v must be a function of type func() T or func() (T, error), got %v", f.Name, t), nil)
+	}
+
+	vt := t.Out(0)
+	if isError(vt) || IsIn(vt) || IsOut(vt) {
+		return nil, newErrInvalidInput(fmt.Sprintf(
+			"dynamic field %v cannot resolve %v: only single values may be resolved dynamically", f.Name, vt), nil)
+	}
+
+	optional, err := isFieldOptional(f)
+	if err != nil {
+		return nil, err
+	}
+
+	return paramDynamic{
+		Type: t,
+		Value: paramSingle{
+			Name:     f.Tag.Get(tags.name()),
+			Type:     vt,
+			Optional: optional,
+		},
+		ReturnsError: t.NumOut() == 2,
+	}, nil
+}
+
+func (pd paramDynamic) String() string {
+	return fmt.Sprintf("func() %v", pd.Value)
+}
+
+// DotParam returns nothing: the value is not needed to build the function,
+// so it is not a dependency in the graph.
+func (pd paramDynamic) DotParam() []*dot.Param {
+	return nil
+}
+
+func (pd paramDynamic) Build(c containerStore) (reflect.Value, error) {
+	return reflect.MakeFunc(pd.Type, func([]reflect.Value) []reflect.Value {
+		v, err := pd.Value.Build(c)
+		if !pd.ReturnsError {
+			if err != nil {
+				panic(err)
+			}
+			return []reflect.Value{v}
+		}
+
+		if err != nil {
+			return []reflect.Value{reflect.Zero(pd.Value.Type), reflect.ValueOf(&err).Elem()}
+		}
+		return []reflect.Value{v, reflect.Zero(_errType)}
+	}), nil
+}
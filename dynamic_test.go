@@ -0,0 +1,251 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestDynamicParams(t *testing.T) {
+	t.Parallel()
+
+	type Config struct{ Version int }
+
+	t.Run("resolves on every call", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			Config func() *Config `dynamic:"true" optional:"true"`
+		}
+
+		c := digtest.New(t)
+		var get func() *Config
+		c.RequireInvoke(func(p params) {
+			get = p.Config
+		})
+		require.NotNil(t, get)
+		assert.Nil(t, get(), "no provider yet")
+
+		calls := 0
+		c.RequireProvide(func() *Config {
+			calls++
+			return &Config{Version: calls}
+		})
+		assert.Equal(t, &Config{Version: 1}, get())
+		assert.Equal(t, &Config{Version: 1}, get(), "values are still cached")
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("does not depend on the value when built", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func(p struct {
+			dig.In
+
+			Config func() (*Config, error) `dynamic:"true"`
+		}) func() (*Config, error) {
+			return p.Config
+		})
+
+		var get func() (*Config, error)
+		c.RequireInvoke(func(f func() (*Config, error)) { get = f })
+
+		_, err := get()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: *dig_test.Config")
+
+		c.RequireProvide(func() (*Config, error) {
+			return &Config{Version: 2}, nil
+		})
+		cfg, err := get()
+		require.NoError(t, err)
+		assert.Equal(t, 2, cfg.Version)
+	})
+
+	t.Run("panics without an error result", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		giveErr := errors.New("great sadness")
+		c.RequireProvide(func() (*Config, error) { return nil, giveErr })
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Config func() *Config `dynamic:"true"`
+		}) {
+			defer func() {
+				err, ok := recover().(error)
+				require.True(t, ok, "expected a panic with an error")
+				assert.ErrorIs(t, err, giveErr)
+			}()
+			p.Config()
+			t.Fatal("must not be reached")
+		})
+	})
+
+	t.Run("named", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{Version: 1} })
+		c.RequireProvide(func() *Config { return &Config{Version: 2} }, dig.Name("next"))
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Config func() *Config `dynamic:"true" name:"next"`
+		}) {
+			assert.Equal(t, 2, p.Config().Version)
+		})
+	})
+
+	t.Run("param tags", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{Version: 3} })
+		c.RequireProvide(func(get func() *Config) string {
+			return "ok"
+		}, dig.ParamTags(`dynamic:"true"`))
+		c.RequireInvoke(func(s string) {
+			assert.Equal(t, "ok", s)
+		})
+	})
+
+	t.Run("reflects the scope that built it", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{Version: 1} })
+
+		child := c.Scope("child")
+		child.RequireDecorate(func(cfg *Config) *Config {
+			return &Config{Version: cfg.Version + 10}
+		})
+
+		type params struct {
+			dig.In
+
+			Config func() *Config `dynamic:"true"`
+		}
+		c.RequireInvoke(func(p params) {
+			assert.Equal(t, 1, p.Config().Version)
+		})
+		child.RequireInvoke(func(p params) {
+			assert.Equal(t, 11, p.Config().Version)
+		})
+	})
+
+	t.Run("dynamic false is a regular dependency", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		get := func() int { return 42 }
+		c.RequireProvide(func() func() int { return get })
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			F func() int `dynamic:"false"`
+		}) {
+			assert.Equal(t, 42, p.F())
+		})
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			desc    string
+			give    interface{}
+			wantErr string
+		}{
+			{
+				desc: "not a function",
+				give: func(struct {
+					dig.In
+
+					Config *Config `dynamic:"true"`
+				}) {
+				},
+				wantErr: "dynamic field Config must be a function of type func() T or func() (T, error), got *dig_test.Config",
+			},
+			{
+				desc: "second result not an error",
+				give: func(struct {
+					dig.In
+
+					Config func() (*Config, int) `dynamic:"true"`
+				}) {
+				},
+				wantErr: "dynamic field Config must be a function",
+			},
+			{
+				desc: "error value",
+				give: func(struct {
+					dig.In
+
+					Err func() error `dynamic:"true"`
+				}) {
+				},
+				wantErr: "dynamic field Err cannot resolve error",
+			},
+			{
+				desc: "bad tag",
+				give: func(struct {
+					dig.In
+
+					Config func() *Config `dynamic:"yes"`
+				}) {
+				},
+				wantErr: `invalid value "yes" for "dynamic" tag on field Config`,
+			},
+			{
+				desc: "group",
+				give: func(struct {
+					dig.In
+
+					Configs func() []*Config `dynamic:"true" group:"configs"`
+				}) {
+				},
+				wantErr: `cannot use value groups with dynamic fields: group:"configs" requested on Configs`,
+			},
+		}
+
+		for _, tt := range tests {
+			tt := tt
+			t.Run(tt.desc, func(t *testing.T) {
+				t.Parallel()
+
+				err := digtest.New(t).Invoke(tt.give)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			})
+		}
+	})
+}
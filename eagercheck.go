@@ -0,0 +1,49 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+// EagerDependencyCheck is an [Option] that makes Provide fail if a required
+// dependency of the constructor has no provider yet. All missing
+// dependencies of the constructor are reported together.
+//
+// By default, dependencies are resolved lazily: Provide accepts
+// constructors whose dependencies are not provided, and only Invoke fails
+// if a function needs such a constructor. This allows registering all
+// constructors of an application, for example one per subcommand of a
+// command line tool, and running only some of them.
+//
+// With this option, constructors must be provided after the constructors
+// that they depend on. Optional dependencies and value groups are not
+// checked. Use MissingDependencies to check all constructors at once
+// instead, regardless of the order in which they were provided.
+func EagerDependencyCheck() Option {
+	return eagerDependencyCheckOption{}
+}
+
+type eagerDependencyCheckOption struct{}
+
+func (eagerDependencyCheckOption) String() string {
+	return "EagerDependencyCheck()"
+}
+
+func (eagerDependencyCheckOption) applyOption(c *Container) {
+	c.scope.eagerDependencyCheck = true
+}
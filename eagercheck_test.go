@@ -0,0 +1,141 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestLazyDependencyResolution(t *testing.T) {
+	t.Parallel()
+
+	type A struct{}
+	type B struct{}
+	type C struct{}
+
+	t.Run("provide does not require dependencies", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func(*A, *B) *C {
+			t.Fatal("constructor must not be called")
+			return &C{}
+		})
+		c.Scope("child").RequireProvide(func(*C) string { return "" })
+
+		called := false
+		c.RequireInvoke(func() { called = true })
+		assert.True(t, called)
+
+		err := c.Invoke(func(*C) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing dependencies")
+		assert.Contains(t, err.Error(), "*dig_test.A (needed by argument 1)")
+	})
+
+	t.Run("dependencies may be provided later", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func(*A) *B { return &B{} })
+		c.RequireProvide(func() *A { return &A{} })
+		c.RequireInvoke(func(*B) {})
+	})
+}
+
+func TestEagerDependencyCheck(t *testing.T) {
+	t.Parallel()
+
+	type A struct{}
+	type B struct{}
+	type C struct{}
+
+	t.Run("reports all missing dependencies", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.EagerDependencyCheck())
+		c.RequireProvide(func() *A { return &A{} })
+
+		err := c.Provide(func(*A, *B, *C) string { return "" })
+		require.Error(t, err)
+		dig.AssertErrorMatches(t, err,
+			`cannot provide function "go.uber.org/dig_test".TestEagerDependencyCheck\S+`,
+			`eagercheck_test.go:\d+`, // file:line
+			`missing types:`,
+			`\*dig_test.B .*\(needed by argument 2\)`,
+			`\*dig_test.C .*\(needed by argument 3\)`,
+		)
+
+		var mt dig.MissingTypeError
+		require.True(t, errors.As(err, &mt))
+		assert.Equal(t, "*dig_test.B", mt.Type.String())
+
+		err = c.Invoke(func(string) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: string",
+			"failed constructor must not be provided")
+	})
+
+	t.Run("dependencies provided first", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.EagerDependencyCheck())
+		c.RequireProvide(func() *A { return &A{} })
+		c.RequireProvide(func(*A) *B { return &B{} })
+		c.RequireInvoke(func(*B) {})
+	})
+
+	t.Run("optional dependencies and groups are not checked", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			A  *A   `optional:"true"`
+			Bs []*B `group:"bs"`
+		}
+
+		c := digtest.New(t, dig.EagerDependencyCheck())
+		c.RequireProvide(func(params) *C { return &C{} })
+	})
+
+	t.Run("scopes", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.EagerDependencyCheck())
+		child := c.Scope("child")
+		child.RequireProvide(func() *A { return &A{} })
+
+		child.RequireProvide(func(*A) *B { return &B{} })
+		err := c.Provide(func(*A) *C { return &C{} })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: *dig_test.A")
+
+		child.RequireProvide(func(*A) *C { return &C{} }, dig.Export(true))
+		c.RequireInvoke(func(*C) {})
+	})
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package dig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// An EquateOption selects how Equate turns values of one type into
+// values of another.
+type EquateOption interface {
+	applyEquateOption(*equateOptions)
+}
+
+type equateOptions struct {
+	Conversion bool
+}
+
+// ViaConversion is an EquateOption that turns values into the target type
+// with a Go type conversion. It is only allowed when the conversion cannot
+// change the value: both types must share the same underlying type, as is
+// the case for the same type declared in two major versions of a module.
+func ViaConversion() EquateOption {
+	return viaConversionOption{}
+}
+
+type viaConversionOption struct{}
+
+func (viaConversionOption) String() string {
+	return "ViaConversion()"
+}
+
+func (viaConversionOption) applyEquateOption(opts *equateOptions) {
+	opts.Conversion = true
+}
+
+// Equate bridges two types in the Container. See Scope.Equate for details.
+func (c *Container) Equate(from, to interface{}, opts ...EquateOption) error {
+	return c.scope.equate(from, to, callerPC(), opts)
+}
+
+// Equate provides the type pointed to by to, built from the value of the
+// type pointed to by from. It is intended as a migration aid for types
+// that moved between import paths, such as after a major version bump of
+// a module:
+//
+//	// Consumers of foo.Config receive the provided foo/v2.Config.
+//	err := s.Equate(new(v2.Config), new(foo.Config), dig.ViaConversion())
+//
+// The bridge only works in the given direction. A strategy such as
+// ViaConversion must be given; Equate fails if the strategy cannot safely
+// turn one type into the other, naming both types by their full import
+// paths.
+//
+// The adapter is an ordinary constructor: the target type must not
+// already be provided, and errors refer to the line that called Equate.
+func (s *Scope) Equate(from, to interface{}, opts ...EquateOption) error {
+	return s.equate(from, to, callerPC(), opts)
+}
+
+func (s *Scope) equate(from, to interface{}, pc uintptr, opts []EquateOption) error {
+	var options equateOptions
+	for _, o := range opts {
+		o.applyEquateOption(&options)
+	}
+
+	fromT, err := equateType("from", from)
+	if err != nil {
+		return err
+	}
+	toT, err := equateType("to", to)
+	if err != nil {
+		return err
+	}
+
+	if fromT == toT {
+		return newErrInvalidInput(fmt.Sprintf(
+			"cannot equate %v with itself", qualifiedTypeName(fromT)), nil)
+	}
+	if !options.Conversion {
+		return newErrInvalidInput(fmt.Sprintf(
+			"cannot equate %v with %v: no strategy given, use dig.ViaConversion()",
+			qualifiedTypeName(fromT), qualifiedTypeName(toT)), nil)
+	}
+	// Conversions between types of different kinds, such as int32 to
+	// int64 or string to []byte, can change or copy the value.
+	if !fromT.ConvertibleTo(toT) || fromT.Kind() != toT.Kind() {
+		return newErrInvalidInput(fmt.Sprintf(
+			"cannot equate %v with %v: conversion is only allowed between types with the same underlying type",
+			qualifiedTypeName(fromT), qualifiedTypeName(toT)), nil)
+	}
+
+	ctor := reflect.MakeFunc(
+		reflect.FuncOf([]reflect.Type{fromT}, []reflect.Type{toT}, false),
+		func(args []reflect.Value) []reflect.Value {
+			return []reflect.Value{args[0].Convert(toT)}
+		},
+	)
+	return s.Provide(ctor.Interface(), LocationForPC(pc))
+}
+
+// equateType returns the type pointed to by the given argument of Equate.
+func equateType(arg string, ptr interface{}) (reflect.Type, error) {
+	t := reflect.TypeOf(ptr)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return nil, newErrInvalidInput(fmt.Sprintf(
+			"Equate argument %q must be a pointer to a type, got %v", arg, t), nil)
+	}
+	return t.Elem(), nil
+}
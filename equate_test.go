@@ -0,0 +1,137 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package dig_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestEquate(t *testing.T) {
+	t.Parallel()
+
+	type ConfigV1 struct{ Addr string }
+	type ConfigV2 struct {
+		Addr string `yaml:"addr"`
+	}
+
+	t.Run("converts values", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(func() ConfigV2 { return ConfigV2{Addr: "localhost"} })
+		require.NoError(t, c.Equate(new(ConfigV2), new(ConfigV1), dig.ViaConversion()))
+
+		c.RequireInvoke(func(cfg ConfigV1) {
+			assert.Equal(t, "localhost", cfg.Addr)
+		})
+	})
+
+	t.Run("pointers", func(t *testing.T) {
+		v2 := &ConfigV2{Addr: "localhost"}
+		c := digtest.New(t)
+		c.RequireProvide(func() *ConfigV2 { return v2 })
+		require.NoError(t, c.Equate(new(*ConfigV2), new(*ConfigV1), dig.ViaConversion()))
+
+		c.RequireInvoke(func(cfg *ConfigV1) {
+			cfg.Addr = "example.com"
+		})
+		assert.Equal(t, "example.com", v2.Addr, "conversion must share the value")
+	})
+
+	t.Run("one direction only", func(t *testing.T) {
+		c := digtest.New(t)
+		require.NoError(t, c.Equate(new(ConfigV2), new(ConfigV1), dig.ViaConversion()))
+
+		err := c.Invoke(func(ConfigV1) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: dig_test.ConfigV2")
+	})
+
+	t.Run("scope", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(func() ConfigV2 { return ConfigV2{Addr: "localhost"} })
+		s := c.Scope("child")
+		require.NoError(t, s.Equate(new(ConfigV2), new(ConfigV1), dig.ViaConversion()))
+
+		s.RequireInvoke(func(cfg ConfigV1) {
+			assert.Equal(t, "localhost", cfg.Addr)
+		})
+		assert.Error(t, c.Invoke(func(ConfigV1) {}), "parent must not see the adapter")
+	})
+
+	t.Run("unsafe conversion", func(t *testing.T) {
+		type Count int32
+		type BigCount int64
+
+		c := digtest.New(t)
+		err := c.Equate(new(Count), new(BigCount), dig.ViaConversion())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			"cannot equate go.uber.org/dig_test.Count with go.uber.org/dig_test.BigCount")
+		assert.Contains(t, err.Error(), "same underlying type")
+	})
+
+	t.Run("different structs", func(t *testing.T) {
+		type Other struct{ Host string }
+
+		c := digtest.New(t)
+		err := c.Equate(new(ConfigV1), new(Other), dig.ViaConversion())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			"cannot equate go.uber.org/dig_test.ConfigV1 with go.uber.org/dig_test.Other")
+	})
+
+	t.Run("no strategy", func(t *testing.T) {
+		c := digtest.New(t)
+		err := c.Equate(new(ConfigV2), new(ConfigV1))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no strategy given, use dig.ViaConversion()")
+	})
+
+	t.Run("same type", func(t *testing.T) {
+		c := digtest.New(t)
+		err := c.Equate(new(ConfigV1), new(ConfigV1), dig.ViaConversion())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot equate go.uber.org/dig_test.ConfigV1 with itself")
+	})
+
+	t.Run("not a pointer", func(t *testing.T) {
+		c := digtest.New(t)
+		err := c.Equate(ConfigV2{}, new(ConfigV1), dig.ViaConversion())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `Equate argument "from" must be a pointer to a type`)
+	})
+
+	t.Run("errors refer to the caller", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(func() ConfigV1 { return ConfigV1{} })
+		err := c.Equate(new(ConfigV2), new(ConfigV1), dig.ViaConversion())
+		require.Error(t, err)
+		assert.Regexp(t, `TestEquate\S+ \(\S+equate_test.go:\d+\)`, err.Error())
+		assert.Contains(t, err.Error(), "already provided")
+	})
+
+	t.Run("option string", func(t *testing.T) {
+		assert.Equal(t, "ViaConversion()", dig.ViaConversion().(interface{ String() string }).String())
+	})
+}
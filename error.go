@@ -26,6 +26,7 @@ import (
 	"io"
 	"reflect"
 	"sort"
+	"strings"
 
 	"go.uber.org/dig/internal/digreflect"
 	"go.uber.org/dig/internal/dot"
@@ -152,7 +153,10 @@ func formatError(e digError, w fmt.State, v rune) {
 // and handle panics in provided/invoked/decorated functions.
 func RootCause(err error) error {
 	var de Error
-	// Dig down to first non dig.Error, or bottom of chain
+	// Dig down to first non dig.Error, or bottom of chain. Errors with
+	// several causes, like failures of several value group members, are
+	// the bottom of the chain; use errors.Is or errors.As to inspect their
+	// causes.
 	for ; errors.As(err, &de); err = errors.Unwrap(de) {
 	}
 
@@ -336,6 +340,172 @@ func (e errParamGroupFailed) updateGraph(g *dot.Graph) {
 	g.FailGroupNodes(e.Key.group, e.Key.t, e.CtorID)
 }
 
+// errGroupMemberType is returned when a member of a value group cannot be
+// assigned to the element type of the slice that consumes the group.
+type errGroupMemberType struct {
+	Key  key
+	Type reflect.Type     // type of the member
+	Func *digreflect.Func // provider of the member, or nil if unknown
+}
+
+// newErrGroupMemberType returns an errGroupMemberType for the given member
+// of the group of type t.
+func newErrGroupMemberType(group string, t reflect.Type, e groupEntry) errGroupMemberType {
+	return errGroupMemberType{
+		Key:  key{group: group, t: t},
+		Type: e.Value.Type(),
+		Func: e.Source,
+	}
+}
+
+var _ digError = errGroupMemberType{}
+
+func (e errGroupMemberType) Error() string { return fmt.Sprint(e) }
+
+func (e errGroupMemberType) writeMessage(w io.Writer, verb string) {
+	fmt.Fprintf(w, "could not build value group %v: value of type %v is not assignable to %v", e.Key, e.Type, e.Key.t)
+	if e.Func != nil {
+		fmt.Fprintf(w, ", provided by function "+verb, e.Func)
+	}
+}
+
+func (e errGroupMemberType) Format(w fmt.State, c rune) {
+	formatError(e, w, c)
+}
+
+// errGroupMember is a single provider of a value group that failed. Errors
+// returned by constructorNode.Call always name the failing function, so
+// Reason identifies the location of the provider.
+type errGroupMember struct {
+	CtorID dot.CtorID
+	Reason error
+}
+
+// errGroupMembersFailed is returned when a value group cannot be built
+// because more than one of its providers failed. It lists every failure so
+// that they may all be fixed at once.
+type errGroupMembersFailed struct {
+	Key     key
+	Members []errGroupMember
+}
+
+var _ digError = errGroupMembersFailed{}
+
+func (e errGroupMembersFailed) Error() string { return fmt.Sprint(e) }
+
+// Unwrap returns the errors of all failed providers, in the order that the
+// providers were called.
+func (e errGroupMembersFailed) Unwrap() []error {
+	errs := make([]error, len(e.Members))
+	for i, m := range e.Members {
+		errs[i] = m.Reason
+	}
+	return errs
+}
+
+func (e errGroupMembersFailed) writeMessage(w io.Writer, _ string) {
+	fmt.Fprintf(w, "could not build value group %v: %d providers failed", e.Key, len(e.Members))
+}
+
+func (e errGroupMembersFailed) Format(w fmt.State, c rune) {
+	e.writeMessage(w, "%v")
+
+	// Each failure is listed on its own line with %+v, and separated by
+	// semicolons otherwise:
+	//
+	//   could not build value group T[group="g"]: 2 providers failed:
+	//     - [1] received non-nil error from function "foo".Bar (...):
+	//     ...
+	if w.Flag('+') && c == 'v' {
+		io.WriteString(w, ":")
+		for i, m := range e.Members {
+			fmt.Fprintf(w, "\n  - [%d] %+v", i+1, m.Reason)
+		}
+		return
+	}
+
+	io.WriteString(w, ": ")
+	for i, m := range e.Members {
+		if i > 0 {
+			io.WriteString(w, "; ")
+		}
+		fmt.Fprintf(w, "[%d] %v", i+1, m.Reason)
+	}
+}
+
+func (e errGroupMembersFailed) updateGraph(g *dot.Graph) {
+	for _, m := range e.Members {
+		// The failure of each member has its own root cause.
+		failGraph(g, m.Reason)
+		g.FailGroupNodes(e.Key.group, e.Key.t, m.CtorID)
+	}
+}
+
+// errInvokeResultsDropped is returned by Invoke with the StrictInvoke
+// option when the function returns values that Invoke would discard.
+type errInvokeResultsDropped struct {
+	Func  *digreflect.Func
+	Types []reflect.Type
+}
+
+var _ digError = errInvokeResultsDropped{}
+
+func (e errInvokeResultsDropped) Error() string { return fmt.Sprint(e) }
+
+func (e errInvokeResultsDropped) writeMessage(w io.Writer, verb string) {
+	fmt.Fprintf(w, "function "+verb+" returns values that Invoke would discard: ", e.Func)
+	for i, t := range e.Types {
+		if i > 0 {
+			io.WriteString(w, ", ")
+		}
+		fmt.Fprint(w, t)
+	}
+	io.WriteString(w, "; use Provide to add them to the container instead")
+}
+
+func (e errInvokeResultsDropped) Format(w fmt.State, c rune) {
+	formatError(e, w, c)
+}
+
+// errTypedNilInterface is returned by constructors with the
+// CheckNilInterfaces option when they produce a value of an interface type
+// that wraps a nil value.
+type errTypedNilInterface struct {
+	Func *digreflect.Func
+	Key  key
+	Type reflect.Type
+}
+
+var _ digError = errTypedNilInterface{}
+
+func (e errTypedNilInterface) Error() string { return fmt.Sprint(e) }
+
+func (e errTypedNilInterface) writeMessage(w io.Writer, verb string) {
+	fmt.Fprintf(w, "function "+verb+" provided a nil %v as %v, which is not a nil interface", e.Func, e.Type, e.Key)
+}
+
+func (e errTypedNilInterface) Format(w fmt.State, c rune) {
+	formatError(e, w, c)
+}
+
+// errValueOmitted is returned when a constructor ran successfully but
+// omitted a value that was marked optional in its dig.Out struct.
+type errValueOmitted struct {
+	Func *digreflect.Func
+}
+
+var _ digError = errValueOmitted{}
+
+func (e errValueOmitted) Error() string { return fmt.Sprint(e) }
+
+func (e errValueOmitted) writeMessage(w io.Writer, verb string) {
+	fmt.Fprintf(w, "optional result was omitted by function "+verb, e.Func)
+}
+
+func (e errValueOmitted) Format(w fmt.State, c rune) {
+	formatError(e, w, c)
+}
+
 // missingType holds information about a type that was missing in the
 // container.
 type missingType struct {
@@ -344,6 +514,21 @@ type missingType struct {
 	// If non-empty, we will include suggestions for what the user may have
 	// meant.
 	suggestions []key
+
+	// Parameter that requested the item, if known.
+	Path ParamPath
+}
+
+func (mt missingType) Error() string { return fmt.Sprint(mt) }
+
+// As allows a missingType to be matched as a MissingTypeError with
+// errors.As.
+func (mt missingType) As(target interface{}) bool {
+	e, ok := target.(*MissingTypeError)
+	if ok {
+		*e = MissingTypeError{Type: mt.Key.t, Name: mt.Key.name, Path: mt.Path}
+	}
+	return ok
 }
 
 // Format prints a string representation of missingType.
@@ -359,10 +544,18 @@ type missingType struct {
 //	io.Writer: did you mean to Provide it?
 //	io.Writer: did you mean to use *bytes.Buffer?
 //	io.Writer: did you mean to use one of *bytes.Buffer, or *os.File?
+//
+// Either way, it ends with the parameter that requested the type if known.
+//
+//	io.Writer (needed by argument 2, field Deps.Output)
+//	io.Writer (did you mean *bytes.Buffer?) (needed by argument 1)
 func (mt missingType) Format(w fmt.State, v rune) {
 	plusV := w.Flag('+') && v == 'v'
 
 	fmt.Fprint(w, mt.Key)
+	if mt.Path.Argument > 0 {
+		defer fmt.Fprintf(w, " (needed by %v)", mt.Path)
+	}
 	switch len(mt.suggestions) {
 	case 0:
 		if plusV {
@@ -396,6 +589,62 @@ func (mt missingType) Format(w fmt.State, v rune) {
 	}
 }
 
+// MissingTypeError is a type that a function depends on, but that is not
+// provided to the container. Use errors.As to find which dependencies of a
+// function passed to Invoke were missing.
+//
+//	var mt dig.MissingTypeError
+//	if errors.As(err, &mt) {
+//		fmt.Println(mt.Type, "is needed by", mt.Path)
+//	}
+type MissingTypeError struct {
+	// Type and Name of the missing value. Name is empty for unnamed values.
+	Type reflect.Type
+	Name string
+
+	// Path to the parameter of the function that requested the value. Its
+	// Argument is zero if the parameter is not known.
+	Path ParamPath
+}
+
+func (e MissingTypeError) Error() string {
+	msg := fmt.Sprintf("missing type: %v", key{t: e.Type, name: e.Name})
+	if e.Path.Argument > 0 {
+		msg += fmt.Sprintf(" (needed by %v)", e.Path)
+	}
+	return msg
+}
+
+// ParamPath identifies a parameter of a function: an argument of the
+// function, and if the argument is a dig.In struct, the fields that lead
+// from it to the parameter.
+type ParamPath struct {
+	// Position of the argument, starting at 1.
+	Argument int
+
+	// Names of the fields of nested dig.In structs, starting with the field
+	// of the argument. Empty if the argument itself is the parameter.
+	Fields []string
+}
+
+// String returns a description of the parameter, for example
+// "argument 2, field Deps.Storage.DB".
+func (p ParamPath) String() string {
+	if len(p.Fields) == 0 {
+		return fmt.Sprintf("argument %d", p.Argument)
+	}
+	return fmt.Sprintf("argument %d, field %v", p.Argument, strings.Join(p.Fields, "."))
+}
+
+// withField returns the path to the given field of the dig.In struct at
+// this path.
+func (p ParamPath) withField(name string) ParamPath {
+	fields := make([]string, len(p.Fields), len(p.Fields)+1)
+	copy(fields, p.Fields)
+	p.Fields = append(fields, name)
+	return p
+}
+
 // errMissingType is returned when one or more values that were expected in
 // the container were not available.
 //
@@ -405,6 +654,12 @@ type errMissingTypes []missingType // inv: len > 0
 var _ digError = errMissingTypes(nil)
 
 func newErrMissingTypes(c containerStore, k key) errMissingTypes {
+	return errMissingTypes{newMissingType(c, k)}
+}
+
+// newMissingType builds a missingType for the given key, with suggestions
+// of similar types that are available in the container.
+func newMissingType(c containerStore, k key) missingType {
 	// Possible types we will look for in the container. We will always look
 	// for pointers to the requested type and some extras on a per-Kind basis.
 	suggestions := []reflect.Type{reflect.PtrTo(k.t)}
@@ -444,12 +699,20 @@ func newErrMissingTypes(c containerStore, k key) errMissingTypes {
 			mt.suggestions = append(mt.suggestions, k)
 		}
 	}
-
-	return errMissingTypes{mt}
+	return mt
 }
 
 func (e errMissingTypes) Error() string { return fmt.Sprint(e) }
 
+// Unwrap returns each of the missing types as an error.
+func (e errMissingTypes) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, mt := range e {
+		errs[i] = mt
+	}
+	return errs
+}
+
 func (e errMissingTypes) writeMessage(w io.Writer, v string) {
 
 	multiline := v == "%+v"
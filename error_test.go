@@ -21,6 +21,7 @@
 package dig
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -249,7 +250,7 @@ func TestRootCauseEndToEnd(t *testing.T) {
 				fmt.Println(s)
 			},
 			wantAsDigError:          true,
-			wantRootCauseMessage:    "missing type: string",
+			wantRootCauseMessage:    "missing type: string (needed by argument 1)",
 			wantRootCauseAsDigError: true,
 		},
 		{
@@ -346,6 +347,27 @@ func TestMissingTypeFormatting(t *testing.T) {
 			wantV:     "dig.type1 (did you mean *dig.type1, or dig.someInterface?)",
 			wantPlusV: "dig.type1 (did you mean to use one of *dig.type1, or dig.someInterface?)",
 		},
+		{
+			desc: "argument",
+			give: missingType{
+				Key:  key{t: reflect.TypeOf(type1{})},
+				Path: ParamPath{Argument: 2},
+			},
+			wantV:     "dig.type1 (needed by argument 2)",
+			wantPlusV: "dig.type1 (did you mean to Provide it?) (needed by argument 2)",
+		},
+		{
+			desc: "field with suggestion",
+			give: missingType{
+				Key: key{t: reflect.TypeOf(type1{})},
+				suggestions: []key{
+					{t: reflect.TypeOf(&type1{})},
+				},
+				Path: ParamPath{Argument: 1, Fields: []string{"Deps", "Storage"}},
+			},
+			wantV:     "dig.type1 (did you mean *dig.type1?) (needed by argument 1, field Deps.Storage)",
+			wantPlusV: "dig.type1 (did you mean to use *dig.type1?) (needed by argument 1, field Deps.Storage)",
+		},
 	}
 
 	for _, tt := range tests {
@@ -356,6 +378,53 @@ func TestMissingTypeFormatting(t *testing.T) {
 	}
 }
 
+func TestMissingTypeErrorPaths(t *testing.T) {
+	type DB struct{}
+	type Logger struct{}
+
+	type storage struct {
+		In
+
+		DB *DB `name:"primary"`
+	}
+	type deps struct {
+		In
+
+		Logger  *Logger
+		Storage storage
+	}
+
+	c := New()
+	err := c.Invoke(func(context.Context, deps) {})
+	if !assert.Error(t, err) {
+		return
+	}
+	assert.Contains(t, err.Error(), "*dig.Logger (needed by argument 2, field Logger)")
+	assert.Contains(t, err.Error(), `*dig.DB[name="primary"] (needed by argument 2, field Storage.DB)`)
+
+	// errors.As finds the first missing type.
+	var mt MissingTypeError
+	if assert.True(t, errors.As(err, &mt), "must match MissingTypeError") {
+		assert.Equal(t, reflect.TypeOf(new(context.Context)).Elem(), mt.Type)
+		assert.Empty(t, mt.Name)
+		assert.Equal(t, ParamPath{Argument: 1}, mt.Path)
+		assert.Equal(t, "missing type: context.Context (needed by argument 1)", mt.Error())
+	}
+
+	var missing errMissingTypes
+	if assert.True(t, errors.As(err, &missing)) && assert.Len(t, missing, 3) {
+		var paths []string
+		for _, m := range missing {
+			paths = append(paths, m.Path.String())
+		}
+		assert.Equal(t, []string{
+			"argument 1",
+			"argument 2, field Logger",
+			"argument 2, field Storage.DB",
+		}, paths)
+	}
+}
+
 func TestErrorFormatting(t *testing.T) {
 	type someType struct{}
 	type anotherType struct{}
@@ -493,6 +562,25 @@ func TestErrorFormatting(t *testing.T) {
 				"lines",
 			),
 		},
+		{
+			desc: "errGroupMembersFailed",
+			give: errGroupMembersFailed{
+				Key: key{t: reflect.TypeOf(someType{}), group: "items"},
+				Members: []errGroupMember{
+					{Reason: simpleErr},
+					{Reason: richError},
+				},
+			},
+			wantString: `could not build value group dig.someType[group="items"]: 2 providers failed: ` +
+				`[1] great sadness; [2] great sadness`,
+			wantPlusV: joinLines(
+				`could not build value group dig.someType[group="items"]: 2 providers failed:`,
+				"  - [1] great sadness",
+				"  - [2] sadness so great",
+				"it needs multiple",
+				"lines",
+			),
+		},
 		{
 			desc: "errMissingTypes/single",
 			give: errMissingTypes{
@@ -526,3 +614,52 @@ func TestErrorFormatting(t *testing.T) {
 		})
 	}
 }
+
+func TestErrorsUnwrapToCause(t *testing.T) {
+	type A struct{}
+	type B struct{}
+	type C struct{}
+	type D struct{}
+
+	t.Run("through nested constructors", func(t *testing.T) {
+		c := New()
+		assert.NoError(t, c.Provide(func() (*D, error) { return nil, context.DeadlineExceeded }))
+		assert.NoError(t, c.Provide(func(*D) *C { return &C{} }))
+		assert.NoError(t, c.Provide(func(*C) *B { return &B{} }))
+		assert.NoError(t, c.Provide(func(*B) *A { return &A{} }))
+
+		err := c.Invoke(func(*A) {})
+		assert.Error(t, err)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded), "expected to find the cause in %+v", err)
+		assert.Equal(t, context.DeadlineExceeded, RootCause(err))
+	})
+
+	t.Run("through several failed group members", func(t *testing.T) {
+		c := New()
+		assert.NoError(t, c.Provide(func() (int, error) { return 0, errors.New("great sadness") }, Group("ints")))
+		assert.NoError(t, c.Provide(func() (int, error) { return 0, context.DeadlineExceeded }, Group("ints")))
+
+		err := c.Invoke(func(struct {
+			In
+
+			Ints []int `group:"ints"`
+		}) {
+		})
+		assert.Error(t, err)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded), "expected to find the cause in %+v", err)
+	})
+
+	t.Run("missing types", func(t *testing.T) {
+		c := New()
+		err := c.Invoke(func(*A, *B) {})
+		assert.Error(t, err)
+
+		var mts errMissingTypes
+		assert.True(t, errors.As(err, &mts))
+		assert.Len(t, mts.Unwrap(), 2)
+
+		var mt missingType
+		assert.True(t, errors.As(err, &mt))
+		assert.Equal(t, reflect.TypeOf(&A{}), mt.Key.t)
+	})
+}
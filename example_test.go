@@ -21,9 +21,14 @@
 package dig_test
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"io"
 	"log"
 	"os"
+	"sort"
+	"strings"
 
 	"go.uber.org/dig"
 )
@@ -67,3 +72,70 @@ func Example_minimal() {
 	// Output:
 	// [foo] You've been invoked
 }
+
+func ExampleContainer_ProvideCtor() {
+	c := dig.New()
+
+	err := c.ProvideCtor(func() *bytes.Buffer {
+		return bytes.NewBufferString("hello")
+	}).Named("greeting").As(new(io.Reader)).Err()
+	if err != nil {
+		panic(err)
+	}
+
+	err = c.Invoke(func(p struct {
+		dig.In
+
+		Reader io.Reader `name:"greeting"`
+	}) {
+		b, _ := io.ReadAll(p.Reader)
+		fmt.Println(string(b))
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	// Output:
+	// hello
+}
+
+func ExampleProvideBuilder_ToGroup() {
+	c := dig.New()
+
+	// A builder for a value group has no Named method, because values in
+	// groups cannot be named.
+	err := c.ProvideCtor(func() *strings.Reader {
+		return strings.NewReader("first")
+	}).ToGroup("readers").As(new(io.Reader)).Err()
+	if err != nil {
+		panic(err)
+	}
+
+	// Flatten adds each element of the returned slice to the group.
+	err = c.ProvideCtor(func() []io.Reader {
+		return []io.Reader{strings.NewReader("second"), strings.NewReader("third")}
+	}).ToGroup("readers").Flatten().Err()
+	if err != nil {
+		panic(err)
+	}
+
+	err = c.Invoke(func(p struct {
+		dig.In
+
+		Readers []io.Reader `group:"readers"`
+	}) {
+		var words []string
+		for _, r := range p.Readers {
+			b, _ := io.ReadAll(r)
+			words = append(words, string(b))
+		}
+		sort.Strings(words)
+		fmt.Println(strings.Join(words, ", "))
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	// Output:
+	// first, second, third
+}
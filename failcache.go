@@ -0,0 +1,96 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+// CacheFailures is an [Option] that remembers the failures of
+// constructors. Once a constructor has failed, every later attempt to
+// build its values fails right away with the same error, without calling
+// the constructor again.
+//
+// Only failures of the constructor itself are remembered: errors that it
+// returned, panics recovered with RecoverFromPanics, and nil values
+// rejected by CheckNilInterfaces. A constructor that could not be called
+// because a dependency is missing or failed is tried again, since the
+// dependency may be provided or fixed later.
+//
+// Use this option to fail fast when a constructor is known to be broken,
+// for example one that connects to an unavailable service, rather than
+// calling it again on every Invoke. Call ResetFailures to try the
+// constructors again.
+//
+// Constructors provided with ReportErrorsToGroup are not affected: their
+// failures are reported to the value group once, like their values.
+func CacheFailures() Option {
+	return cacheFailuresOption{}
+}
+
+type cacheFailuresOption struct{}
+
+func (cacheFailuresOption) String() string {
+	return "CacheFailures()"
+}
+
+func (cacheFailuresOption) applyOption(c *Container) {
+	c.scope.cacheFailures = true
+}
+
+// ResetFailures forgets the failures of constructors remembered under the
+// CacheFailures option, so that they are called again the next time their
+// values are needed. It has no effect without that option.
+func (c *Container) ResetFailures() {
+	c.scope.ResetFailures()
+}
+
+// ResetFailures forgets the failures of the constructors of this Scope and
+// its descendants remembered under the CacheFailures option.
+func (s *Scope) ResetFailures() {
+	defer s.lock()()
+	for _, scope := range s.appendSubscopes(nil) {
+		scope.failedCtors = nil
+	}
+}
+
+// cachedFailure returns the error that the given constructor, which must be
+// owned by this Scope, failed with earlier under CacheFailures, if any.
+func (s *Scope) cachedFailure(n *constructorNode) error {
+	defer s.lock()()
+	return s.failedCtors[n]
+}
+
+// cacheFailure records the error that the given constructor, which must be
+// owned by this Scope, failed with if CacheFailures is enabled and the
+// failure is of the constructor itself.
+func (s *Scope) cacheFailure(n *constructorNode, err error) {
+	switch err.(type) {
+	case errConstructorFailed, PanicError, errTypedNilInterface:
+	default:
+		return
+	}
+
+	defer s.lock()()
+	if !s.rootScope().cacheFailures {
+		return
+	}
+	if s.failedCtors == nil {
+		s.failedCtors = make(map[*constructorNode]error)
+	}
+	s.failedCtors[n] = err
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestCacheFailures(t *testing.T) {
+	t.Parallel()
+
+	type A struct{}
+	type B struct{}
+
+	// newFailing returns a constructor of A that fails until ok is set,
+	// and counts its calls.
+	newFailing := func(calls *int, ok *bool) func() (*A, error) {
+		return func() (*A, error) {
+			*calls++
+			if *ok {
+				return &A{}, nil
+			}
+			return nil, errors.New("great sadness")
+		}
+	}
+
+	t.Run("constructor is not called again", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			calls int
+			ok    bool
+		)
+		c := digtest.New(t, dig.CacheFailures())
+		c.RequireProvide(newFailing(&calls, &ok))
+
+		err := c.Invoke(func(*A) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "great sadness")
+
+		ok = true
+		for i := 0; i < 3; i++ {
+			again := c.Invoke(func(*A) {})
+			require.Error(t, again)
+			assert.Equal(t, dig.RootCause(err), dig.RootCause(again), "must fail with the same error")
+		}
+		assert.Equal(t, 1, calls, "constructor must be called once")
+	})
+
+	t.Run("dependents fail fast", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			calls int
+			ok    bool
+		)
+		c := digtest.New(t, dig.CacheFailures())
+		c.RequireProvide(newFailing(&calls, &ok))
+		c.RequireProvide(func(*A) *B { return &B{} })
+
+		require.Error(t, c.Invoke(func(*B) {}))
+		require.Error(t, c.Invoke(func(*B) {}))
+		require.Error(t, c.Invoke(func(*A) {}))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("ResetFailures", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			calls int
+			ok    bool
+		)
+		c := digtest.New(t, dig.CacheFailures())
+		c.RequireProvide(newFailing(&calls, &ok))
+
+		require.Error(t, c.Invoke(func(*A) {}))
+
+		ok = true
+		c.ResetFailures()
+		c.RequireInvoke(func(*A) {})
+		c.RequireInvoke(func(*A) {})
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("ResetFailures on Scope", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			calls int
+			ok    bool
+		)
+		c := digtest.New(t, dig.CacheFailures())
+		child := c.Scope("child")
+		child.RequireProvide(newFailing(&calls, &ok))
+
+		require.Error(t, child.Invoke(func(*A) {}))
+
+		ok = true
+		child.ResetFailures()
+		child.RequireInvoke(func(*A) {})
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("panics", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		c := digtest.New(t, dig.CacheFailures(), dig.RecoverFromPanics())
+		c.RequireProvide(func() *A {
+			calls++
+			panic("great sadness")
+		})
+
+		for i := 0; i < 2; i++ {
+			err := c.Invoke(func(*A) {})
+			var pe dig.PanicError
+			require.True(t, errors.As(err, &pe), "expected a PanicError, got %v", err)
+		}
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("missing dependencies are not cached", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		c := digtest.New(t, dig.CacheFailures())
+		c.RequireProvide(func(*B) *A {
+			calls++
+			return &A{}
+		})
+
+		require.Error(t, c.Invoke(func(*A) {}))
+
+		c.RequireProvide(func() *B { return &B{} })
+		c.RequireInvoke(func(*A) {})
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			calls int
+			ok    bool
+		)
+		c := digtest.New(t)
+		c.RequireProvide(newFailing(&calls, &ok))
+
+		require.Error(t, c.Invoke(func(*A) {}))
+
+		ok = true
+		c.RequireInvoke(func(*A) {})
+		assert.Equal(t, 2, calls)
+	})
+}
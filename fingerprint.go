@@ -0,0 +1,97 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.uber.org/dig/internal/dot"
+)
+
+// GraphFingerprint returns a hash of the shape of the dependency graph of
+// the Container and all its Scopes. It changes when constructors or
+// decorators are added or removed, or when the values they consume or
+// produce change, but it does not depend on the order in which they were
+// provided, where they are defined in their files, or whether any of them
+// were called.
+//
+// This is useful to detect changes to the graph, for example in tests,
+// without storing the whole graph.
+//
+// Functions are identified by their package and name, which may be
+// overridden with the LocationForPC option.
+func (c *Container) GraphFingerprint() string {
+	var entries []string
+	for _, s := range c.scope.appendSubscopes(nil) {
+		path := s.path()
+		for _, n := range s.nodes {
+			entries = append(entries, fingerprintEntry(
+				path, "provide", n.location.Package, n.location.Name,
+				n.paramList.DotParam(), n.resultList.DotResult()))
+		}
+		for _, d := range s.decorators {
+			entries = append(entries, fingerprintEntry(
+				path, "decorate", d.location.Package, d.location.Name,
+				d.params.DotParam(), d.results.DotResult()))
+		}
+	}
+	sort.Strings(entries)
+
+	h := sha256.New()
+	for _, e := range entries {
+		fmt.Fprintln(h, e)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// path returns the names of the Scopes from the root to this Scope,
+// separated by "/".
+func (s *Scope) path() string {
+	scopes := s.ancestors()
+	names := make([]string, len(scopes))
+	for i, scope := range scopes {
+		names[len(scopes)-1-i] = scope.name
+	}
+	return strings.Join(names, "/")
+}
+
+// fingerprintEntry describes a single function of the graph for
+// GraphFingerprint.
+func fingerprintEntry(scope, kind, pkg, name string, params []*dot.Param, results []*dot.Result) string {
+	consumes := make([]string, len(params))
+	for i, p := range params {
+		consumes[i] = fmt.Sprintf("%v name=%q group=%q optional=%v", p.Type, p.Name, p.Group, p.Optional)
+	}
+	sort.Strings(consumes)
+
+	produces := make([]string, len(results))
+	for i, r := range results {
+		produces[i] = fmt.Sprintf("%v name=%q group=%q", r.Type, r.Name, r.Group)
+	}
+	sort.Strings(produces)
+
+	return fmt.Sprintf("scope=%q %v %q.%v consumes=[%v] produces=[%v]",
+		scope, kind, pkg, name, strings.Join(consumes, ", "), strings.Join(produces, ", "))
+}
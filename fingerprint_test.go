@@ -0,0 +1,124 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestGraphFingerprint(t *testing.T) {
+	t.Parallel()
+
+	type A struct{}
+	type B struct{}
+	type C struct{}
+
+	newA := func() *A { return &A{} }
+	newB := func(*A) *B { return &B{} }
+	newC := func(*B) *C { return &C{} }
+
+	t.Run("stable across provide order", func(t *testing.T) {
+		t.Parallel()
+
+		c1 := digtest.New(t)
+		c1.RequireProvide(newA)
+		c1.RequireProvide(newB)
+		c1.RequireProvide(newC)
+
+		c2 := digtest.New(t)
+		c2.RequireProvide(newC)
+		c2.RequireProvide(newA)
+		c2.RequireProvide(newB)
+
+		assert.Equal(t, c1.GraphFingerprint(), c2.GraphFingerprint())
+	})
+
+	t.Run("unaffected by invoking", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(newA)
+		c.RequireProvide(newB)
+
+		before := c.GraphFingerprint()
+		c.RequireInvoke(func(*B) {})
+		assert.Equal(t, before, c.GraphFingerprint())
+	})
+
+	t.Run("changes when an edge is added", func(t *testing.T) {
+		t.Parallel()
+
+		c1 := digtest.New(t)
+		c1.RequireProvide(newA)
+		c1.RequireProvide(func() *B { return &B{} })
+
+		c2 := digtest.New(t)
+		c2.RequireProvide(newA)
+		c2.RequireProvide(newB)
+
+		assert.NotEqual(t, c1.GraphFingerprint(), c2.GraphFingerprint())
+	})
+
+	t.Run("changes with param flags", func(t *testing.T) {
+		t.Parallel()
+
+		type required struct {
+			dig.In
+
+			A *A
+		}
+		type optional struct {
+			dig.In
+
+			A *A `optional:"true"`
+		}
+
+		c1 := digtest.New(t)
+		c1.RequireProvide(func(required) *B { return &B{} })
+
+		c2 := digtest.New(t)
+		c2.RequireProvide(func(optional) *B { return &B{} })
+
+		assert.NotEqual(t, c1.GraphFingerprint(), c2.GraphFingerprint())
+	})
+
+	t.Run("includes scopes and decorators", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(newA)
+		before := c.GraphFingerprint()
+
+		child := c.Scope("child")
+		assert.Equal(t, before, c.GraphFingerprint())
+
+		child.RequireProvide(newB)
+		withChild := c.GraphFingerprint()
+		assert.NotEqual(t, before, withChild)
+
+		child.RequireDecorate(func(a *A) *A { return a })
+		assert.NotEqual(t, withChild, c.GraphFingerprint())
+	})
+}
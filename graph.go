@@ -33,7 +33,9 @@ type graphNode struct {
 // It implements the graph interface defined by internal/graph.
 // It has 1-1 correspondence with the Scope whose graph it represents.
 type graphHolder struct {
-	// all the nodes defined in the graph.
+	// all the nodes defined in the graph. Guarded by the lock of the
+	// Scope: graph algorithms walk a snapshot of the graph instead, since
+	// other Invokes may add nodes for their value group parameters.
 	nodes []*graphNode
 
 	// Scope whose graph this holder contains.
@@ -42,9 +44,16 @@ type graphHolder struct {
 	// Number of nodes in the graph at last snapshot.
 	// -1 if no snapshot has been taken.
 	snap int
+
+	// Constructors on a cycle with each node, as found by graph.Cycles.
+	// Computed on first use by cycleWith and reset whenever nodes are
+	// added or removed, which also counts as a change. Guarded by the lock
+	// of the Scope.
+	cycles  [][]*constructorNode
+	changes int
 }
 
-var _ graph.Graph = (*graphHolder)(nil)
+var _ graph.SoftGraph = (*graphHolder)(nil)
 
 func newGraphHolder(s *Scope) *graphHolder {
 	return &graphHolder{s: s, snap: -1}
@@ -61,49 +70,114 @@ func (gh *graphHolder) Order() int { return len(gh.nodes) }
 //
 // For value group nodes, it retrieves the group providers from the container
 // and reports their orders.
+//
+// For named map nodes, it reports the orders of all providers of named
+// values of the map's element type.
 func (gh *graphHolder) EdgesFrom(u int) []int {
+	return gh.edgesFrom(u, true /* withOptional */)
+}
+
+// HardEdgesFrom returns the indices of nodes that are dependencies of node
+// u, except for the providers of optional dependencies of constructors.
+// A cycle that goes through an optional dependency can be broken by
+// giving it the zero value.
+func (gh *graphHolder) HardEdgesFrom(u int) []int {
+	return gh.edgesFrom(u, false /* withOptional */)
+}
+
+func (gh *graphHolder) edgesFrom(u int, withOptional bool) []int {
 	var orders []int
 	switch w := gh.Lookup(u).(type) {
 	case *constructorNode:
 		for _, param := range w.paramList.Params {
-			orders = append(orders, getParamOrder(gh, param)...)
+			orders = append(orders, getParamOrder(gh, param, withOptional)...)
 		}
 	case *paramGroupedSlice:
 		providers := gh.s.getAllGroupProviders(w.Group, w.Type.Elem())
 		for _, provider := range providers {
 			orders = append(orders, provider.Order(gh.s))
 		}
+	case *paramNamedMap:
+		for _, s := range gh.s.ancestors() {
+			for _, name := range s.getValueNames(w.Type.Elem()) {
+				for _, provider := range s.getValueProviders(name, w.Type.Elem()) {
+					orders = append(orders, provider.Order(gh.s))
+				}
+			}
+		}
 	}
 	return orders
 }
 
 // NewNode adds a new value to the graph and returns its order.
+// The caller must hold the lock of the Scope.
 func (gh *graphHolder) NewNode(wrapped interface{}) int {
 	order := len(gh.nodes)
 	gh.nodes = append(gh.nodes, &graphNode{
 		Wrapped: wrapped,
 	})
+	gh.changed()
 	return order
 }
 
+// changed resets what was computed from the nodes of the graph. The
+// caller must hold the lock of the Scope.
+func (gh *graphHolder) changed() {
+	gh.cycles = nil
+	gh.changes++
+}
+
+// clone returns a copy of the graph with its current nodes, which may be
+// walked without the lock of the Scope while nodes are added to the graph.
+// The caller must hold the lock of the Scope.
+func (gh *graphHolder) clone() *graphHolder {
+	return &graphHolder{
+		nodes: append([]*graphNode(nil), gh.nodes...),
+		s:     gh.s,
+		snap:  -1,
+	}
+}
+
+// Clone returns a copy of the graph with its current nodes. See clone.
+func (gh *graphHolder) Clone() *graphHolder {
+	defer gh.s.lock()()
+	return gh.clone()
+}
+
 // Lookup retrieves the value for the node with the given order.
 // Lookup panics if i is invalid.
 func (gh *graphHolder) Lookup(i int) interface{} {
 	return gh.nodes[i].Wrapped
 }
 
+// Remove replaces the node that wraps the given value, if any, with an
+// empty node that has no edges. Orders of the other nodes are unchanged.
+func (gh *graphHolder) Remove(wrapped interface{}) {
+	defer gh.s.lock()()
+	for i, n := range gh.nodes {
+		if n.Wrapped == wrapped {
+			// Nodes may be shared with other Scopes, so replace the node
+			// rather than changing it.
+			gh.nodes[i] = &graphNode{}
+		}
+	}
+	gh.changed()
+}
+
 // Snapshot takes a temporary snapshot of the current state of the graph.
 // Use with Rollback to undo changes to the graph.
 //
 // Only one snapshot is allowed at a time.
 // Multiple calls to snapshot will overwrite prior snapshots.
 func (gh *graphHolder) Snapshot() {
+	defer gh.s.lock()()
 	gh.snap = len(gh.nodes)
 }
 
 // Rollback rolls back a snapshot to a previously captured state.
 // This is a no-op if no snapshot was captured.
 func (gh *graphHolder) Rollback() {
+	defer gh.s.lock()()
 	if gh.snap < 0 {
 		return
 	}
@@ -112,4 +186,55 @@ func (gh *graphHolder) Rollback() {
 	// extraneous entries from the slice.
 	gh.nodes = gh.nodes[:gh.snap]
 	gh.snap = -1
+	gh.changed()
+}
+
+// cycleWith returns the constructors that are on a cycle with the node u,
+// following both hard and soft edges, or nil if u is on no cycle.
+func (gh *graphHolder) cycleWith(u int) []*constructorNode {
+	unlock := gh.s.lock()
+	cycles, changes := gh.cycles, gh.changes
+	var g *graphHolder
+	if cycles == nil {
+		g = gh.clone()
+	}
+	unlock()
+
+	if cycles == nil {
+		cycles = g.constructorCycles()
+		defer gh.s.lock()()
+		// Do not replace the cycles of a graph that changed since it was
+		// cloned.
+		if gh.changes == changes {
+			gh.cycles = cycles
+		}
+	}
+	if u >= len(cycles) {
+		return nil
+	}
+	return cycles[u]
+}
+
+// constructorCycles returns, for each node of the graph, the constructors on
+// a cycle with it, as found by graph.Cycles. Nodes on the same cycles share
+// the same slice.
+func (gh *graphHolder) constructorCycles() [][]*constructorNode {
+	cycles := make([][]*constructorNode, gh.Order())
+	shared := make(map[*int][]*constructorNode)
+	for u, cycle := range graph.Cycles(gh) {
+		if len(cycle) == 0 {
+			continue
+		}
+		ctors, ok := shared[&cycle[0]]
+		if !ok {
+			for _, v := range cycle {
+				if n, ok := gh.Lookup(v).(*constructorNode); ok {
+					ctors = append(ctors, n)
+				}
+			}
+			shared[&cycle[0]] = ctors
+		}
+		cycles[u] = ctors
+	}
+	return cycles
 }
@@ -0,0 +1,183 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+
+	"go.uber.org/dig/internal/dot"
+)
+
+// Graph is the dependency graph of a Container, as drawn by Visualize. It
+// is intended for tools that render the graph in formats other than DOT.
+//
+// Constructors are listed in the same order as in the output of Visualize.
+type Graph struct {
+	// Constructors provided to the Container and the values they produce.
+	Ctors []*GraphCtor
+
+	// Value groups that constructors add values to or depend on.
+	Groups []*GraphGroup
+
+	// Dependencies of constructors on values and value groups.
+	Edges []GraphEdge
+}
+
+// GraphCtor is a constructor in a Graph.
+type GraphCtor struct {
+	// ID of the constructor, as reported by ProvideInfo.
+	ID ID
+
+	// Package, name, file, and line of the constructor.
+	Package  string
+	Function string
+	File     string
+	Line     int
+
+	// Values produced by the constructor.
+	Results []*GraphValue
+
+	// Metadata attached to the constructor with the Tags option, if any.
+	Tags map[string]string
+}
+
+// GraphValue is a value produced by a constructor in a Graph.
+type GraphValue struct {
+	// Type of the value and its name or group, if any. At most one of
+	// Name and Group is set.
+	Type  reflect.Type
+	Name  string
+	Group string
+
+	// GroupIndex tells apart the values that constructors add to the same
+	// value group. It is zero for values that are not part of a group.
+	GroupIndex int
+
+	// ID of the constructor that produces the value.
+	CtorID ID
+}
+
+func (v *GraphValue) String() string {
+	switch {
+	case v.Name != "":
+		return fmt.Sprintf("%v[name=%v]", v.Type, v.Name)
+	case v.Group != "":
+		return fmt.Sprintf("%v[group=%v]%v", v.Type, v.Group, v.GroupIndex)
+	}
+	return v.Type.String()
+}
+
+// GraphGroup is a value group in a Graph.
+type GraphGroup struct {
+	// Type of the values in the group and name of the group.
+	Type reflect.Type
+	Name string
+
+	// Values added to the group, which are also listed in the Results of
+	// the constructors that produce them.
+	Values []*GraphValue
+}
+
+func (g *GraphGroup) String() string {
+	return fmt.Sprintf("[type=%v group=%v]", g.Type, g.Name)
+}
+
+// GraphEdge is a dependency of a constructor in a Graph.
+type GraphEdge struct {
+	// ID of the constructor that depends on the value.
+	From ID
+
+	// Type and name of the value that the constructor depends on. For
+	// dependencies on value groups, Type is the type of the values in the
+	// group and Group is the name of the group.
+	//
+	// The value may not be produced by any constructor in the Graph if
+	// it is missing or provided to a parent Scope.
+	Type  reflect.Type
+	Name  string
+	Group string
+
+	// Optional reports whether the dependency is optional.
+	Optional bool
+}
+
+// Graph returns the dependency graph of the Container. It holds the same
+// information as the output of Visualize, without rendering it.
+func (c *Container) Graph() *Graph {
+	defer c.scope.lock()()
+
+	dg := c.createGraph()
+	dg.Sort()
+	return newGraph(dg)
+}
+
+// newGraph converts the internal model of the DOT graph to a Graph.
+func newGraph(dg *dot.Graph) *Graph {
+	g := new(Graph)
+	values := make(map[*dot.Result]*GraphValue)
+	for _, dc := range dg.Ctors {
+		ctor := &GraphCtor{
+			ID:       ID(dc.ID),
+			Package:  dc.Package,
+			Function: dc.Name,
+			File:     dc.File,
+			Line:     dc.Line,
+			Tags:     dc.Tags,
+		}
+		for _, r := range dc.Results {
+			v := &GraphValue{
+				Type:       r.Type,
+				Name:       r.Name,
+				Group:      r.Group,
+				GroupIndex: r.GroupIndex,
+				CtorID:     ctor.ID,
+			}
+			values[r] = v
+			ctor.Results = append(ctor.Results, v)
+		}
+		for _, p := range dc.Params {
+			g.Edges = append(g.Edges, GraphEdge{
+				From:     ctor.ID,
+				Type:     p.Type,
+				Name:     p.Name,
+				Optional: p.Optional,
+			})
+		}
+		for _, gp := range dc.GroupParams {
+			g.Edges = append(g.Edges, GraphEdge{
+				From:  ctor.ID,
+				Type:  gp.Type,
+				Group: gp.Name,
+			})
+		}
+		g.Ctors = append(g.Ctors, ctor)
+	}
+
+	for _, dgr := range dg.Groups {
+		group := &GraphGroup{Type: dgr.Type, Name: dgr.Name}
+		for _, r := range dgr.Results {
+			group.Values = append(group.Values, values[r])
+		}
+		g.Groups = append(g.Groups, group)
+	}
+	return g
+}
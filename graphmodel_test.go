@@ -0,0 +1,116 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestGraph(t *testing.T) {
+	t.Parallel()
+
+	type A struct{}
+	type B struct{}
+	type params struct {
+		dig.In
+
+		A  *A   `name:"primary"`
+		B  *B   `optional:"true"`
+		Bs []*B `group:"bs"`
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		g := digtest.New(t).Graph()
+		assert.Empty(t, g.Ctors)
+		assert.Empty(t, g.Groups)
+		assert.Empty(t, g.Edges)
+	})
+
+	t.Run("contents", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		var aInfo, bInfo, sInfo dig.ProvideInfo
+		c.RequireProvide(func() *A { return &A{} },
+			dig.Name("primary"),
+			dig.Tags(map[string]string{"team": "core"}),
+			dig.FillProvideInfo(&aInfo))
+		c.RequireProvide(func() *B { return &B{} }, dig.Group("bs"), dig.FillProvideInfo(&bInfo))
+		c.RequireProvide(func(params) string { return "" }, dig.FillProvideInfo(&sInfo))
+
+		g := c.Graph()
+		require.Len(t, g.Ctors, 3)
+
+		a, b, s := g.Ctors[0], g.Ctors[1], g.Ctors[2]
+		assert.Equal(t, aInfo.ID, a.ID)
+		assert.Equal(t, bInfo.ID, b.ID)
+		assert.Equal(t, sInfo.ID, s.ID)
+
+		assert.Equal(t, "go.uber.org/dig_test", a.Package)
+		assert.Contains(t, a.Function, "TestGraph")
+		assert.Contains(t, a.File, "graphmodel_test.go")
+		assert.NotZero(t, a.Line)
+		assert.Equal(t, map[string]string{"team": "core"}, a.Tags)
+
+		require.Len(t, a.Results, 1)
+		assert.Equal(t, reflect.TypeOf(&A{}), a.Results[0].Type)
+		assert.Equal(t, "primary", a.Results[0].Name)
+		assert.Equal(t, a.ID, a.Results[0].CtorID)
+
+		require.Len(t, b.Results, 1)
+		assert.Equal(t, "bs", b.Results[0].Group)
+
+		require.Len(t, g.Groups, 1)
+		group := g.Groups[0]
+		assert.Equal(t, reflect.TypeOf(&B{}), group.Type)
+		assert.Equal(t, "bs", group.Name)
+		require.Len(t, group.Values, 1)
+		assert.Same(t, b.Results[0], group.Values[0],
+			"group values must be the results of their constructors")
+
+		assert.ElementsMatch(t, []dig.GraphEdge{
+			{From: s.ID, Type: reflect.TypeOf(&A{}), Name: "primary"},
+			{From: s.ID, Type: reflect.TypeOf(&B{}), Optional: true},
+			{From: s.ID, Type: reflect.TypeOf(&B{}), Group: "bs"},
+		}, g.Edges)
+	})
+
+	t.Run("missing dependency", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func(*A) string { return "" })
+
+		g := c.Graph()
+		require.Len(t, g.Ctors, 1)
+		assert.Equal(t, []dig.GraphEdge{
+			{From: g.Ctors[0].ID, Type: reflect.TypeOf(&A{})},
+		}, g.Edges)
+	})
+}
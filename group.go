@@ -23,45 +23,493 @@ package dig
 import (
 	"fmt"
 	"io"
+	"reflect"
 	"strings"
+
+	"go.uber.org/dig/internal/digreflect"
 )
 
 const (
 	_groupTag = "group"
+
+	// _groupNamespaceSep separates the namespaces of a value group from its
+	// name, as in "payments/handlers".
+	_groupNamespaceSep = "/"
+
+	// _groupTagOption introduces the GroupTag that consumers of a value
+	// group filter its values by, as in "routes,tag=admin".
+	_groupTagOption = "tag"
+
+	// _groupSelectOption introduces the way a consumer selects a single
+	// value from a value group, as in "backends,select=weighted".
+	_groupSelectOption = "select"
+
+	// _groupSelectWeighted selects a random value of a value group,
+	// weighted by the Weight of its constructor.
+	_groupSelectWeighted = "weighted"
+
+	// _groupOmitNil makes a result skip the value group when it is nil.
+	_groupOmitNil = "omitnil"
 )
 
+// group is the parsed form of a group tag, like "routes,soft,tag=admin".
 type group struct {
 	Name    string
 	Flatten bool
 	Soft    bool
+
+	// Tag, if set, restricts a consumed value group to the values provided
+	// by constructors with a matching GroupTag.
+	Tag string
+
+	// Select, if set, is how a single value is selected from a consumed
+	// value group. The only supported selection is _groupSelectWeighted.
+	Select string
+
+	// OmitNil, if set, keeps nil results out of the value group.
+	OmitNil bool
+}
+
+// String returns the group tag that parses to g, with its options in the
+// order of _groupOptionSpecs.
+func (g group) String() string {
+	parts := []string{g.Name}
+	for _, spec := range _groupOptionSpecs {
+		if v, ok := spec.get(g); ok {
+			if spec.Value != "" {
+				parts = append(parts, spec.Name+"="+v)
+			} else {
+				parts = append(parts, spec.Name)
+			}
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// groupOptionSpec describes an option that may follow the name of a value
+// group in a group tag. Options are either flags, like "flatten", or take
+// a value, like "tag=admin".
+type groupOptionSpec struct {
+	// Name of the option, as written before the "=", if any.
+	Name string
+
+	// Value describes the value of the option for error messages, as in
+	// "tag=<tag>". Empty for flags.
+	Value string
+
+	// set applies the option with the given value to g, or explains why
+	// the value is invalid. The value of a flag is always empty.
+	set func(g *group, value string) (reason string)
+
+	// get reports whether the option is set on g, and its value.
+	get func(g group) (value string, ok bool)
+}
+
+// _groupOptionSpecs lists the options that may follow the name of a value
+// group in a group tag.
+var _groupOptionSpecs = []groupOptionSpec{
+	{
+		Name: "flatten",
+		set:  func(g *group, _ string) string { g.Flatten = true; return "" },
+		get:  func(g group) (string, bool) { return "", g.Flatten },
+	},
+	{
+		Name: "soft",
+		set:  func(g *group, _ string) string { g.Soft = true; return "" },
+		get:  func(g group) (string, bool) { return "", g.Soft },
+	},
+	{
+		Name:  _groupTagOption,
+		Value: "<tag>",
+		set: func(g *group, v string) string {
+			if v == "" {
+				return fmt.Sprintf("the tag cannot be empty, as in %q", _groupTagOption+"=admin")
+			}
+			g.Tag = v
+			return ""
+		},
+		get: func(g group) (string, bool) { return g.Tag, g.Tag != "" },
+	},
+	{
+		Name:  _groupSelectOption,
+		Value: _groupSelectWeighted,
+		set: func(g *group, v string) string {
+			if v != _groupSelectWeighted {
+				return fmt.Sprintf("the only supported selection is %q", _groupSelectWeighted)
+			}
+			g.Select = v
+			return ""
+		},
+		get: func(g group) (string, bool) { return g.Select, g.Select != "" },
+	},
+	{
+		Name: _groupOmitNil,
+		set:  func(g *group, _ string) string { g.OmitNil = true; return "" },
+		get:  func(g group) (string, bool) { return "", g.OmitNil },
+	},
+}
+
+// String returns the option as listed in error messages.
+func (spec groupOptionSpec) String() string {
+	if spec.Value == "" {
+		return spec.Name
+	}
+	return spec.Name + "=" + spec.Value
+}
+
+func findGroupOptionSpec(name string) (groupOptionSpec, bool) {
+	for _, spec := range _groupOptionSpecs {
+		if spec.Name == name {
+			return spec, true
+		}
+	}
+	return groupOptionSpec{}, false
 }
 
-type errInvalidGroupOption struct{ Option string }
+// errInvalidGroupOption is returned for an option of a group tag that
+// cannot be parsed.
+type errInvalidGroupOption struct {
+	// Option as written in the tag.
+	Option string
+
+	// Reason the option is invalid.
+	Reason string
+}
 
 var _ digError = errInvalidGroupOption{}
 
 func (e errInvalidGroupOption) Error() string { return fmt.Sprint(e) }
 
 func (e errInvalidGroupOption) writeMessage(w io.Writer, v string) {
-	fmt.Fprintf(w, "invalid option %q", e.Option)
+	fmt.Fprintf(w, "invalid option %q: %v", e.Option, e.Reason)
 }
 
 func (e errInvalidGroupOption) Format(w fmt.State, c rune) {
 	formatError(e, w, c)
 }
 
-func parseGroupString(s string) (group, error) {
+// groupToken is one of the comma-separated options of a group tag.
+type groupToken struct {
+	// Option as written in the tag.
+	Raw string
+
+	// Name of the option and its value, if it was written as name=value.
+	Name     string
+	Value    string
+	HasValue bool
+}
+
+// tokenizeGroupString splits a group tag into the name of the group and
+// its options. It never fails: validating the tokens is up to the caller.
+func tokenizeGroupString(s string) (name string, tokens []groupToken) {
 	components := strings.Split(s, ",")
-	g := group{Name: components[0]}
 	for _, c := range components[1:] {
-		switch c {
-		case "flatten":
-			g.Flatten = true
-		case "soft":
-			g.Soft = true
-		default:
-			return g, errInvalidGroupOption{Option: c}
+		tok := groupToken{Raw: c, Name: c}
+		if i := strings.IndexByte(c, '='); i >= 0 {
+			tok.Name, tok.Value, tok.HasValue = c[:i], c[i+1:], true
+		}
+		tokens = append(tokens, tok)
+	}
+	return components[0], tokens
+}
+
+func parseGroupString(s string) (group, error) {
+	name, tokens := tokenizeGroupString(s)
+	g := group{Name: name}
+	switch {
+	case name == "":
+		return g, newErrInvalidInput(fmt.Sprintf("invalid group %q: the name of the group cannot be empty", s), nil)
+	case strings.TrimSpace(name) != name:
+		return g, newErrInvalidInput(fmt.Sprintf("invalid group %q: the name of the group cannot begin or end with spaces", s), nil)
+	}
+	if err := validateGroupName(name); err != nil {
+		return g, err
+	}
+
+	seen := make(map[string]struct{}, len(tokens))
+	for _, tok := range tokens {
+		if err := applyGroupToken(&g, tok, seen); err != nil {
+			return g, err
 		}
 	}
 	return g, nil
 }
+
+// applyGroupToken applies the given option of a group tag to g. seen holds
+// the names of the options that were already applied.
+func applyGroupToken(g *group, tok groupToken, seen map[string]struct{}) error {
+	invalid := func(reason string) error {
+		return errInvalidGroupOption{Option: tok.Raw, Reason: reason}
+	}
+
+	switch {
+	case tok.Raw == "":
+		return invalid(`options cannot be empty, remove the extra ","`)
+	case strings.TrimSpace(tok.Raw) != tok.Raw:
+		return invalid("options cannot contain spaces, " + validGroupOptions())
+	}
+
+	spec, ok := findGroupOptionSpec(tok.Name)
+	if !ok {
+		return invalid("unknown option, " + validGroupOptions())
+	}
+	if _, ok := seen[spec.Name]; ok {
+		return invalid("the option is specified more than once")
+	}
+	seen[spec.Name] = struct{}{}
+
+	switch {
+	case spec.Value == "" && tok.HasValue:
+		return invalid(fmt.Sprintf("%v does not take a value", spec.Name))
+	case spec.Value != "" && !tok.HasValue:
+		return invalid(fmt.Sprintf("%v requires a value, as in %q", spec.Name, spec))
+	}
+	if reason := spec.set(g, tok.Value); reason != "" {
+		return invalid(reason)
+	}
+	return nil
+}
+
+// validGroupOptions lists the options that may follow the name of a value
+// group in a group tag, for error messages.
+func validGroupOptions() string {
+	valid := make([]string, len(_groupOptionSpecs))
+	for i, spec := range _groupOptionSpecs {
+		valid[i] = spec.String()
+	}
+	return fmt.Sprintf("valid options are %q", valid)
+}
+
+// validateGroupName checks that none of the namespaces or the name of a
+// namespaced value group, like "payments/handlers", are empty.
+func validateGroupName(name string) error {
+	if !strings.Contains(name, _groupNamespaceSep) {
+		return nil
+	}
+	for _, part := range strings.Split(name, _groupNamespaceSep) {
+		if part == "" {
+			return newErrInvalidInput(fmt.Sprintf(
+				"invalid group name %q: namespaces and names separated by %q cannot be empty", name, _groupNamespaceSep), nil)
+		}
+	}
+	return nil
+}
+
+// qualifyGroup places the given value group in the given namespace, if any.
+func qualifyGroup(namespace, name string) string {
+	if namespace == "" || name == "" {
+		return name
+	}
+	return namespace + _groupNamespaceSep + name
+}
+
+// groupBaseName returns the name of a value group without its namespaces.
+func groupBaseName(name string) string {
+	return name[strings.LastIndex(name, _groupNamespaceSep)+1:]
+}
+
+// GroupNamespace is a ProvideOption that places all value groups that a
+// constructor provides into the given namespace, so that the same group
+// name may be used by unrelated constructors without their values being
+// mixed. The namespace is prepended to the group name with a "/".
+//
+//	c.Provide(NewRefundHandler, dig.Group("handlers"), dig.GroupNamespace("payments"))
+//
+// The value above is part of the "payments/handlers" group, which consumers
+// request by its full name.
+//
+//	type Params struct {
+//	  dig.In
+//
+//	  Handlers []Handler `group:"payments/handlers"`
+//	}
+//
+// The namespace applies to groups specified with Group, ResultTags,
+// ReportErrorsToGroup, and dig.Out struct tags. Packages that provide many
+// constructors may pass the same GroupNamespace to all of them. Namespaces
+// may be nested with "/", and cannot contain ",", quotes, or backquotes. An
+// empty namespace has no effect.
+func GroupNamespace(namespace string) ProvideOption {
+	return provideGroupNamespaceOption(namespace)
+}
+
+type provideGroupNamespaceOption string
+
+func (o provideGroupNamespaceOption) String() string {
+	return fmt.Sprintf("GroupNamespace(%q)", string(o))
+}
+
+func (o provideGroupNamespaceOption) applyProvideOption(opts *provideOptions) {
+	opts.GroupNamespace = string(o)
+}
+
+// validateGroupNamespace checks that the given namespace may be prepended
+// to the names of value groups in struct tags.
+func validateGroupNamespace(namespace string) error {
+	if strings.ContainsAny(namespace, ",\"`") {
+		return newErrInvalidInput(fmt.Sprintf(
+			"invalid dig.GroupNamespace(%q): namespaces cannot contain commas, quotes, or backquotes", namespace), nil)
+	}
+	if err := validateGroupName(namespace); err != nil {
+		return newErrInvalidInput(fmt.Sprintf("invalid dig.GroupNamespace(%q)", namespace), err)
+	}
+	return nil
+}
+
+// GroupTag is a ProvideOption that tags all values that a constructor
+// provides to value groups, so that consumers may request only the values
+// with that tag. Consumers filter a value group by tag with the "tag"
+// option of the group tag.
+//
+//	c.Provide(NewUsersRoute, dig.Group("routes"), dig.GroupTag("admin"))
+//
+//	type AdminParams struct {
+//	  dig.In
+//
+//	  Routes []Route `group:"routes,tag=admin"`
+//	}
+//
+// Consumers that do not specify a tag receive all values of the group
+// regardless of their tags. Values produced by decorators have no tag, so
+// consumers cannot filter value groups that were decorated. An empty tag
+// has no effect.
+func GroupTag(tag string) ProvideOption {
+	return provideGroupTagOption(tag)
+}
+
+type provideGroupTagOption string
+
+func (o provideGroupTagOption) String() string {
+	return fmt.Sprintf("GroupTag(%q)", string(o))
+}
+
+func (o provideGroupTagOption) applyProvideOption(opts *provideOptions) {
+	opts.GroupTag = string(o)
+}
+
+// groupFilter selects the members of a value group that a consumer
+// receives, by the GroupTag and the labels that they were provided with.
+type groupFilter struct {
+	// Tag, if set, is the GroupTag that members must have.
+	Tag string
+
+	// Labels that members must all have. Without labels, only the members
+	// that have no labels are received, unless AnyLabels is set.
+	Labels    []string
+	AnyLabels bool
+}
+
+// matchesAll reports whether the filter receives every member.
+func (f groupFilter) matchesAll() bool {
+	return f.Tag == "" && f.AnyLabels
+}
+
+// matches reports whether the filter receives the given member.
+func (f groupFilter) matches(m groupMember) bool {
+	switch {
+	case f.Tag != "" && m.Tag != f.Tag:
+		return false
+	case f.AnyLabels:
+		return true
+	case len(f.Labels) == 0:
+		return len(m.Labels) == 0
+	}
+	return hasGroupLabels(m.Labels, f.Labels)
+}
+
+// String returns the filter as it is listed after the name of a consumed
+// value group, as in `, tag="internal", labels="admin"`.
+func (f groupFilter) String() string {
+	var b strings.Builder
+	if f.Tag != "" {
+		fmt.Fprintf(&b, ", tag=%q", f.Tag)
+	}
+	switch {
+	case f.AnyLabels:
+		fmt.Fprintf(&b, ", labels=%q", _anyLabels)
+	case len(f.Labels) > 0:
+		fmt.Fprintf(&b, ", labels=%q", strings.Join(f.Labels, ","))
+	}
+	return b.String()
+}
+
+// GroupValue is a member of a value group along with information about the
+// constructor that provided it. Consume a value group as a slice of
+// GroupValue to learn where each of its values came from.
+//
+//	type Params struct {
+//	  dig.In
+//
+//	  Plugins []dig.GroupValue[Plugin] `group:"plugins"`
+//	}
+//
+// Values produced by decorators do not carry information about their
+// provider.
+type GroupValue[T any] struct {
+	// Value is the member of the value group.
+	Value T
+
+	// Package, Function, File, and Line identify the constructor that
+	// provided Value.
+	Package  string
+	Function string
+	File     string
+	Line     int
+}
+
+func (GroupValue[T]) digGroupValue() {}
+
+// groupValue is implemented by all instantiations of GroupValue.
+type groupValue interface{ digGroupValue() }
+
+var _groupValueType = reflect.TypeOf((*groupValue)(nil)).Elem()
+
+// isGroupValue reports whether t is an instantiation of GroupValue.
+func isGroupValue(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.Implements(_groupValueType)
+}
+
+// groupMember holds information about the constructor that provided a
+// member of a value group.
+type groupMember struct {
+	// Function that produced the value, or nil if unknown.
+	Source *digreflect.Func
+
+	// Tag is the GroupTag of the constructor, if any.
+	Tag string
+
+	// Labels of the value, if any.
+	Labels []string
+
+	// Weight of the value set with the Weight option, or zero if unset.
+	Weight int
+
+	// Seq is the number of the constructor that produced the value, in
+	// the order constructors were provided, or zero if unknown.
+	Seq uint64
+
+	// Index of the value among the values that its constructor submitted
+	// to the group, in the order they were returned.
+	Index int
+}
+
+// groupEntry is a member of a value group along with information about the
+// constructor that produced it, if known.
+type groupEntry struct {
+	Value reflect.Value
+
+	groupMember
+}
+
+// newGroupValue builds a GroupValue of type t for the given entry.
+func newGroupValue(t reflect.Type, e groupEntry) reflect.Value {
+	gv := reflect.New(t).Elem()
+	gv.FieldByName("Value").Set(e.Value)
+	if src := e.Source; src != nil {
+		gv.FieldByName("Package").SetString(src.Package)
+		gv.FieldByName("Function").SetString(src.Name)
+		gv.FieldByName("File").SetString(src.File)
+		gv.FieldByName("Line").SetInt(int64(src.Line))
+	}
+	return gv
+}
@@ -21,6 +21,7 @@
 package dig
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -48,11 +49,146 @@ func TestParseGroup(t *testing.T) {
 			group: "somegroup,soft",
 			wantG: group{Name: "somegroup", Soft: true},
 		},
+		{
+			name:  "namespaced group",
+			group: `payments/handlers,flatten`,
+			wantG: group{Name: "payments/handlers", Flatten: true},
+		},
+		{
+			name:  "tagged group",
+			group: `routes,soft,tag=admin`,
+			wantG: group{Name: "routes", Soft: true, Tag: "admin"},
+		},
+		{
+			name:    "empty tag",
+			group:   `routes,tag=`,
+			wantErr: `invalid option "tag=": the tag cannot be empty, as in "tag=admin"`,
+		},
+		{
+			name:  "weighted selection",
+			group: `backends,select=weighted`,
+			wantG: group{Name: "backends", Select: "weighted"},
+		},
+		{
+			name:  "omitnil",
+			group: `routes,omitnil`,
+			wantG: group{Name: "routes", OmitNil: true},
+		},
+		{
+			name:    "unknown selection",
+			group:   `backends,select=random`,
+			wantErr: `invalid option "select=random": the only supported selection is "weighted"`,
+		},
+		{
+			name:    "empty namespace",
+			group:   `/handlers`,
+			wantErr: `invalid group name "/handlers": namespaces and names separated by "/" cannot be empty`,
+		},
+		{
+			name:    "empty name",
+			group:   `payments/`,
+			wantErr: `invalid group name "payments/"`,
+		},
 		{
 			name:    "error",
 			group:   `somegroup,abc`,
 			wantErr: `invalid option "abc"`,
 		},
+		{
+			name:    "unknown option",
+			group:   `somegroup,flaten`,
+			wantErr: `invalid option "flaten": unknown option, valid options are ["flatten" "soft" "tag=<tag>" "select=weighted" "omitnil"]`,
+		},
+		{
+			name:    "empty option",
+			group:   `a,,flatten`,
+			wantErr: `invalid option "": options cannot be empty, remove the extra ","`,
+		},
+		{
+			name:    "trailing comma",
+			group:   `a,`,
+			wantErr: `invalid option "": options cannot be empty`,
+		},
+		{
+			name:    "spaces",
+			group:   `a, flatten`,
+			wantErr: `invalid option " flatten": options cannot contain spaces, valid options are`,
+		},
+		{
+			name:    "duplicate option",
+			group:   `a,soft,soft`,
+			wantErr: `invalid option "soft": the option is specified more than once`,
+		},
+		{
+			name:    "duplicate tag",
+			group:   `a,tag=x,tag=y`,
+			wantErr: `invalid option "tag=y": the option is specified more than once`,
+		},
+		{
+			name:    "empty name",
+			group:   `,flatten`,
+			wantErr: `invalid group ",flatten": the name of the group cannot be empty`,
+		},
+		{
+			name:    "only commas",
+			group:   `,,`,
+			wantErr: `the name of the group cannot be empty`,
+		},
+		{
+			name:    "option with value",
+			group:   `a,flatten=true`,
+			wantErr: `invalid option "flatten=true": flatten does not take a value`,
+		},
+		{
+			name:    "empty value of flag",
+			group:   `a,soft=`,
+			wantErr: `invalid option "soft=": soft does not take a value`,
+		},
+		{
+			name:    "option without value",
+			group:   `a,tag`,
+			wantErr: `invalid option "tag": tag requires a value, as in "tag=<tag>"`,
+		},
+		{
+			name:  "value containing equals",
+			group: `a,tag=x=y`,
+			wantG: group{Name: "a", Tag: "x=y"},
+		},
+		{
+			name:    "duplicate option with different values",
+			group:   `a,select=weighted,select=random`,
+			wantErr: `invalid option "select=random": the option is specified more than once`,
+		},
+		{
+			name:    "empty and trailing options",
+			group:   `a,,flatten,`,
+			wantErr: `invalid option "": options cannot be empty, remove the extra ","`,
+		},
+		{
+			name:    "spaces around name",
+			group:   ` a,flatten`,
+			wantErr: `invalid group " a,flatten": the name of the group cannot begin or end with spaces`,
+		},
+		{
+			name:  "spaces inside name",
+			group: `a b`,
+			wantG: group{Name: "a b"},
+		},
+		{
+			name:    "trailing spaces in option",
+			group:   `a,soft `,
+			wantErr: `invalid option "soft ": options cannot contain spaces`,
+		},
+		{
+			name:    "option name is case sensitive",
+			group:   `a,Flatten`,
+			wantErr: `invalid option "Flatten": unknown option`,
+		},
+		{
+			name:  "all options",
+			group: `a,omitnil,select=weighted,tag=x,soft,flatten`,
+			wantG: group{Name: "a", Flatten: true, Soft: true, Tag: "x", Select: "weighted", OmitNil: true},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -62,7 +198,45 @@ func TestParseGroup(t *testing.T) {
 				assert.Contains(t, err.Error(), tt.wantErr)
 				return
 			}
+			assert.NoError(t, err)
 			assert.Equal(t, tt.wantG, gotG)
+
+			// The canonical form of a group must parse to the same group.
+			again, err := parseGroupString(gotG.String())
+			assert.NoError(t, err)
+			assert.Equal(t, gotG, again)
 		})
 	}
 }
+
+func TestGroupString(t *testing.T) {
+	assert.Equal(t, "a", group{Name: "a"}.String())
+	assert.Equal(t, "a,flatten,soft,tag=x,select=weighted,omitnil",
+		group{Name: "a", Flatten: true, Soft: true, Tag: "x", Select: "weighted", OmitNil: true}.String())
+}
+
+func FuzzParseGroupString(f *testing.F) {
+	for _, seed := range []string{
+		"a", "a,flatten", "a,soft,tag=admin", "payments/handlers,omitnil",
+		"a,select=weighted", "a,,flatten,", ",", " a", "a, soft", "a,tag=",
+		"a,flatten=true", "a,tag", "/", "a//b",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		g, err := parseGroupString(s)
+		if err != nil {
+			return
+		}
+
+		assert.NotEmpty(t, g.Name, "name must not be empty")
+		assert.Equal(t, strings.TrimSpace(g.Name), g.Name, "name must not begin or end with spaces")
+		assert.NotContains(t, g.Name, ",", "name must not contain commas")
+
+		again, err := parseGroupString(g.String())
+		if assert.NoError(t, err, "canonical form %q of %q must parse", g.String(), s) {
+			assert.Equal(t, g, again, "canonical form %q of %q must parse to the same group", g.String(), s)
+		}
+	})
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// GroupInfo describes the constructors that add values to a value group,
+// and the values that they already added. See Container.GroupInfo.
+type GroupInfo struct {
+	// Type of the values in the group and name of the group.
+	Type  reflect.Type
+	Group string
+
+	// Locations of the constructors visible to the Scope that add values
+	// to the group, starting with those provided to the Scope itself.
+	Providers []string
+
+	// Number of values that were already added to the group, because the
+	// constructors that provide them were called.
+	Values int
+
+	// Flatten reports whether any of the constructors adds a slice of
+	// values to the group with the flatten option, in which case the group
+	// may end up with more or fewer values than it has Providers.
+	Flatten bool
+}
+
+// GroupInfo describes the value group with the given name and type of
+// values, without calling any constructors. elemType must be a pointer to
+// the type of the values, as with As. For example,
+//
+//	info := c.GroupInfo("health", (*HealthCheck)(nil))
+//	if len(info.Providers) < 3 {
+//		return errors.New("not enough health checks")
+//	}
+//
+// GroupInfo panics if elemType is not a pointer.
+func (c *Container) GroupInfo(group string, elemType interface{}) GroupInfo {
+	return c.scope.GroupInfo(group, elemType)
+}
+
+// GroupInfo describes the value group with the given name and type of
+// values, as consumed by the constructors of this Scope.
+// See Container.GroupInfo for details.
+func (s *Scope) GroupInfo(group string, elemType interface{}) GroupInfo {
+	t := reflect.TypeOf(elemType)
+	if t == nil || t.Kind() != reflect.Ptr {
+		panic(fmt.Sprintf("dig.GroupInfo: elemType must be a pointer, got %v", t))
+	}
+
+	k := key{group: group, t: t.Elem()}
+	info := GroupInfo{Type: k.t, Group: group}
+
+	defer s.lock()()
+	for _, scope := range s.ancestors() {
+		for _, n := range scope.providers[k] {
+			info.Providers = append(info.Providers, fmt.Sprint(n.Location()))
+			if !info.Flatten {
+				walkResult(n.ResultList(), groupFlattenVisitor{k: k, flatten: &info.Flatten})
+			}
+		}
+		info.Values += len(scope.groups[k])
+	}
+	return info
+}
+
+// groupFlattenVisitor is a resultVisitor that reports whether any of the
+// results it visits adds values to a value group with the flatten option.
+type groupFlattenVisitor struct {
+	k       key
+	flatten *bool
+}
+
+func (v groupFlattenVisitor) Visit(res result) resultVisitor {
+	if rg, ok := res.(resultGrouped); ok && rg.Flatten && rg.Group == v.k.group {
+		if rg.Type == v.k.t {
+			*v.flatten = true
+		}
+		for _, t := range rg.As {
+			if t == v.k.t {
+				*v.flatten = true
+			}
+		}
+	}
+	return v
+}
+
+func (v groupFlattenVisitor) AnnotateWithField(resultObjectField) resultVisitor { return v }
+
+func (v groupFlattenVisitor) AnnotateWithPosition(int) resultVisitor { return v }
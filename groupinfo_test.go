@@ -0,0 +1,132 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestGroupInfo(t *testing.T) {
+	t.Parallel()
+
+	type HealthCheck string
+
+	type checks struct {
+		dig.In
+
+		Checks []HealthCheck `group:"health"`
+	}
+
+	t.Run("does not call constructors", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		for i := 0; i < 3; i++ {
+			c.RequireProvide(func() HealthCheck {
+				t.Fatal("must not be called")
+				return ""
+			}, dig.Group("health"))
+		}
+
+		info := c.GroupInfo("health", (*HealthCheck)(nil))
+		assert.Equal(t, reflect.TypeOf(HealthCheck("")), info.Type)
+		assert.Equal(t, "health", info.Group)
+		require.Len(t, info.Providers, 3)
+		assert.Contains(t, info.Providers[0], "TestGroupInfo")
+		assert.Zero(t, info.Values)
+		assert.False(t, info.Flatten)
+	})
+
+	t.Run("counts values", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() HealthCheck { return "db" }, dig.Group("health"))
+		c.RequireProvide(func() HealthCheck { return "cache" }, dig.Group("health"))
+		c.RequireInvoke(func(checks) {})
+
+		info := c.GroupInfo("health", (*HealthCheck)(nil))
+		assert.Len(t, info.Providers, 2)
+		assert.Equal(t, 2, info.Values)
+	})
+
+	t.Run("flatten", func(t *testing.T) {
+		t.Parallel()
+
+		type result struct {
+			dig.Out
+
+			Checks []HealthCheck `group:"health,flatten"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() HealthCheck { return "db" }, dig.Group("health"))
+		c.RequireProvide(func() result {
+			return result{Checks: []HealthCheck{"a", "b", "c"}}
+		})
+		c.RequireInvoke(func(checks) {})
+
+		info := c.GroupInfo("health", (*HealthCheck)(nil))
+		assert.Len(t, info.Providers, 2)
+		assert.Equal(t, 4, info.Values)
+		assert.True(t, info.Flatten)
+	})
+
+	t.Run("scopes", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() HealthCheck { return "db" }, dig.Group("health"))
+		child := c.Scope("child")
+		child.RequireProvide(func() HealthCheck { return "cache" }, dig.Group("health"))
+
+		assert.Len(t, c.GroupInfo("health", (*HealthCheck)(nil)).Providers, 1,
+			"constructors of child scopes must not be visible to the parent")
+		assert.Len(t, child.GroupInfo("health", (*HealthCheck)(nil)).Providers, 2,
+			"constructors of the parent must be visible to child scopes")
+	})
+
+	t.Run("unknown group", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() HealthCheck { return "db" }, dig.Group("health"))
+
+		info := c.GroupInfo("checks", (*HealthCheck)(nil))
+		assert.Empty(t, info.Providers)
+		assert.Zero(t, info.Values)
+	})
+
+	t.Run("not a pointer", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		assert.PanicsWithValue(t,
+			"dig.GroupInfo: elemType must be a pointer, got dig_test.HealthCheck",
+			func() { c.GroupInfo("health", HealthCheck("")) })
+	})
+}
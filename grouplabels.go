@@ -0,0 +1,158 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	// _labelsTag is the struct tag key of the labels of values provided to,
+	// or consumed from, a value group.
+	_labelsTag = "labels"
+
+	// _anyLabels is the labels tag of a consumer that receives all values
+	// of a value group regardless of their labels.
+	_anyLabels = "*"
+)
+
+// GroupLabels is a ProvideOption that labels all values that a constructor
+// provides to value groups, so that consumers may request only the values
+// with certain labels. Consumers filter a value group by label with the
+// "labels" struct tag, and receive only the values that have all of the
+// labels it lists.
+//
+//	c.Provide(NewUsersRoute, dig.Group("routes"), dig.GroupLabels("admin", "v2"))
+//
+//	type AdminParams struct {
+//	  dig.In
+//
+//	  Routes []Route `group:"routes" labels:"admin"`
+//	}
+//
+// Fields of dig.Out structs may label their values with the same tag, in
+// addition to the labels of the constructor.
+//
+//	type Result struct {
+//	  dig.Out
+//
+//	  Route Route `group:"routes" labels:"admin"`
+//	}
+//
+// Consumers that do not specify labels receive only the values that have
+// no labels. Consumers that specify `labels:"*"` receive all values of the
+// group, whether they have labels or not. Values produced by decorators
+// have no labels, so consumers cannot filter value groups that were
+// decorated by label.
+func GroupLabels(labels ...string) ProvideOption {
+	return provideGroupLabelsOption(labels)
+}
+
+type provideGroupLabelsOption []string
+
+func (o provideGroupLabelsOption) String() string {
+	return fmt.Sprintf("GroupLabels(%q)", []string(o))
+}
+
+func (o provideGroupLabelsOption) applyProvideOption(opts *provideOptions) {
+	opts.GroupLabels = append(opts.GroupLabels, o...)
+}
+
+// validateGroupLabel returns an error if label cannot be used in a labels
+// tag.
+func validateGroupLabel(label string) error {
+	switch {
+	case label == "":
+		return fmt.Errorf("labels cannot be empty")
+	case label == _anyLabels:
+		return fmt.Errorf("%q matches all labels and cannot be used as a label", _anyLabels)
+	case strings.ContainsAny(label, ",`\" "):
+		return fmt.Errorf("labels cannot contain commas, quotes, backquotes, or spaces")
+	}
+	return nil
+}
+
+// parseGroupLabels parses the labels tag of the given field, if any.
+func parseGroupLabels(f reflect.StructField) ([]string, error) {
+	tag, ok := f.Tag.Lookup(_labelsTag)
+	if !ok {
+		return nil, nil
+	}
+
+	labels := strings.Split(tag, ",")
+	for _, l := range labels {
+		if err := validateGroupLabel(l); err != nil {
+			return nil, newErrInvalidInput(
+				fmt.Sprintf("invalid labels %q of field %q", tag, f.Name), err)
+		}
+	}
+	return labels, nil
+}
+
+// newGroupFilter returns the filter of a consumer of the given value group
+// with the labels tag of the given field, if any.
+func newGroupFilter(g group, f reflect.StructField) (groupFilter, error) {
+	filter := groupFilter{Tag: g.Tag}
+	if f.Tag.Get(_labelsTag) == _anyLabels {
+		filter.AnyLabels = true
+		return filter, nil
+	}
+	labels, err := parseGroupLabels(f)
+	filter.Labels = labels
+	return filter, err
+}
+
+// checkGroupLabels returns an error if the given field of a dig.In or dig.Out
+// struct has a labels tag but is not part of a value group.
+func checkGroupLabels(f reflect.StructField, tags tagKeys) error {
+	if _, ok := f.Tag.Lookup(_labelsTag); ok && f.Tag.Get(tags.group()) == "" {
+		return newErrInvalidInput(fmt.Sprintf(
+			"labels can be applied to value groups only: field %q (%v) is not part of a value group", f.Name, f.Type), nil)
+	}
+	return nil
+}
+
+// hasGroupLabels reports whether have contains all of the labels in want.
+func hasGroupLabels(have, want []string) bool {
+	for _, l := range want {
+		if !containsString(have, l) {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeGroupLabels returns the labels in a followed by the labels in b that
+// are not in a.
+func mergeGroupLabels(a, b []string) []string {
+	if len(b) == 0 {
+		return a
+	}
+	merged := append([]string(nil), a...)
+	for _, l := range b {
+		if !containsString(merged, l) {
+			merged = append(merged, l)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"reflect"
+	"sort"
+)
+
+// DeterministicGroupOrder is an [Option] that gives the values of value
+// groups in a fixed order instead of shuffling them.
+//
+// Values are ordered by the constructor that produced them, in the order
+// constructors were provided. The values of a constructor that returns a
+// slice to a flattened group keep their order within the slice, so a
+// constructor that returns []Migration{m1, m2, m3} contributes m1, m2, and
+// m3 in this order. Values provided to a Scope come before those provided
+// to its ancestors.
+//
+// By default, value groups are shuffled so that consumers do not depend on
+// the order of Provide calls. Use this option if the order matters, for
+// example to run migrations or middleware in sequence.
+func DeterministicGroupOrder() Option {
+	return deterministicGroupOrderOption{}
+}
+
+type deterministicGroupOrderOption struct{}
+
+func (deterministicGroupOrderOption) String() string {
+	return "DeterministicGroupOrder()"
+}
+
+func (deterministicGroupOrderOption) applyOption(c *Container) {
+	c.scope.deterministicGroupOrder = true
+}
+
+// groupOrder returns the indexes of the given members of a value group
+// sorted by the order of their constructors and their index among the
+// values of the constructor.
+func groupOrder(members []groupMember) []int {
+	order := make([]int, len(members))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		mi, mj := members[order[i]], members[order[j]]
+		if mi.Seq != mj.Seq {
+			return mi.Seq < mj.Seq
+		}
+		return mi.Index < mj.Index
+	})
+	return order
+}
+
+// orderedCopy returns a copy of the given values of a value group, ordered
+// by groupOrder of their members.
+func orderedCopy(items []reflect.Value, members []groupMember) []reflect.Value {
+	newItems := make([]reflect.Value, len(items))
+	for i, j := range groupOrder(members) {
+		newItems[i] = items[j]
+	}
+	return newItems
+}
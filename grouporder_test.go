@@ -0,0 +1,119 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestDeterministicGroupOrder(t *testing.T) {
+	t.Parallel()
+
+	type out struct {
+		dig.Out
+
+		Value string `group:"migrations"`
+	}
+
+	type flatOut struct {
+		dig.Out
+
+		Values []string `group:"migrations,flatten"`
+	}
+
+	type in struct {
+		dig.In
+
+		Values []string `group:"migrations"`
+	}
+
+	t.Run("flattened and scalar values", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.DeterministicGroupOrder())
+		c.RequireProvide(func() out { return out{Value: "a"} })
+		c.RequireProvide(func() flatOut { return flatOut{Values: []string{"b", "c", "d"}} })
+		c.RequireProvide(func() out { return out{Value: "e"} })
+		c.RequireProvide(func() flatOut { return flatOut{Values: []string{"f", "g"}} })
+
+		for i := 0; i < 10; i++ {
+			c.RequireInvoke(func(p in) {
+				assert.Equal(t, []string{"a", "b", "c", "d", "e", "f", "g"}, p.Values)
+			})
+		}
+	})
+
+	t.Run("provide order rather than call order", func(t *testing.T) {
+		t.Parallel()
+
+		type trigger struct{}
+
+		c := digtest.New(t, dig.DeterministicGroupOrder())
+		// This constructor is called last because it depends on the
+		// value of a constructor provided after it.
+		c.RequireProvide(func(trigger) flatOut { return flatOut{Values: []string{"a", "b"}} })
+		c.RequireProvide(func() out { return out{Value: "c"} })
+		c.RequireProvide(func() trigger { return trigger{} })
+
+		c.RequireInvoke(func(trigger) {})
+		c.RequireInvoke(func(p in) {
+			assert.Equal(t, []string{"a", "b", "c"}, p.Values)
+		})
+	})
+
+	t.Run("group values", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.DeterministicGroupOrder())
+		c.RequireProvide(func() flatOut { return flatOut{Values: []string{"a", "b"}} })
+		c.RequireProvide(func() out { return out{Value: "c"} })
+
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Entries []dig.GroupValue[string] `group:"migrations"`
+		}) {
+			values := make([]string, len(p.Entries))
+			for i, e := range p.Entries {
+				values[i] = e.Value
+			}
+			assert.Equal(t, []string{"a", "b", "c"}, values)
+		})
+	})
+
+	t.Run("scopes", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.DeterministicGroupOrder())
+		c.RequireProvide(func() flatOut { return flatOut{Values: []string{"c", "d"}} })
+		s := c.Scope("child")
+		s.RequireProvide(func() out { return out{Value: "a"} })
+		s.RequireProvide(func() out { return out{Value: "b"} })
+
+		s.RequireInvoke(func(p in) {
+			assert.Equal(t, []string{"a", "b", "c", "d"}, p.Values)
+		})
+	})
+}
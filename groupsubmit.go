@@ -0,0 +1,59 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// OnGroupSubmit is an [Option] that calls f every time a value is added to
+// a value group, with the name and type of the group and the value.
+//
+//	c := dig.New(dig.OnGroupSubmit(func(group string, t reflect.Type, v reflect.Value) {
+//	  log.Printf("added %v to group %q", v, group)
+//	}))
+//
+// Value groups are built lazily: the constructors that provide values to a
+// group are called only when the group is first consumed, so f is called
+// while the group is being built for its first consumer, not when the
+// constructors are provided. Values produced by decorators do not trigger
+// f. A value provided under several types with the As option triggers f
+// once for each type.
+//
+// f is called on the goroutine that builds the group, after the value was
+// added, and must not use the Container. If the option is given more than
+// once, all functions are called in order.
+func OnGroupSubmit(f func(group string, t reflect.Type, v reflect.Value)) Option {
+	return onGroupSubmitOption{f: f}
+}
+
+type onGroupSubmitOption struct {
+	f func(group string, t reflect.Type, v reflect.Value)
+}
+
+func (o onGroupSubmitOption) String() string {
+	return fmt.Sprintf("OnGroupSubmit(%p)", o.f)
+}
+
+func (o onGroupSubmitOption) applyOption(c *Container) {
+	c.scope.groupSubmitHooks = append(c.scope.groupSubmitHooks, o.f)
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestOnGroupSubmit(t *testing.T) {
+	t.Parallel()
+
+	type Route string
+
+	type submission struct {
+		Group string
+		Type  reflect.Type
+		Value interface{}
+	}
+
+	t.Run("called when the group is consumed", func(t *testing.T) {
+		t.Parallel()
+
+		var got []submission
+		c := digtest.New(t, dig.OnGroupSubmit(func(group string, t reflect.Type, v reflect.Value) {
+			got = append(got, submission{group, t, v.Interface()})
+		}))
+		c.RequireProvide(func() Route { return "/users" }, dig.Group("routes"))
+		c.RequireProvide(func() []Route { return []Route{"/a", "/b"} }, dig.Group("routes,flatten"))
+		assert.Empty(t, got, "must not be called before the group is consumed")
+
+		c.RequireInvoke(func(struct {
+			dig.In
+
+			Routes []Route `group:"routes"`
+		}) {
+		})
+		assert.ElementsMatch(t, []submission{
+			{"routes", reflect.TypeOf(Route("")), Route("/users")},
+			{"routes", reflect.TypeOf(Route("")), Route("/a")},
+			{"routes", reflect.TypeOf(Route("")), Route("/b")},
+		}, got)
+	})
+
+	t.Run("scopes and several hooks", func(t *testing.T) {
+		t.Parallel()
+
+		var first, second []string
+		c := digtest.New(t,
+			dig.OnGroupSubmit(func(group string, _ reflect.Type, v reflect.Value) {
+				first = append(first, group+":"+string(v.Interface().(Route)))
+			}),
+			dig.OnGroupSubmit(func(group string, _ reflect.Type, v reflect.Value) {
+				second = append(second, group+":"+string(v.Interface().(Route)))
+			}),
+		)
+		child := c.Scope("child")
+		child.RequireProvide(func() Route { return "/admin" }, dig.Group("routes"))
+		child.RequireInvoke(func(struct {
+			dig.In
+
+			Routes []Route `group:"routes"`
+		}) {
+		})
+		assert.Equal(t, []string{"routes:/admin"}, first)
+		assert.Equal(t, first, second)
+	})
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// GroupUnless is a ProvideOption that makes a constructor of a value group
+// contribute to the group only if pred returns false for the values that
+// the group already holds. If pred returns true, the constructor is not
+// called and the group does not receive its values.
+//
+// For example, the following adds a default backend only if no other
+// backends were provided.
+//
+//	c.Provide(newDefaultBackend,
+//	  dig.Group("backends"),
+//	  dig.GroupUnless(func(backends []reflect.Value) bool {
+//	    return len(backends) > 0
+//	  }))
+//
+// pred is called once, when the group is first consumed, after all other
+// constructors of the group were called. It receives the values of the
+// group that are visible from the Scope that the constructor was provided
+// to, in an unspecified order. If several constructors of the same group
+// use GroupUnless, the values of those that were called before are visible
+// to the later ones.
+//
+// The constructor must provide values to a single value group, as a single
+// type, and nothing else.
+func GroupUnless(pred func([]reflect.Value) bool) ProvideOption {
+	return provideGroupUnlessOption{pred: pred}
+}
+
+type provideGroupUnlessOption struct {
+	pred func([]reflect.Value) bool
+}
+
+func (o provideGroupUnlessOption) String() string {
+	return fmt.Sprintf("GroupUnless(%p)", o.pred)
+}
+
+func (o provideGroupUnlessOption) applyProvideOption(opts *provideOptions) {
+	opts.GroupUnless = o.pred
+}
+
+// groupUnlessKey returns the key of the value group that a constructor
+// provided with GroupUnless contributes to, given the keys of all the
+// values it provides.
+func groupUnlessKey(keys map[key]struct{}) (key, error) {
+	var k key
+	for k = range keys {
+		if k.group == "" {
+			return k, newErrInvalidInput(fmt.Sprintf(
+				"cannot use dig.GroupUnless: the constructor provides %v, which is not a value group", k), nil)
+		}
+	}
+	if len(keys) != 1 {
+		return k, newErrInvalidInput(
+			"cannot use dig.GroupUnless: the constructor must provide values of a single type to a single value group", nil)
+	}
+	return k, nil
+}
+
+// Conditional reports whether this constructor was provided with
+// GroupUnless.
+func (n *constructorNode) Conditional() bool {
+	return n.groupUnless != nil
+}
+
+// skipsGroup reports whether this constructor, provided with GroupUnless,
+// must not contribute to its value group given the values that the group
+// holds.
+func (n *constructorNode) skipsGroup() bool {
+	k := n.groupUnlessKey
+	var values []reflect.Value
+	for _, s := range n.s.storesToRoot() {
+		values = append(values, s.getValueGroup(k.group, k.t)...)
+	}
+	return n.groupUnless(values)
+}
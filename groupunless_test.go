@@ -0,0 +1,156 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestGroupUnless(t *testing.T) {
+	t.Parallel()
+
+	type backends struct {
+		dig.In
+
+		Names []string `group:"backends"`
+	}
+
+	nonEmpty := dig.GroupUnless(func(vs []reflect.Value) bool {
+		return len(vs) > 0
+	})
+
+	t.Run("group is empty", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "default" }, dig.Group("backends"), nonEmpty)
+
+		c.RequireInvoke(func(b backends) {
+			assert.Equal(t, []string{"default"}, b.Names)
+		})
+	})
+
+	t.Run("group has members", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string {
+			t.Fatal("default backend must not be built")
+			return ""
+		}, dig.Group("backends"), nonEmpty)
+		// Provided after the default, but called before it.
+		c.RequireProvide(func() string { return "custom" }, dig.Group("backends"))
+
+		c.RequireInvoke(func(b backends) {
+			assert.Equal(t, []string{"custom"}, b.Names)
+		})
+		c.RequireInvoke(func(b backends) {
+			assert.Equal(t, []string{"custom"}, b.Names)
+		})
+	})
+
+	t.Run("predicate sees values", func(t *testing.T) {
+		t.Parallel()
+
+		var seen []string
+		c := digtest.New(t)
+		c.RequireProvide(func() []string { return []string{"a", "b"} }, dig.Group("backends,flatten"))
+		c.RequireProvide(func() string { return "c" }, dig.Group("backends"),
+			dig.GroupUnless(func(vs []reflect.Value) bool {
+				for _, v := range vs {
+					seen = append(seen, v.String())
+				}
+				return false
+			}))
+
+		c.RequireInvoke(func(b backends) {
+			assert.ElementsMatch(t, []string{"a", "b", "c"}, b.Names)
+		})
+		assert.ElementsMatch(t, []string{"a", "b"}, seen)
+	})
+
+	t.Run("several conditional constructors", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "first" }, dig.Group("backends"), nonEmpty)
+		c.RequireProvide(func() string { return "second" }, dig.Group("backends"), nonEmpty)
+
+		c.RequireInvoke(func(b backends) {
+			assert.Equal(t, []string{"first"}, b.Names)
+		})
+	})
+
+	t.Run("result object", func(t *testing.T) {
+		t.Parallel()
+
+		type out struct {
+			dig.Out
+
+			Name string `group:"backends"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "custom" }, dig.Group("backends"))
+		c.RequireProvide(func() out { return out{Name: "default"} }, nonEmpty)
+
+		c.RequireInvoke(func(b backends) {
+			assert.Equal(t, []string{"custom"}, b.Names)
+		})
+	})
+
+	t.Run("not a value group", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Provide(func() string { return "" }, nonEmpty)
+		require.Error(t, err)
+		dig.AssertErrorMatches(t, err,
+			`cannot provide function "go.uber.org/dig_test".TestGroupUnless\S+`,
+			`groupunless_test.go:\d+`,
+			`cannot use dig.GroupUnless: the constructor provides string, which is not a value group`)
+	})
+
+	t.Run("several types", func(t *testing.T) {
+		t.Parallel()
+
+		type out struct {
+			dig.Out
+
+			Name  string `group:"backends"`
+			Count int    `group:"counts"`
+		}
+
+		c := digtest.New(t)
+		err := c.Provide(func() out { return out{} }, nonEmpty)
+		require.Error(t, err)
+		dig.AssertErrorMatches(t, err,
+			`cannot provide function "go.uber.org/dig_test".TestGroupUnless\S+`,
+			`groupunless_test.go:\d+`,
+			`cannot use dig.GroupUnless: the constructor must provide values of a single type to a single value group`)
+	})
+}
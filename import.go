@@ -0,0 +1,208 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"io"
+
+	"go.uber.org/dig/internal/digreflect"
+)
+
+// ImportOption modifies the default behavior of Import.
+type ImportOption interface {
+	applyImportOption(*importOptions)
+}
+
+type importOptions struct {
+	GroupNamespace string
+	SkipProvided   bool
+}
+
+// ImportGroupNamespace is an ImportOption that places the value groups that
+// imported constructors provide into the given namespace, as if they were
+// provided with the GroupNamespace option. If a constructor was provided
+// with a GroupNamespace already, its namespace is nested inside this one.
+func ImportGroupNamespace(namespace string) ImportOption {
+	return importGroupNamespaceOption(namespace)
+}
+
+type importGroupNamespaceOption string
+
+func (o importGroupNamespaceOption) String() string {
+	return fmt.Sprintf("ImportGroupNamespace(%q)", string(o))
+}
+
+func (o importGroupNamespaceOption) applyImportOption(opts *importOptions) {
+	opts.GroupNamespace = string(o)
+}
+
+// SkipProvided is an ImportOption that leaves out the constructors that
+// conflict with the Container they are imported into, instead of failing.
+func SkipProvided() ImportOption {
+	return skipProvidedOption{}
+}
+
+type skipProvidedOption struct{}
+
+func (skipProvidedOption) String() string {
+	return "SkipProvided()"
+}
+
+func (skipProvidedOption) applyImportOption(opts *importOptions) {
+	opts.SkipProvided = true
+}
+
+// Import provides all constructors that were provided to the Container
+// other to this Container, with the same options. This allows composing
+// containers that were built independently.
+//
+//	app := dig.New()
+//	err := app.Import(payments.Container())
+//
+// Import is about wiring, not state: it refers to the same constructor
+// functions, but values that other already built are not copied, and the
+// imported constructors are called again to build the values of this
+// Container. Constructors provided to the Scopes of other are not imported,
+// unless they were exported to it.
+//
+// A constructor conflicts with this Container if a value that it provides
+// is already provided to it, or if the same function was provided to it.
+// Import fails before providing anything if any constructor conflicts,
+// reporting where both constructors were defined. With the SkipProvided
+// option, conflicting constructors are left out instead. Otherwise,
+// imported constructors are checked like those given to Provide, including
+// for cycles across both sets of constructors, and the constructors
+// imported before a failure remain provided.
+//
+// Imported constructors that consume the value groups they provide must
+// request them by their full name if ImportGroupNamespace is used.
+func (c *Container) Import(other *Container, opts ...ImportOption) error {
+	var options importOptions
+	for _, o := range opts {
+		o.applyImportOption(&options)
+	}
+	if other == c {
+		return newErrInvalidInput("cannot import a Container into itself", nil)
+	}
+	if err := validateGroupNamespace(options.GroupNamespace); err != nil {
+		return err
+	}
+
+	unlock := other.scope.lock()
+	nodes := append([]*constructorNode(nil), other.scope.nodes...)
+	unlock()
+
+	var imports []*constructorNode
+	for _, n := range nodes {
+		err := c.scope.findImportConflict(n)
+		if err == nil {
+			imports = append(imports, n)
+			continue
+		}
+		if !options.SkipProvided {
+			return err
+		}
+	}
+
+	for _, n := range imports {
+		popts := n.provideOpts
+		popts.Location = n.location
+		popts.Exported = false
+		popts.PrivateTo = nil
+		popts.Info = nil
+		if ns := options.GroupNamespace; ns != "" {
+			popts.GroupNamespace = qualifyGroup(ns, popts.GroupNamespace)
+			if popts.GroupNamespace == "" {
+				popts.GroupNamespace = ns
+			}
+		}
+		if err := c.scope.provide(n.ctor, popts); err != nil {
+			return errProvide{Func: n.location, Reason: err}
+		}
+	}
+	return nil
+}
+
+// findFunction returns the first constructor provided to this Scope or any
+// of its descendants with the same function as n, or nil if there is none.
+// Functions built with reflect.MakeFunc share their address, so they are
+// told apart by the location that they were provided from.
+func (s *Scope) findFunction(n *constructorNode) *constructorNode {
+	for _, s := range s.appendSubscopes(nil) {
+		for _, local := range s.nodes {
+			if local.fn == n.fn && *local.location == *n.location {
+				return local
+			}
+		}
+	}
+	return nil
+}
+
+// findImportConflict returns an error if the given constructor cannot be
+// imported into this Scope because it conflicts with one of its
+// constructors.
+func (s *Scope) findImportConflict(n *constructorNode) error {
+	if local := s.findFunction(n); local != nil {
+		return errImportConflict{Func: n.location, Local: local.location}
+	}
+	for k := range resultKeys(n.resultList) {
+		if k.group != "" {
+			continue
+		}
+		if ps := s.providers[k]; len(ps) > 0 {
+			return errImportConflict{Func: n.location, Key: k, Local: ps[0].Location()}
+		}
+	}
+	return nil
+}
+
+// errImportConflict is returned by Import when an imported constructor
+// provides a value that is already provided, or is itself already
+// provided.
+type errImportConflict struct {
+	// Imported constructor.
+	Func *digreflect.Func
+
+	// Value provided by both constructors. Unset if the same function was
+	// provided.
+	Key key
+
+	// Constructor that was already provided.
+	Local *digreflect.Func
+}
+
+var _ digError = errImportConflict{}
+
+func (e errImportConflict) Error() string { return fmt.Sprint(e) }
+
+func (e errImportConflict) writeMessage(w io.Writer, verb string) {
+	fmt.Fprintf(w, "cannot import function "+verb+": ", e.Func)
+	if e.Key.t == nil {
+		fmt.Fprintf(w, "the same function was already provided by "+verb, e.Local)
+		return
+	}
+	fmt.Fprintf(w, "%v already provided by "+verb, e.Key, e.Local)
+}
+
+func (e errImportConflict) Format(w fmt.State, c rune) {
+	formatError(e, w, c)
+}
@@ -0,0 +1,214 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestImport(t *testing.T) {
+	t.Parallel()
+
+	type Config struct{ Name string }
+	type Users struct{ *Config }
+	type Route string
+
+	newConfig := func() *Config { return &Config{Name: "users"} }
+	newUsers := func(cfg *Config) *Users { return &Users{cfg} }
+
+	t.Run("option strings", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, `ImportGroupNamespace("users")`, fmt.Sprint(dig.ImportGroupNamespace("users")))
+		assert.Equal(t, "SkipProvided()", fmt.Sprint(dig.SkipProvided()))
+	})
+
+	t.Run("provides constructors without values", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		other := digtest.New(t)
+		other.RequireProvide(func() *Config {
+			calls++
+			return &Config{Name: "users"}
+		})
+		other.RequireProvide(newUsers)
+		other.RequireInvoke(func(*Users) {})
+
+		c := digtest.New(t)
+		require.NoError(t, c.Import(other.Container))
+		c.RequireInvoke(func(u *Users) {
+			assert.Equal(t, "users", u.Name)
+		})
+		assert.Equal(t, 2, calls, "values of the other container must not be copied")
+	})
+
+	t.Run("keeps options", func(t *testing.T) {
+		t.Parallel()
+
+		other := digtest.New(t)
+		other.RequireProvide(newConfig, dig.Name("primary"))
+		other.RequireProvide(func() Route { return "/users" }, dig.Group("routes"))
+
+		c := digtest.New(t)
+		c.RequireProvide(func() Route { return "/orders" }, dig.Group("routes"))
+		require.NoError(t, c.Import(other.Container))
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Config *Config `name:"primary"`
+			Routes []Route `group:"routes"`
+		}) {
+			assert.NotNil(t, p.Config)
+			assert.ElementsMatch(t, []Route{"/users", "/orders"}, p.Routes)
+		})
+	})
+
+	t.Run("group namespace", func(t *testing.T) {
+		t.Parallel()
+
+		other := digtest.New(t)
+		other.RequireProvide(func() Route { return "/users" }, dig.Group("routes"))
+		other.RequireProvide(func() Route { return "/admin" }, dig.Group("routes"), dig.GroupNamespace("admin"))
+
+		c := digtest.New(t)
+		require.NoError(t, c.Import(other.Container, dig.ImportGroupNamespace("users")))
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Routes []Route `group:"routes"`
+			Users  []Route `group:"users/routes"`
+			Admin  []Route `group:"users/admin/routes"`
+		}) {
+			assert.Empty(t, p.Routes)
+			assert.Equal(t, []Route{"/users"}, p.Users)
+			assert.Equal(t, []Route{"/admin"}, p.Admin)
+		})
+	})
+
+	t.Run("conflicts", func(t *testing.T) {
+		t.Parallel()
+
+		other := digtest.New(t)
+		other.RequireProvide(newConfig)
+		other.RequireProvide(newUsers)
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Users { return &Users{} })
+
+		err := c.Import(other.Container)
+		require.Error(t, err)
+		assert.Regexp(t,
+			`cannot import function "go.uber.org/dig_test".TestImport.func2 \(\S+import_test.go:\d+\): `+
+				`\*dig_test.Users already provided by "go.uber.org/dig_test".TestImport.func\d+.\d+ \(\S+import_test.go:\d+\)`,
+			err.Error())
+
+		// Nothing was imported.
+		err = c.Invoke(func(*Config) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: *dig_test.Config")
+	})
+
+	t.Run("same function", func(t *testing.T) {
+		t.Parallel()
+
+		other := digtest.New(t)
+		other.RequireProvide(newConfig)
+
+		c := digtest.New(t)
+		c.RequireProvide(newConfig, dig.Name("primary"))
+
+		err := c.Import(other.Container)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "the same function was already provided by")
+	})
+
+	t.Run("provided values", func(t *testing.T) {
+		t.Parallel()
+
+		type in struct {
+			dig.In
+
+			Names []string `group:"names"`
+		}
+
+		other := digtest.New(t)
+		require.NoError(t, other.ProvideValue(reflect.ValueOf("other"), dig.Group("names")))
+
+		c := digtest.New(t)
+		require.NoError(t, c.ProvideValue(reflect.ValueOf("local"), dig.Group("names")))
+
+		require.NoError(t, c.Import(other.Container))
+		c.RequireInvoke(func(p in) {
+			assert.ElementsMatch(t, []string{"local", "other"}, p.Names)
+		})
+	})
+
+	t.Run("skip provided", func(t *testing.T) {
+		t.Parallel()
+
+		other := digtest.New(t)
+		other.RequireProvide(newConfig)
+		other.RequireProvide(newUsers)
+
+		var info dig.ProvideInfo
+		c := digtest.New(t)
+		c.RequireProvide(newConfig, dig.FillProvideInfo(&info))
+		require.NoError(t, c.Import(other.Container, dig.SkipProvided()))
+
+		sig, err := c.ProviderSignature(info.ID)
+		require.NoError(t, err)
+		assert.Len(t, sig.Outputs, 1)
+		c.RequireInvoke(func(u *Users) {
+			assert.Equal(t, "users", u.Name)
+		})
+	})
+
+	t.Run("cycles across containers", func(t *testing.T) {
+		t.Parallel()
+
+		other := digtest.New(t)
+		other.RequireProvide(newUsers)
+
+		c := digtest.New(t)
+		c.RequireProvide(func(*Users) *Config { return &Config{} })
+
+		err := c.Import(other.Container)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "this function introduces a cycle")
+	})
+
+	t.Run("into itself", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Import(c.Container)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot import a Container into itself")
+	})
+}
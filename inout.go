@@ -23,8 +23,10 @@ package dig
 import (
 	"container/list"
 	"fmt"
+	"io"
 	"reflect"
 	"strconv"
+	"strings"
 )
 
 var (
@@ -57,6 +59,17 @@ type digSentinel struct{}
 //	group       Name of the Value Group from which this field will be filled.
 //	            The field must be a slice type. See Value Groups in the
 //	            package documentation for more information.
+//	fromctx     Name of a context key registered with ContextKey. The field
+//	            is filled from the context passed to InvokeContext. Only
+//	            valid in parameters of functions passed to Invoke.
+//	dynamic     If set to true, the field must be a func() T or
+//	            func() (T, error) that resolves T from the container each
+//	            time it is called. May be combined with name and optional.
+//	names       Must be "*". The field must be a map[string]T, which
+//	            receives all named values of type T keyed by name.
+//
+// The name and group tag keys may be changed with the WithNameTag and
+// WithGroupTag options.
 type In struct{ _ digSentinel }
 
 // Out is an embeddable type that signals to dig that the returned
@@ -78,6 +91,12 @@ type In struct{ _ digSentinel }
 //	group       Name of the Value Group to which this field's value is being
 //	            sent. See Value Groups in the package documentation for more
 //	            information.
+//	optional    If set to true, the value is not added to the container
+//	            when the field holds the zero value of its type. Consumers
+//	            will treat it as absent.
+//
+// The name and group tag keys may be changed with the WithNameTag and
+// WithGroupTag options.
 type Out struct{ _ digSentinel }
 
 func isError(t reflect.Type) bool {
@@ -173,3 +192,82 @@ func isFieldOptional(f reflect.StructField) (bool, error) {
 
 	return optional, err
 }
+
+// isResultObject reports whether t is a dig.Out struct, a pointer to one, or
+// a struct that embeds *dig.Out, none of which may be used as parameters.
+func isResultObject(t reflect.Type) bool {
+	return IsOut(t) || (t.Kind() == reflect.Ptr && IsOut(t.Elem())) || embedsType(t, _outPtrType)
+}
+
+// isParamObject reports whether t is a dig.In struct or a pointer to one,
+// neither of which may be used as results.
+func isParamObject(t reflect.Type) bool {
+	return IsIn(t) || (t.Kind() == reflect.Ptr && IsIn(t.Elem())) || embedsType(t, _inPtrType)
+}
+
+// errMisplacedObject is returned when a function depends on a result object,
+// or returns a parameter object, possibly nested inside other objects or as
+// the values of a value group.
+type errMisplacedObject struct {
+	// Type that embeds Marker.
+	Type reflect.Type
+
+	// _outType for result objects used as parameters, and _inType for
+	// parameter objects used as results.
+	Marker reflect.Type
+
+	// Outermost parameter or result object that contains Type, and the
+	// names of the fields that lead to it from there. Unset if Type is a
+	// parameter or result of the function itself.
+	Root   reflect.Type
+	Fields []string
+
+	// Whether Type is the type of the values of a value group.
+	Group bool
+}
+
+var _ digError = errMisplacedObject{}
+
+// inField returns a copy of this error for a Type found through the named
+// field of the object t.
+func (e errMisplacedObject) inField(t reflect.Type, field string) errMisplacedObject {
+	e.Root = t
+	e.Fields = append([]string{field}, e.Fields...)
+	return e
+}
+
+func (e errMisplacedObject) Error() string { return fmt.Sprint(e) }
+
+func (e errMisplacedObject) writeMessage(w io.Writer, _ string) {
+	var path string
+	if e.Root != nil {
+		path = fmt.Sprintf("%v.%v", e.Root, strings.Join(e.Fields, "."))
+	}
+
+	if e.Marker == _inType {
+		fmt.Fprintf(w, "cannot provide parameter objects: %v embeds a dig.In", e.Type)
+		switch {
+		case e.Group:
+			fmt.Fprintf(w, ", added to a value group by field %v", path)
+		case path != "":
+			fmt.Fprintf(w, ", returned by field %v", path)
+		}
+		io.WriteString(w, "; accept it as a parameter of the function instead")
+		return
+	}
+
+	fmt.Fprintf(w, "cannot depend on result objects: %v embeds a dig.Out", e.Type)
+	switch {
+	case e.Group:
+		fmt.Fprintf(w, ", requested as a value group by field %v; "+
+			"add the fields of the result object to the value group with `group:\"..\"` tags and request their type instead", path)
+	case path != "":
+		fmt.Fprintf(w, ", requested by field %v; return these fields from the constructor instead, and request them individually", path)
+	default:
+		io.WriteString(w, "; return these fields from the constructor instead, and request them individually")
+	}
+}
+
+func (e errMisplacedObject) Format(w fmt.State, c rune) {
+	formatError(e, w, c)
+}
@@ -0,0 +1,215 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+type misplacedResult struct {
+	dig.Out
+
+	Name string
+}
+
+type misplacedParams struct {
+	dig.In
+
+	Name string
+}
+
+func TestMisplacedObjectErrors(t *testing.T) {
+	t.Parallel()
+
+	const (
+		dependHint  = "; return these fields from the constructor instead, and request them individually"
+		groupHint   = "; add the fields of the result object to the value group with `group:\"..\"` tags and request their type instead"
+		provideHint = "; accept it as a parameter of the function instead"
+	)
+
+	type inner struct {
+		dig.In
+
+		Result misplacedResult
+	}
+
+	type innerOut struct {
+		dig.Out
+
+		Params misplacedParams
+	}
+
+	tests := []struct {
+		desc    string
+		give    interface{}
+		wantErr string
+	}{
+		{
+			desc:    "positional result object",
+			give:    func(misplacedResult) int { return 0 },
+			wantErr: "bad argument 1: cannot depend on result objects: dig_test.misplacedResult embeds a dig.Out" + dependHint,
+		},
+		{
+			desc: "field",
+			give: func(struct {
+				dig.In
+
+				Result misplacedResult
+			}) int {
+				return 0
+			},
+			wantErr: "cannot depend on result objects: dig_test.misplacedResult embeds a dig.Out, " +
+				"requested by field struct { dig.In; Result dig_test.misplacedResult }.Result" + dependHint,
+		},
+		{
+			desc: "pointer field",
+			give: func(struct {
+				dig.In
+
+				Result *misplacedResult
+			}) int {
+				return 0
+			},
+			wantErr: "cannot depend on result objects: *dig_test.misplacedResult embeds a dig.Out, " +
+				"requested by field struct { dig.In; Result *dig_test.misplacedResult }.Result" + dependHint,
+		},
+		{
+			desc: "nested field",
+			give: func(struct {
+				dig.In
+
+				Inner inner
+			}) int {
+				return 0
+			},
+			wantErr: "cannot depend on result objects: dig_test.misplacedResult embeds a dig.Out, " +
+				"requested by field struct { dig.In; Inner dig_test.inner }.Inner.Result" + dependHint,
+		},
+		{
+			desc: "value group",
+			give: func(struct {
+				dig.In
+
+				Results []misplacedResult `group:"results"`
+			}) int {
+				return 0
+			},
+			wantErr: "cannot depend on result objects: dig_test.misplacedResult embeds a dig.Out, " +
+				"requested as a value group by field struct { dig.In; Results []dig_test.misplacedResult \"group:\\\"results\\\"\" }.Results" + groupHint,
+		},
+		{
+			desc:    "positional parameter object",
+			give:    func() misplacedParams { return misplacedParams{} },
+			wantErr: "bad result 1: cannot provide parameter objects: dig_test.misplacedParams embeds a dig.In" + provideHint,
+		},
+		{
+			desc: "result field",
+			give: func() (r struct {
+				dig.Out
+
+				Params misplacedParams
+			}) {
+				return
+			},
+			wantErr: "cannot provide parameter objects: dig_test.misplacedParams embeds a dig.In, " +
+				"returned by field struct { dig.Out; Params dig_test.misplacedParams }.Params" + provideHint,
+		},
+		{
+			desc: "nested result field",
+			give: func() (r struct {
+				dig.Out
+
+				Inner innerOut
+			}) {
+				return
+			},
+			wantErr: "cannot provide parameter objects: dig_test.misplacedParams embeds a dig.In, " +
+				"returned by field struct { dig.Out; Inner dig_test.innerOut }.Inner.Params" + provideHint,
+		},
+		{
+			desc: "result value group",
+			give: func() (r struct {
+				dig.Out
+
+				Params *misplacedParams `group:"params"`
+			}) {
+				return
+			},
+			wantErr: "cannot provide parameter objects: *dig_test.misplacedParams embeds a dig.In, added to a value group by field",
+		},
+		{
+			desc: "flattened result value group",
+			give: func() (r struct {
+				dig.Out
+
+				Params []misplacedParams `group:"params,flatten"`
+			}) {
+				return
+			},
+			wantErr: "cannot provide parameter objects: dig_test.misplacedParams embeds a dig.In, added to a value group by field",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := digtest.New(t).Provide(tt.give)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	t.Run("decorator", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "" })
+		err := c.Decorate(func(struct {
+			dig.In
+
+			Result misplacedResult
+		}) string {
+			return ""
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "requested by field struct { dig.In; Result dig_test.misplacedResult }.Result"+dependHint)
+	})
+
+	t.Run("invoke", func(t *testing.T) {
+		t.Parallel()
+
+		err := digtest.New(t).Invoke(func(struct {
+			dig.In
+
+			Inner inner
+		}) {
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "requested by field struct { dig.In; Inner dig_test.inner }.Inner.Result"+dependHint)
+	})
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// _interfaces holds the interfaces registered with RegisterInterface, in
+// the order they were registered.
+var _interfaces struct {
+	sync.RWMutex
+
+	types []reflect.Type
+}
+
+// RegisterInterface registers an interface for use with the
+// AsImplementedInterfaces ProvideOption. It expects a pointer to the
+// interface, and panics otherwise.
+//
+//	dig.RegisterInterface(new(io.Reader))
+//
+// Interfaces are registered for all Containers. Registering an interface
+// more than once has no effect. RegisterInterface is safe for concurrent
+// use, but should be called before the constructors that rely on it are
+// provided, for example from an init function.
+func RegisterInterface(i interface{}) {
+	t := reflect.TypeOf(i)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Interface {
+		panic(fmt.Sprintf("dig.RegisterInterface: argument must be a pointer to an interface, got %v", t))
+	}
+
+	_interfaces.Lock()
+	defer _interfaces.Unlock()
+	for _, it := range _interfaces.types {
+		if it == t.Elem() {
+			return
+		}
+	}
+	_interfaces.types = append(_interfaces.types, t.Elem())
+}
+
+// AsImplementedInterfaces is a ProvideOption that provides the values
+// produced by the constructor as all interfaces registered with
+// RegisterInterface that they implement, as if they were given to As.
+//
+// This avoids naming the interfaces of test doubles at every Provide:
+//
+//	dig.RegisterInterface(new(Clock))
+//
+//	c.Provide(func() *fakeClock { return &fakeClock{} }, dig.AsImplementedInterfaces())
+//
+// Like As, values are not provided as their own type if they implement
+// any registered interface, and values that implement none of them are
+// provided as their own type. Interfaces may also be given to As along
+// with this option. If another constructor already provides one of the
+// interfaces, Provide fails and names both constructors.
+func AsImplementedInterfaces() ProvideOption {
+	return provideAsImplementedOption{}
+}
+
+type provideAsImplementedOption struct{}
+
+func (provideAsImplementedOption) String() string {
+	return "AsImplementedInterfaces()"
+}
+
+func (provideAsImplementedOption) applyProvideOption(opts *provideOptions) {
+	opts.AsImplemented = true
+}
+
+// asFor returns pointers to the interfaces that values of type t are
+// provided as: those given to As, followed by the registered interfaces
+// that t implements if AsImplementedInterfaces was used.
+func (o resultOptions) asFor(t reflect.Type) []interface{} {
+	if !o.AsImplemented {
+		return o.As
+	}
+
+	as := o.As[:len(o.As):len(o.As)]
+	given := make(map[reflect.Type]struct{}, len(o.As))
+	for _, i := range o.As {
+		given[reflect.TypeOf(i).Elem()] = struct{}{}
+	}
+
+	_interfaces.RLock()
+	defer _interfaces.RUnlock()
+	for _, it := range _interfaces.types {
+		if _, ok := given[it]; ok || !t.Implements(it) {
+			continue
+		}
+		as = append(as, reflect.New(it).Interface())
+	}
+	return as
+}
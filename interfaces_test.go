@@ -0,0 +1,160 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+type implClock interface{ Now() int }
+
+type implLogger interface{ Log(string) }
+
+type implUnregistered interface{ Flush() }
+
+func init() {
+	dig.RegisterInterface(new(implClock))
+	dig.RegisterInterface(new(implLogger))
+	dig.RegisterInterface(new(implClock)) // no effect
+}
+
+type implFake struct{ now int }
+
+func (f *implFake) Now() int   { return f.now }
+func (f *implFake) Log(string) {}
+func (f *implFake) Flush()     {}
+
+func TestAsImplementedInterfaces(t *testing.T) {
+	t.Parallel()
+
+	t.Run("registered interfaces", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		fake := &implFake{now: 42}
+		c.RequireProvide(func() *implFake { return fake }, dig.AsImplementedInterfaces())
+
+		c.RequireInvoke(func(c implClock, l implLogger) {
+			assert.Same(t, fake, c)
+			assert.Same(t, fake, l)
+		})
+
+		err := c.Invoke(func(implUnregistered) {})
+		require.Error(t, err, "unregistered interfaces must not be provided")
+		err = c.Invoke(func(*implFake) {})
+		require.Error(t, err, "the concrete type must not be provided")
+	})
+
+	t.Run("anonymous fake", func(t *testing.T) {
+		t.Parallel()
+
+		type fakeClock struct{ implClock }
+
+		c := digtest.New(t)
+		c.RequireProvide(func() fakeClock {
+			return fakeClock{&implFake{now: 1}}
+		}, dig.AsImplementedInterfaces())
+
+		c.RequireInvoke(func(c implClock) {
+			assert.Equal(t, 1, c.Now())
+		})
+	})
+
+	t.Run("with As", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *implFake { return &implFake{} },
+			dig.As(new(implClock), new(implUnregistered)),
+			dig.AsImplementedInterfaces())
+
+		c.RequireInvoke(func(implClock, implLogger, implUnregistered) {})
+	})
+
+	t.Run("no registered interface", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "hello" }, dig.AsImplementedInterfaces())
+		c.RequireInvoke(func(s string) {
+			assert.Equal(t, "hello", s)
+		})
+	})
+
+	t.Run("named", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *implFake { return &implFake{now: 2} },
+			dig.AsImplementedInterfaces(), dig.Name("fake"))
+
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Clock implClock `name:"fake"`
+		}) {
+			assert.Equal(t, 2, p.Clock.Now())
+		})
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		t.Parallel()
+
+		type otherClock struct{ implClock }
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *implFake { return &implFake{} }, dig.AsImplementedInterfaces())
+
+		err := c.Provide(func() otherClock { return otherClock{} }, dig.AsImplementedInterfaces())
+		require.Error(t, err)
+		dig.AssertErrorMatches(t, err,
+			`cannot provide function "go.uber.org/dig_test".TestAsImplementedInterfaces.func6.2`,
+			`interfaces_test.go:\d+`,
+			`cannot provide dig_test.implClock from \[0\]:`,
+			`already provided by "go.uber.org/dig_test".TestAsImplementedInterfaces.func6.1 \(\S+interfaces_test.go:\d+\)`)
+	})
+}
+
+func TestRegisterInterface(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		give interface{}
+	}{
+		{desc: "nil", give: nil},
+		{desc: "not a pointer", give: implFake{}},
+		{desc: "pointer to struct", give: new(implFake)},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Panics(t, func() { dig.RegisterInterface(tt.give) })
+		})
+	}
+}
@@ -23,6 +23,7 @@ package dot
 import (
 	"fmt"
 	"reflect"
+	"sort"
 )
 
 // ErrorType of a constructor or group is updated when they fail to build.
@@ -48,6 +49,15 @@ type Ctor struct {
 	GroupParams []*Group
 	Results     []*Result
 	ErrorType   ErrorType
+
+	// Metadata attached to the constructor. Each entry is rendered as a
+	// "tag:key" attribute of the constructor's node.
+	Tags map[string]string
+
+	// Tooltip and URL of the constructor's node, if set. Visualize sets
+	// them to the location of the constructor when asked to.
+	Tooltip string
+	URL     string
 }
 
 // removeParam deletes the dependency on the provided result's nodeKey.
@@ -132,6 +142,9 @@ type Graph struct {
 	consumers map[nodeKey][]*Ctor
 
 	Failed *FailedNodes
+
+	// ShowLegend includes the Legend in the graph.
+	ShowLegend bool
 }
 
 // FailedNodes is the nodes that failed in the graph.
@@ -288,9 +301,109 @@ func (dg *Graph) FailGroupNodes(name string, t reflect.Type, id CtorID) {
 			c.ErrorType = rootCause
 		} else {
 			group.ErrorType = transitiveFailure
-			c.ErrorType = transitiveFailure
+			// A constructor that is already a root cause of another
+			// failure stays one.
+			if c.ErrorType != rootCause {
+				c.ErrorType = transitiveFailure
+			}
+		}
+	}
+}
+
+// FailMissing marks the constructor with the given id as failed because
+// the given values, which nothing in the graph provides, are missing. The
+// missing values and groups are added to the graph as root causes.
+func (dg *Graph) FailMissing(missing []*Node, id CtorID) {
+	c, ok := dg.ctorMap[id]
+	if !ok {
+		return
+	}
+
+	for _, n := range missing {
+		k := n.nodeKey()
+		if k.group != "" {
+			g := dg.getGroup(k)
+			g.ErrorType = rootCause
+			dg.Failed.groups[k] = struct{}{}
+			continue
+		}
+
+		if !dg.isRootCause(k) {
+			dg.addRootCause(&Result{Node: n})
+		}
+	}
+
+	dg.failTransitively(c)
+}
+
+// FailConsumers marks all constructors that depend, directly or through
+// other constructors and value groups, on a failed constructor as transitive
+// failures.
+func (dg *Graph) FailConsumers() {
+	groupConsumers := make(map[nodeKey][]*Ctor)
+	var queue []*Ctor
+	for _, c := range dg.Ctors {
+		for _, g := range c.GroupParams {
+			k := g.nodeKey()
+			groupConsumers[k] = append(groupConsumers[k], c)
+		}
+		if _, ok := dg.Failed.ctors[c.ID]; ok {
+			queue = append(queue, c)
+		}
+	}
+
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+
+		for _, r := range c.Results {
+			consumers := dg.consumers[r.nodeKey()]
+			if r.Group != "" {
+				consumers = groupConsumers[nodeKey{t: r.Type, group: r.Group}]
+			}
+
+			for _, consumer := range consumers {
+				if _, ok := dg.Failed.ctors[consumer.ID]; ok {
+					continue
+				}
+				dg.failTransitively(consumer)
+				queue = append(queue, consumer)
+			}
+		}
+	}
+}
+
+// failTransitively marks the given constructor, its results, and the groups
+// it contributes to as transitive failures.
+func (dg *Graph) failTransitively(c *Ctor) {
+	dg.Failed.ctors[c.ID] = struct{}{}
+	if c.ErrorType != rootCause {
+		c.ErrorType = transitiveFailure
+	}
+
+	for _, r := range c.Results {
+		dg.addTransitiveFailure(r)
+		if r.Group == "" {
+			continue
+		}
+
+		k := nodeKey{t: r.Type, group: r.Group}
+		if g := dg.getGroup(k); g.ErrorType != rootCause {
+			g.ErrorType = transitiveFailure
+		}
+		dg.Failed.groups[k] = struct{}{}
+	}
+}
+
+// isRootCause reports whether a node with the given key is already a root
+// cause of a failure.
+func (dg *Graph) isRootCause(k nodeKey) bool {
+	for _, r := range dg.Failed.RootCauses {
+		if r.nodeKey() == k {
+			return true
 		}
 	}
+	return false
 }
 
 // getGroup finds the group by nodeKey from the graph. If it is not available,
@@ -399,6 +512,42 @@ func (dg *Graph) pruneGroupResults(c *Ctor, groups map[nodeKey]*Group) {
 	}
 }
 
+// Sort orders the constructors of the graph by their location, and the
+// groups and the parameters of each constructor by their string
+// representation, so that a graph is rendered the same way every time.
+// Results are left in the order they were declared.
+func (dg *Graph) Sort() {
+	sort.SliceStable(dg.Ctors, func(i, j int) bool {
+		return dg.Ctors[i].less(dg.Ctors[j])
+	})
+	sort.SliceStable(dg.Groups, func(i, j int) bool {
+		return dg.Groups[i].String() < dg.Groups[j].String()
+	})
+
+	for _, c := range dg.Ctors {
+		sort.SliceStable(c.Params, func(i, j int) bool {
+			return c.Params[i].String() < c.Params[j].String()
+		})
+		sort.SliceStable(c.GroupParams, func(i, j int) bool {
+			return c.GroupParams[i].String() < c.GroupParams[j].String()
+		})
+	}
+}
+
+// less reports whether c should be placed before other in a sorted graph.
+func (c *Ctor) less(other *Ctor) bool {
+	if c.Package != other.Package {
+		return c.Package < other.Package
+	}
+	if c.File != other.File {
+		return c.File < other.File
+	}
+	if c.Line != other.Line {
+		return c.Line < other.Line
+	}
+	return c.Name < other.Name
+}
+
 // String implements fmt.Stringer for Param.
 func (p *Param) String() string {
 	if p.Name != "" {
@@ -424,13 +573,30 @@ func (g *Group) String() string {
 	return fmt.Sprintf("[type=%v group=%v]", g.Type.String(), g.Name)
 }
 
+// Styles of the nodes of the graph, as documented by the Legend:
+//
+//   - constructors are plain text inside a box that holds their results
+//   - unnamed values are ellipses
+//   - named values are rounded boxes
+//   - values added to groups are dashed ellipses
+//   - value groups are diamonds
+//
+// Required dependencies are solid edges and optional ones are dashed.
+// Failed nodes are red, and nodes that failed because of them are orange.
+const (
+	_ctorStyle        = "shape=plaintext"
+	_namedResultStyle = "shape=box style=rounded"
+	_groupResultStyle = "style=dashed"
+	_groupStyle       = "shape=diamond"
+)
+
 // Attributes composes and returns a string of the Result node's attributes.
 func (r *Result) Attributes() string {
 	switch {
 	case r.Name != "":
-		return fmt.Sprintf(`label=<%v<BR /><FONT POINT-SIZE="10">Name: %v</FONT>>`, r.Type, r.Name)
+		return fmt.Sprintf(`%v label=<%v<BR /><FONT POINT-SIZE="10">Name: %v</FONT>>`, _namedResultStyle, r.Type, r.Name)
 	case r.Group != "":
-		return fmt.Sprintf(`label=<%v<BR /><FONT POINT-SIZE="10">Group: %v</FONT>>`, r.Type, r.Group)
+		return fmt.Sprintf(`%v label=<%v<BR /><FONT POINT-SIZE="10">Group: %v</FONT>>`, _groupResultStyle, r.Type, r.Group)
 	default:
 		return fmt.Sprintf(`label=<%v>`, r.Type)
 	}
@@ -438,13 +604,30 @@ func (r *Result) Attributes() string {
 
 // Attributes composes and returns a string of the Group node's attributes.
 func (g *Group) Attributes() string {
-	attr := fmt.Sprintf(`shape=diamond label=<%v<BR /><FONT POINT-SIZE="10">Group: %v</FONT>>`, g.Type, g.Name)
+	attr := fmt.Sprintf(`%v label=<%v<BR /><FONT POINT-SIZE="10">Group: %v</FONT>>`, _groupStyle, g.Type, g.Name)
 	if g.ErrorType != noError {
 		attr += " color=" + g.ErrorType.Color()
 	}
 	return attr
 }
 
+// Legend returns a DOT subgraph that explains the styles of the nodes and
+// edges of the graph.
+func (dg *Graph) Legend() string {
+	return `subgraph cluster_legend {
+		label = "Legend";
+		legend_ctor [` + _ctorStyle + ` label="constructor"];
+		legend_value [label="value"];
+		legend_named [` + _namedResultStyle + ` label="named value"];
+		legend_grouped [` + _groupResultStyle + ` label="value in a group"];
+		legend_group [` + _groupStyle + ` label="value group"];
+		legend_failed [label="failed" color=` + rootCause.Color() + `];
+		legend_transitive [label="failed dependency" color=` + transitiveFailure.Color() + `];
+		legend_ctor -> legend_value [label="requires"];
+		legend_ctor -> legend_named [label="optional" style=dashed];
+	}`
+}
+
 // Color returns the color representation of each ErrorType.
 func (s ErrorType) Color() string {
 	switch s {
@@ -196,6 +196,77 @@ func TestFailNodes(t *testing.T) {
 	})
 }
 
+func TestFailMissing(t *testing.T) {
+	type1 := reflect.TypeOf(&t1{})
+	type2 := reflect.TypeOf(&t2{})
+	type3 := reflect.TypeOf(&t3{})
+
+	t.Parallel()
+
+	t.Run("missing values", func(t *testing.T) {
+		dg := NewGraph()
+		c0 := &Ctor{ID: 123}
+		c1 := &Ctor{ID: 456}
+		r := &Result{Node: &Node{Type: type2}}
+		dg.AddCtor(c0, []*Param{{Node: &Node{Type: type1}}}, []*Result{r})
+		dg.AddCtor(c1, []*Param{{Node: &Node{Type: type1}}}, nil)
+
+		dg.FailMissing([]*Node{{Type: type1}}, 123)
+		dg.FailMissing([]*Node{{Type: type1}}, 456)
+		assert.Equal(t, []*Result{{Node: &Node{Type: type1}}}, dg.Failed.RootCauses,
+			"missing values must be reported once")
+		assert.Equal(t, []*Result{r}, dg.Failed.TransitiveFailures)
+		assert.Equal(t, transitiveFailure, c0.ErrorType)
+		assert.Equal(t, transitiveFailure, c1.ErrorType)
+	})
+
+	t.Run("missing group", func(t *testing.T) {
+		dg := NewGraph()
+		c := &Ctor{ID: 123}
+		dg.AddCtor(c, []*Param{{Node: &Node{Type: reflect.SliceOf(type1), Group: "foo"}}}, nil)
+
+		dg.FailMissing([]*Node{{Type: type1, Group: "foo"}}, 123)
+		k := nodeKey{t: type1, group: "foo"}
+		assert.Empty(t, dg.Failed.RootCauses)
+		assert.Equal(t, rootCause, dg.groupMap[k].ErrorType)
+		assert.Contains(t, dg.Failed.groups, k)
+	})
+
+	t.Run("unknown constructor", func(t *testing.T) {
+		dg := NewGraph()
+		dg.FailMissing([]*Node{{Type: type1}}, 123)
+		assert.Empty(t, dg.Failed.RootCauses)
+	})
+
+	t.Run("consumers", func(t *testing.T) {
+		dg := NewGraph()
+		// c0 is missing t1 and provides t2 to group foo, which c1 consumes
+		// to provide t3, which c2 consumes. c3 is unrelated.
+		c0 := &Ctor{ID: 1}
+		c1 := &Ctor{ID: 2}
+		c2 := &Ctor{ID: 3}
+		c3 := &Ctor{ID: 4}
+		r0 := &Result{Node: &Node{Type: type2, Group: "foo"}}
+		r1 := &Result{Node: &Node{Type: type3}}
+		dg.AddCtor(c0, []*Param{{Node: &Node{Type: type1}}}, []*Result{r0})
+		dg.AddCtor(c1, []*Param{{Node: &Node{Type: reflect.SliceOf(type2), Group: "foo"}}}, []*Result{r1})
+		dg.AddCtor(c2, []*Param{{Node: &Node{Type: type3}}}, nil)
+		dg.AddCtor(c3, nil, []*Result{{Node: &Node{Type: type1, Name: "bar"}}})
+
+		dg.FailMissing([]*Node{{Type: type1}}, 1)
+		dg.FailConsumers()
+		assert.Equal(t, transitiveFailure, c0.ErrorType)
+		assert.Equal(t, transitiveFailure, c1.ErrorType)
+		assert.Equal(t, transitiveFailure, c2.ErrorType)
+		assert.Equal(t, noError, c3.ErrorType)
+		assert.Equal(t, transitiveFailure, dg.groupMap[nodeKey{t: type2, group: "foo"}].ErrorType)
+		assert.Equal(t, []*Result{r0, r1}, dg.Failed.TransitiveFailures)
+
+		dg.PruneSuccess()
+		assert.Equal(t, []*Ctor{c0, c1, c2}, dg.Ctors)
+	})
+}
+
 func TestPruneSuccess(t *testing.T) {
 	type1 := reflect.TypeOf(&t1{})
 	type2 := reflect.TypeOf(&t2{})
@@ -437,8 +508,8 @@ func TestStringerAndAttribute(t *testing.T) {
 
 	t.Run("result attributes", func(t *testing.T) {
 		assert.Equal(t, `label=<dot.t1>`, r1.Attributes())
-		assert.Equal(t, `label=<dot.t2<BR /><FONT POINT-SIZE="10">Name: bar</FONT>>`, r2.Attributes())
-		assert.Equal(t, `label=<dot.t3<BR /><FONT POINT-SIZE="10">Group: foo</FONT>>`, r3.Attributes())
+		assert.Equal(t, `shape=box style=rounded label=<dot.t2<BR /><FONT POINT-SIZE="10">Name: bar</FONT>>`, r2.Attributes())
+		assert.Equal(t, `style=dashed label=<dot.t3<BR /><FONT POINT-SIZE="10">Group: foo</FONT>>`, r3.Attributes())
 	})
 
 	t.Run("group attributes", func(t *testing.T) {
@@ -448,6 +519,39 @@ func TestStringerAndAttribute(t *testing.T) {
 	})
 }
 
+func TestSort(t *testing.T) {
+	type1 := reflect.TypeOf(t1{})
+	type2 := reflect.TypeOf(t2{})
+
+	p1 := &Param{Node: &Node{Type: type1}}
+	p2 := &Param{Node: &Node{Type: type2}}
+	g1 := NewGroup(nodeKey{t: type1, group: "a"})
+	g2 := NewGroup(nodeKey{t: type2, group: "b"})
+
+	c1 := &Ctor{Package: "foo", File: "foo/a.go", Line: 10, Name: "A"}
+	c2 := &Ctor{Package: "foo", File: "foo/a.go", Line: 20, Name: "B"}
+	c3 := &Ctor{Package: "foo", File: "foo/b.go", Line: 5, Name: "C"}
+	c4 := &Ctor{
+		Package:     "bar",
+		File:        "bar/z.go",
+		Line:        1,
+		Name:        "D",
+		Params:      []*Param{p2, p1},
+		GroupParams: []*Group{g2, g1},
+	}
+
+	dg := &Graph{
+		Ctors:  []*Ctor{c3, c2, c4, c1},
+		Groups: []*Group{g2, g1},
+	}
+	dg.Sort()
+
+	assert.Equal(t, []*Ctor{c4, c1, c2, c3}, dg.Ctors)
+	assert.Equal(t, []*Group{g1, g2}, dg.Groups)
+	assert.Equal(t, []*Param{p1, p2}, c4.Params)
+	assert.Equal(t, []*Group{g1, g2}, c4.GroupParams)
+}
+
 func TestColor(t *testing.T) {
 	assert.Equal(t, "black", noError.Color())
 	assert.Equal(t, "red", rootCause.Color())
@@ -34,10 +34,32 @@ type Graph interface {
 	EdgesFrom(u int) []int
 }
 
+// SoftGraph is a Graph in which some edges are soft: they may be dropped
+// to break a cycle, for example because they represent optional
+// dependencies. Cycle detection only reports cycles made of hard edges,
+// since any other cycle can be broken by dropping one of its soft edges.
+type SoftGraph interface {
+	Graph
+
+	// HardEdgesFrom returns the nodes that node u has hard edges to. They
+	// must be a subset of EdgesFrom(u).
+	HardEdgesFrom(u int) []int
+}
+
+// hardEdgesFrom returns the nodes that node u has hard edges to. All edges
+// of graphs that do not implement SoftGraph are hard.
+func hardEdgesFrom(g Graph, u int) []int {
+	if sg, ok := g.(SoftGraph); ok {
+		return sg.HardEdgesFrom(u)
+	}
+	return g.EdgesFrom(u)
+}
+
 // IsAcyclic uses depth-first search to find cycles
 // in a generic graph represented by Graph interface.
 // If a cycle is found, it returns a list of nodes that
 // are in the cyclic path, identified by their orders.
+// For a SoftGraph, only cycles of hard edges are found.
 func IsAcyclic(g Graph) (bool, []int) {
 	// cycleStart is a node that introduces a cycle in
 	// the graph. Values in the range [1, g.Order()) mean
@@ -56,6 +78,146 @@ func IsAcyclic(g Graph) (bool, []int) {
 	return true, nil
 }
 
+// IsAcyclicFrom uses depth-first search to find cycles that are reachable
+// from the node u. If the graph was acyclic before u and its edges were
+// added to it, any cycle in the graph must pass through u, so this is
+// sufficient to check the whole graph while only visiting nodes that u
+// depends on.
+// If a cycle is found, it returns a list of nodes that are in the cyclic
+// path, identified by their orders. The path starts at the node in the
+// cycle with the lowest order, which is where IsAcyclic would report it.
+func IsAcyclicFrom(g Graph, u int) (bool, []int) {
+	info := newCycleInfo(g.Order())
+	if cycle := isAcyclic(g, u, info, nil /* cycle path */); len(cycle) > 0 {
+		return false, rotateCycle(cycle)
+	}
+	return true, nil
+}
+
+// IsAcyclicFromAll uses depth-first search to find cycles that are
+// reachable from any of the nodes in us. Nodes for which verified is true
+// are known to reach no cycles and are not visited again; verified must
+// have an entry for every node of g. If no cycle is found, all nodes
+// reachable from us are marked as verified, so that later calls only
+// visit the parts of the graph that they did not reach.
+// If a cycle is found, it returns a list of nodes that are in the cyclic
+// path, identified by their orders, starting at the node in the cycle with
+// the lowest order.
+func IsAcyclicFromAll(g Graph, us []int, verified []bool) (bool, []int) {
+	info := newCycleInfo(g.Order())
+	for i, ok := range verified {
+		info[i].Visited = ok
+	}
+
+	for _, u := range us {
+		if cycle := isAcyclic(g, u, info, nil /* cycle path */); len(cycle) > 0 {
+			return false, rotateCycle(cycle)
+		}
+	}
+
+	for i := range info {
+		verified[i] = info[i].Visited
+	}
+	return true, nil
+}
+
+// Cycles finds the strongly connected components of g, following both hard
+// and soft edges. It returns, for each node, the nodes that are on a cycle
+// with it, including the node itself, or nil if the node is on no cycle.
+// Nodes on the same cycles share the same slice.
+func Cycles(g Graph) [][]int {
+	t := tarjan{
+		g:       g,
+		index:   make([]int, g.Order()),
+		low:     make([]int, g.Order()),
+		onStack: make([]bool, g.Order()),
+		cycles:  make([][]int, g.Order()),
+	}
+	for u := 0; u < g.Order(); u++ {
+		if t.index[u] == 0 {
+			t.visit(u)
+		}
+	}
+	return t.cycles
+}
+
+// tarjan holds the state of Tarjan's strongly connected components
+// algorithm.
+type tarjan struct {
+	g Graph
+
+	// Order in which each node was visited, starting at 1. Zero for
+	// nodes that were not visited yet.
+	index []int
+
+	// Lowest index of the nodes on the stack reachable from each node.
+	low []int
+
+	stack   []int
+	onStack []bool
+	visited int
+	cycles  [][]int
+}
+
+func (t *tarjan) visit(u int) {
+	t.visited++
+	t.index[u] = t.visited
+	t.low[u] = t.visited
+	t.stack = append(t.stack, u)
+	t.onStack[u] = true
+
+	selfLoop := false
+	for _, v := range t.g.EdgesFrom(u) {
+		switch {
+		case v == u:
+			selfLoop = true
+		case t.index[v] == 0:
+			t.visit(v)
+			if t.low[v] < t.low[u] {
+				t.low[u] = t.low[v]
+			}
+		case t.onStack[v] && t.index[v] < t.low[u]:
+			t.low[u] = t.index[v]
+		}
+	}
+	if t.low[u] != t.index[u] {
+		return
+	}
+
+	// u is the root of a component: pop it off the stack.
+	i := len(t.stack) - 1
+	for t.stack[i] != u {
+		i--
+	}
+	component := append([]int(nil), t.stack[i:]...)
+	t.stack = t.stack[:i]
+	for _, v := range component {
+		t.onStack[v] = false
+	}
+	if len(component) > 1 || selfLoop {
+		for _, v := range component {
+			t.cycles[v] = component
+		}
+	}
+}
+
+// rotateCycle rotates a cycle path, in which the first and last nodes are
+// the same, so that it starts and ends at its node with the lowest order.
+func rotateCycle(cycle []int) []int {
+	nodes := cycle[:len(cycle)-1]
+	start := 0
+	for i, v := range nodes {
+		if v < nodes[start] {
+			start = i
+		}
+	}
+
+	rotated := make([]int, 0, len(cycle))
+	rotated = append(rotated, nodes[start:]...)
+	rotated = append(rotated, nodes[:start]...)
+	return append(rotated, nodes[start])
+}
+
 // isAcyclic traverses the given graph starting from a specific node
 // using depth-first search using recursion. If a cycle is detected,
 // it returns the node that contains the "last" edge that introduces
@@ -73,7 +235,7 @@ func isAcyclic(g Graph, u int, info cycleInfo, path []int) []int {
 	info[u].OnStack = true
 
 	path = append(path, u)
-	for _, v := range g.EdgesFrom(u) {
+	for _, v := range hardEdgesFrom(g, u) {
 		if !info[v].Visited {
 			if cycle := isAcyclic(g, v, info, path); len(cycle) > 0 {
 				return cycle
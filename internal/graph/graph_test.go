@@ -155,3 +155,253 @@ func TestGraphIsCyclic(t *testing.T) {
 		assert.Equal(t, tt.cycle, c)
 	}
 }
+
+func TestGraphIsAcyclicFrom(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		edges [][]int
+		from  int
+		cycle []int
+	}{
+		{
+			desc: "no cycle",
+			// 0 ---> 1 ---> 2
+			edges: [][]int{
+				{1},
+				{2},
+				nil,
+			},
+			from: 0,
+		},
+		{
+			desc: "cycle through start",
+			// 0 ---> 1 ---> 2 ---> 3
+			//        ^             |
+			//        '-------------'
+			edges: [][]int{
+				{1},
+				{2},
+				{3},
+				{1},
+			},
+			from:  3,
+			cycle: []int{1, 2, 3, 1},
+		},
+		{
+			desc: "cycle not reachable from start",
+			// 0 ---> 1    2 ---> 3
+			//             ^      |
+			//             '------'
+			edges: [][]int{
+				{1},
+				nil,
+				{3},
+				{2},
+			},
+			from: 0,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			g := newTestGraph()
+			for i, neighbors := range tt.edges {
+				g.Nodes[i] = neighbors
+			}
+			ok, c := IsAcyclicFrom(g, tt.from)
+			assert.Equal(t, len(tt.cycle) == 0, ok)
+			assert.Equal(t, tt.cycle, c)
+		})
+	}
+}
+
+func TestGraphIsAcyclicFromAll(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		edges    [][]int
+		from     []int
+		verified []bool
+		cycle    []int
+		want     []bool // verified after the call
+	}{
+		{
+			desc: "no cycle",
+			// 0 ---> 1 ---> 2    3
+			edges: [][]int{
+				{1},
+				{2},
+				nil,
+				nil,
+			},
+			from: []int{0},
+			want: []bool{true, true, true, false},
+		},
+		{
+			desc: "cycle reachable from second node",
+			// 0 ---> 1    2 ---> 3
+			//             ^      |
+			//             '------'
+			edges: [][]int{
+				{1},
+				nil,
+				{3},
+				{2},
+			},
+			from:  []int{0, 3},
+			cycle: []int{2, 3, 2},
+		},
+		{
+			desc: "cycle not reachable",
+			// 0 ---> 1    2 ---> 3
+			//             ^      |
+			//             '------'
+			edges: [][]int{
+				{1},
+				nil,
+				{3},
+				{2},
+			},
+			from: []int{1, 0},
+			want: []bool{true, true, false, false},
+		},
+		{
+			desc: "verified nodes are skipped",
+			// 0 ---> 1 ---> 2 ---> 3
+			edges: [][]int{
+				{1},
+				{2},
+				{3},
+				nil,
+			},
+			from:     []int{0},
+			verified: []bool{false, false, true, false},
+			want:     []bool{true, true, true, false},
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			g := newTestGraph()
+			for i, neighbors := range tt.edges {
+				g.Nodes[i] = neighbors
+			}
+			verified := make([]bool, len(tt.edges))
+			copy(verified, tt.verified)
+
+			ok, c := IsAcyclicFromAll(g, tt.from, verified)
+			assert.Equal(t, len(tt.cycle) == 0, ok)
+			assert.Equal(t, tt.cycle, c)
+			if ok {
+				assert.Equal(t, tt.want, verified)
+			}
+		})
+	}
+}
+
+// testSoftGraph is a TestGraph with some soft edges.
+type testSoftGraph struct {
+	TestGraph
+
+	// Soft[u] lists the nodes that node u has soft edges to.
+	Soft map[int][]int
+}
+
+func (g testSoftGraph) HardEdgesFrom(u int) []int {
+	var hard []int
+	for _, v := range g.Nodes[u] {
+		soft := false
+		for _, s := range g.Soft[u] {
+			soft = soft || s == v
+		}
+		if !soft {
+			hard = append(hard, v)
+		}
+	}
+	return hard
+}
+
+func TestSoftGraphIsAcyclic(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		edges [][]int
+		soft  map[int][]int
+		cycle []int
+	}{
+		{
+			desc: "cycle broken by a soft edge",
+			// 0 ---> 1 ---> 2
+			// ^             :
+			// '.............'
+			edges: [][]int{
+				{1},
+				{2},
+				{0},
+			},
+			soft: map[int][]int{2: {0}},
+		},
+		{
+			desc: "cycle of hard edges",
+			// 0 ---> 1 ---> 2
+			// :      ^      |
+			// :      '------'
+			// '...> 3
+			edges: [][]int{
+				{1, 3},
+				{2},
+				{1},
+				nil,
+			},
+			soft:  map[int][]int{0: {3}},
+			cycle: []int{1, 2, 1},
+		},
+		{
+			desc: "hard cycle beside a broken one",
+			// 0 ---> 1 ---> 2 ---> 3
+			// ^      :      ^      |
+			// '......'      '------'
+			edges: [][]int{
+				{1},
+				{0, 2},
+				{3},
+				{2},
+			},
+			soft:  map[int][]int{1: {0}},
+			cycle: []int{2, 3, 2},
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			g := testSoftGraph{TestGraph: *newTestGraph(), Soft: tt.soft}
+			for i, neighbors := range tt.edges {
+				g.Nodes[i] = neighbors
+			}
+			ok, c := IsAcyclic(g)
+			assert.Equal(t, len(tt.cycle) == 0, ok)
+			assert.Equal(t, tt.cycle, c)
+		})
+	}
+}
+
+func TestGraphCycles(t *testing.T) {
+	// 0 ---> 1 ---> 2 ---> 3 ---> 4    5 --.
+	//        ^      |      ^      |    ^   |
+	//        '------'      '------'    '---'
+	g := newTestGraph()
+	for i, neighbors := range [][]int{
+		{1},
+		{2},
+		{1, 3},
+		{4},
+		{3},
+		{5},
+		nil,
+	} {
+		g.Nodes[i] = neighbors
+	}
+	cycles := Cycles(g)
+	assert.Empty(t, cycles[0])
+	assert.ElementsMatch(t, []int{1, 2}, cycles[1])
+	assert.ElementsMatch(t, []int{1, 2}, cycles[2])
+	assert.ElementsMatch(t, []int{3, 4}, cycles[3])
+	assert.ElementsMatch(t, []int{3, 4}, cycles[4])
+	assert.Equal(t, []int{5}, cycles[5])
+	assert.Empty(t, cycles[6])
+}
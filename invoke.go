@@ -21,6 +21,7 @@
 package dig
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 
@@ -28,10 +29,107 @@ import (
 	"go.uber.org/dig/internal/graph"
 )
 
-// An InvokeOption modifies the default behavior of Invoke. It's included for
-// future functionality; currently, there are no concrete implementations.
+// An InvokeOption modifies the default behavior of Invoke.
 type InvokeOption interface {
-	unimplemented()
+	applyInvokeOption(*invokeOptions)
+}
+
+type invokeOptions struct {
+	Context         context.Context
+	Consumed        *[]reflect.Type
+	ProvideResults  bool
+	ContinueOnError bool
+	Decorators      []interface{}
+
+	// WrapErrors wraps errors returned by the function with its location.
+	// It is set by InvokeAll, whose errors name the function that they
+	// came from.
+	WrapErrors bool
+
+	// Caller is the call that invoked the function through its Resolver,
+	// if any. It is set by the Resolver.
+	Caller *callFrame
+}
+
+func newInvokeOptions(opts []InvokeOption) invokeOptions {
+	var options invokeOptions
+	for _, o := range opts {
+		o.applyInvokeOption(&options)
+	}
+	return options
+}
+
+// ProvideResults is an InvokeOption that adds the non-error results of the
+// invoked function to the container, as if the function had been passed to
+// Provide and called. Results may be dig.Out structs, and are subject to the
+// same checks as the results of constructors, so Invoke fails without
+// calling the function if they conflict with values that are already
+// provided.
+//
+//	err := c.Invoke(func(db *sql.DB) (*Fixtures, error) {
+//	  return loadFixtures(db)
+//	}, dig.ProvideResults())
+//
+// Later Invokes may then depend on the results. Results are not added if
+// the function returns an error. This option is allowed for functions that
+// return values even with the StrictInvoke option.
+func ProvideResults() InvokeOption {
+	return provideResultsOption{}
+}
+
+type provideResultsOption struct{}
+
+func (provideResultsOption) String() string {
+	return "ProvideResults()"
+}
+
+func (provideResultsOption) applyInvokeOption(opts *invokeOptions) {
+	opts.ProvideResults = true
+}
+
+// RecordConsumed is an InvokeOption that writes the types of all values
+// that were read from the container while building the arguments of the
+// invoked function to the given slice.
+//
+//	var types []reflect.Type
+//	err := c.Invoke(run, dig.RecordConsumed(&types))
+//
+// Unlike the parameters of the function, this reflects what happened at run
+// time: values read by constructors that were called to build the
+// arguments are included, and optional values that were not present are
+// not. Dependencies of values that were already built are not read again,
+// so they are only recorded by the Invoke that built them. Value groups are
+// recorded as the slice type that was read.
+//
+// Each type is recorded once, in the order it was first read. The slice is
+// written even if Invoke fails, with the values read before the failure.
+func RecordConsumed(types *[]reflect.Type) InvokeOption {
+	return recordConsumedOption{types: types}
+}
+
+type recordConsumedOption struct{ types *[]reflect.Type }
+
+func (o recordConsumedOption) String() string {
+	return fmt.Sprintf("RecordConsumed(%p)", o.types)
+}
+
+func (o recordConsumedOption) applyInvokeOption(opts *invokeOptions) {
+	opts.Consumed = o.types
+}
+
+// consumedRecorder collects the types of the values read by an Invoke with
+// the RecordConsumed option.
+type consumedRecorder struct {
+	seen  map[reflect.Type]struct{}
+	types []reflect.Type
+}
+
+func (r *consumedRecorder) record(t reflect.Type) {
+	if _, ok := r.seen[t]; ok {
+		return
+	}
+	r.seen[t] = struct{}{}
+	r.types = append(r.types, t)
 }
 
 // Invoke runs the given function after instantiating its dependencies.
@@ -46,6 +144,13 @@ type InvokeOption interface {
 // If the [RecoverFromPanics] option was given to the container and a panic
 // occurs when invoking, a [PanicError] with the panic contained will be
 // returned. See [PanicError] for more info.
+//
+// Invoke may be called from several goroutines at once, but not
+// concurrently with Provide, Decorate, Remove, or Scope. Each constructor is
+// called at most once: goroutines that need a value that is being built
+// wait for it, while unrelated constructors run in parallel. Decorators are
+// not guarded this way and should not be needed by concurrent Invokes for
+// the first time.
 func (c *Container) Invoke(function interface{}, opts ...InvokeOption) error {
 	return c.scope.Invoke(function, opts...)
 }
@@ -58,39 +163,176 @@ func (c *Container) Invoke(function interface{}, opts ...InvokeOption) error {
 //
 // The function may return an error to indicate failure. The error will be
 // returned to the caller as-is.
-func (s *Scope) Invoke(function interface{}, opts ...InvokeOption) (err error) {
+func (s *Scope) Invoke(function interface{}, opts ...InvokeOption) error {
+	return s.invoke(function, newInvokeOptions(opts))
+}
+
+func (s *Scope) invoke(function interface{}, options invokeOptions) error {
+	inv, err := s.compileInvoke(function, options.ProvideResults)
+	if err != nil {
+		return err
+	}
+	return inv.invoke(options)
+}
+
+// Invoker is a function whose dependencies were resolved ahead of time with
+// CompileInvoke. It may be invoked any number of times.
+type Invoker struct {
+	s        *Scope
+	fn       reflect.Value
+	location *digreflect.Func
+	params   paramList
+}
+
+// CompileInvoke prepares the given function to be invoked repeatedly in
+// the Container. See Scope.CompileInvoke for details.
+func (c *Container) CompileInvoke(function interface{}) (*Invoker, error) {
+	return c.scope.CompileInvoke(function)
+}
+
+// CompileInvoke prepares the given function to be invoked repeatedly in the
+// Scope.
+//
+// The function's parameters are inspected and checked against the Scope
+// once, here, rather than on every call to Invoke. This is useful for
+// functions that are invoked many times, such as per-request handlers.
+//
+//	inv, err := s.CompileInvoke(func(h *Handler) error { ... })
+//	if err != nil {
+//	  return err
+//	}
+//	for req := range requests {
+//	  if err := inv.Invoke(); err != nil {
+//	    // ...
+//	  }
+//	}
+//
+// Values are still read from the Scope on every call, so constructors run
+// at most once and their results are shared as they are with Invoke.
+func (s *Scope) CompileInvoke(function interface{}) (*Invoker, error) {
+	return s.compileInvoke(function, false /* keepResults */)
+}
+
+// compileInvoke compiles the given function. If keepResults is set, the
+// results of the function will be provided to the Scope, so they are not
+// dropped.
+func (s *Scope) compileInvoke(function interface{}, keepResults bool) (*Invoker, error) {
 	ftype := reflect.TypeOf(function)
 	if ftype == nil {
-		return newErrInvalidInput("can't invoke an untyped nil", nil)
+		return nil, newErrInvalidInput("can't invoke an untyped nil", nil)
 	}
 	if ftype.Kind() != reflect.Func {
-		return newErrInvalidInput(
+		return nil, newErrInvalidInput(
 			fmt.Sprintf("can't invoke non-function %v (type %v)", function, ftype), nil)
 	}
 
-	pl, err := newParamList(ftype, s)
-	if err != nil {
+	// Functions of the same type have the same parameters, and their
+	// dependencies need not be checked again until the constructors of the
+	// Container change.
+	pl, checked, ok := s.cachedInvokeParams(ftype)
+	if !ok {
+		var err error
+		pl, err = newParamList(ftype, s)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkNoCallInfo(pl); err != nil {
+			return nil, err
+		}
+	}
+
+	location := digreflect.InspectFunc(function)
+	if s.strictInvoke && !keepResults {
+		if dropped := droppedResults(ftype); len(dropped) > 0 {
+			return nil, errInvokeResultsDropped{Func: location, Types: dropped}
+		}
+	}
+
+	if !checked {
+		generation := s.providersGeneration()
+		if err := shallowCheckDependencies(s, pl); err != nil {
+			return nil, errMissingDependencies{
+				Func:   location,
+				Reason: err,
+			}
+		}
+		s.cacheInvokeParams(ftype, pl, generation)
+	}
+
+	return &Invoker{
+		s:        s,
+		fn:       reflect.ValueOf(function),
+		location: location,
+		params:   pl,
+	}, nil
+}
+
+// Invoke runs the compiled function after instantiating its dependencies.
+// It behaves like Scope.Invoke.
+func (inv *Invoker) Invoke(opts ...InvokeOption) error {
+	return inv.invoke(newInvokeOptions(opts))
+}
+
+func (inv *Invoker) invoke(options invokeOptions) (err error) {
+	s := inv.s
+	if err := s.checkReentrant("Invoke"); err != nil {
 		return err
 	}
+	invocation := s.beginInvoke(inv.location)
+	frame := newInvocationFrame(invocation, options.Caller)
 
-	if err := shallowCheckDependencies(s, pl); err != nil {
-		return errMissingDependencies{
-			Func:   digreflect.InspectFunc(function),
+	var results resultList
+	if options.ProvideResults {
+		if results, err = inv.checkResults(); err != nil {
+			return errProvide{Func: inv.location, Reason: err}
+		}
+	}
+
+	if err := s.checkContextParams(inv.params, options.Context); err != nil {
+		return errArgumentsFailed{
+			Func:   inv.location,
 			Reason: err,
 		}
 	}
 
-	if !s.isVerifiedAcyclic {
-		if ok, cycle := graph.IsAcyclic(s.gh); !ok {
-			return newErrInvalidInput("cycle detected in dependency graph", s.cycleDetectedError(cycle))
+	if err := s.verifyAcyclicFrom(inv.params); err != nil {
+		return err
+	}
+
+	if err := s.callEagerCtors(frameStore{containerStore: s, frame: frame}); err != nil {
+		return err
+	}
+
+	var store containerStore = s
+	if len(options.Decorators) > 0 {
+		ds, err := s.decorateInvoke(options.Decorators)
+		if err != nil {
+			return err
+		}
+		store = ds
+	}
+	store = frameStore{containerStore: store, frame: frame}
+	if options.Context != nil {
+		store = contextStore{
+			containerStore: store,
+			ctx:            options.Context,
+			keys:           s.rootScope().contextKeys,
 		}
-		s.isVerifiedAcyclic = true
 	}
 
-	args, err := pl.BuildList(s)
+	if options.Consumed != nil {
+		rec := &consumedRecorder{seen: make(map[reflect.Type]struct{})}
+		invocation.consumed = rec
+		defer func() { *options.Consumed = rec.types }()
+	}
+
+	args, err := inv.params.BuildList(store)
+	if err == nil {
+		err = s.checkSharedMutations(inv.location, inv.params, args)
+	}
 	if err != nil {
 		return errArgumentsFailed{
-			Func:   digreflect.InspectFunc(function),
+			Func:   inv.location,
 			Reason: err,
 		}
 	}
@@ -98,34 +340,96 @@ func (s *Scope) Invoke(function interface{}, opts ...InvokeOption) (err error) {
 		defer func() {
 			if p := recover(); p != nil {
 				err = PanicError{
-					fn:    digreflect.InspectFunc(function),
+					fn:    inv.location,
 					Panic: p,
 				}
 			}
 		}()
 	}
 
-	returned := s.invokerFn(reflect.ValueOf(function), args)
+	invoker := s.invokerFn
+	if s.IsDryRun() && dependsOnRunMode(inv.params.Params) {
+		// The function asked whether this is a dry run, so it may be
+		// called to pretend to run.
+		invoker = defaultInvoker
+	}
+	returned := invoker(inv.fn, args)
 	if len(returned) == 0 {
 		return nil
 	}
 	if last := returned[len(returned)-1]; isError(last.Type()) {
 		if err, _ := last.Interface().(error); err != nil {
+			if options.WrapErrors {
+				return errConstructorFailed{Func: inv.location, Reason: err}
+			}
 			return err
 		}
 	}
 
+	if options.ProvideResults {
+		return inv.provideResults(results, returned)
+	}
 	return nil
 }
 
+// checkResults verifies that the results of the invoked function can be
+// provided to the Scope.
+func (inv *Invoker) checkResults() (resultList, error) {
+	ftype := inv.fn.Type()
+	rl, err := newResultList(ftype, resultOptions{Tags: inv.s.tagKeys()})
+	if err != nil {
+		return rl, err
+	}
+
+	keys, err := inv.s.findAndValidateResults(rl)
+	if err != nil {
+		return rl, err
+	}
+	if len(keys) == 0 {
+		return rl, newErrInvalidInput(
+			fmt.Sprintf("%v must return at least one non-error type to use dig.ProvideResults", ftype), nil)
+	}
+	return rl, nil
+}
+
+// provideResults provides the values returned by the invoked function to
+// the Scope through a constructor that returns them.
+func (inv *Invoker) provideResults(rl resultList, returned []reflect.Value) error {
+	var (
+		types  []reflect.Type
+		values []reflect.Value
+	)
+	for i, v := range returned {
+		if rl.resultIndexes[i] < 0 {
+			continue // error
+		}
+		types = append(types, inv.fn.Type().Out(i))
+		values = append(values, v)
+	}
+
+	ctor := reflect.MakeFunc(
+		reflect.FuncOf(nil, types, false),
+		func([]reflect.Value) []reflect.Value {
+			return values
+		},
+	)
+	return inv.s.Provide(ctor.Interface(), provideLocationOption{loc: inv.location})
+}
+
 // Checks that all direct dependencies of the provided parameters are present in
 // the container. Returns an error if not.
 func shallowCheckDependencies(c containerStore, pl paramList) error {
+	if len(pl.Params) == 0 {
+		return nil
+	}
+
 	var err errMissingTypes
 
 	missingDeps := findMissingDependencies(c, pl.Params...)
 	for _, dep := range missingDeps {
-		err = append(err, newErrMissingTypes(c, key{name: dep.Name, t: dep.Type})...)
+		mt := newMissingType(c, key{name: dep.Name, t: dep.Type})
+		mt.Path = dep.Path
+		err = append(err, mt)
 	}
 
 	if len(err) > 0 {
@@ -134,26 +438,154 @@ func shallowCheckDependencies(c containerStore, pl paramList) error {
 	return nil
 }
 
-func findMissingDependencies(c containerStore, params ...param) []paramSingle {
-	var missingDeps []paramSingle
-
-	for _, param := range params {
-		switch p := param.(type) {
-		case paramSingle:
-			allProviders := c.getAllValueProviders(p.Name, p.Type)
-			_, hasDecoratedValue := c.getDecoratedValue(p.Name, p.Type)
-			// This means that there is no provider that provides this value,
-			// and it is NOT being decorated and is NOT optional.
-			// In the case that there is no providers but there is a decorated value
-			// of this type, it can be provided safely so we can safely skip this.
-			if len(allProviders) == 0 && !hasDecoratedValue && !p.Optional {
-				missingDeps = append(missingDeps, p)
+// hasBuiltValue reports whether the value of the given param was already
+// built in the given containerStore or one of its parents, for example
+// because it was imported with ImportValues.
+func hasBuiltValue(c containerStore, p paramSingle) bool {
+	for _, s := range c.storesToRoot() {
+		if _, ok := s.getValue(p.Name, p.Type); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyAcyclic checks the graph of this Scope for cycles if it was not
+// checked since the last call to Provide.
+func (s *Scope) verifyAcyclic() error {
+	unlock := s.lock()
+	verified := s.isVerifiedAcyclic
+	unlock()
+	if verified {
+		return nil
+	}
+
+	g := s.gh.Clone()
+	if ok, cycle := graph.IsAcyclic(g); !ok {
+		return newErrInvalidInput("cycle detected in dependency graph", s.cycleDetectedError(g, cycle))
+	}
+
+	defer s.lock()()
+	s.isVerifiedAcyclic = true
+	return nil
+}
+
+// verifyAcyclicFrom checks the part of the graph of this Scope that the
+// given params and the eager constructors depend on for cycles, unless the
+// whole graph was checked since the last call to Provide. Nodes that were
+// checked are remembered, so that later calls only check the nodes that
+// they did not reach.
+func (s *Scope) verifyAcyclicFrom(pl paramList) error {
+	unlock := s.lock()
+	if s.isVerifiedAcyclic {
+		unlock()
+		return nil
+	}
+	g := s.gh.clone()
+	verified := make([]bool, g.Order())
+	copy(verified, s.acyclicNodes)
+	unlock()
+
+	var seeds []int
+	for _, p := range pl.Params {
+		seeds = append(seeds, getParamOrder(g, p, true /* withOptional */)...)
+	}
+	for _, as := range s.ancestors() {
+		for _, n := range as.nodes {
+			if n.eager {
+				seeds = append(seeds, n.Order(s))
 			}
-		case paramObject:
-			for _, f := range p.Fields {
-				missingDeps = append(missingDeps, findMissingDependencies(c, f.Param)...)
+		}
+	}
+
+	if ok, cycle := graph.IsAcyclicFromAll(g, seeds, verified); !ok {
+		return newErrInvalidInput("cycle detected in dependency graph", s.cycleDetectedError(g, cycle))
+	}
+
+	defer s.lock()()
+	// Another Invoke may have checked a newer clone of the graph with more nodes.
+	if n := len(s.acyclicNodes) - len(verified); n > 0 {
+		verified = append(verified, make([]bool, n)...)
+	}
+	for i, ok := range s.acyclicNodes {
+		verified[i] = verified[i] || ok
+	}
+	s.acyclicNodes = verified
+	return nil
+}
+
+func findMissingDependencies(c containerStore, params ...param) []missingParam {
+	var missingDeps []missingParam
+	for i, p := range params {
+		missingDeps = appendMissingDependencies(c, missingDeps, ParamPath{Argument: i + 1}, p)
+	}
+	return missingDeps
+}
+
+// missingParam is a required dependency that has no provider, along with
+// the path to the parameter that requested it.
+type missingParam struct {
+	paramSingle
+
+	Path ParamPath
+}
+
+// appendMissingDependencies appends the missing dependencies of the given
+// param, which was found at the given path, to missingDeps.
+func appendMissingDependencies(c containerStore, missingDeps []missingParam, path ParamPath, param param) []missingParam {
+	switch p := param.(type) {
+	case paramSingle:
+		allProviders := c.getAllValueProviders(p.Name, p.Type)
+		_, hasDecoratedValue := c.getDecoratedValue(p.Name, p.Type)
+		// This means that there is no provider that provides this value,
+		// and it is NOT being decorated, was NOT already built (for
+		// example, imported with ImportValues), and is NOT optional.
+		// In the case that there is no providers but there is a decorated value
+		// of this type, it can be provided safely so we can safely skip this.
+		// Named values may also be provided through NamedResults, which is
+		// only known once they are built.
+		if len(allProviders) == 0 && !hasDecoratedValue && !p.Optional && !hasBuiltValue(c, p) &&
+			(p.Name == "" || !hasNamedResultsProviders(c)) {
+			// Under PreferMostDerived, another type may satisfy it.
+			// Ambiguities are reported when the value is built.
+			if dt, err := p.derivedType(c); dt == nil && err == nil {
+				missingDeps = append(missingDeps, missingParam{paramSingle: p, Path: path})
 			}
 		}
+	case paramObject:
+		for _, f := range p.Fields {
+			missingDeps = appendMissingDependencies(c, missingDeps, path.withField(f.FieldName), f.Param)
+		}
 	}
 	return missingDeps
 }
+
+// droppedResults returns the types of the non-error values returned by a
+// function, which Invoke discards.
+func droppedResults(ftype reflect.Type) []reflect.Type {
+	var types []reflect.Type
+	for i := 0; i < ftype.NumOut(); i++ {
+		if t := ftype.Out(i); !isError(t) {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// callEagerCtors calls the constructors provided with the Eager option to
+// this Scope and its ancestors that were not called yet, starting at the
+// root, on behalf of the call in progress in c.
+func (s *Scope) callEagerCtors(c containerStore) error {
+	scopes := s.ancestors()
+	for i := len(scopes) - 1; i >= 0; i-- {
+		for _, n := range scopes[i].nodes {
+			if !n.eager {
+				continue
+			}
+			if err := n.Call(withCallFrame(n.OrigScope(), c)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,562 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestStrictInvoke(t *testing.T) {
+	t.Parallel()
+
+	type Server struct{}
+	newServer := func() (*Server, string, error) { return &Server{}, "", nil }
+
+	t.Run("non-error results are rejected", func(t *testing.T) {
+		c := digtest.New(t, dig.StrictInvoke())
+
+		var called bool
+		err := c.Invoke(func() (*Server, string, error) {
+			called = true
+			return newServer()
+		})
+		require.Error(t, err)
+		assert.False(t, called, "function must not be called")
+		assert.Regexp(t,
+			`^function "go.uber.org/dig_test".TestStrictInvoke\S+ \(\S+invoke_test.go:\d+\) `+
+				`returns values that Invoke would discard: \*dig_test.Server, string; `+
+				`use Provide to add them to the container instead$`,
+			err.Error())
+	})
+
+	t.Run("applies to child scopes", func(t *testing.T) {
+		c := digtest.New(t, dig.StrictInvoke())
+		s := c.Scope("child")
+
+		err := s.Invoke(newServer)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "returns values that Invoke would discard")
+	})
+
+	t.Run("error results are allowed", func(t *testing.T) {
+		c := digtest.New(t, dig.StrictInvoke())
+		c.RequireInvoke(func() error { return nil })
+		c.RequireInvoke(func() {})
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireInvoke(newServer)
+	})
+}
+
+func TestCompileInvoke(t *testing.T) {
+	t.Parallel()
+
+	t.Run("invoked repeatedly", func(t *testing.T) {
+		c := digtest.New(t)
+
+		var constructed int
+		c.RequireProvide(func() *bytes.Buffer {
+			constructed++
+			return &bytes.Buffer{}
+		})
+
+		var calls int
+		var first *bytes.Buffer
+		inv, err := c.CompileInvoke(func(b *bytes.Buffer) {
+			calls++
+			if first == nil {
+				first = b
+			}
+			assert.True(t, first == b, "must receive the same singleton")
+		})
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, inv.Invoke())
+		}
+		assert.Equal(t, 3, calls)
+		assert.Equal(t, 1, constructed, "constructor must be called at most once")
+
+		c.RequireInvoke(func(b *bytes.Buffer) {
+			assert.True(t, first == b, "Invoke must share values with the compiled invoker")
+		})
+	})
+
+	t.Run("scope", func(t *testing.T) {
+		c := digtest.New(t)
+		s := c.Scope("child")
+		s.RequireProvide(func() string { return "child" })
+
+		inv, err := s.CompileInvoke(func(v string) {
+			assert.Equal(t, "child", v)
+		})
+		require.NoError(t, err)
+		require.NoError(t, inv.Invoke())
+	})
+
+	t.Run("returns error", func(t *testing.T) {
+		c := digtest.New(t)
+		giveErr := errors.New("great sadness")
+		inv, err := c.CompileInvoke(func() error { return giveErr })
+		require.NoError(t, err)
+		assert.Equal(t, giveErr, inv.Invoke())
+	})
+
+	t.Run("missing dependencies fail at compile time", func(t *testing.T) {
+		c := digtest.New(t)
+		_, err := c.CompileInvoke(func(*bytes.Buffer) {
+			t.Fatal("function must not be called")
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: *bytes.Buffer")
+	})
+
+	t.Run("non-function", func(t *testing.T) {
+		c := digtest.New(t)
+		_, err := c.CompileInvoke(42)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "can't invoke non-function")
+	})
+}
+
+func BenchmarkInvoke(b *testing.B) {
+	type params struct {
+		dig.In
+
+		Buffer *bytes.Buffer
+		Name   string `name:"name"`
+	}
+
+	c := dig.New()
+	require.NoError(b, c.Provide(func() *bytes.Buffer { return &bytes.Buffer{} }))
+	require.NoError(b, c.Provide(func() string { return "foo" }, dig.Name("name")))
+	fn := func(params) {}
+
+	b.Run("Invoke", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := c.Invoke(fn); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("CompileInvoke", func(b *testing.B) {
+		inv, err := c.CompileInvoke(fn)
+		require.NoError(b, err)
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := inv.Invoke(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Invoke closures of the same type", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var n int
+			if err := c.Invoke(func(params) { n++ }); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestInvokeRepeatedFunctionType(t *testing.T) {
+	t.Parallel()
+
+	type A struct{}
+	type B struct{}
+
+	t.Run("provide after invoke", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{} })
+		c.RequireInvoke(func(*A) {})
+
+		err := c.Invoke(func(*A, *B) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: *dig_test.B")
+
+		c.RequireProvide(func() *B { return &B{} })
+		c.RequireInvoke(func(*A, *B) {})
+	})
+
+	t.Run("remove after invoke", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		var info dig.ProvideInfo
+		c.RequireProvide(func() *A { return &A{} }, dig.FillProvideInfo(&info))
+		c.RequireInvoke(func(*A) {})
+
+		require.NoError(t, c.Remove(info))
+		err := c.Invoke(func(*A) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: *dig_test.A")
+	})
+
+	t.Run("provide to parent after invoke in child", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		child := c.Scope("child")
+		require.Error(t, child.Invoke(func(*A) {}))
+
+		c.RequireProvide(func() *A { return &A{} })
+		child.RequireInvoke(func(*A) {})
+	})
+}
+
+func TestRecordConsumed(t *testing.T) {
+	t.Parallel()
+
+	type A struct{}
+	type B struct{}
+	type C struct{}
+	type D struct{}
+
+	typesOf := func(vs ...interface{}) []reflect.Type {
+		types := make([]reflect.Type, len(vs))
+		for i, v := range vs {
+			types[i] = reflect.TypeOf(v)
+		}
+		return types
+	}
+
+	t.Run("runtime path", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			B *B
+			C *C `optional:"true"`
+			D *D `optional:"true"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{} })
+		c.RequireProvide(func(*A) *B { return &B{} })
+		c.RequireProvide(func() *D { return &D{} })
+
+		var types []reflect.Type
+		c.RequireInvoke(func(params, *A) {}, dig.RecordConsumed(&types))
+		assert.Equal(t, typesOf(&A{}, &B{}, &D{}), types,
+			"must include transitive reads and skip absent optional values")
+
+		// *A is not read again to build *B.
+		c.RequireInvoke(func(*B) {}, dig.RecordConsumed(&types))
+		assert.Equal(t, typesOf(&B{}), types)
+	})
+
+	t.Run("value groups", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func(*A) string { return "a" }, dig.Group("strings"))
+		c.RequireProvide(func() *A { return &A{} })
+
+		var types []reflect.Type
+		c.RequireInvoke(func(struct {
+			dig.In
+
+			Strings []string `group:"strings"`
+		}) {
+		}, dig.RecordConsumed(&types))
+		assert.Equal(t, typesOf(&A{}, []string{}), types)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{} })
+		c.RequireProvide(func(*A) (*B, error) { return nil, errors.New("great sadness") })
+
+		var types []reflect.Type
+		err := c.Invoke(func(*B) {}, dig.RecordConsumed(&types))
+		require.Error(t, err)
+		assert.Equal(t, typesOf(&A{}), types)
+	})
+
+	t.Run("only during the recording invoke", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{} })
+		c.RequireProvide(func() *B { return &B{} })
+
+		var types []reflect.Type
+		c.RequireInvoke(func(*A) {}, dig.RecordConsumed(&types))
+		c.RequireInvoke(func(*B) {})
+		assert.Equal(t, typesOf(&A{}), types)
+	})
+
+	t.Run("not shared with concurrent invokes", func(t *testing.T) {
+		t.Parallel()
+
+		started, release := make(chan struct{}), make(chan struct{})
+		c := digtest.New(t)
+		c.RequireProvide(func() *A {
+			close(started)
+			<-release
+			return &A{}
+		})
+		c.RequireProvide(func() *B { return &B{} })
+
+		var types []reflect.Type
+		done := make(chan error)
+		go func() { done <- c.Invoke(func(*A) {}, dig.RecordConsumed(&types)) }()
+		<-started
+		c.RequireInvoke(func(*B) {})
+		close(release)
+		require.NoError(t, <-done)
+		assert.Equal(t, typesOf(&A{}), types)
+	})
+
+	t.Run("String", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "RecordConsumed(0x0)", fmt.Sprint(dig.RecordConsumed(nil)))
+	})
+}
+
+func TestEager(t *testing.T) {
+	t.Parallel()
+
+	type DB struct{ migrated bool }
+
+	t.Run("side effects only", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		c := digtest.New(t)
+		c.RequireProvide(func() *DB { return &DB{} })
+		c.RequireProvide(func(db *DB) error {
+			calls = append(calls, "migrate")
+			db.migrated = true
+			return nil
+		}, dig.Eager(), dig.AllowNoResults())
+		c.RequireProvide(func() {
+			calls = append(calls, "init")
+		}, dig.Eager(), dig.AllowNoResults())
+		assert.Empty(t, calls, "eager constructors must not run on Provide")
+
+		c.RequireInvoke(func(db *DB) {
+			assert.True(t, db.migrated)
+		})
+		c.RequireInvoke(func() {})
+		assert.Equal(t, []string{"migrate", "init"}, calls, "eager constructors must run once, in order")
+	})
+
+	t.Run("constructor with results", func(t *testing.T) {
+		t.Parallel()
+
+		var called bool
+		c := digtest.New(t)
+		c.RequireProvide(func() *DB {
+			called = true
+			return &DB{}
+		}, dig.Eager())
+		c.RequireInvoke(func() {})
+		assert.True(t, called)
+	})
+
+	t.Run("failure is retried", func(t *testing.T) {
+		t.Parallel()
+
+		fail := true
+		c := digtest.New(t)
+		c.RequireProvide(func() error {
+			if fail {
+				return errors.New("great sadness")
+			}
+			return nil
+		}, dig.Eager(), dig.AllowNoResults())
+
+		var invoked bool
+		err := c.Invoke(func() { invoked = true })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "great sadness")
+		assert.False(t, invoked)
+
+		fail = false
+		c.RequireInvoke(func() { invoked = true })
+		assert.True(t, invoked)
+	})
+
+	t.Run("scopes", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		c := digtest.New(t)
+		child := c.Scope("child")
+		c.RequireProvide(func() { calls = append(calls, "root") }, dig.Eager(), dig.AllowNoResults())
+		child.RequireProvide(func() { calls = append(calls, "child") }, dig.Eager(), dig.AllowNoResults())
+
+		c.RequireInvoke(func() {})
+		assert.Equal(t, []string{"root"}, calls, "eager constructors of child scopes must not run")
+
+		child.RequireInvoke(func() {})
+		assert.Equal(t, []string{"root", "child"}, calls)
+	})
+
+	t.Run("no results without AllowNoResults", func(t *testing.T) {
+		t.Parallel()
+
+		err := digtest.New(t).Provide(func() error { return nil }, dig.Eager())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must provide at least one non-error type")
+	})
+
+	t.Run("no results without Eager", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() { t.Fatal("must not be called") }, dig.AllowNoResults())
+		c.RequireInvoke(func() {})
+	})
+}
+
+func TestProvideResults(t *testing.T) {
+	t.Parallel()
+
+	type Fixtures struct{ Users []string }
+	type DB struct{}
+
+	t.Run("single result", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *DB { return &DB{} })
+		c.RequireInvoke(func(*DB) (*Fixtures, error) {
+			return &Fixtures{Users: []string{"alice"}}, nil
+		}, dig.ProvideResults())
+		c.RequireInvoke(func(f *Fixtures) {
+			assert.Equal(t, []string{"alice"}, f.Users)
+		})
+	})
+
+	t.Run("result object", func(t *testing.T) {
+		t.Parallel()
+
+		type results struct {
+			dig.Out
+
+			Admin string   `name:"admin"`
+			Users []string `group:"users,flatten"`
+		}
+
+		c := digtest.New(t)
+		c.RequireInvoke(func() results {
+			return results{Admin: "root", Users: []string{"alice", "bob"}}
+		}, dig.ProvideResults())
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Admin string   `name:"admin"`
+			Users []string `group:"users"`
+		}) {
+			assert.Equal(t, "root", p.Admin)
+			assert.ElementsMatch(t, []string{"alice", "bob"}, p.Users)
+		})
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Fixtures { return &Fixtures{} })
+
+		var called bool
+		err := c.Invoke(func() *Fixtures {
+			called = true
+			return &Fixtures{}
+		}, dig.ProvideResults())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot provide function")
+		assert.Contains(t, err.Error(), "already provided")
+		assert.False(t, called, "function must not be called if its results conflict")
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Invoke(func() (*Fixtures, error) {
+			return nil, errors.New("great sadness")
+		}, dig.ProvideResults())
+		require.Error(t, err)
+		assert.Equal(t, "great sadness", err.Error())
+
+		err = c.Invoke(func(*Fixtures) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: *dig_test.Fixtures")
+	})
+
+	t.Run("no results", func(t *testing.T) {
+		t.Parallel()
+
+		err := digtest.New(t).Invoke(func() error { return nil }, dig.ProvideResults())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "func() error must return at least one non-error type to use dig.ProvideResults")
+	})
+
+	t.Run("strict invoke", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.StrictInvoke())
+		c.RequireInvoke(func() *Fixtures { return &Fixtures{} }, dig.ProvideResults())
+		c.RequireInvoke(func(*Fixtures) {})
+	})
+
+	t.Run("compiled", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		inv, err := c.CompileInvoke(func() *Fixtures { return &Fixtures{} })
+		require.NoError(t, err)
+		require.NoError(t, inv.Invoke(dig.ProvideResults()))
+
+		err = inv.Invoke(dig.ProvideResults())
+		require.Error(t, err, "results may only be provided once")
+		assert.Contains(t, err.Error(), "already provided")
+	})
+
+	t.Run("String", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "ProvideResults()", fmt.Sprint(dig.ProvideResults()))
+	})
+}
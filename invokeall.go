@@ -0,0 +1,185 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// ContinueOnError is an InvokeOption for InvokeAll that runs all functions
+// even if some of them fail. The errors of all failed functions are
+// returned together. It has no effect on Invoke.
+func ContinueOnError() InvokeOption {
+	return continueOnErrorOption{}
+}
+
+type continueOnErrorOption struct{}
+
+func (continueOnErrorOption) String() string {
+	return "ContinueOnError()"
+}
+
+func (continueOnErrorOption) applyInvokeOption(opts *invokeOptions) {
+	opts.ContinueOnError = true
+}
+
+// InvokeAll runs the given functions in order after instantiating their
+// dependencies. See Scope.InvokeAll for details.
+func (c *Container) InvokeAll(functions ...interface{}) error {
+	return c.scope.InvokeAll(functions...)
+}
+
+// InvokeAll runs the given functions in order after instantiating their
+// dependencies, as if each was passed to Invoke.
+//
+//	err := s.InvokeAll(
+//	  registerHandlers,
+//	  startWorkers,
+//	  dig.ContinueOnError(),
+//	)
+//
+// The parameters of all functions are checked against the Scope before any
+// of them runs, and the missing dependencies of every function are reported
+// together. Values built for one function are shared with the functions
+// after it. Because of this, a function cannot depend on the values added
+// by an earlier function with the ProvideResults option.
+//
+// InvokeAll stops at the first function that fails and returns its error.
+// With the ContinueOnError option, it runs the remaining functions and
+// returns the errors of all functions that failed. Either way, each error
+// names the function that it came from.
+//
+// Arguments that are InvokeOptions are not invoked: they apply to all
+// functions. With RecordConsumed, the types read by all functions are
+// recorded together.
+func (s *Scope) InvokeAll(functions ...interface{}) error {
+	var (
+		fns  []interface{}
+		opts []InvokeOption
+	)
+	for _, f := range functions {
+		if o, ok := f.(InvokeOption); ok {
+			opts = append(opts, o)
+			continue
+		}
+		fns = append(fns, f)
+	}
+	options := newInvokeOptions(opts)
+	options.WrapErrors = true
+
+	var (
+		invs []*Invoker
+		errs []error
+	)
+	for _, f := range fns {
+		inv, err := s.compileInvoke(f, options.ProvideResults)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		invs = append(invs, inv)
+	}
+	if err := newErrInvokeAllFailed(len(fns), errs); err != nil {
+		return err
+	}
+
+	var rec *consumedRecorder
+	if options.Consumed != nil {
+		rec = &consumedRecorder{seen: make(map[reflect.Type]struct{})}
+		defer func(types *[]reflect.Type) {
+			*types = rec.types
+		}(options.Consumed)
+	}
+
+	for _, inv := range invs {
+		var consumed []reflect.Type
+		if rec != nil {
+			options.Consumed = &consumed
+		}
+		err := inv.invoke(options)
+		for _, t := range consumed {
+			rec.record(t)
+		}
+		if err == nil {
+			continue
+		}
+		if !options.ContinueOnError {
+			return err
+		}
+		errs = append(errs, err)
+	}
+	return newErrInvokeAllFailed(len(fns), errs)
+}
+
+// errInvokeAllFailed is returned by InvokeAll when more than one of its
+// functions failed, either before any of them ran or with the
+// ContinueOnError option.
+type errInvokeAllFailed struct {
+	Total  int
+	Errors []error // inv: len > 1
+}
+
+// newErrInvokeAllFailed returns the errors of the failed functions out of
+// total. A single error is returned as-is, like Invoke would.
+func newErrInvokeAllFailed(total int, errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	return errInvokeAllFailed{Total: total, Errors: errs}
+}
+
+var _ digError = errInvokeAllFailed{}
+
+func (e errInvokeAllFailed) Error() string { return fmt.Sprint(e) }
+
+// Unwrap returns the errors of all failed functions, in order.
+func (e errInvokeAllFailed) Unwrap() []error { return e.Errors }
+
+func (e errInvokeAllFailed) writeMessage(w io.Writer, _ string) {
+	fmt.Fprintf(w, "%d of %d functions failed", len(e.Errors), e.Total)
+}
+
+func (e errInvokeAllFailed) Format(w fmt.State, c rune) {
+	e.writeMessage(w, "%v")
+
+	// As with errGroupMembersFailed, each failure is listed on its own
+	// line with %+v, and separated by semicolons otherwise.
+	if w.Flag('+') && c == 'v' {
+		io.WriteString(w, ":")
+		for i, err := range e.Errors {
+			fmt.Fprintf(w, "\n  - [%d] %+v", i+1, err)
+		}
+		return
+	}
+
+	io.WriteString(w, ": ")
+	for i, err := range e.Errors {
+		if i > 0 {
+			io.WriteString(w, "; ")
+		}
+		fmt.Fprintf(w, "[%d] %v", i+1, err)
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestInvokeAll(t *testing.T) {
+	t.Parallel()
+
+	type A struct{}
+	type B struct{}
+	type C struct{}
+
+	t.Run("runs in order sharing values", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		calls := 0
+		c.RequireProvide(func() *A {
+			calls++
+			return &A{}
+		})
+
+		var got []string
+		require.NoError(t, c.InvokeAll(
+			func(*A) { got = append(got, "first") },
+			func(*A) { got = append(got, "second") },
+		))
+		assert.Equal(t, []string{"first", "second"}, got)
+		assert.Equal(t, 1, calls, "constructor must be called once")
+	})
+
+	t.Run("reports all missing dependencies before running", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		ran := false
+		err := c.InvokeAll(
+			func() { ran = true },
+			func(*A) {},
+			func(*B) {},
+		)
+		require.Error(t, err)
+		assert.False(t, ran, "no function may run if any is invalid")
+		assert.Contains(t, err.Error(), "2 of 3 functions failed")
+		assert.Contains(t, err.Error(), "missing type: *dig_test.A")
+		assert.Contains(t, err.Error(), "missing type: *dig_test.B")
+	})
+
+	t.Run("single invalid function", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.InvokeAll(func() {}, func(*A) {})
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "functions failed")
+		assert.Contains(t, err.Error(), "missing type: *dig_test.A")
+	})
+
+	t.Run("stops at first error", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		giveErr := errors.New("great sadness")
+		ran := false
+		err := c.InvokeAll(
+			func() error { return giveErr },
+			func() { ran = true },
+		)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, giveErr)
+		assert.False(t, ran, "functions after a failure must not run")
+		dig.AssertErrorMatches(t, err,
+			`received non-nil error from function "go.uber.org/dig_test".TestInvokeAll\S+`,
+			`invokeall_test.go:\d+`, // file:line
+			"great sadness",
+		)
+	})
+
+	t.Run("continue on error", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		errA := errors.New("a failed")
+		errC := errors.New("c failed")
+		ran := false
+		err := c.InvokeAll(
+			func() error { return errA },
+			func() { ran = true },
+			func() error { return errC },
+			dig.ContinueOnError(),
+		)
+		require.Error(t, err)
+		assert.True(t, ran, "functions after a failure must run")
+		assert.ErrorIs(t, err, errA)
+		assert.ErrorIs(t, err, errC)
+		dig.AssertErrorMatches(t, err,
+			"2 of 3 functions failed",
+			`\[1\] received non-nil error from function "go.uber.org/dig_test".TestInvokeAll\S+`,
+			`invokeall_test.go:\d+`, // file:line
+			"a failed",
+			`\[2\] received non-nil error from function "go.uber.org/dig_test".TestInvokeAll\S+`,
+			`invokeall_test.go:\d+`, // file:line
+			"c failed",
+		)
+		assert.Contains(t, fmt.Sprintf("%+v", err), "2 of 3 functions failed:\n  - [1] received non-nil error")
+	})
+
+	t.Run("continue on error with single failure", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		giveErr := errors.New("great sadness")
+		err := c.InvokeAll(
+			func() {},
+			func() error { return giveErr },
+			dig.ContinueOnError(),
+		)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, giveErr)
+		assert.NotContains(t, err.Error(), "functions failed")
+		dig.AssertErrorMatches(t, err,
+			`received non-nil error from function "go.uber.org/dig_test".TestInvokeAll\S+`,
+			`invokeall_test.go:\d+`, // file:line
+			"great sadness",
+		)
+	})
+
+	t.Run("record consumed", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{} })
+		c.RequireProvide(func() *B { return &B{} })
+		c.RequireProvide(func() *C { return &C{} })
+
+		var types []reflect.Type
+		require.NoError(t, c.InvokeAll(
+			func(*A, *B) {},
+			func(*B, *C) {},
+			dig.RecordConsumed(&types),
+		))
+		assert.Equal(t, []reflect.Type{
+			reflect.TypeOf(&A{}),
+			reflect.TypeOf(&B{}),
+			reflect.TypeOf(&C{}),
+		}, types)
+	})
+
+	t.Run("scope", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		s := c.Scope("child")
+		s.RequireProvide(func() *A { return &A{} })
+
+		called := false
+		require.NoError(t, s.InvokeAll(func(*A) { called = true }))
+		assert.True(t, called)
+	})
+
+	t.Run("option string", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "ContinueOnError()", fmt.Sprint(dig.ContinueOnError()))
+	})
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import "reflect"
+
+// invokeParams are the parameters of functions of a type invoked in a
+// Scope, remembered so that invoking another function of the same type
+// does not inspect them again.
+type invokeParams struct {
+	params paramList
+
+	// Generation of the root Scope when the dependencies of the
+	// parameters were last found to have providers.
+	generation uint64
+}
+
+// cachedInvokeParams returns the parameters of functions of the given type
+// invoked earlier in this Scope, and whether their dependencies were
+// checked since the constructors of the Container last changed.
+func (s *Scope) cachedInvokeParams(ftype reflect.Type) (pl paramList, checked, ok bool) {
+	defer s.lock()()
+	ip, ok := s.invokeParams[ftype]
+	if !ok {
+		return paramList{}, false, false
+	}
+	return ip.params, ip.generation == s.rootScope().generation, true
+}
+
+// cacheInvokeParams remembers the parameters of functions of the given type,
+// whose dependencies were found to have providers at the given generation
+// of the root Scope.
+func (s *Scope) cacheInvokeParams(ftype reflect.Type, pl paramList, generation uint64) {
+	defer s.lock()()
+	if s.invokeParams == nil {
+		s.invokeParams = make(map[reflect.Type]invokeParams)
+	}
+	s.invokeParams[ftype] = invokeParams{params: pl, generation: generation}
+}
+
+// providersGeneration returns the generation of the root Scope, which
+// changes whenever constructors are provided or removed.
+func (s *Scope) providersGeneration() uint64 {
+	defer s.lock()()
+	return s.rootScope().generation
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import "reflect"
+
+// LastError returns the most recent error that the Container encountered
+// while building the value of type t with the given name, or nil if
+// building it never failed. Pass an empty name for unnamed values.
+//
+// Errors are recorded even if they were swallowed because the value was
+// requested as an optional dependency, in which case the function that
+// requested it received the zero value instead. This makes it possible to
+// tell a value that was genuinely absent from one that failed to build.
+// This includes errors that constructors provided with the
+// ReportErrorsToGroup option reported to a value group.
+//
+// The error is forgotten once the value is built successfully.
+func (c *Container) LastError(t reflect.Type, name string) error {
+	return c.scope.lastError(key{t: t, name: name})
+}
+
+func (s *Scope) lastError(k key) error {
+	defer s.lock()()
+	return s.rootScope().buildErrors[k]
+}
+
+func (s *Scope) recordBuildError(k key, err error) {
+	defer s.lock()()
+	root := s.rootScope()
+	if err == nil {
+		delete(root.buildErrors, k)
+		return
+	}
+	if root.buildErrors == nil {
+		root.buildErrors = make(map[key]error)
+	}
+	root.buildErrors[k] = err
+}
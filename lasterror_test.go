@@ -0,0 +1,130 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestLastError(t *testing.T) {
+	t.Parallel()
+
+	type Missing struct{}
+	type Feature struct{}
+	featureType := reflect.TypeOf(&Feature{})
+
+	type params struct {
+		dig.In
+
+		Feature *Feature `optional:"true"`
+	}
+
+	t.Run("nil when never built", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Feature { return &Feature{} })
+		assert.NoError(t, c.LastError(featureType, ""))
+	})
+
+	t.Run("swallowed by optional", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func(*Missing) *Feature { return &Feature{} })
+		c.RequireInvoke(func(p params) {
+			assert.Nil(t, p.Feature)
+		})
+
+		err := c.LastError(featureType, "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing dependencies")
+		assert.Contains(t, err.Error(), "*dig_test.Missing")
+	})
+
+	t.Run("reported to error group", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (*Feature, error) {
+			return nil, errors.New("great sadness")
+		}, dig.ReportErrorsToGroup("errors"))
+		c.RequireInvoke(func(p params) {
+			assert.Nil(t, p.Feature)
+		})
+
+		err := c.LastError(featureType, "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "great sadness")
+	})
+
+	t.Run("required dependency", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (*Feature, error) {
+			return nil, errors.New("great sadness")
+		}, dig.Name("f"))
+		require.Error(t, c.Invoke(func(struct {
+			dig.In
+
+			Feature *Feature `name:"f"`
+		}) {
+		}))
+
+		assert.ErrorContains(t, c.LastError(featureType, "f"), "great sadness")
+		assert.NoError(t, c.LastError(featureType, ""), "unnamed value was never built")
+	})
+
+	t.Run("forgotten after success", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func(*Missing) *Feature { return &Feature{} })
+		c.RequireInvoke(func(params) {})
+		require.Error(t, c.LastError(featureType, ""))
+
+		c.RequireProvide(func() *Missing { return &Missing{} })
+		c.RequireInvoke(func(p params) {
+			assert.NotNil(t, p.Feature)
+		})
+		assert.NoError(t, c.LastError(featureType, ""))
+	})
+
+	t.Run("recorded from child scope", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		child := c.Scope("child")
+		child.RequireProvide(func(*Missing) *Feature { return &Feature{} })
+		child.RequireInvoke(func(p params) {
+			assert.Nil(t, p.Feature)
+		})
+		assert.Error(t, c.LastError(featureType, ""))
+	})
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"sort"
+)
+
+// LazyOptionals is an [Option] that keeps optional dependencies from
+// calling constructors. An optional dependency receives its value only if
+// it was already built, for example because a required dependency on the
+// same value was resolved earlier, and its zero value otherwise.
+//
+// By default, an optional dependency with a provider calls that provider,
+// so a constructor whose results are only ever consumed optionally is
+// still called. Use this option to skip such constructors when they are
+// expensive and rarely needed.
+//
+// With this option, the value received by a function that optionally
+// depends on a value depends on whether it was built before that function
+// was called. Functions that were already called with a zero value do not
+// see values that are built later. Use OptionalOnlyProviders to find the
+// constructors affected by this option.
+func LazyOptionals() Option {
+	return lazyOptionalsOption{}
+}
+
+type lazyOptionalsOption struct{}
+
+func (lazyOptionalsOption) String() string {
+	return "LazyOptionals()"
+}
+
+func (lazyOptionalsOption) applyOption(c *Container) {
+	c.scope.lazyOptionals = true
+}
+
+func (s *Scope) buildsOptionalsLazily() bool {
+	return s.rootScope().lazyOptionals
+}
+
+// isBuilt reports whether the value of this param is available without
+// calling any constructor.
+func (ps paramSingle) isBuilt(c containerStore) bool {
+	for _, s := range c.storesToRoot() {
+		if _, ok := s.getDecoratedValue(ps.Name, ps.Type); ok {
+			return true
+		}
+		if _, ok := s.getValue(ps.Name, ps.Type); ok {
+			return true
+		}
+		if len(s.getValueProviders(ps.Name, ps.Type)) > 0 {
+			return false
+		}
+	}
+	return false
+}
+
+// OptionalOnlyProvider is a constructor whose results are consumed by
+// other constructors or decorators, but only as optional dependencies.
+type OptionalOnlyProvider struct {
+	// Provider describes the constructor.
+	Provider string
+
+	// Consumers describes the constructors and decorators that optionally
+	// depend on the results of the constructor, in sorted order.
+	Consumers []string
+}
+
+func (p OptionalOnlyProvider) String() string {
+	return fmt.Sprintf("%v is only consumed optionally by %v", p.Provider, p.Consumers)
+}
+
+// OptionalOnlyProviders reports the constructors provided to the Container,
+// or to any of its Scopes, whose results are consumed by other constructors
+// or decorators, but only as optional dependencies. Without the
+// LazyOptionals option, these constructors are called whenever one of their
+// consumers is. Constructors whose results are not consumed at all, or
+// that add values to value groups that are consumed, are not reported.
+//
+// Functions passed to Invoke are not known in advance, so their
+// dependencies are not taken into account.
+func (c *Container) OptionalOnlyProviders() []OptionalOnlyProvider {
+	return c.scope.OptionalOnlyProviders()
+}
+
+// OptionalOnlyProviders reports the constructors provided to this Scope, or
+// to any of its descendants, whose results are only consumed optionally.
+// See Container.OptionalOnlyProviders for details.
+func (s *Scope) OptionalOnlyProviders() []OptionalOnlyProvider {
+	var (
+		optional = make(map[provider][]string)
+		required = make(map[provider]struct{})
+	)
+	scopes := s.appendSubscopes(nil)
+	for _, scope := range scopes {
+		consume := func(consumer string, params []param) {
+			visitConsumedKeys(scope, params, func(p provider, isOptional bool) {
+				if isOptional {
+					optional[p] = append(optional[p], consumer)
+				} else {
+					required[p] = struct{}{}
+				}
+			})
+		}
+		for _, n := range scope.nodes {
+			consume(fmt.Sprint(n.Location()), n.ParamList().Params)
+		}
+		for _, d := range scope.decorators {
+			consume(fmt.Sprint(d.location), d.params.Params)
+		}
+	}
+
+	var providers []OptionalOnlyProvider
+	for _, scope := range scopes {
+		for _, n := range scope.nodes {
+			consumers, ok := optional[n]
+			if _, req := required[n]; !ok || req {
+				continue
+			}
+			sort.Strings(consumers)
+			providers = append(providers, OptionalOnlyProvider{
+				Provider:  fmt.Sprint(n.Location()),
+				Consumers: consumers,
+			})
+		}
+	}
+	return providers
+}
+
+// visitConsumedKeys calls fn with each provider visible to the given Scope
+// that the given params depend on, and whether they depend on it only
+// optionally.
+func visitConsumedKeys(s *Scope, params []param, fn func(p provider, optional bool)) {
+	for _, p := range params {
+		switch p := p.(type) {
+		case paramSingle:
+			for _, pr := range s.getAllValueProviders(p.Name, p.Type) {
+				fn(pr, p.Optional)
+			}
+		case paramDynamic:
+			visitConsumedKeys(s, []param{p.Value}, fn)
+		case paramGroupedSlice:
+			for _, pr := range s.getAllGroupProviders(p.Group, p.Type.Elem()) {
+				fn(pr, false)
+			}
+		case paramNamedMap:
+			t := p.Type.Elem()
+			for _, scope := range s.ancestors() {
+				for _, name := range scope.getValueNames(t) {
+					for _, pr := range scope.getValueProviders(name, t) {
+						fn(pr, false)
+					}
+				}
+			}
+		case paramObject:
+			for _, f := range p.Fields {
+				visitConsumedKeys(s, []param{f.Param}, fn)
+			}
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestLazyOptionals(t *testing.T) {
+	t.Parallel()
+
+	type Cache struct{ Size int }
+
+	type optionalParams struct {
+		dig.In
+
+		Cache *Cache `optional:"true"`
+	}
+
+	t.Run("eager by default", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Cache { return &Cache{Size: 1} })
+		c.RequireInvoke(func(p optionalParams) {
+			assert.Equal(t, &Cache{Size: 1}, p.Cache)
+		})
+	})
+
+	t.Run("does not call constructors", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.LazyOptionals())
+		c.RequireProvide(func() *Cache {
+			t.Fatal("must not be called")
+			return nil
+		})
+		c.RequireInvoke(func(p optionalParams) {
+			assert.Nil(t, p.Cache)
+		})
+	})
+
+	t.Run("uses values that were already built", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		c := digtest.New(t, dig.LazyOptionals())
+		c.RequireProvide(func() *Cache {
+			calls++
+			return &Cache{Size: calls}
+		})
+		c.RequireInvoke(func(p optionalParams) {
+			assert.Nil(t, p.Cache)
+		})
+		c.RequireInvoke(func(*Cache) {})
+		c.RequireInvoke(func(p optionalParams) {
+			assert.Equal(t, &Cache{Size: 1}, p.Cache)
+		})
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("mixed consumers in one function", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.LazyOptionals())
+		c.RequireProvide(func() *Cache { return &Cache{Size: 1} })
+
+		// Parameters are resolved in order, so the optional field only sees
+		// the value if the required one is resolved first.
+		c.RequireInvoke(func(required *Cache, p optionalParams) {
+			assert.Same(t, required, p.Cache)
+		})
+	})
+
+	t.Run("required dependencies of constructors", func(t *testing.T) {
+		t.Parallel()
+
+		type Server struct{ Cache *Cache }
+
+		c := digtest.New(t, dig.LazyOptionals())
+		c.RequireProvide(func() *Cache { return &Cache{Size: 1} })
+		c.RequireProvide(func(p optionalParams) *Server { return &Server{Cache: p.Cache} })
+		c.RequireInvoke(func(s *Server) {
+			assert.Nil(t, s.Cache)
+		})
+	})
+
+	t.Run("child scopes", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.LazyOptionals())
+		c.RequireProvide(func() *Cache { return &Cache{Size: 1} })
+		c.RequireInvoke(func(*Cache) {})
+
+		child := c.Scope("child")
+		child.RequireInvoke(func(p optionalParams) {
+			assert.Equal(t, &Cache{Size: 1}, p.Cache)
+		})
+
+		shadowing := c.Scope("shadowing")
+		shadowing.RequireProvide(func() *Cache { return &Cache{Size: 2} })
+		shadowing.RequireInvoke(func(p optionalParams) {
+			assert.Nil(t, p.Cache, "the provider of the child scope was not called")
+		})
+	})
+}
+
+func TestOptionalOnlyProviders(t *testing.T) {
+	t.Parallel()
+
+	type Cache struct{}
+	type Metrics struct{}
+	type Server struct{}
+	type Client struct{}
+
+	type optionalCache struct {
+		dig.In
+
+		Cache *Cache `optional:"true"`
+	}
+
+	c := digtest.New(t)
+	c.RequireProvide(func() *Cache { return nil })
+	c.RequireProvide(func() *Metrics { return nil })
+	c.RequireProvide(func(optionalCache, *Metrics) *Server { return nil })
+	c.RequireProvide(func(struct {
+		dig.In
+
+		Metrics *Metrics `optional:"true"`
+	}) *Client {
+		return nil
+	})
+
+	child := c.Scope("child")
+	child.RequireProvide(func(optionalCache) string { return "" })
+
+	providers := c.OptionalOnlyProviders()
+	require.Len(t, providers, 1, "only the cache is consumed optionally by all its consumers")
+	assert.Contains(t, providers[0].Provider, "TestOptionalOnlyProviders.func1")
+	require.Len(t, providers[0].Consumers, 2)
+	assert.Contains(t, providers[0].String(), "is only consumed optionally by")
+
+	t.Run("required consumer in a child scope", func(t *testing.T) {
+		child.RequireProvide(func(*Cache) int { return 0 })
+		assert.Empty(t, c.OptionalOnlyProviders())
+	})
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"io"
+
+	"go.uber.org/dig/internal/digreflect"
+)
+
+// MaxProviders is an Option that limits the number of constructors that
+// may be provided to the Container and all of its Scopes. Provide fails
+// once the limit is reached. By default, there is no limit.
+//
+// Use this to detect code that generates providers unboundedly.
+func MaxProviders(n int) Option {
+	return maxProvidersOption(n)
+}
+
+type maxProvidersOption int
+
+func (o maxProvidersOption) String() string {
+	return fmt.Sprintf("MaxProviders(%d)", int(o))
+}
+
+func (o maxProvidersOption) applyOption(c *Container) {
+	c.scope.maxProviders = int(o)
+}
+
+// MaxResolutionDepth is an Option that limits how deeply constructor calls
+// may nest while building the dependencies of a function. Invoke fails if a
+// constructor would be called beyond this depth. By default, there is no
+// limit.
+//
+// The dependencies of a function passed to Invoke are at depth 1, their
+// dependencies are at depth 2, and so on. Each Invoke counts its own
+// depth, so concurrent Invokes do not affect each other.
+func MaxResolutionDepth(n int) Option {
+	return maxResolutionDepthOption(n)
+}
+
+type maxResolutionDepthOption int
+
+func (o maxResolutionDepthOption) String() string {
+	return fmt.Sprintf("MaxResolutionDepth(%d)", int(o))
+}
+
+func (o maxResolutionDepthOption) applyOption(c *Container) {
+	c.scope.maxResolutionDepth = int(o)
+}
+
+// enterResolution returns the frame of a call of the given constructor,
+// owned by a Scope of this root Scope, from the call in progress in c, or
+// fails if the call would exceed MaxResolutionDepth.
+func (s *Scope) enterResolution(c containerStore, n *constructorNode) (*callFrame, error) {
+	f := c.callFrame().enter(n)
+	if s.maxResolutionDepth > 0 && f.depth > s.maxResolutionDepth {
+		return nil, errMaxResolutionDepth{Limit: s.maxResolutionDepth, Func: n.location}
+	}
+	return f, nil
+}
+
+// errMaxProviders is returned when a constructor is provided to a container
+// that already holds the maximum number of constructors allowed by
+// MaxProviders.
+type errMaxProviders struct {
+	Limit int
+}
+
+var _ digError = errMaxProviders{}
+
+func (e errMaxProviders) Error() string { return fmt.Sprint(e) }
+
+func (e errMaxProviders) writeMessage(w io.Writer, _ string) {
+	fmt.Fprintf(w, "container already has the maximum number of providers (%d)", e.Limit)
+}
+
+func (e errMaxProviders) Format(w fmt.State, c rune) {
+	formatError(e, w, c)
+}
+
+// errMaxResolutionDepth is returned when calling a constructor would
+// exceed the depth allowed by MaxResolutionDepth.
+type errMaxResolutionDepth struct {
+	Limit int
+	Func  *digreflect.Func
+}
+
+var _ digError = errMaxResolutionDepth{}
+
+func (e errMaxResolutionDepth) Error() string { return fmt.Sprint(e) }
+
+func (e errMaxResolutionDepth) writeMessage(w io.Writer, verb string) {
+	fmt.Fprintf(w, "maximum resolution depth (%d) exceeded by function "+verb, e.Limit, e.Func)
+}
+
+func (e errMaxResolutionDepth) Format(w fmt.State, c rune) {
+	formatError(e, w, c)
+}
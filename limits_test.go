@@ -0,0 +1,129 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestMaxProviders(t *testing.T) {
+	t.Parallel()
+
+	t.Run("limit reached", func(t *testing.T) {
+		c := digtest.New(t, dig.MaxProviders(2))
+		c.RequireProvide(func() int { return 0 })
+		c.RequireProvide(func() string { return "" })
+
+		err := c.Provide(func() float64 { return 0 })
+		require.Error(t, err)
+		dig.AssertErrorMatches(t, err,
+			`cannot provide function "go.uber.org/dig_test".TestMaxProviders\S+`,
+			`limits_test.go:\d+`, // file:line
+			`container already has the maximum number of providers \(2\)`,
+		)
+	})
+
+	t.Run("scopes count towards the limit", func(t *testing.T) {
+		c := digtest.New(t, dig.MaxProviders(2))
+		c.RequireProvide(func() int { return 0 })
+		c.Scope("child").RequireProvide(func() string { return "" })
+
+		err := c.Scope("other").Provide(func() float64 { return 0 })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "maximum number of providers (2)")
+	})
+
+	t.Run("failed provides do not count", func(t *testing.T) {
+		c := digtest.New(t, dig.MaxProviders(1))
+		require.Error(t, c.Provide(func() {}))
+		c.RequireProvide(func() int { return 0 })
+	})
+}
+
+func TestMaxResolutionDepth(t *testing.T) {
+	t.Parallel()
+
+	type A struct{}
+	type B struct{}
+	type C struct{}
+
+	newContainer := func(t *testing.T, depth int) *digtest.Container {
+		c := digtest.New(t, dig.MaxResolutionDepth(depth))
+		c.RequireProvide(func() *A { return &A{} })
+		c.RequireProvide(func(*A) *B { return &B{} })
+		c.RequireProvide(func(*B) *C { return &C{} })
+		return c
+	}
+
+	t.Run("within limit", func(t *testing.T) {
+		c := newContainer(t, 3)
+		c.RequireInvoke(func(*C) {})
+	})
+
+	t.Run("limit exceeded", func(t *testing.T) {
+		c := newContainer(t, 2)
+		err := c.Invoke(func(*C) {
+			t.Fatal("function must not be called")
+		})
+		require.Error(t, err)
+		dig.AssertErrorMatches(t, err,
+			`could not build arguments for function "go.uber.org/dig_test".TestMaxResolutionDepth\S+`,
+			`failed to build \*dig_test.C`,
+			`could not build arguments for function`,
+			`failed to build \*dig_test.B`,
+			`could not build arguments for function`,
+			`failed to build \*dig_test.A:`,
+			`maximum resolution depth \(2\) exceeded by function "go.uber.org/dig_test".TestMaxResolutionDepth\S+`,
+		)
+	})
+
+	t.Run("depth is reset between invokes", func(t *testing.T) {
+		c := newContainer(t, 1)
+		c.RequireInvoke(func(*A) {})
+		c.RequireInvoke(func(*B) {})
+		c.RequireInvoke(func(*C) {})
+	})
+
+	t.Run("concurrent invokes count separately", func(t *testing.T) {
+		type D struct{}
+
+		started, release := make(chan struct{}), make(chan struct{})
+		c := digtest.New(t, dig.MaxResolutionDepth(1))
+		c.RequireProvide(func() *A {
+			close(started)
+			<-release
+			return &A{}
+		})
+		c.RequireProvide(func() *D { return &D{} })
+
+		done := make(chan error)
+		go func() { done <- c.Invoke(func(*A) {}) }()
+		<-started
+		c.RequireInvoke(func(*D) {})
+		close(release)
+		require.NoError(t, <-done)
+	})
+}
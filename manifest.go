@@ -0,0 +1,215 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+
+	"go.uber.org/dig/internal/digreflect"
+	"go.uber.org/dig/internal/dot"
+)
+
+// Manifest describes how a Container is wired: the constructors and
+// decorators provided to it and its Scopes, and the values they depend on
+// and produce. Unlike a Snapshot, it holds no state, such as which
+// constructors were called, and no IDs, so the manifest of a Container is
+// the same every time the program runs. Check it into version control to
+// review changes to the wiring of an application.
+type Manifest struct {
+	// Root is the Scope of the Container itself.
+	Root ScopeManifest `json:"root"`
+}
+
+// ScopeManifest describes a single Scope in a Manifest.
+type ScopeManifest struct {
+	// Name of the Scope. The name of the root Scope is empty.
+	Name string `json:"name"`
+
+	// Constructors provided to the Scope, in the order they were provided,
+	// followed by those provided to the Scope with the Export option.
+	Providers []FuncManifest `json:"providers,omitempty"`
+
+	// Decorators provided to the Scope, sorted by location.
+	Decorators []FuncManifest `json:"decorators,omitempty"`
+
+	// Child Scopes of the Scope, in the order they were created.
+	Children []ScopeManifest `json:"children,omitempty"`
+}
+
+// FuncManifest describes a constructor or decorator in a Manifest.
+type FuncManifest struct {
+	// Package, name, file, and line of the function. File is the base
+	// name of the file, so that manifests do not depend on where the
+	// program was built.
+	Package  string `json:"package"`
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+
+	// Values that the function depends on and produces.
+	Inputs  []KeyManifest `json:"inputs,omitempty"`
+	Outputs []KeyManifest `json:"outputs,omitempty"`
+
+	// Exported reports whether the constructor was provided with the
+	// Export option.
+	Exported bool `json:"exported,omitempty"`
+
+	// Metadata attached to the constructor with the Tags option, if any.
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// KeyManifest identifies a value that a function depends on or produces in
+// a Manifest.
+type KeyManifest struct {
+	// Type of the value. For value groups, this is the type of the values
+	// in the group.
+	Type string `json:"type"`
+
+	// Name or Group of the value, if any. At most one of these is set.
+	Name  string `json:"name,omitempty"`
+	Group string `json:"group,omitempty"`
+
+	// Optional reports whether a dependency is optional.
+	Optional bool `json:"optional,omitempty"`
+}
+
+// Manifest returns the Manifest of the Container as indented JSON. See
+// Manifest for details.
+func (c *Container) Manifest() ([]byte, error) {
+	s := c.scope
+	unlock := s.lock()
+	exported := make(map[*Scope][]*constructorNode)
+	for _, scope := range s.appendSubscopes(nil) {
+		for _, n := range scope.nodes {
+			if n.provideOpts.Exported {
+				exported[n.origS] = append(exported[n.origS], n)
+			}
+		}
+	}
+	m := Manifest{Root: s.manifest(exported)}
+	unlock()
+
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// ParseManifest reads a Manifest returned by Container.Manifest.
+func ParseManifest(data []byte) (*Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, newErrInvalidInput("cannot parse manifest", err)
+	}
+	return &m, nil
+}
+
+// manifest describes this Scope and its descendants. exported maps Scopes
+// to the constructors provided to them with the Export option. The caller
+// must hold the lock.
+func (s *Scope) manifest(exported map[*Scope][]*constructorNode) ScopeManifest {
+	sm := ScopeManifest{Name: s.name}
+	for _, n := range s.nodes {
+		if !n.provideOpts.Exported {
+			sm.Providers = append(sm.Providers, constructorManifest(n))
+		}
+	}
+	for _, n := range exported[s] {
+		f := constructorManifest(n)
+		f.Exported = true
+		sm.Providers = append(sm.Providers, f)
+	}
+
+	// A decorator is registered once for every value it produces.
+	seen := make(map[*decoratorNode]struct{}, len(s.decorators))
+	for _, d := range s.decorators {
+		if _, ok := seen[d]; ok {
+			continue
+		}
+		seen[d] = struct{}{}
+
+		f := funcManifest(d.location)
+		f.Inputs = manifestInputs(d.params.DotParam())
+		f.Outputs = manifestOutputs(d.results.DotResult())
+		sm.Decorators = append(sm.Decorators, f)
+	}
+	sort.Slice(sm.Decorators, func(i, j int) bool {
+		a, b := sm.Decorators[i], sm.Decorators[j]
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+
+	for _, child := range s.childScopes {
+		sm.Children = append(sm.Children, child.manifest(exported))
+	}
+	return sm
+}
+
+func constructorManifest(n *constructorNode) FuncManifest {
+	f := funcManifest(n.location)
+	f.Inputs = manifestInputs(n.paramList.DotParam())
+	f.Outputs = manifestOutputs(n.resultList.DotResult())
+	f.Tags = n.Tags()
+	return f
+}
+
+func funcManifest(loc *digreflect.Func) FuncManifest {
+	return FuncManifest{
+		Package:  loc.Package,
+		Function: loc.Name,
+		File:     filepath.Base(loc.File),
+		Line:     loc.Line,
+	}
+}
+
+func manifestInputs(params []*dot.Param) []KeyManifest {
+	var keys []KeyManifest
+	for _, p := range params {
+		keys = append(keys, manifestKey(p.Node, p.Optional))
+	}
+	return keys
+}
+
+func manifestOutputs(results []*dot.Result) []KeyManifest {
+	var keys []KeyManifest
+	for _, r := range results {
+		keys = append(keys, manifestKey(r.Node, false))
+	}
+	return keys
+}
+
+func manifestKey(n *dot.Node, optional bool) KeyManifest {
+	k := snapshotKey(n, optional)
+	return KeyManifest{
+		Type:     k.Type,
+		Name:     k.Name,
+		Group:    k.Group,
+		Optional: k.Optional,
+	}
+}
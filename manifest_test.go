@@ -0,0 +1,110 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestManifest(t *testing.T) {
+	t.Parallel()
+
+	type A struct{}
+	type B struct{}
+	type params struct {
+		dig.In
+
+		A  *A   `name:"primary"`
+		B  *B   `optional:"true"`
+		Bs []*B `group:"bs"`
+	}
+
+	newContainer := func(t *testing.T) *digtest.Container {
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{} }, dig.Name("primary"), dig.Tags(map[string]string{"team": "core"}))
+		c.RequireProvide(func(params) string { return "" })
+
+		child := c.Scope("child")
+		child.RequireProvide(func() *B { return &B{} }, dig.Group("bs"))
+		child.RequireProvide(func() int { return 0 }, dig.Export(true))
+		child.RequireDecorate(func(s string) (string, int) { return s, 1 })
+		return c
+	}
+
+	c := newContainer(t)
+	b, err := c.Manifest()
+	require.NoError(t, err)
+
+	t.Run("deterministic", func(t *testing.T) {
+		other, err := newContainer(t).Manifest()
+		require.NoError(t, err)
+		assert.Equal(t, string(b), string(other), "equally wired containers must have the same manifest")
+
+		c.RequireInvoke(func(string) {})
+		after, err := c.Manifest()
+		require.NoError(t, err)
+		assert.Equal(t, string(b), string(after), "manifest must not depend on state")
+	})
+
+	t.Run("contents", func(t *testing.T) {
+		m, err := dig.ParseManifest(b)
+		require.NoError(t, err)
+
+		root := m.Root
+		require.Len(t, root.Providers, 2)
+		a := root.Providers[0]
+		assert.Equal(t, "go.uber.org/dig_test", a.Package)
+		assert.Contains(t, a.Function, "TestManifest")
+		assert.Equal(t, "manifest_test.go", a.File)
+		assert.NotZero(t, a.Line)
+		assert.Empty(t, a.Inputs)
+		assert.Equal(t, []dig.KeyManifest{{Type: "*dig_test.A", Name: "primary"}}, a.Outputs)
+		assert.Equal(t, map[string]string{"team": "core"}, a.Tags)
+
+		assert.Equal(t, []dig.KeyManifest{
+			{Type: "*dig_test.A", Name: "primary"},
+			{Type: "*dig_test.B", Optional: true},
+			{Type: "*dig_test.B", Group: "bs"},
+		}, root.Providers[1].Inputs)
+
+		require.Len(t, root.Children, 1)
+		child := root.Children[0]
+		assert.Equal(t, "child", child.Name)
+		require.Len(t, child.Providers, 2)
+		assert.Equal(t, []dig.KeyManifest{{Type: "*dig_test.B", Group: "bs"}}, child.Providers[0].Outputs)
+		assert.True(t, child.Providers[1].Exported)
+
+		require.Len(t, child.Decorators, 1, "decorators must be listed once")
+		assert.Equal(t, []dig.KeyManifest{{Type: "string"}}, child.Decorators[0].Inputs)
+		assert.Equal(t, []dig.KeyManifest{{Type: "string"}, {Type: "int"}}, child.Decorators[0].Outputs)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := dig.ParseManifest([]byte("{"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot parse manifest")
+	})
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"go.uber.org/dig/internal/dot"
+)
+
+// MissingDep is a dependency of a constructor that no constructor in the
+// container provides.
+type MissingDep struct {
+	// Type of the missing value. For value groups, this is the type of the
+	// values in the group.
+	Type reflect.Type
+
+	// Name or Group of the missing value, if any. At most one of these is
+	// set.
+	Name  string
+	Group string
+
+	// Consumer describes the constructor that depends on the missing value.
+	Consumer string
+
+	// For a value group without a namespace, the namespaced value groups
+	// of the same type and name that do have providers, like
+	// "payments/handlers" for "handlers". This usually means that the
+	// consumer should request one of these groups instead.
+	NamespacedGroups []string
+}
+
+func (d MissingDep) String() string {
+	k := key{t: d.Type, name: d.Name, group: d.Group}
+	msg := fmt.Sprintf("%v required by %v", k, d.Consumer)
+	if len(d.NamespacedGroups) > 0 {
+		msg += fmt.Sprintf(" (provided in namespaced groups %q)", d.NamespacedGroups)
+	}
+	return msg
+}
+
+// MissingDependencies reports the dependencies of all constructors provided
+// to the Container, or to any of its Scopes, that have no provider. Optional
+// dependencies are not reported. Value groups are reported if no
+// constructor contributes to them.
+//
+// Unlike Invoke, this does not require knowing which functions will be
+// invoked, so it may be used to check that a container is fully wired, for
+// example in tests.
+func (c *Container) MissingDependencies() []MissingDep {
+	return c.scope.MissingDependencies()
+}
+
+// MissingDependencies reports the dependencies of all constructors provided
+// to this Scope, or to any of its descendants, that have no provider.
+// See Container.MissingDependencies for details.
+func (s *Scope) MissingDependencies() []MissingDep {
+	var deps []MissingDep
+	for _, scope := range s.appendSubscopes(nil) {
+		for _, n := range scope.nodes {
+			consumer := fmt.Sprint(n.Location())
+			for _, k := range findMissingKeys(scope, n.ParamList().Params...) {
+				deps = append(deps, MissingDep{
+					Type:             k.t,
+					Name:             k.name,
+					Group:            k.group,
+					Consumer:         consumer,
+					NamespacedGroups: scope.findNamespacedGroups(k),
+				})
+			}
+		}
+	}
+	return deps
+}
+
+// findNamespacedGroups returns the names of the value groups visible to
+// this Scope that have the same type and name as the given value group
+// without a namespace, but are in a namespace.
+func (s *Scope) findNamespacedGroups(k key) []string {
+	if k.group == "" || strings.Contains(k.group, _groupNamespaceSep) {
+		return nil
+	}
+
+	var groups []string
+	seen := make(map[string]struct{})
+	for _, scope := range s.ancestors() {
+		for pk := range scope.providers {
+			if pk.t != k.t || pk.group == k.group || groupBaseName(pk.group) != k.group {
+				continue
+			}
+			if _, ok := seen[pk.group]; !ok {
+				seen[pk.group] = struct{}{}
+				groups = append(groups, pk.group)
+			}
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// failMissing marks the constructors of this Scope with required
+// dependencies that have no provider as failed in the given graph, along
+// with the missing values.
+func (s *Scope) failMissing(dg *dot.Graph) {
+	for _, n := range s.nodes {
+		keys := findMissingKeys(s, n.ParamList().Params...)
+		if len(keys) == 0 {
+			continue
+		}
+
+		missing := make([]*dot.Node, len(keys))
+		for i, k := range keys {
+			missing[i] = &dot.Node{Type: k.t, Name: k.name, Group: k.group}
+		}
+		dg.FailMissing(missing, n.id)
+	}
+}
+
+// findMissingKeys returns the keys of all required values and value groups
+// in the given params that have no providers in the given Scope.
+func findMissingKeys(s *Scope, params ...param) []key {
+	var keys []key
+	for _, p := range params {
+		switch p := p.(type) {
+		case paramSingle:
+			for _, ps := range appendMissingDependencies(s, nil, ParamPath{}, p) {
+				keys = append(keys, key{t: ps.Type, name: ps.Name})
+			}
+		case paramGroupedSlice:
+			k := key{t: p.Type.Elem(), group: p.Group}
+			if len(s.getAllGroupProviders(k.group, k.t)) > 0 {
+				continue
+			}
+			if _, decorated := p.getDecoratedValues(s); decorated {
+				continue
+			}
+			keys = append(keys, k)
+		case paramObject:
+			for _, f := range p.Fields {
+				keys = append(keys, findMissingKeys(s, f.Param)...)
+			}
+		}
+	}
+	return keys
+}
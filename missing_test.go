@@ -0,0 +1,85 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestMissingDependencies(t *testing.T) {
+	t.Parallel()
+
+	t.Run("complete graph", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(func() *bytes.Buffer { return &bytes.Buffer{} })
+		c.RequireProvide(func(*bytes.Buffer) io.Reader { return nil })
+		assert.Empty(t, c.MissingDependencies())
+	})
+
+	t.Run("reports all gaps", func(t *testing.T) {
+		type params struct {
+			dig.In
+
+			Writer   io.Writer   `name:"out"`
+			Optional io.Closer   `optional:"true"`
+			Readers  []io.Reader `group:"readers"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func(*bytes.Buffer) string { return "" })
+		c.RequireProvide(func(params) int { return 0 })
+
+		deps := c.MissingDependencies()
+		require.Len(t, deps, 3)
+
+		assert.Equal(t, reflect.TypeOf(&bytes.Buffer{}), deps[0].Type)
+		assert.Contains(t, deps[0].Consumer, "TestMissingDependencies")
+
+		assert.Equal(t, reflect.TypeOf((*io.Writer)(nil)).Elem(), deps[1].Type)
+		assert.Equal(t, "out", deps[1].Name)
+
+		assert.Equal(t, reflect.TypeOf((*io.Reader)(nil)).Elem(), deps[2].Type)
+		assert.Equal(t, "readers", deps[2].Group)
+		assert.Contains(t, deps[2].String(), `io.Reader[group="readers"] required by`)
+	})
+
+	t.Run("scopes", func(t *testing.T) {
+		c := digtest.New(t)
+		child := c.Scope("child")
+		child.RequireProvide(func() *bytes.Buffer { return &bytes.Buffer{} })
+		child.RequireProvide(func(*bytes.Buffer) string { return "" })
+		c.RequireProvide(func(*bytes.Buffer) int { return 0 })
+
+		deps := c.MissingDependencies()
+		require.Len(t, deps, 1, "only the root constructor is missing *bytes.Buffer")
+		assert.Equal(t, reflect.TypeOf(&bytes.Buffer{}), deps[0].Type)
+
+		assert.Empty(t, child.MissingDependencies())
+	})
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import "reflect"
+
+// Named is a value of type T along with the name it was provided with.
+// Depend on Named to consume a named value in a constructor without
+// declaring a parameter object. The name is set with the ParamTags option,
+// or with a name tag if Named is a field of a dig.In struct.
+//
+//	c.Provide(func(ro dig.Named[*sql.DB], rw dig.Named[*sql.DB]) *Repo {
+//	  ...
+//	}, dig.ParamTags(`name:"ro"`, `name:"rw"`))
+//
+// Named without a name consumes the unnamed value of type T.
+type Named[T any] struct {
+	// Name of the value, or an empty string if it has no name.
+	Name string
+
+	// Value is the value named Name.
+	Value T
+}
+
+func (Named[T]) digNamed() {}
+
+// named is implemented by all instantiations of Named.
+type named interface{ digNamed() }
+
+var _namedType = reflect.TypeOf((*named)(nil)).Elem()
+
+// isNamed reports whether t is an instantiation of Named.
+func isNamed(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.Implements(_namedType)
+}
+
+// newNamedParam builds a paramSingle for the value wrapped by the Named
+// type t.
+func newNamedParam(t reflect.Type) paramSingle {
+	f, _ := t.FieldByName("Value")
+	return paramSingle{Type: f.Type, Wrapper: t}
+}
+
+// wrap returns v wrapped in the Named type of this param, if any.
+func (ps paramSingle) wrap(v reflect.Value) reflect.Value {
+	if ps.Wrapper == nil {
+		return v
+	}
+	nv := reflect.New(ps.Wrapper).Elem()
+	nv.FieldByName("Name").SetString(ps.Name)
+	nv.FieldByName("Value").Set(v)
+	return nv
+}
+
+// unwrap returns the value wrapped by v, which was built by this param.
+func (ps paramSingle) unwrap(v reflect.Value) reflect.Value {
+	if ps.Wrapper == nil {
+		return v
+	}
+	return v.FieldByName("Value")
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestNamed(t *testing.T) {
+	t.Parallel()
+
+	type Connection struct{ Mode string }
+
+	provideConnections := func(c *digtest.Container) {
+		c.RequireProvide(func() *Connection { return &Connection{Mode: "ro"} }, dig.Name("ro"))
+		c.RequireProvide(func() *Connection { return &Connection{Mode: "rw"} }, dig.Name("rw"))
+	}
+
+	t.Run("positional with ParamTags", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		provideConnections(c)
+
+		type Repo struct{ RO, RW *Connection }
+		c.RequireProvide(func(ro, rw dig.Named[*Connection]) *Repo {
+			assert.Equal(t, "ro", ro.Name)
+			assert.Equal(t, "rw", rw.Name)
+			return &Repo{RO: ro.Value, RW: rw.Value}
+		}, dig.ParamTags(`name:"ro"`, `name:"rw"`))
+
+		c.RequireInvoke(func(r *Repo) {
+			assert.Equal(t, "ro", r.RO.Mode)
+			assert.Equal(t, "rw", r.RW.Mode)
+		})
+	})
+
+	t.Run("field of parameter object", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		provideConnections(c)
+
+		type params struct {
+			dig.In
+
+			Conn dig.Named[*Connection] `name:"rw"`
+		}
+		c.RequireInvoke(func(p params) {
+			assert.Equal(t, "rw", p.Conn.Name)
+			assert.Equal(t, "rw", p.Conn.Value.Mode)
+		})
+	})
+
+	t.Run("unnamed", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Connection { return &Connection{Mode: "default"} })
+		c.RequireInvoke(func(conn dig.Named[*Connection]) {
+			assert.Empty(t, conn.Name)
+			assert.Equal(t, "default", conn.Value.Mode)
+		})
+	})
+
+	t.Run("optional", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func(conn dig.Named[*Connection]) string {
+			assert.Equal(t, "ro", conn.Name)
+			assert.Nil(t, conn.Value)
+			return "ok"
+		}, dig.ParamTags(`name:"ro" optional:"true"`))
+		c.RequireInvoke(func(string) {})
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Invoke(func(dig.Named[*Connection]) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: *dig_test.Connection")
+	})
+
+	t.Run("decorated", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Connection { return &Connection{Mode: "default"} })
+		c.RequireDecorate(func(conn *Connection) *Connection {
+			return &Connection{Mode: conn.Mode + "-decorated"}
+		})
+		c.RequireInvoke(func(conn dig.Named[*Connection]) {
+			assert.Equal(t, "default-decorated", conn.Value.Mode)
+		})
+	})
+}
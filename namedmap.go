@@ -0,0 +1,179 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"go.uber.org/dig/internal/dot"
+)
+
+const (
+	_namesTag = "names"
+
+	// _allNames is the value of the names tag for maps keyed by name.
+	_allNames = "*"
+
+	// _sortedNames is the value of the names tag for slices sorted by
+	// name.
+	_sortedNames = "sorted"
+)
+
+// paramNamedMap is a dig.In field of type map[string]T tagged with
+// `names:"*"`. It receives every named value of type T, keyed by name.
+//
+//	type Params struct {
+//	  dig.In
+//
+//	  Handlers map[string]Handler `names:"*"`
+//	}
+//
+// Alternatively, a field of type []T tagged with `names:"sorted"` receives
+// the same values in a slice, sorted by their names.
+//
+//	type Params struct {
+//	  dig.In
+//
+//	  Handlers []Handler `names:"sorted"`
+//	}
+//
+// Every constructor that provides a named T visible to the Scope is called.
+// Values of type T without a name are not included, and the map or slice is
+// empty if no named values of type T were provided. Unlike value groups,
+// this only includes values that were provided with a name.
+type paramNamedMap struct {
+	// Map or slice type of the field.
+	Type reflect.Type
+
+	// Whether Type is a slice sorted by name rather than a map.
+	Sorted bool
+
+	orders map[*Scope]int
+}
+
+var _ param = paramNamedMap{}
+
+// newParamNamedMap builds a param for a field with a names tag.
+func newParamNamedMap(f reflect.StructField, c containerStore) (paramNamedMap, error) {
+	pm := paramNamedMap{Type: f.Type, orders: make(map[*Scope]int)}
+
+	tags := c.tagKeys()
+	t := f.Type
+	names := f.Tag.Get(_namesTag)
+	switch names {
+	case _allNames:
+		if t.Kind() != reflect.Map || t.Key().Kind() != reflect.String {
+			return pm, newErrInvalidInput(fmt.Sprintf(
+				"named values may be consumed as maps keyed by string only: field %q (%v) is not a map[string]T", f.Name, t), nil)
+		}
+	case _sortedNames:
+		if t.Kind() != reflect.Slice {
+			return pm, newErrInvalidInput(fmt.Sprintf(
+				"named values may be consumed in sorted order as slices only: field %q (%v) is not a slice", f.Name, t), nil)
+		}
+		pm.Sorted = true
+	default:
+		return pm, newErrInvalidInput(fmt.Sprintf(
+			"invalid value %q for %q tag on field %v: only %q and %q are supported", names, _namesTag, f.Name, _allNames, _sortedNames), nil)
+	}
+
+	switch {
+	case isError(t.Elem()) || IsIn(t.Elem()) || IsOut(t.Elem()):
+		return pm, newErrInvalidInput(fmt.Sprintf(
+			"cannot consume %v as named values: field %q (%v)", t.Elem(), f.Name, t), nil)
+	case f.Tag.Get(tags.name()) != "":
+		return pm, newErrInvalidInput(fmt.Sprintf(
+			"cannot use name with names:%q on field %q", names, f.Name), nil)
+	case f.Tag.Get(tags.group()) != "":
+		return pm, newErrInvalidInput(fmt.Sprintf(
+			"cannot use value groups with names:%q on field %q", names, f.Name), nil)
+	}
+	if optional, _ := isFieldOptional(f); optional {
+		return pm, newErrInvalidInput(fmt.Sprintf(
+			"fields tagged with names:%q cannot be optional: field %q", names, f.Name), nil)
+	}
+
+	c.newGraphNode(&pm, pm.orders)
+	return pm, nil
+}
+
+func (pm paramNamedMap) String() string {
+	if pm.Sorted {
+		return fmt.Sprintf("%v[names=%q]", pm.Type.Elem(), _sortedNames)
+	}
+	return fmt.Sprintf("%v[name=%q]", pm.Type.Elem(), _allNames)
+}
+
+// DotParam returns nothing: the names that are consumed are only known when
+// the map is built.
+func (pm paramNamedMap) DotParam() []*dot.Param {
+	return nil
+}
+
+// names returns the sorted names of all values of the element type that
+// are visible to the given containerStore, including those provided
+// through NamedResults.
+func (pm paramNamedMap) names(c containerStore) []string {
+	seen := make(map[string]struct{})
+	var names []string
+	for _, s := range c.storesToRoot() {
+		valueNames := s.getValueNames(pm.Type.Elem())
+		valueNames = append(valueNames, s.getNamedResultNames(pm.Type.Elem())...)
+		for _, name := range valueNames {
+			if _, ok := seen[name]; !ok {
+				seen[name] = struct{}{}
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (pm paramNamedMap) Build(c containerStore) (reflect.Value, error) {
+	// Constructors that return NamedResults may provide more names.
+	k := key{t: pm.Type.Elem(), name: _allNames}
+	if err := callNamedResultsProviders(c, k, func(containerStore) bool { return false }); err != nil {
+		return _noValue, err
+	}
+
+	names := pm.names(c)
+	var result reflect.Value
+	if pm.Sorted {
+		result = reflect.MakeSlice(pm.Type, 0, len(names))
+	} else {
+		result = reflect.MakeMapWithSize(pm.Type, len(names))
+	}
+	for _, name := range names {
+		v, err := paramSingle{Name: name, Type: pm.Type.Elem()}.Build(c)
+		if err != nil {
+			return _noValue, err
+		}
+		if pm.Sorted {
+			result = reflect.Append(result, v)
+		} else {
+			result.SetMapIndex(reflect.ValueOf(name).Convert(pm.Type.Key()), v)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,261 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestNamedMapParams(t *testing.T) {
+	t.Parallel()
+
+	type Handler string
+
+	type params struct {
+		dig.In
+
+		Handlers map[string]Handler `names:"*"`
+	}
+
+	t.Run("collects named values", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() Handler { return "users" }, dig.Name("users"))
+		c.RequireProvide(func() Handler { return "orders" }, dig.Name("orders"))
+		c.RequireProvide(func() Handler { return "unnamed" })
+		c.RequireProvide(func() Handler { return "grouped" }, dig.Group("handlers"))
+		c.RequireInvoke(func(p params) {
+			assert.Equal(t, map[string]Handler{
+				"users":  "users",
+				"orders": "orders",
+			}, p.Handlers)
+		})
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireInvoke(func(p params) {
+			assert.NotNil(t, p.Handlers)
+			assert.Empty(t, p.Handlers)
+		})
+	})
+
+	t.Run("decorated and scoped values", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() Handler { return "users" }, dig.Name("users"))
+		child := c.Scope("child")
+		child.RequireProvide(func() Handler { return "billing" }, dig.Name("billing"))
+		child.RequireDecorate(func(p struct {
+			dig.In
+
+			Users Handler `name:"users"`
+		}) struct {
+			dig.Out
+
+			Users Handler `name:"users"`
+		} {
+			return struct {
+				dig.Out
+
+				Users Handler `name:"users"`
+			}{Users: p.Users + "!"}
+		})
+
+		child.RequireInvoke(func(p params) {
+			assert.Equal(t, map[string]Handler{
+				"users":   "users!",
+				"billing": "billing",
+			}, p.Handlers)
+		})
+		c.RequireInvoke(func(p params) {
+			assert.Equal(t, map[string]Handler{"users": "users"}, p.Handlers)
+		})
+	})
+
+	t.Run("named key type", func(t *testing.T) {
+		t.Parallel()
+
+		type Route string
+
+		c := digtest.New(t)
+		c.RequireProvide(func() Handler { return "users" }, dig.Name("users"))
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Handlers map[Route]Handler `names:"*"`
+		}) {
+			assert.Equal(t, map[Route]Handler{"users": "users"}, p.Handlers)
+		})
+	})
+
+	t.Run("sorted slice", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() Handler { return "users" }, dig.Name("users"))
+		c.RequireProvide(func() Handler { return "accounts" }, dig.Name("accounts"))
+		c.RequireProvide(func() Handler { return "orders" }, dig.Name("orders"))
+		c.RequireProvide(func() Handler { return "unnamed" })
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Handlers []Handler `names:"sorted"`
+		}) {
+			assert.Equal(t, []Handler{"accounts", "orders", "users"}, p.Handlers)
+		})
+	})
+
+	t.Run("sorted slice empty", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Handlers []Handler `names:"sorted"`
+		}) {
+			assert.NotNil(t, p.Handlers)
+			assert.Empty(t, p.Handlers)
+		})
+	})
+
+	t.Run("constructor errors", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (Handler, error) {
+			return "", errors.New("great sadness")
+		}, dig.Name("users"))
+
+		err := c.Invoke(func(params) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "great sadness")
+	})
+
+	t.Run("cycles", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.DeferAcyclicVerification())
+		c.RequireProvide(func(params) Handler { return "users" }, dig.Name("users"))
+
+		err := c.Invoke(func(params) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cycle detected in dependency graph")
+	})
+
+	t.Run("invalid fields", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			desc    string
+			give    interface{}
+			wantErr string
+		}{
+			{
+				desc: "unsupported pattern",
+				give: func(struct {
+					dig.In
+
+					Handlers map[string]Handler `names:"user*"`
+				}) {
+				},
+				wantErr: `invalid value "user*" for "names" tag on field Handlers: only "*" and "sorted" are supported`,
+			},
+			{
+				desc: "not a map",
+				give: func(struct {
+					dig.In
+
+					Handlers []Handler `names:"*"`
+				}) {
+				},
+				wantErr: `named values may be consumed as maps keyed by string only: field "Handlers" ([]dig_test.Handler) is not a map[string]T`,
+			},
+			{
+				desc: "sorted not a slice",
+				give: func(struct {
+					dig.In
+
+					Handlers map[string]Handler `names:"sorted"`
+				}) {
+				},
+				wantErr: `named values may be consumed in sorted order as slices only: field "Handlers" (map[string]dig_test.Handler) is not a slice`,
+			},
+			{
+				desc: "sorted in group",
+				give: func(struct {
+					dig.In
+
+					Handlers []Handler `names:"sorted" group:"handlers"`
+				}) {
+				},
+				wantErr: `cannot use value groups with names:"sorted" on field "Handlers"`,
+			},
+			{
+				desc: "not keyed by string",
+				give: func(struct {
+					dig.In
+
+					Handlers map[int]Handler `names:"*"`
+				}) {
+				},
+				wantErr: "is not a map[string]T",
+			},
+			{
+				desc: "named",
+				give: func(struct {
+					dig.In
+
+					Handlers map[string]Handler `names:"*" name:"users"`
+				}) {
+				},
+				wantErr: `cannot use name with names:"*" on field "Handlers"`,
+			},
+			{
+				desc: "optional",
+				give: func(struct {
+					dig.In
+
+					Handlers map[string]Handler `names:"*" optional:"true"`
+				}) {
+				},
+				wantErr: `fields tagged with names:"*" cannot be optional`,
+			},
+		}
+
+		for _, tt := range tests {
+			err := digtest.New(t).Invoke(tt.give)
+			require.Error(t, err, tt.desc)
+			assert.Contains(t, err.Error(), tt.wantErr, tt.desc)
+		}
+	})
+}
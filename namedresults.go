@@ -0,0 +1,255 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"go.uber.org/dig/internal/dot"
+)
+
+// NamedResults may be returned by a constructor to provide named values
+// whose names are only known when the constructor is called. Each entry
+// provides its value under its name, with the type of the value.
+//
+//	c.Provide(func(cfg *Config) (dig.NamedResults, error) {
+//	  conns := make(dig.NamedResults)
+//	  for _, e := range cfg.Endpoints {
+//	    conn, err := dial(e.Addr)
+//	    if err != nil {
+//	      return nil, err
+//	    }
+//	    conns[e.Name] = conn
+//	  }
+//	  return conns, nil
+//	})
+//
+// NamedResults must be the only result of the constructor, apart from an
+// error, and cannot be used with the Name, Group, As, or ResultTags
+// options.
+//
+// Because the names and types of the values are not known when the
+// constructor is provided, it is only called when a named value is
+// requested that no other constructor provides, or when named values are
+// consumed with a names:"*" or names:"sorted" field. Its dependencies are
+// not checked for cycles through the values it provides, so it must not
+// depend, even indirectly, on those values. A value provided by another
+// constructor cannot be provided again through NamedResults.
+type NamedResults map[string]interface{}
+
+var _namedResultsType = reflect.TypeOf(NamedResults(nil))
+
+// resultNamedResults is a NamedResults returned by a constructor.
+//
+// It has no place in the graph: the values it produces are only known once
+// the constructor is called.
+type resultNamedResults struct{}
+
+var _ result = resultNamedResults{}
+
+// newResultNamedResults builds the result of a constructor of type ctype
+// that returns NamedResults.
+func newResultNamedResults(ctype reflect.Type, opts resultOptions) (resultNamedResults, error) {
+	var rn resultNamedResults
+	if !opts.NamedResults {
+		return rn, newErrInvalidInput(fmt.Sprintf(
+			"cannot return %v: only constructors may return it", _namedResultsType), nil)
+	}
+
+	for i := 0; i < ctype.NumOut(); i++ {
+		if t := ctype.Out(i); t != _namedResultsType && !isError(t) {
+			return rn, newErrInvalidInput(fmt.Sprintf(
+				"%v must be the only non-error result of %v: result %d is %v", _namedResultsType, ctype, i+1, t), nil)
+		}
+	}
+
+	var option string
+	switch {
+	case opts.Name != "":
+		option = "dig.Name"
+	case opts.Group != "":
+		option = "dig.Group"
+	case len(opts.As) > 0:
+		option = "dig.As"
+	case len(opts.ResultTags) > 0:
+		option = "dig.ResultTags"
+	}
+	if option != "" {
+		return rn, newErrInvalidInput(fmt.Sprintf(
+			"cannot use %v with %v: the names and types of the values are set by the constructor", option, _namedResultsType), nil)
+	}
+	return rn, nil
+}
+
+// DotResult returns nothing: the values that are produced are only known
+// when the constructor is called.
+func (resultNamedResults) DotResult() []*dot.Result {
+	return nil
+}
+
+func (resultNamedResults) Extract(cw containerWriter, _ bool, v reflect.Value) {
+	for name, value := range v.Interface().(NamedResults) {
+		rv := reflect.ValueOf(value)
+		cw.setValue(name, rv.Type(), rv)
+	}
+}
+
+// check verifies that every entry of the given NamedResults can be
+// provided.
+func (resultNamedResults) check(v reflect.Value) error {
+	results := v.Interface().(NamedResults)
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := results[name]
+		t := reflect.TypeOf(value)
+		switch {
+		case name == "":
+			return newErrInvalidInput(fmt.Sprintf(
+				"cannot provide %v with an empty name through %v", t, _namedResultsType), nil)
+		case t == nil:
+			return newErrInvalidInput(fmt.Sprintf(
+				"cannot provide nil as %q through %v: its type is unknown", name, _namedResultsType), nil)
+		case isError(t) || IsIn(t) || IsOut(t) || t == _namedResultsType:
+			return newErrInvalidInput(fmt.Sprintf(
+				"cannot provide %v as %q through %v", t, name, _namedResultsType), nil)
+		}
+	}
+	return nil
+}
+
+// returnsNamedResults reports whether the constructor returns NamedResults.
+func (rl resultList) returnsNamedResults() bool {
+	for _, r := range rl.Results {
+		if _, ok := r.(resultNamedResults); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Scope) getNamedResultsProviders() []provider {
+	providers := make([]provider, len(s.namedResultsCtors))
+	for i, n := range s.namedResultsCtors {
+		providers[i] = n
+	}
+	return providers
+}
+
+func (s *Scope) getNamedResultNames(t reflect.Type) []string {
+	defer s.lock()()
+	var names []string
+	for _, keys := range s.namedResults {
+		for _, k := range keys {
+			if k.t == t {
+				names = append(names, k.name)
+			}
+		}
+	}
+	return names
+}
+
+// commitNamedResults records the values that the given constructor, which
+// must be owned by this Scope, returned through NamedResults. It fails if
+// any of them is already provided by another constructor.
+func (s *Scope) commitNamedResults(n *constructorNode, values map[key]reflect.Value) error {
+	keys := make([]key, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+
+	defer s.lock()()
+	for _, k := range keys {
+		for _, ss := range s.ancestors() {
+			if ps := ss.providers[k]; len(ps) > 0 {
+				return newErrInvalidInput(fmt.Sprintf("cannot provide %v through %v", k, _namedResultsType),
+					newErrInvalidInput(fmt.Sprintf("already provided by %v", ps[0].Location()), nil))
+			}
+		}
+		if _, ok := s.values[k]; ok {
+			return newErrInvalidInput(fmt.Sprintf("cannot provide %v through %v", k, _namedResultsType),
+				newErrInvalidInput("already provided by another constructor", nil))
+		}
+	}
+
+	if s.namedResults == nil {
+		s.namedResults = make(map[*constructorNode][]key)
+	}
+	s.namedResults[n] = keys
+	return nil
+}
+
+// hasNamedResultsProviders reports whether any constructor that returns
+// NamedResults is visible to the given containerStore.
+func hasNamedResultsProviders(c containerStore) bool {
+	for _, s := range c.storesToRoot() {
+		if len(s.getNamedResultsProviders()) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// callNamedResultsProviders calls the constructors that return NamedResults
+// visible to the given containerStore, starting with those of the
+// containerStore itself, until fn reports that the value it looks for was
+// found in the store that the constructors provide to. Failures are
+// reported as failures to build the value with the given key.
+//
+// Constructors that are already being called are skipped, because they
+// cannot provide the values that their own dependencies require.
+func callNamedResultsProviders(c containerStore, k key, fn func(containerStore) bool) error {
+	for _, s := range c.storesToRoot() {
+		providers := s.getNamedResultsProviders()
+		for _, p := range providers {
+			if n, ok := p.(*constructorNode); ok && n.s.isCalling(n) {
+				continue
+			}
+			if err := p.Call(withCallFrame(p.OrigScope(), c)); err != nil {
+				return errParamSingleFailed{CtorID: p.ID(), Key: k, Reason: err}
+			}
+		}
+		if len(providers) > 0 && fn(s) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// buildNamedResult builds the value of ps from the constructors that return
+// NamedResults, and reports whether one of them provided it.
+func buildNamedResult(c containerStore, ps paramSingle) (v reflect.Value, found bool, err error) {
+	k := key{t: ps.Type, name: ps.Name}
+	err = callNamedResultsProviders(c, k, func(s containerStore) bool {
+		v, found = s.getValue(ps.Name, ps.Type)
+		return found
+	})
+	return v, found, err
+}
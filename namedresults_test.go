@@ -0,0 +1,289 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestNamedResults(t *testing.T) {
+	t.Parallel()
+
+	type Conn struct{ Addr string }
+
+	newConns := func(calls *int) func([]string) (dig.NamedResults, error) {
+		return func(addrs []string) (dig.NamedResults, error) {
+			*calls++
+			results := make(dig.NamedResults)
+			for _, addr := range addrs {
+				results[addr] = &Conn{Addr: addr}
+			}
+			results["count"] = len(addrs)
+			return results, nil
+		}
+	}
+
+	t.Run("named values", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			Primary   *Conn `name:"primary"`
+			Secondary *Conn `name:"secondary"`
+			Count     int   `name:"count"`
+		}
+
+		var calls int
+		c := digtest.New(t)
+		c.RequireProvide(func() []string { return []string{"primary", "secondary"} })
+		c.RequireProvide(newConns(&calls))
+
+		c.RequireInvoke(func(p params) {
+			assert.Equal(t, "primary", p.Primary.Addr)
+			assert.Equal(t, "secondary", p.Secondary.Addr)
+			assert.Equal(t, 2, p.Count)
+		})
+		c.RequireInvoke(func(p params) {})
+		assert.Equal(t, 1, calls, "constructor must be called once")
+	})
+
+	t.Run("all names", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			Conns  map[string]*Conn `names:"*"`
+			Sorted []*Conn          `names:"sorted"`
+		}
+
+		var calls int
+		c := digtest.New(t)
+		c.RequireProvide(func() []string { return []string{"b", "a"} })
+		c.RequireProvide(newConns(&calls))
+		c.RequireProvide(func() *Conn { return &Conn{Addr: "static"} }, dig.Name("c"))
+
+		c.RequireInvoke(func(p params) {
+			require.Len(t, p.Conns, 3)
+			assert.Equal(t, "a", p.Conns["a"].Addr)
+			assert.Equal(t, "b", p.Conns["b"].Addr)
+			assert.Equal(t, "static", p.Conns["c"].Addr)
+
+			require.Len(t, p.Sorted, 3)
+			assert.Equal(t, "a", p.Sorted[0].Addr)
+			assert.Equal(t, "b", p.Sorted[1].Addr)
+			assert.Equal(t, "static", p.Sorted[2].Addr)
+		})
+	})
+
+	t.Run("child scope", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			Conn *Conn `name:"a"`
+		}
+
+		var calls int
+		c := digtest.New(t)
+		c.RequireProvide(func() []string { return []string{"a"} })
+		c.RequireProvide(newConns(&calls))
+
+		child := c.Scope("child")
+		child.RequireInvoke(func(p params) {
+			assert.Equal(t, "a", p.Conn.Addr)
+		})
+	})
+
+	t.Run("missing name", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			Conn *Conn `name:"missing"`
+		}
+
+		var calls int
+		c := digtest.New(t)
+		c.RequireProvide(func() []string { return []string{"a"} })
+		c.RequireProvide(newConns(&calls))
+
+		err := c.Invoke(func(params) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `missing type: *dig_test.Conn[name="missing"]`)
+	})
+
+	t.Run("constructor fails", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			Conn *Conn `name:"a"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (dig.NamedResults, error) {
+			return nil, errors.New("great sadness")
+		})
+
+		err := c.Invoke(func(params) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "great sadness")
+	})
+
+	t.Run("invalid entries", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			Conn *Conn `name:"a"`
+		}
+
+		tests := []struct {
+			desc    string
+			results dig.NamedResults
+			wantErr string
+		}{
+			{
+				desc:    "empty name",
+				results: dig.NamedResults{"": &Conn{}},
+				wantErr: "cannot provide *dig_test.Conn with an empty name through dig.NamedResults",
+			},
+			{
+				desc:    "nil value",
+				results: dig.NamedResults{"a": nil},
+				wantErr: `cannot provide nil as "a" through dig.NamedResults: its type is unknown`,
+			},
+			{
+				desc:    "error value",
+				results: dig.NamedResults{"a": errors.New("great sadness")},
+				wantErr: `through dig.NamedResults`,
+			},
+		}
+
+		for _, tt := range tests {
+			tt := tt
+			t.Run(tt.desc, func(t *testing.T) {
+				t.Parallel()
+
+				c := digtest.New(t)
+				c.RequireProvide(func() dig.NamedResults { return tt.results })
+
+				err := c.Invoke(func(params) {})
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("conflicts with a provided value", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			Conns map[string]*Conn `names:"*"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Conn { return &Conn{} }, dig.Name("a"))
+		c.RequireProvide(func() dig.NamedResults {
+			return dig.NamedResults{"a": &Conn{}}
+		})
+
+		err := c.Invoke(func(params) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `cannot provide *dig_test.Conn[name="a"] through dig.NamedResults`)
+		assert.Contains(t, err.Error(), "already provided by")
+	})
+
+	t.Run("provide errors", func(t *testing.T) {
+		t.Parallel()
+
+		type out struct {
+			dig.Out
+
+			Results dig.NamedResults
+		}
+
+		tests := []struct {
+			desc    string
+			ctor    interface{}
+			opts    []dig.ProvideOption
+			wantErr string
+		}{
+			{
+				desc:    "other results",
+				ctor:    func() (dig.NamedResults, *Conn) { return nil, nil },
+				wantErr: "dig.NamedResults must be the only non-error result of func() (dig.NamedResults, *dig_test.Conn): result 2 is *dig_test.Conn",
+			},
+			{
+				desc:    "name",
+				ctor:    func() dig.NamedResults { return nil },
+				opts:    []dig.ProvideOption{dig.Name("a")},
+				wantErr: "cannot use dig.Name with dig.NamedResults",
+			},
+			{
+				desc:    "group",
+				ctor:    func() dig.NamedResults { return nil },
+				opts:    []dig.ProvideOption{dig.Group("a")},
+				wantErr: "cannot use dig.Group with dig.NamedResults",
+			},
+			{
+				desc:    "result object field",
+				ctor:    func() out { return out{} },
+				wantErr: "cannot provide dig.NamedResults here: it must be the only non-error result of a constructor",
+			},
+		}
+
+		for _, tt := range tests {
+			tt := tt
+			t.Run(tt.desc, func(t *testing.T) {
+				t.Parallel()
+
+				err := digtest.New(t).Provide(tt.ctor, tt.opts...)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("decorate", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Conn { return &Conn{} })
+		err := c.Decorate(func(*Conn) dig.NamedResults { return nil })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot return dig.NamedResults: only constructors may return it")
+	})
+}
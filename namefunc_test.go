@@ -0,0 +1,159 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestNameFunc(t *testing.T) {
+	t.Parallel()
+
+	type Conn struct{ shard string }
+	type Stats struct{ shard string }
+
+	prefix := func(p string) func(reflect.Type) string {
+		return func(t reflect.Type) string {
+			return p + strings.ToLower(t.Elem().Name())
+		}
+	}
+
+	t.Run("names every result", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		for _, shard := range []string{"a", "b"} {
+			shard := shard
+			c.RequireProvide(func() (*Conn, *Stats) {
+				return &Conn{shard: shard}, &Stats{shard: shard}
+			}, dig.NameFunc(prefix("shard-"+shard+"-")))
+		}
+
+		type params struct {
+			dig.In
+
+			ConnA  *Conn  `name:"shard-a-conn"`
+			ConnB  *Conn  `name:"shard-b-conn"`
+			StatsB *Stats `name:"shard-b-stats"`
+		}
+		c.RequireInvoke(func(p params) {
+			assert.Equal(t, "a", p.ConnA.shard)
+			assert.Equal(t, "b", p.ConnB.shard)
+			assert.Equal(t, "b", p.StatsB.shard)
+		})
+	})
+
+	t.Run("empty name", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Conn { return &Conn{} },
+			dig.NameFunc(func(reflect.Type) string { return "" }))
+		c.RequireInvoke(func(*Conn) {})
+	})
+
+	t.Run("result object fields", func(t *testing.T) {
+		t.Parallel()
+
+		type out struct {
+			dig.Out
+
+			Conn    *Conn
+			Stats   *Stats `name:"stats"`
+			Grouped *Conn  `group:"conns"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() out {
+			return out{Conn: &Conn{}, Stats: &Stats{}, Grouped: &Conn{}}
+		}, dig.NameFunc(prefix("x-")))
+
+		type params struct {
+			dig.In
+
+			Conn  *Conn   `name:"x-conn"`
+			Stats *Stats  `name:"stats"`
+			Conns []*Conn `group:"conns"`
+		}
+		c.RequireInvoke(func(p params) {
+			assert.NotNil(t, p.Conn)
+			assert.NotNil(t, p.Stats)
+			assert.Len(t, p.Conns, 1)
+		})
+	})
+
+	t.Run("invalid combinations", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			desc string
+			opt  dig.ProvideOption
+			err  string
+		}{
+			{
+				desc: "Name",
+				opt:  dig.Name("foo"),
+				err:  "cannot use dig.NameFunc with dig.Name",
+			},
+			{
+				desc: "Group",
+				opt:  dig.Group("foo"),
+				err:  `cannot use named values with value groups: dig.NameFunc provided with group:"foo"`,
+			},
+			{
+				desc: "ResultTags",
+				opt:  dig.ResultTags(`name:"foo"`),
+				err:  "cannot use dig.ResultTags with dig.NameFunc",
+			},
+		}
+
+		for _, tt := range tests {
+			tt := tt
+			t.Run(tt.desc, func(t *testing.T) {
+				t.Parallel()
+
+				c := digtest.New(t)
+				err := c.Provide(func() *Conn { return &Conn{} },
+					dig.NameFunc(prefix("x-")), tt.opt)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+			})
+		}
+	})
+
+	t.Run("backquote in name", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Provide(func() *Conn { return &Conn{} },
+			dig.NameFunc(func(reflect.Type) string { return "a`b" }))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			"invalid name \"a`b\" for *dig_test.Conn returned by dig.NameFunc: names cannot contain backquotes")
+	})
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// OmitNilFromGroup is a ProvideOption that leaves nil values out of the
+// value groups that a constructor provides to. This allows a constructor to
+// decide at runtime that it has nothing to contribute to a value group.
+//
+//	c.Provide(func(cfg *Config) Route {
+//	  if !cfg.AdminEnabled {
+//	    return nil
+//	  }
+//	  return newAdminRoute()
+//	}, dig.Group("routes"), dig.OmitNilFromGroup())
+//
+// Only values of pointer and interface types are left out; this applies to
+// the constructor's results and to the grouped fields of its dig.Out
+// structs. Individual fields of dig.Out structs may request the same with
+// the omitnil option of their group tag.
+//
+//	type Result struct {
+//	  dig.Out
+//
+//	  Route Route `group:"routes,omitnil"`
+//	}
+//
+// Flattened groups never need this: a nil slice adds no values to a group.
+// Constructors that left out values are listed in the Omitted field of the
+// value group's GroupSnapshot.
+func OmitNilFromGroup() ProvideOption {
+	return provideOmitNilFromGroupOption{}
+}
+
+type provideOmitNilFromGroupOption struct{}
+
+func (provideOmitNilFromGroupOption) String() string {
+	return "OmitNilFromGroup()"
+}
+
+func (provideOmitNilFromGroupOption) applyProvideOption(opts *provideOptions) {
+	opts.OmitNilFromGroup = true
+}
+
+// omitsNil reports whether nil values of type t are left out of the value
+// group g, or returns an error if the omitnil option of g cannot be applied
+// to t.
+func omitsNil(g group, t reflect.Type, opts resultOptions) (bool, error) {
+	nillable := t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface
+	if g.OmitNil {
+		switch {
+		case g.Flatten:
+			return false, newErrInvalidInput(fmt.Sprintf(
+				"cannot use %s with flatten: nil slices add no values to group %q", _groupOmitNil, g.Name), nil)
+		case !nillable:
+			return false, newErrInvalidInput(fmt.Sprintf(
+				"%s can be applied to pointers and interfaces only: %v is neither", _groupOmitNil, t), nil)
+		}
+		return true, nil
+	}
+	return opts.OmitNilFromGroup && !g.Flatten && nillable, nil
+}
+
+// isNilValue reports whether v is a nil pointer or interface.
+func isNilValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestOmitNilFromGroup(t *testing.T) {
+	t.Parallel()
+
+	type Route interface{ Path() string }
+
+	type params struct {
+		dig.In
+
+		Routes []Route `group:"routes"`
+	}
+
+	t.Run("option", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() Route { return staticRoute("/users") },
+			dig.Group("routes"), dig.OmitNilFromGroup())
+		c.RequireProvide(func() Route { return nil },
+			dig.Group("routes"), dig.OmitNilFromGroup())
+		c.RequireInvoke(func(p params) {
+			require.Len(t, p.Routes, 1)
+			assert.Equal(t, "/users", p.Routes[0].Path())
+		})
+	})
+
+	t.Run("without option", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() Route { return nil }, dig.Group("routes"))
+		c.RequireInvoke(func(p params) {
+			assert.Equal(t, []Route{nil}, p.Routes)
+		})
+	})
+
+	t.Run("result object", func(t *testing.T) {
+		t.Parallel()
+
+		type result struct {
+			dig.Out
+
+			Route    Route        `group:"routes"`
+			Optional Route        `group:"routes,omitnil"`
+			Routes   []Route      `group:"routes,flatten"`
+			Count    int          `group:"counts"`
+			Pointer  *staticRoute `group:"handlers"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() result {
+			return result{Route: staticRoute("/users")}
+		})
+		c.RequireProvide(func() result {
+			return result{Route: staticRoute("/orders")}
+		}, dig.OmitNilFromGroup())
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Routes   []Route        `group:"routes"`
+			Counts   []int          `group:"counts"`
+			Handlers []*staticRoute `group:"handlers"`
+		}) {
+			assert.ElementsMatch(t, []Route{staticRoute("/users"), staticRoute("/orders")}, p.Routes)
+			assert.Equal(t, []int{0, 0}, p.Counts)
+			assert.Equal(t, []*staticRoute{nil}, p.Handlers)
+		})
+	})
+
+	t.Run("recorded in snapshot", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() Route { return nil },
+			dig.Group("routes"), dig.OmitNilFromGroup())
+		c.RequireInvoke(func(params) {})
+
+		snap := dig.DebugSnapshot(c.Container)
+		require.Len(t, snap.Root.Providers, 1)
+		assert.Equal(t, []dig.GroupSnapshot{
+			{
+				Type:       "dig_test.Route",
+				Group:      "routes",
+				Omitted:    []dig.ID{snap.Root.Providers[0].ID},
+				Registered: []dig.ID{snap.Root.Providers[0].ID},
+			},
+		}, snap.Root.Groups)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			desc    string
+			give    interface{}
+			wantErr string
+		}{
+			{
+				desc: "not nillable",
+				give: func() (out struct {
+					dig.Out
+
+					Count int `group:"counts,omitnil"`
+				}) {
+					return
+				},
+				wantErr: `omitnil can be applied to pointers and interfaces only: int is neither`,
+			},
+			{
+				desc: "flatten",
+				give: func() (out struct {
+					dig.Out
+
+					Routes []Route `group:"routes,flatten,omitnil"`
+				}) {
+					return
+				},
+				wantErr: `cannot use omitnil with flatten: nil slices add no values to group "routes"`,
+			},
+			{
+				desc: "consumer",
+				give: func(struct {
+					dig.In
+
+					Routes []Route `group:"routes,omitnil"`
+				}) int {
+					return 0
+				},
+				wantErr: `cannot use omitnil in parameter value groups`,
+			},
+		}
+
+		for _, tt := range tests {
+			err := digtest.New(t).Provide(tt.give)
+			require.Error(t, err, tt.desc)
+			assert.Contains(t, err.Error(), tt.wantErr, tt.desc)
+		}
+	})
+}
+
+type staticRoute string
+
+func (r staticRoute) Path() string { return string(r) }
@@ -23,6 +23,7 @@ package dig
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -42,6 +43,17 @@ import (
 //	              A slice consuming a value group. This will receive all
 //	              values produced with a `group:".."` tag with the same name
 //	              as a slice.
+//	paramContextValue
+//	              A value read from the context given to a context-aware
+//	              Invoke, requested with a `fromctx:".."` tag.
+//	paramDynamic  A func() T that resolves T every time it is called,
+//	              requested with a `dynamic:"true"` tag.
+//	paramNamedMap A map[string]T of all named values of type T, requested
+//	              with a `names:"*"` tag.
+//	paramCleanup  The func(func()) used to register cleanup functions.
+//	paramResolver A Resolver for the Scope that builds the parameters.
+//	paramRunMode  The RunMode of the container.
+//	paramCallInfo The CallInfo of the constructor being called.
 type param interface {
 	fmt.Stringer
 
@@ -60,15 +72,15 @@ var (
 	_ param = paramObject{}
 	_ param = paramList{}
 	_ param = paramGroupedSlice{}
+	_ param = paramContextValue{}
 )
 
 // newParam builds a param from the given type. If the provided type is a
 // dig.In struct, an paramObject will be returned.
 func newParam(t reflect.Type, c containerStore) (param, error) {
 	switch {
-	case IsOut(t) || (t.Kind() == reflect.Ptr && IsOut(t.Elem())) || embedsType(t, _outPtrType):
-		return nil, newErrInvalidInput(fmt.Sprintf(
-			"cannot depend on result objects: %v embeds a dig.Out", t), nil)
+	case isResultObject(t):
+		return nil, errMisplacedObject{Type: t, Marker: _outType}
 	case IsIn(t):
 		return newParamObject(t, c)
 	case embedsType(t, _inPtrType):
@@ -77,11 +89,40 @@ func newParam(t reflect.Type, c containerStore) (param, error) {
 	case t.Kind() == reflect.Ptr && IsIn(t.Elem()):
 		return nil, newErrInvalidInput(fmt.Sprintf(
 			"cannot depend on a pointer to a parameter object, use a value instead: %v is a pointer to a struct that embeds dig.In", t), nil)
+	case t == _cleanupFuncType:
+		return paramCleanup{}, nil
+	case t == _resolverType:
+		return paramResolver{}, nil
+	case t == _runModeType:
+		return paramRunMode{}, nil
+	case t == _callInfoType:
+		return newParamCallInfo(c)
+	case isNamed(t):
+		return newNamedParam(t), nil
 	default:
 		return paramSingle{Type: t}, nil
 	}
 }
 
+// describeParam names the kind of a param that is not a plain value, for
+// error messages.
+func describeParam(p param) string {
+	switch p.(type) {
+	case paramObject:
+		return "a parameter object"
+	case paramResolver:
+		return "a Resolver"
+	case paramCallInfo:
+		return "a CallInfo"
+	case paramCleanup:
+		return "the function that registers cleanup functions"
+	case paramRunMode:
+		return "the RunMode"
+	default:
+		return "not a plain value"
+	}
+}
+
 // paramList holds all arguments of the constructor as params.
 //
 // NOTE: Build() MUST NOT be called on paramList. Instead, BuildList
@@ -136,6 +177,45 @@ func newParamList(ctype reflect.Type, c containerStore) (paramList, error) {
 	return pl, nil
 }
 
+// withTags returns a copy of this paramList with the given struct tags
+// applied to its positional parameters, as if each parameter were a field of
+// a dig.In struct.
+func (pl paramList) withTags(tags []string, c containerStore) (paramList, error) {
+	if len(tags) > len(pl.Params) {
+		return pl, newErrInvalidInput(fmt.Sprintf(
+			"cannot apply %d parameter tags to %v: it has %d parameters", len(tags), pl.ctype, len(pl.Params)), nil)
+	}
+
+	params := make([]param, len(pl.Params))
+	copy(params, pl.Params)
+	for i, tag := range tags {
+		if tag == "" {
+			continue
+		}
+
+		t := pl.ctype.In(i)
+		if _, ok := params[i].(paramSingle); !ok {
+			return pl, newErrInvalidInput(fmt.Sprintf(
+				"cannot apply tags to argument %d: %v is %v, tags can only be applied to plain values",
+				i+1, t, describeParam(params[i])), nil)
+		}
+
+		f := reflect.StructField{
+			Name: fmt.Sprintf("Arg%d", i+1),
+			Type: t,
+			Tag:  reflect.StructTag(tag),
+		}
+		pof, err := newParamObjectField(i, f, c)
+		if err != nil {
+			return pl, newErrInvalidInput(fmt.Sprintf("bad tags for argument %d", i+1), err)
+		}
+		params[i] = pof.Param
+	}
+
+	pl.Params = params
+	return pl, nil
+}
+
 func (pl paramList) Build(containerStore) (reflect.Value, error) {
 	digerror.BugPanicf("paramList.Build() must never be called")
 	panic("") // Unreachable, as BugPanicf above will panic.
@@ -144,6 +224,10 @@ func (pl paramList) Build(containerStore) (reflect.Value, error) {
 // BuildList returns an ordered list of values which may be passed directly
 // to the underlying constructor.
 func (pl paramList) BuildList(c containerStore) ([]reflect.Value, error) {
+	if len(pl.Params) == 0 {
+		return nil, nil
+	}
+
 	args := make([]reflect.Value, len(pl.Params))
 	for i, p := range pl.Params {
 		var err error
@@ -163,6 +247,9 @@ type paramSingle struct {
 	Name     string
 	Optional bool
 	Type     reflect.Type
+
+	// If non-nil, the value is consumed wrapped in this Named type.
+	Wrapper reflect.Type
 }
 
 func (ps paramSingle) DotParam() []*dot.Param {
@@ -233,7 +320,7 @@ func (ps paramSingle) buildWithDecorators(c containerStore) (v reflect.Value, fo
 	if !found || d == nil {
 		return _noValue, false, nil
 	}
-	if err = d.Call(decoratingScope); err != nil {
+	if err = d.Call(withCallFrame(decoratingScope, c)); err != nil {
 		v, err = _noValue, errParamSingleFailed{
 			CtorID: 1,
 			Key:    key{t: ps.Type, name: ps.Name},
@@ -245,20 +332,44 @@ func (ps paramSingle) buildWithDecorators(c containerStore) (v reflect.Value, fo
 	return
 }
 
-func (ps paramSingle) Build(c containerStore) (reflect.Value, error) {
+func (ps paramSingle) Build(c containerStore) (v reflect.Value, err error) {
+	if mws := c.paramResolverMiddleware(); len(mws) > 0 {
+		v, err = ps.resolve(c, mws)
+	} else {
+		v, err = ps.build(c)
+	}
+	if err != nil {
+		return v, err
+	}
+	return ps.wrap(v), nil
+}
+
+func (ps paramSingle) build(c containerStore) (reflect.Value, error) {
+	if ps.Optional && c.buildsOptionalsLazily() && !ps.isBuilt(c) {
+		return reflect.Zero(ps.Type), nil
+	}
+
 	v, found, err := ps.buildWithDecorators(c)
 	if found {
-		return v, err
+		if err != nil {
+			return v, err
+		}
+		return ps.found(c, v)
 	}
 
 	// Check whether the value is a decorated value first.
 	if v, ok := ps.getDecoratedValue(c); ok {
-		return v, nil
+		return ps.found(c, v)
 	}
 
 	// Starting at the given container and working our way up its parents,
 	// find one that provides this dependency.
 	//
+	// The nearest container that has either a cached value or a provider
+	// wins, so a provider in a child scope shadows a value that its parent
+	// already built. A container's own cached value always came from one of
+	// its own providers, so it is checked before calling them.
+	//
 	// Once found, we'll use that container for the rest of the invocation.
 	// Dependencies of this type will begin searching at that container,
 	// rather than starting at base.
@@ -267,7 +378,7 @@ func (ps paramSingle) Build(c containerStore) (reflect.Value, error) {
 	for _, container := range c.storesToRoot() {
 		// first check if the scope already has cached a value for the type.
 		if v, ok := container.getValue(ps.Name, ps.Type); ok {
-			return v, nil
+			return ps.found(c, v)
 		}
 		providers = container.getValueProviders(ps.Name, ps.Type)
 		if len(providers) > 0 {
@@ -277,6 +388,24 @@ func (ps paramSingle) Build(c containerStore) (reflect.Value, error) {
 	}
 
 	if len(providers) == 0 {
+		if ps.Name != "" {
+			v, found, err := buildNamedResult(c, ps)
+			if err != nil {
+				return _noValue, err
+			}
+			if found {
+				c.recordBuildError(key{t: ps.Type, name: ps.Name}, nil)
+				return ps.found(c, v)
+			}
+		}
+
+		dt, err := ps.derivedType(c)
+		if err != nil {
+			return _noValue, err
+		}
+		if dt != nil {
+			return ps.buildDerived(c, dt)
+		}
 		if ps.Optional {
 			return reflect.Zero(ps.Type), nil
 		}
@@ -284,11 +413,21 @@ func (ps paramSingle) Build(c containerStore) (reflect.Value, error) {
 	}
 
 	for _, n := range providers {
-		err := n.Call(n.OrigScope())
+		// Calling the provider of an optional dependency on a cycle that is
+		// being built would wait for the cycle to complete, so the cycle is
+		// broken here with the zero value.
+		if cn, ok := n.(*constructorNode); ok && ps.Optional && cn.closesCycle() {
+			return reflect.Zero(ps.Type), nil
+		}
+
+		err := n.Call(withCallFrame(n.OrigScope(), c))
 		if err == nil {
 			continue
 		}
 
+		k := key{t: ps.Type, name: ps.Name}
+		c.recordBuildError(k, err)
+
 		// If we're missing dependencies but the parameter itself is optional,
 		// we can just move on.
 		if _, ok := err.(errMissingDependencies); ok && ps.Optional {
@@ -297,14 +436,51 @@ func (ps paramSingle) Build(c containerStore) (reflect.Value, error) {
 
 		return _noValue, errParamSingleFailed{
 			CtorID: n.ID(),
-			Key:    key{t: ps.Type, name: ps.Name},
+			Key:    k,
 			Reason: err,
 		}
 	}
 
-	// If we get here, it's impossible for the value to be absent from the
-	// container.
-	v, _ = providingContainer.getValue(ps.Name, ps.Type)
+	// If we get here, the value is only absent from the container if it
+	// was omitted by an optional result, or if its constructor failed and
+	// reported the failure to a value group. It may also have been moved to
+	// an ancestor with Promote.
+	var ok bool
+	for _, container := range providingContainer.storesToRoot() {
+		if v, ok = container.getValue(ps.Name, ps.Type); ok {
+			break
+		}
+	}
+	k := key{t: ps.Type, name: ps.Name}
+	if !ok {
+		n := providers[len(providers)-1]
+		var reason error = errValueOmitted{Func: n.Location()}
+		if cn, ok := n.(*constructorNode); ok {
+			if err := cn.s.reportedError(cn); err != nil {
+				c.recordBuildError(k, err)
+				reason = err
+			}
+		}
+		if ps.Optional {
+			return reflect.Zero(ps.Type), nil
+		}
+		return _noValue, errParamSingleFailed{
+			CtorID: n.ID(),
+			Key:    k,
+			Reason: reason,
+		}
+	}
+	c.recordBuildError(k, nil)
+	return ps.found(c, v)
+}
+
+// found records that the value of this param was read from the container
+// and returns it.
+func (ps paramSingle) found(c containerStore, v reflect.Value) (reflect.Value, error) {
+	recordConsumed(c, ps.Type)
+	if c.copiesSharedValues() {
+		v = shallowCopy(v)
+	}
 	return v, nil
 }
 
@@ -312,8 +488,13 @@ func (ps paramSingle) Build(c containerStore) (reflect.Value, error) {
 //
 // This object is not expected in the graph as-is.
 type paramObject struct {
-	Type        reflect.Type
-	Fields      []paramObjectField
+	Type   reflect.Type
+	Fields []paramObjectField
+
+	// Indexes of Fields in the order they are built: required fields
+	// first, so that a missing or failing dependency fails the object
+	// before the constructors of its optional siblings are called, then
+	// optional fields, then soft value groups.
 	FieldOrders []int
 }
 
@@ -333,12 +514,21 @@ func (po paramObject) String() string {
 	return strings.Join(fields, " ")
 }
 
-// getParamOrder returns the order(s) of a parameter type.
-func getParamOrder(gh *graphHolder, param param) []int {
+// getParamOrder returns the order(s) of a parameter type. Optional
+// parameters are left out unless withOptional is set.
+func getParamOrder(gh *graphHolder, param param, withOptional bool) []int {
 	var orders []int
 	switch p := param.(type) {
 	case paramSingle:
+		if p.Optional && !withOptional {
+			break
+		}
 		providers := gh.s.getAllValueProviders(p.Name, p.Type)
+		if len(providers) == 0 {
+			if dt, _ := p.derivedType(gh.s); dt != nil {
+				providers = gh.s.getAllValueProviders(p.Name, dt)
+			}
+		}
 		for _, provider := range providers {
 			orders = append(orders, provider.Order(gh.s))
 		}
@@ -346,9 +536,11 @@ func getParamOrder(gh *graphHolder, param param) []int {
 		// value group parameters have nodes of their own.
 		// We can directly return that here.
 		orders = append(orders, p.orders[gh.s])
+	case paramNamedMap:
+		orders = append(orders, p.orders[gh.s])
 	case paramObject:
 		for _, pf := range p.Fields {
-			orders = append(orders, getParamOrder(gh, pf.Param)...)
+			orders = append(orders, getParamOrder(gh, pf.Param, withOptional)...)
 		}
 	}
 	return orders
@@ -364,6 +556,9 @@ func newParamObject(t reflect.Type, c containerStore) (paramObject, error) {
 	for i := 0; i < t.NumField(); i++ {
 		f := t.Field(i)
 		if f.Type == _inType {
+			if err := checkTagTypos(f, c.tagKeys()); err != nil {
+				return po, err
+			}
 			var err error
 			ignoreUnexported, err = isIgnoreUnexportedSet(f)
 			if err != nil {
@@ -384,31 +579,66 @@ func newParamObject(t reflect.Type, c containerStore) (paramObject, error) {
 			continue
 		}
 		pof, err := newParamObjectField(i, f, c)
+		if e, ok := err.(errMisplacedObject); ok {
+			return po, e.inField(t, f.Name)
+		}
 		if err != nil {
 			return po, newErrInvalidInput(
 				fmt.Sprintf("bad field %q of %v", f.Name, t), err)
 		}
+		if pc, ok := pof.Param.(paramContextValue); ok {
+			pc.Field = fmt.Sprintf("%v.%v", t, f.Name)
+			pof.Param = pc
+		}
 		po.Fields = append(po.Fields, pof)
 	}
+	po.FieldOrders = fieldOrders(po.Fields, true /* optionalsLast */)
 	return po, nil
 }
 
+// fieldOrders returns the indexes of the given fields sorted by the order
+// they are built in, keeping the order they were declared in otherwise.
+//
+// Soft value groups are built after all other fields, to avoid cases when
+// a field calls a provider for a soft value group, but the value is not
+// provided to it because the value group is declared before the field. If
+// optionalsLast is set, optional fields are built after required ones.
+func fieldOrders(fields []paramObjectField, optionalsLast bool) []int {
+	rank := func(f paramObjectField) int {
+		switch p := f.Param.(type) {
+		case paramGroupedSlice:
+			if p.Soft {
+				return 2
+			}
+		case paramSingle:
+			if p.Optional && optionalsLast {
+				return 1
+			}
+		}
+		return 0
+	}
+
+	orders := make([]int, len(fields))
+	for i := range orders {
+		orders[i] = i
+	}
+	sort.SliceStable(orders, func(i, j int) bool {
+		return rank(fields[orders[i]]) < rank(fields[orders[j]])
+	})
+	return orders
+}
+
 func (po paramObject) Build(c containerStore) (reflect.Value, error) {
 	dest := reflect.New(po.Type).Elem()
-	// We have to build soft groups after all other fields, to avoid cases
-	// when a field calls a provider for a soft value group, but the value is
-	// not provided to it because the value group is declared before the field
-	var softGroupsQueue []paramObjectField
-	var fields []paramObjectField
-	for _, f := range po.Fields {
-		if p, ok := f.Param.(paramGroupedSlice); ok && p.Soft {
-			softGroupsQueue = append(softGroupsQueue, f)
-			continue
-		}
-		fields = append(fields, f)
+	orders := po.FieldOrders
+	if c.buildsOptionalsLazily() {
+		// Optional fields only receive values that were already built,
+		// possibly by the fields declared before them, so they must be
+		// built in the order they were declared.
+		orders = fieldOrders(po.Fields, false /* optionalsLast */)
 	}
-	fields = append(fields, softGroupsQueue...)
-	for _, f := range fields {
+	for _, i := range orders {
+		f := po.Fields[i]
 		v, err := f.Build(c)
 		if err != nil {
 			return dest, err
@@ -443,13 +673,42 @@ func newParamObjectField(idx int, f reflect.StructField, c containerStore) (para
 		FieldIndex: idx,
 	}
 
+	tags := c.tagKeys()
+	if err := checkTagTypos(f, tags); err != nil {
+		return pof, err
+	}
+	if err := checkGroupLabels(f, tags); err != nil {
+		return pof, err
+	}
+
 	var p param
 	switch {
 	case f.PkgPath != "":
 		return pof, newErrInvalidInput(
 			fmt.Sprintf("unexported fields not allowed in dig.In, did you mean to export %q (%v)?", f.Name, f.Type), nil)
 
-	case f.Tag.Get(_groupTag) != "":
+	case f.Tag.Get(_fromCtxTag) != "":
+		var err error
+		p, err = newParamContextValue(f, tags)
+		if err != nil {
+			return pof, err
+		}
+
+	case f.Tag.Get(_dynamicTag) != "":
+		var err error
+		p, err = newParamDynamic(f, c)
+		if err != nil {
+			return pof, err
+		}
+
+	case f.Tag.Get(_namesTag) != "":
+		var err error
+		p, err = newParamNamedMap(f, c)
+		if err != nil {
+			return pof, err
+		}
+
+	case f.Tag.Get(tags.group()) != "":
 		var err error
 		p, err = newParamGroupedSlice(f, c)
 		if err != nil {
@@ -465,7 +724,7 @@ func newParamObjectField(idx int, f reflect.StructField, c containerStore) (para
 	}
 
 	if ps, ok := p.(paramSingle); ok {
-		ps.Name = f.Tag.Get(_nameTag)
+		ps.Name = f.Tag.Get(tags.name())
 
 		var err error
 		ps.Optional, err = isFieldOptional(f)
@@ -495,19 +754,41 @@ type paramGroupedSlice struct {
 	Group string
 
 	// Type of the slice.
+	//
+	// If the group is consumed as a slice of GroupValue, this is the slice
+	// of the underlying values rather than the type of the field.
 	Type reflect.Type
 
+	// If non-nil, the group is consumed as a slice of this GroupValue type
+	// rather than a slice of Type.Elem().
+	EntryType reflect.Type
+
 	// Soft is used to denote a soft dependency between this param and its
 	// constructors, if it's true its constructors are only called if they
 	// provide another value requested in the graph
 	Soft bool
 
+	// Selects the values that are consumed by the GroupTag and the labels
+	// that they were provided with.
+	Filter groupFilter
+
+	// If set, a single value of the group, selected at random according to
+	// the weights of the constructors, is consumed instead of a slice.
+	// Type is still the slice type of the group.
+	Weighted bool
+
 	orders map[*Scope]int
 }
 
 func (pt paramGroupedSlice) String() string {
 	// io.Reader[group="foo"] refers to a group of io.Readers called 'foo'
-	return fmt.Sprintf("%v[group=%q]", pt.Type.Elem(), pt.Group)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v[group=%q%v", pt.Type.Elem(), pt.Group, pt.Filter)
+	if pt.Weighted {
+		fmt.Fprintf(&b, ", select=%v", _groupSelectWeighted)
+	}
+	b.WriteString("]")
+	return b.String()
 }
 
 func (pt paramGroupedSlice) DotParam() []*dot.Param {
@@ -526,9 +807,11 @@ func (pt paramGroupedSlice) DotParam() []*dot.Param {
 //
 // The type MUST be a slice type.
 func newParamGroupedSlice(f reflect.StructField, c containerStore) (paramGroupedSlice, error) {
-	g, err := parseGroupString(f.Tag.Get(_groupTag))
+	tags := c.tagKeys()
+	g, err := parseGroupString(f.Tag.Get(tags.group()))
 	if err != nil {
-		return paramGroupedSlice{}, err
+		return paramGroupedSlice{}, newErrInvalidInput(
+			fmt.Sprintf("cannot parse group %q of field %q", f.Tag.Get(tags.group()), f.Name), err)
 	}
 	pg := paramGroupedSlice{
 		Group:  g.Name,
@@ -536,22 +819,38 @@ func newParamGroupedSlice(f reflect.StructField, c containerStore) (paramGrouped
 		orders: make(map[*Scope]int),
 		Soft:   g.Soft,
 	}
+	if g.Select != "" {
+		pg.Weighted = true
+		pg.Type = reflect.SliceOf(f.Type)
+	}
+	if pg.Filter, err = newGroupFilter(g, f); err != nil {
+		return pg, err
+	}
 
-	name := f.Tag.Get(_nameTag)
+	name := f.Tag.Get(tags.name())
 	optional, _ := isFieldOptional(f)
 	switch {
-	case f.Type.Kind() != reflect.Slice:
+	case !pg.Weighted && f.Type.Kind() != reflect.Slice:
 		return pg, newErrInvalidInput(
 			fmt.Sprintf("value groups may be consumed as slices only: field %q (%v) is not a slice", f.Name, f.Type), nil)
+	case isResultObject(pg.Type.Elem()):
+		return pg, errMisplacedObject{Type: pg.Type.Elem(), Marker: _outType, Group: true}
 	case g.Flatten:
 		return pg, newErrInvalidInput(
 			fmt.Sprintf("cannot use flatten in parameter value groups: field %q (%v) specifies flatten", f.Name, f.Type), nil)
+	case g.OmitNil:
+		return pg, newErrInvalidInput(
+			fmt.Sprintf("cannot use %s in parameter value groups: field %q (%v) specifies %s", _groupOmitNil, f.Name, f.Type, _groupOmitNil), nil)
 	case name != "":
 		return pg, newErrInvalidInput(
 			fmt.Sprintf("cannot use named values with value groups: name:%q requested with group:%q", name, pg.Group), nil)
 	case optional:
 		return pg, newErrInvalidInput("value groups cannot be optional", nil)
 	}
+	if et := pg.Type.Elem(); isGroupValue(et) {
+		pg.EntryType = et
+		pg.Type = reflect.SliceOf(et.Field(0).Type)
+	}
 	c.newGraphNode(&pg, pg.orders)
 	return pg, nil
 }
@@ -578,14 +877,14 @@ func (pt paramGroupedSlice) getDecoratedValues(c containerStore) (reflect.Value,
 func (pt paramGroupedSlice) callGroupDecorators(c containerStore) error {
 	stores := c.storesToRoot()
 	for i := len(stores) - 1; i >= 0; i-- {
-		c := stores[i]
-		if d, found := c.getGroupDecorator(pt.Group, pt.Type.Elem()); found {
+		s := stores[i]
+		if d, found := s.getGroupDecorator(pt.Group, pt.Type.Elem()); found {
 			if d.State() == decoratorOnStack {
 				// This decorator is already being run. Avoid cycle
 				// and look further.
 				continue
 			}
-			if err := d.Call(c); err != nil {
+			if err := d.Call(withCallFrame(s, c)); err != nil {
 				return errParamGroupFailed{
 					CtorID: d.ID(),
 					Key:    key{group: pt.Group, t: pt.Type.Elem()},
@@ -598,27 +897,73 @@ func (pt paramGroupedSlice) callGroupDecorators(c containerStore) error {
 }
 
 // search the given container and its parent for matching group providers and
-// call them to commit values. If an error is encountered, return the number
-// of providers called and a non-nil error from the first provided.
+// call them to commit values. All providers are called even if some of them
+// fail. If exactly one provider fails, its error is returned. If more than
+// one fails, an error listing all failures is returned.
 func (pt paramGroupedSlice) callGroupProviders(c containerStore) (int, error) {
+	k := key{group: pt.Group, t: pt.Type.Elem()}
 	itemCount := 0
-	for _, c := range c.storesToRoot() {
-		providers := c.getGroupProviders(pt.Group, pt.Type.Elem())
+	var failures []errGroupMember
+	var conditional []provider
+	for _, s := range c.storesToRoot() {
+		providers := s.getGroupProviders(pt.Group, pt.Type.Elem())
 		itemCount += len(providers)
 		for _, n := range providers {
-			if err := n.Call(c); err != nil {
-				return 0, errParamGroupFailed{
-					CtorID: n.ID(),
-					Key:    key{group: pt.Group, t: pt.Type.Elem()},
-					Reason: err,
-				}
+			// Constructors provided with GroupUnless depend on the
+			// values of the other constructors, so they are called last.
+			if n.Conditional() {
+				conditional = append(conditional, n)
+				continue
 			}
+			failures = pt.callGroupProvider(c, n, failures)
+		}
+	}
+	for _, n := range conditional {
+		failures = pt.callGroupProvider(c, n, failures)
+	}
+
+	switch len(failures) {
+	case 0:
+		return itemCount, nil
+	case 1:
+		return 0, errParamGroupFailed{
+			CtorID: failures[0].CtorID,
+			Key:    k,
+			Reason: failures[0].Reason,
 		}
+	default:
+		return 0, errGroupMembersFailed{Key: k, Members: failures}
+	}
+}
+
+// callGroupProvider calls the given constructor of this group on behalf of
+// the call in progress in c, appending its failure, if any, to failures.
+func (pt paramGroupedSlice) callGroupProvider(c containerStore, n provider, failures []errGroupMember) []errGroupMember {
+	// Constructors provided with Export live in the root Scope, but build
+	// their dependencies in the Scope they were provided to, as with other
+	// values.
+	if err := n.Call(withCallFrame(n.OrigScope(), c)); err != nil {
+		failures = append(failures, errGroupMember{
+			CtorID: n.ID(),
+			Reason: err,
+		})
 	}
-	return itemCount, nil
+	return failures
 }
 
 func (pt paramGroupedSlice) Build(c containerStore) (reflect.Value, error) {
+	if pt.Weighted {
+		return pt.buildWeighted(c)
+	}
+
+	items, err := pt.build(c)
+	if err != nil || !c.copiesSharedValues() {
+		return items, err
+	}
+	return pt.copyShared(items), nil
+}
+
+func (pt paramGroupedSlice) build(c containerStore) (reflect.Value, error) {
 	// do not call this if we are already inside a decorator since
 	// it will result in an infinite recursion. (i.e. decorate -> params.BuildList() -> Decorate -> params.BuildList...)
 	// this is safe since a value can be decorated at most once in a given scope.
@@ -628,6 +973,18 @@ func (pt paramGroupedSlice) Build(c containerStore) (reflect.Value, error) {
 
 	// Check if we have decorated values
 	if decoratedItems, ok := pt.getDecoratedValues(c); ok {
+		if pt.Filter.Tag != "" {
+			return _noValue, newErrInvalidInput(fmt.Sprintf(
+				"cannot consume %v: decorated values have no tag", pt), nil)
+		}
+		if len(pt.Filter.Labels) > 0 {
+			return _noValue, newErrInvalidInput(fmt.Sprintf(
+				"cannot consume %v: decorated values have no labels", pt), nil)
+		}
+		recordConsumed(c, pt.Type)
+		if pt.EntryType != nil {
+			return pt.decoratedEntries(decoratedItems), nil
+		}
 		return decoratedItems, nil
 	}
 
@@ -641,17 +998,149 @@ func (pt paramGroupedSlice) Build(c containerStore) (reflect.Value, error) {
 			return _noValue, err
 		}
 	}
+	recordConsumed(c, pt.Type)
 
 	stores := c.storesToRoot()
+	et := pt.Type.Elem()
+	if pt.EntryType != nil || !pt.Filter.matchesAll() {
+		sliceType := pt.Type
+		if pt.EntryType != nil {
+			sliceType = reflect.SliceOf(pt.EntryType)
+		}
+		result := reflect.MakeSlice(sliceType, 0, itemCount)
+		for _, c := range stores {
+			for _, e := range c.getValueGroupEntries(pt.Group, et) {
+				if !e.Value.Type().AssignableTo(et) {
+					return _noValue, newErrGroupMemberType(pt.Group, et, e)
+				}
+				switch {
+				case !pt.Filter.matches(e.groupMember):
+					continue
+				case pt.EntryType != nil:
+					result = reflect.Append(result, newGroupValue(pt.EntryType, e))
+				default:
+					result = reflect.Append(result, e.Value)
+				}
+			}
+		}
+		return result, nil
+	}
+
 	result := reflect.MakeSlice(pt.Type, 0, itemCount)
 	for _, c := range stores {
-		result = reflect.Append(result, c.getValueGroup(pt.Group, pt.Type.Elem())...)
+		values := c.getValueGroup(pt.Group, et)
+		if err := pt.checkMemberTypes(c, values); err != nil {
+			return _noValue, err
+		}
+		result = reflect.Append(result, values...)
 	}
 	return result, nil
 }
 
-// Checks if ignoring unexported files in an In struct is allowed.
-// The struct field MUST be an _inType.
+// checkMemberTypes verifies that the given members of the group in c may
+// be appended to the slice that consumes it, so that a bad member is
+// reported along with its provider instead of panicking.
+func (pt paramGroupedSlice) checkMemberTypes(c containerStore, values []reflect.Value) error {
+	et := pt.Type.Elem()
+	for _, v := range values {
+		if v.Type().AssignableTo(et) {
+			continue
+		}
+		// Entries carry the provider of each member, but they are more
+		// expensive to get, so they are only read to report the error.
+		for _, e := range c.getValueGroupEntries(pt.Group, et) {
+			if !e.Value.Type().AssignableTo(et) {
+				return newErrGroupMemberType(pt.Group, et, e)
+			}
+		}
+	}
+	return nil
+}
+
+// Wraps decorated values of a group consumed as a slice of GroupValue.
+// Decorated values have no known provider.
+func (pt paramGroupedSlice) decoratedEntries(items reflect.Value) reflect.Value {
+	result := reflect.MakeSlice(reflect.SliceOf(pt.EntryType), 0, items.Len())
+	for i := 0; i < items.Len(); i++ {
+		result = reflect.Append(result, newGroupValue(pt.EntryType, groupEntry{Value: items.Index(i)}))
+	}
+	return result
+}
+
+// paramContextValue is a value read from the context passed to a
+// context-aware Invoke.
+type paramContextValue struct {
+	// Name of the context key as specified in the `fromctx:".."` tag.
+	Name string
+
+	// Type of the value.
+	Type reflect.Type
+
+	Optional bool
+
+	// Path of the field requesting this value, used in error messages.
+	Field string
+}
+
+func newParamContextValue(f reflect.StructField, tags tagKeys) (paramContextValue, error) {
+	pc := paramContextValue{
+		Name:  f.Tag.Get(_fromCtxTag),
+		Type:  f.Type,
+		Field: f.Name,
+	}
+	switch {
+	case f.Tag.Get(tags.name()) != "":
+		return pc, newErrInvalidInput(fmt.Sprintf(
+			"cannot use named values with context values: name:%q requested with fromctx:%q", f.Tag.Get(tags.name()), pc.Name), nil)
+	case f.Tag.Get(tags.group()) != "":
+		return pc, newErrInvalidInput(fmt.Sprintf(
+			"cannot use value groups with context values: group:%q requested with fromctx:%q", f.Tag.Get(tags.group()), pc.Name), nil)
+	}
+
+	var err error
+	pc.Optional, err = isFieldOptional(f)
+	return pc, err
+}
+
+func (pc paramContextValue) String() string {
+	if pc.Optional {
+		return fmt.Sprintf("%v[optional, fromctx=%q]", pc.Type, pc.Name)
+	}
+	return fmt.Sprintf("%v[fromctx=%q]", pc.Type, pc.Name)
+}
+
+// DotParam returns nothing: context values are not part of the graph.
+func (pc paramContextValue) DotParam() []*dot.Param {
+	return nil
+}
+
+func (pc paramContextValue) Build(c containerStore) (reflect.Value, error) {
+	cs, ok := c.(contextStore)
+	if !ok {
+		digerror.BugPanicf("paramContextValue.Build() called outside a context-aware Invoke for %v", pc.Field)
+	}
+
+	v := cs.ctx.Value(cs.keys[pc.Name])
+	if v == nil {
+		if pc.Optional {
+			return reflect.Zero(pc.Type), nil
+		}
+		return _noValue, errMissingContextValue{Field: pc.Field, Name: pc.Name}
+	}
+
+	rv := reflect.ValueOf(v)
+	if !rv.Type().AssignableTo(pc.Type) {
+		return _noValue, newErrInvalidInput(fmt.Sprintf(
+			"context value %q for field %v has type %v, which is not assignable to %v", pc.Name, pc.Field, rv.Type(), pc.Type), nil)
+	}
+
+	dest := reflect.New(pc.Type).Elem()
+	dest.Set(rv)
+	return dest, nil
+}
+
+// Checks if ignoring unexported files in an In or Out struct is allowed.
+// The struct field MUST be an _inType or an _outType.
 func isIgnoreUnexportedSet(f reflect.StructField) (bool, error) {
 	tag := f.Tag.Get(_ignoreUnexportedTag)
 	if tag == "" {
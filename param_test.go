@@ -21,12 +21,14 @@
 package dig
 
 import (
+	"errors"
 	"io"
 	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/dig/internal/digreflect"
 )
 
 func TestParamListBuild(t *testing.T) {
@@ -179,6 +181,97 @@ func TestParamObjectFailure(t *testing.T) {
 	})
 }
 
+func TestParamObjectFieldOrders(t *testing.T) {
+	type A struct{}
+	type B struct{}
+	type C struct{}
+	type in struct {
+		In
+
+		A1 A   `optional:"true"`
+		Bs []B `group:"bs,soft"`
+		A2 A   `name:"a2"`
+		Cs []C `group:"cs"`
+		B  B   `optional:"true"`
+		C  C
+	}
+
+	po, err := newParamObject(reflect.TypeOf(in{}), newScope())
+	require.NoError(t, err)
+
+	var names []string
+	for _, i := range po.FieldOrders {
+		names = append(names, po.Fields[i].FieldName)
+	}
+	assert.Equal(t, []string{"A2", "Cs", "C", "A1", "B", "Bs"}, names)
+}
+
+func TestParamObjectBuildRequiredFirst(t *testing.T) {
+	type Expensive struct{}
+	type Required struct{}
+	type Missing struct{}
+	type in struct {
+		In
+
+		Expensive *Expensive `optional:"true"`
+		Required  *Required
+	}
+
+	t.Run("required field fails", func(t *testing.T) {
+		c := New()
+		var called bool
+		require.NoError(t, c.Provide(func() *Expensive {
+			called = true
+			return &Expensive{}
+		}))
+		require.NoError(t, c.Provide(func() (*Required, error) {
+			return nil, errors.New("great sadness")
+		}))
+
+		err := c.Invoke(func(in) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "great sadness")
+		assert.False(t, called, "optional sibling must not be built")
+	})
+
+	t.Run("required field is missing a dependency", func(t *testing.T) {
+		c := New()
+		var called bool
+		require.NoError(t, c.Provide(func() *Expensive {
+			called = true
+			return &Expensive{}
+		}))
+		require.NoError(t, c.Provide(func(*Missing) *Required { return &Required{} }))
+
+		err := c.Invoke(func(in) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: *dig.Missing")
+		assert.False(t, called, "optional sibling must not be built")
+	})
+
+	t.Run("all fields are set", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() *Expensive { return &Expensive{} }))
+		require.NoError(t, c.Provide(func() *Required { return &Required{} }))
+
+		require.NoError(t, c.Invoke(func(p in) {
+			assert.NotNil(t, p.Expensive)
+			assert.NotNil(t, p.Required)
+		}))
+	})
+
+	t.Run("lazy optionals keep declaration order", func(t *testing.T) {
+		c := New(LazyOptionals())
+		require.NoError(t, c.Provide(func() *Expensive { return &Expensive{} }))
+		require.NoError(t, c.Provide(func(*Expensive) *Required { return &Required{} }))
+
+		require.NoError(t, c.Invoke(func(p in) {
+			assert.Nil(t, p.Expensive, "optional field must not see values built by later fields")
+			assert.NotNil(t, p.Required)
+		}))
+	})
+}
+
 func TestParamGroupSliceErrors(t *testing.T) {
 	tests := []struct {
 		desc    string
@@ -233,3 +326,50 @@ func TestParamGroupSliceErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestParamGroupSliceMemberTypes(t *testing.T) {
+	badProvider := func() int { return 42 }
+
+	newBadScope := func() *Scope {
+		s := New().scope
+		s.submitGroupedValueFrom("closers", reflect.TypeOf((*io.Closer)(nil)).Elem(), reflect.ValueOf(42),
+			groupMember{Source: digreflect.InspectFunc(badProvider)})
+		return s
+	}
+
+	tests := []struct {
+		desc  string
+		shape interface{}
+	}{
+		{
+			desc: "slice",
+			shape: struct {
+				In
+
+				Closers []io.Closer `group:"closers"`
+			}{},
+		},
+		{
+			desc: "group values",
+			shape: struct {
+				In
+
+				Closers []GroupValue[io.Closer] `group:"closers"`
+			}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			s := newBadScope()
+			po, err := newParamObject(reflect.TypeOf(tt.shape), s)
+			require.NoError(t, err)
+
+			_, err = po.Build(s)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(),
+				`could not build value group io.Closer[group="closers"]: value of type int is not assignable to io.Closer`)
+			assert.Contains(t, err.Error(), `provided by function "go.uber.org/dig".TestParamGroupSliceMemberTypes.func1`)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ParamKey identifies a value that a function depends on.
+type ParamKey struct {
+	// Type of the value.
+	Type reflect.Type
+
+	// Name of the value, if any.
+	Name string
+
+	// Optional reports whether the dependency is optional.
+	Optional bool
+}
+
+// ResolveFunc resolves the value of a dependency.
+type ResolveFunc func(ParamKey) (reflect.Value, error)
+
+// WithParamResolver is an [Option] that wraps the resolution of every
+// dependency on a single value with the given middleware. The middleware
+// receives the key of each value that a constructor, decorator, or invoked
+// function depends on, and the next ResolveFunc, which resolves it as the
+// Container normally would.
+//
+// Use this to intercept all resolutions of a type across the Container,
+// for example to replace it with a mock in tests:
+//
+//	c := dig.New(dig.WithParamResolver(func(next dig.ResolveFunc) dig.ResolveFunc {
+//	  return func(k dig.ParamKey) (reflect.Value, error) {
+//	    if k.Type == reflect.TypeOf((*Clock)(nil)).Elem() {
+//	      return reflect.ValueOf(fakeClock), nil
+//	    }
+//	    return next(k)
+//	  }
+//	}))
+//
+// The middleware may call next with a different key to resolve another
+// value instead. The value it returns must be assignable to the type of
+// the key it was given. Dependencies are still checked against the
+// providers of the Container before the middleware is called, so values
+// that the middleware replaces must have a provider too. Values of value
+// groups are not resolved through the middleware.
+//
+// If the option is given more than once, the first middleware is the
+// outermost one.
+func WithParamResolver(mw func(next ResolveFunc) ResolveFunc) Option {
+	return paramResolverOption{mw: mw}
+}
+
+type paramResolverOption struct {
+	mw func(next ResolveFunc) ResolveFunc
+}
+
+func (o paramResolverOption) String() string {
+	return fmt.Sprintf("WithParamResolver(%p)", o.mw)
+}
+
+func (o paramResolverOption) applyOption(c *Container) {
+	c.scope.paramResolvers = append(c.scope.paramResolvers, o.mw)
+}
+
+func (s *Scope) paramResolverMiddleware() []func(ResolveFunc) ResolveFunc {
+	return s.rootScope().paramResolvers
+}
+
+// resolve builds the value of this param through the middleware set with
+// WithParamResolver.
+func (ps paramSingle) resolve(c containerStore, mws []func(ResolveFunc) ResolveFunc) (reflect.Value, error) {
+	next := func(k ParamKey) (reflect.Value, error) {
+		return paramSingle{Name: k.Name, Type: k.Type, Optional: k.Optional}.build(c)
+	}
+	for i := len(mws) - 1; i >= 0; i-- {
+		next = mws[i](next)
+	}
+
+	k := ParamKey{Type: ps.Type, Name: ps.Name, Optional: ps.Optional}
+	v, err := next(k)
+	if err != nil {
+		return _noValue, err
+	}
+	if !v.IsValid() || !v.Type().AssignableTo(ps.Type) {
+		return _noValue, newErrInvalidInput(fmt.Sprintf(
+			"param resolver returned %v for %v: it is not assignable to %v", describeValue(v), ps, ps.Type), nil)
+	}
+	return v, nil
+}
+
+// describeValue returns the type of the given value, or "no value" if it
+// is the zero Value.
+func describeValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return "no value"
+	}
+	return "a value of type " + v.Type().String()
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestWithParamResolver(t *testing.T) {
+	t.Parallel()
+
+	type clock interface{ Now() int }
+	type service struct{ c clock }
+
+	clockType := reflect.TypeOf((*clock)(nil)).Elem()
+
+	// mockClock replaces all dependencies on clock.
+	mockClock := func(c clock) dig.Option {
+		return dig.WithParamResolver(func(next dig.ResolveFunc) dig.ResolveFunc {
+			return func(k dig.ParamKey) (reflect.Value, error) {
+				if k.Type == clockType {
+					return reflect.ValueOf(&c).Elem(), nil
+				}
+				return next(k)
+			}
+		})
+	}
+
+	t.Run("short-circuit", func(t *testing.T) {
+		t.Parallel()
+
+		mock := fakeClock(42)
+		c := digtest.New(t, mockClock(mock))
+		c.RequireProvide(func() clock {
+			t.Fatal("clock constructor must not be called")
+			return nil
+		})
+		c.RequireProvide(func(c clock) *service { return &service{c: c} })
+
+		c.RequireInvoke(func(s *service, c clock) {
+			assert.Equal(t, 42, s.c.Now())
+			assert.Equal(t, 42, c.Now())
+		})
+	})
+
+	t.Run("falls through", func(t *testing.T) {
+		t.Parallel()
+
+		var keys []dig.ParamKey
+		c := digtest.New(t, dig.WithParamResolver(func(next dig.ResolveFunc) dig.ResolveFunc {
+			return func(k dig.ParamKey) (reflect.Value, error) {
+				keys = append(keys, k)
+				return next(k)
+			}
+		}))
+		c.RequireProvide(func() string { return "hello" }, dig.Name("greeting"))
+		c.RequireProvide(func() int { return 42 })
+
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Greeting string `name:"greeting"`
+			Count    int
+			Missing  float64 `optional:"true"`
+		}) {
+			assert.Equal(t, "hello", p.Greeting)
+			assert.Equal(t, 42, p.Count)
+			assert.Zero(t, p.Missing)
+		})
+		assert.ElementsMatch(t, []dig.ParamKey{
+			{Type: reflect.TypeOf(""), Name: "greeting"},
+			{Type: reflect.TypeOf(0)},
+			{Type: reflect.TypeOf(0.0), Optional: true},
+		}, keys)
+	})
+
+	t.Run("redirect", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.WithParamResolver(func(next dig.ResolveFunc) dig.ResolveFunc {
+			return func(k dig.ParamKey) (reflect.Value, error) {
+				if k.Type == reflect.TypeOf("") && k.Name == "" {
+					k.Name = "test"
+				}
+				return next(k)
+			}
+		}))
+		c.RequireProvide(func() string { return "prod" })
+		c.RequireProvide(func() string { return "test" }, dig.Name("test"))
+
+		c.RequireInvoke(func(s string) {
+			assert.Equal(t, "test", s)
+		})
+	})
+
+	t.Run("order", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		trace := func(name string) dig.Option {
+			return dig.WithParamResolver(func(next dig.ResolveFunc) dig.ResolveFunc {
+				return func(k dig.ParamKey) (reflect.Value, error) {
+					calls = append(calls, name)
+					return next(k)
+				}
+			})
+		}
+		c := digtest.New(t, trace("outer"), trace("inner"))
+		c.RequireProvide(func() int { return 1 })
+		c.RequireInvoke(func(int) {})
+		assert.Equal(t, []string{"outer", "inner"}, calls)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		giveErr := errors.New("great sadness")
+		c := digtest.New(t, dig.WithParamResolver(func(dig.ResolveFunc) dig.ResolveFunc {
+			return func(dig.ParamKey) (reflect.Value, error) {
+				return reflect.Value{}, giveErr
+			}
+		}))
+		c.RequireProvide(func() int { return 1 })
+
+		err := c.Invoke(func(int) {})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, giveErr)
+	})
+
+	t.Run("wrong type", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.WithParamResolver(func(dig.ResolveFunc) dig.ResolveFunc {
+			return func(dig.ParamKey) (reflect.Value, error) {
+				return reflect.ValueOf("not an int"), nil
+			}
+		}))
+		c.RequireProvide(func() int { return 1 })
+
+		err := c.Invoke(func(int) {})
+		require.Error(t, err)
+		dig.AssertErrorMatches(t, err,
+			`could not build arguments for function "go.uber.org/dig_test".TestWithParamResolver\S+`,
+			`param resolver returned a value of type string for int: it is not assignable to int`)
+	})
+}
+
+type fakeClock int
+
+func (c fakeClock) Now() int { return int(c) }
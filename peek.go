@@ -0,0 +1,169 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// A ResolveOption modifies the default behavior of functions that read
+// values from the container directly, such as PeekValue.
+type ResolveOption interface {
+	applyResolveOption(*resolveOptions)
+}
+
+type resolveOptions struct {
+	Name  string
+	Group string
+}
+
+// ResolveName is a ResolveOption that reads the value with the given name,
+// as provided with the Name option or a `name:".."` tag.
+func ResolveName(name string) ResolveOption {
+	return resolveNameOption(name)
+}
+
+type resolveNameOption string
+
+func (o resolveNameOption) String() string {
+	return fmt.Sprintf("ResolveName(%q)", string(o))
+}
+
+func (o resolveNameOption) applyResolveOption(opts *resolveOptions) {
+	opts.Name = string(o)
+}
+
+// ResolveGroup is a ResolveOption that reads the values of the given value
+// group, as provided with the Group option or a `group:".."` tag. The
+// target must be a pointer to a slice of the group's value type.
+func ResolveGroup(group string) ResolveOption {
+	return resolveGroupOption(group)
+}
+
+type resolveGroupOption string
+
+func (o resolveGroupOption) String() string {
+	return fmt.Sprintf("ResolveGroup(%q)", string(o))
+}
+
+func (o resolveGroupOption) applyResolveOption(opts *resolveOptions) {
+	opts.Group = string(o)
+}
+
+// PeekValue reads a value that the Container has already built. See
+// Scope.PeekValue for details.
+func (c *Container) PeekValue(target interface{}, opts ...ResolveOption) (bool, error) {
+	return c.scope.PeekValue(target, opts...)
+}
+
+// PeekValue fills target, which must be a pointer, with the value of its
+// element type that the Scope has already built. It never calls
+// constructors or decorators, and reports false without modifying target
+// if the value has not been built yet.
+//
+//	var cfg *Config
+//	ok, err := s.PeekValue(&cfg)
+//
+// Values are found with the same rules as Invoke: a value built by an
+// ancestor is not reported if a nearer Scope has its own constructor for
+// that type.
+//
+// With the ResolveGroup option, target must point to a slice, and it is
+// filled with a copy of the values of the group that were built so far.
+// PeekValue reports false if there are none.
+func (s *Scope) PeekValue(target interface{}, opts ...ResolveOption) (bool, error) {
+	var options resolveOptions
+	for _, o := range opts {
+		o.applyResolveOption(&options)
+	}
+
+	ptr := reflect.ValueOf(target)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() {
+		return false, newErrInvalidInput(
+			fmt.Sprintf("cannot peek into %T: target must be a non-nil pointer", target), nil)
+	}
+	t := ptr.Type().Elem()
+
+	if len(options.Group) > 0 {
+		if len(options.Name) > 0 {
+			return false, newErrInvalidInput(fmt.Sprintf(
+				"cannot use named values with value groups: name:%q requested with group:%q", options.Name, options.Group), nil)
+		}
+		if t.Kind() != reflect.Slice {
+			return false, newErrInvalidInput(
+				fmt.Sprintf("cannot peek into %v: value groups may be read into slices only", t), nil)
+		}
+		v, ok := s.peekGroup(options.Group, t)
+		if ok {
+			ptr.Elem().Set(v)
+		}
+		return ok, nil
+	}
+
+	v, ok := s.peekValue(options.Name, t)
+	if ok {
+		ptr.Elem().Set(v)
+	}
+	return ok, nil
+}
+
+func (s *Scope) peekValue(name string, t reflect.Type) (reflect.Value, bool) {
+	for _, c := range s.storesToRoot() {
+		if v, ok := c.getDecoratedValue(name, t); ok {
+			return v, true
+		}
+		if _, ok := c.getValueDecorator(name, t); ok {
+			// The decorator has not run yet, so the value that Invoke
+			// would receive has not been built.
+			return _noValue, false
+		}
+	}
+
+	for _, c := range s.storesToRoot() {
+		if v, ok := c.getValue(name, t); ok {
+			return v, true
+		}
+		if len(c.getValueProviders(name, t)) > 0 {
+			return _noValue, false
+		}
+	}
+	return _noValue, false
+}
+
+func (s *Scope) peekGroup(name string, t reflect.Type) (reflect.Value, bool) {
+	for _, c := range s.storesToRoot() {
+		if items, ok := c.getDecoratedValueGroup(name, t); ok {
+			result := reflect.MakeSlice(t, items.Len(), items.Len())
+			reflect.Copy(result, items)
+			return result, true
+		}
+		if _, ok := c.getGroupDecorator(name, t.Elem()); ok {
+			return _noValue, false
+		}
+	}
+
+	result := reflect.MakeSlice(t, 0, 0)
+	for _, c := range s.storesToRoot() {
+		result = reflect.Append(result, c.getValueGroup(name, t.Elem())...)
+	}
+	return result, result.Len() > 0
+}
@@ -0,0 +1,196 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestPeekValue(t *testing.T) {
+	t.Parallel()
+
+	type Config struct{ name string }
+
+	t.Run("not built yet", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config {
+			assert.Fail(t, "constructor must not be called")
+			return &Config{}
+		})
+
+		var cfg *Config
+		ok, err := c.PeekValue(&cfg)
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.Nil(t, cfg)
+	})
+
+	t.Run("built", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{name: "foo"} })
+
+		var built *Config
+		c.RequireInvoke(func(cfg *Config) { built = cfg })
+
+		var cfg *Config
+		ok, err := c.PeekValue(&cfg)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.True(t, built == cfg, "must return the cached value")
+	})
+
+	t.Run("named", func(t *testing.T) {
+		type params struct {
+			dig.In
+
+			Config *Config `name:"primary"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{name: "primary"} }, dig.Name("primary"))
+		c.RequireInvoke(func(params) {})
+
+		var cfg *Config
+		ok, err := c.PeekValue(&cfg)
+		require.NoError(t, err)
+		assert.False(t, ok, "unnamed value must not be found")
+
+		ok, err = c.PeekValue(&cfg, dig.ResolveName("primary"))
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "primary", cfg.name)
+	})
+
+	t.Run("decorated", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{name: "raw"} })
+		c.RequireDecorate(func(*Config) *Config { return &Config{name: "decorated"} })
+
+		var cfg *Config
+		ok, err := c.PeekValue(&cfg)
+		require.NoError(t, err)
+		assert.False(t, ok, "value must not be found before decoration")
+
+		c.RequireInvoke(func(*Config) {})
+		ok, err = c.PeekValue(&cfg)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "decorated", cfg.name)
+	})
+
+	t.Run("scope provider shadows parent value", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{name: "root"} })
+		c.RequireInvoke(func(*Config) {})
+
+		child := c.Scope("child")
+		child.RequireProvide(func() *Config { return &Config{name: "child"} })
+
+		var cfg *Config
+		ok, err := child.PeekValue(&cfg)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		child.RequireInvoke(func(*Config) {})
+		ok, err = child.PeekValue(&cfg)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "child", cfg.name)
+	})
+
+	t.Run("value group", func(t *testing.T) {
+		type params struct {
+			dig.In
+
+			Names []string `group:"names"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "a" }, dig.Group("names"))
+		c.RequireProvide(func() string { return "b" }, dig.Group("names"))
+
+		var names []string
+		ok, err := c.PeekValue(&names, dig.ResolveGroup("names"))
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		c.RequireInvoke(func(params) {})
+		ok, err = c.PeekValue(&names, dig.ResolveGroup("names"))
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.ElementsMatch(t, []string{"a", "b"}, names)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		c := digtest.New(t)
+
+		tests := []struct {
+			desc    string
+			target  interface{}
+			opts    []dig.ResolveOption
+			wantErr string
+		}{
+			{
+				desc:    "not a pointer",
+				target:  Config{},
+				wantErr: "cannot peek into dig_test.Config: target must be a non-nil pointer",
+			},
+			{
+				desc:    "nil pointer",
+				target:  (*Config)(nil),
+				wantErr: "cannot peek into *dig_test.Config: target must be a non-nil pointer",
+			},
+			{
+				desc:    "group into non-slice",
+				target:  new(string),
+				opts:    []dig.ResolveOption{dig.ResolveGroup("names")},
+				wantErr: "cannot peek into string: value groups may be read into slices only",
+			},
+			{
+				desc:    "name and group",
+				target:  new([]string),
+				opts:    []dig.ResolveOption{dig.ResolveGroup("names"), dig.ResolveName("foo")},
+				wantErr: `cannot use named values with value groups: name:"foo" requested with group:"names"`,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.desc, func(t *testing.T) {
+				_, err := c.PeekValue(tt.target, tt.opts...)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			})
+		}
+	})
+}
+
+func TestResolveOptionStrings(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, `ResolveName("foo")`, fmt.Sprint(dig.ResolveName("foo")))
+	assert.Equal(t, `ResolveGroup("bar")`, fmt.Sprint(dig.ResolveGroup("bar")))
+}
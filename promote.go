@@ -0,0 +1,73 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Promote moves a value that is stored in this Scope to its parent, so that
+// sibling Scopes share it instead of building their own. Name is the name
+// of the value, or "" for an unnamed value.
+//
+//	err := request.Promote(reflect.TypeOf(&Session{}), "")
+//
+// The value must have been built by a constructor provided to this Scope,
+// or promoted to it from a child, and must not be decorated in it. Call
+// Promote on the parent to move the value further up. Afterwards, the
+// value is read from the parent by this Scope and by all other Scopes
+// that inherit from the parent, even those that cannot see its
+// constructor. The constructor is not called again.
+//
+// Promote fails and leaves both Scopes unchanged if the parent already has
+// a value of the same type and name: a value is never replaced by one that
+// was built in a descendant.
+func (s *Scope) Promote(t reflect.Type, name string) error {
+	if t == nil {
+		return newErrInvalidInput("cannot promote a value of an untyped nil type", nil)
+	}
+	k := key{t: t, name: name}
+
+	to := s.parentScope
+	if to == nil {
+		return newErrInvalidInput(fmt.Sprintf("cannot promote %v: the root Scope has no parent", k), nil)
+	}
+
+	defer s.lock()()
+	v, ok := s.values[k]
+	if !ok {
+		return newErrInvalidInput(fmt.Sprintf(
+			"cannot promote %v: no value was built in Scope %q", k, s.name), nil)
+	}
+	if _, ok := s.decoratedValues[k]; ok {
+		return newErrInvalidInput(fmt.Sprintf(
+			"cannot promote %v: it was decorated in Scope %q", k, s.name), nil)
+	}
+	if _, ok := to.values[k]; ok {
+		return newErrInvalidInput(fmt.Sprintf(
+			"cannot promote %v: Scope %q already has a value", k, to.name), nil)
+	}
+
+	delete(s.values, k)
+	to.values[k] = v
+	return nil
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestPromote(t *testing.T) {
+	t.Parallel()
+
+	type Session struct{ id int }
+	sessionType := reflect.TypeOf(&Session{})
+
+	t.Run("siblings share promoted value", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		first := c.Scope("first")
+		calls := 0
+		first.RequireProvide(func() *Session {
+			calls++
+			return &Session{id: calls}
+		})
+		first.RequireInvoke(func(*Session) {})
+		require.NoError(t, first.Promote(sessionType, ""))
+
+		second := c.Scope("second")
+		second.RequireInvoke(func(s *Session) {
+			assert.Equal(t, 1, s.id)
+		})
+		first.RequireInvoke(func(s *Session) {
+			assert.Equal(t, 1, s.id, "promoting scope must still see the value")
+		})
+		assert.Equal(t, 1, calls, "constructor must not be called again")
+	})
+
+	t.Run("named value to root", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		parent := c.Scope("parent")
+		child := parent.Scope("child")
+		child.RequireProvide(func() *Session { return &Session{id: 42} }, dig.Name("main"))
+
+		type params struct {
+			dig.In
+
+			Session *Session `name:"main"`
+		}
+		child.RequireInvoke(func(params) {})
+		require.NoError(t, child.Promote(sessionType, "main"))
+		require.NoError(t, parent.Promote(sessionType, "main"))
+
+		var s *Session
+		ok, err := c.PeekValue(&s, dig.ResolveName("main"))
+		require.NoError(t, err)
+		require.True(t, ok, "root must have the value")
+		assert.Equal(t, 42, s.id)
+	})
+
+	t.Run("ancestor already has value", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		child := c.Scope("child")
+		child.RequireProvide(func() *Session { return &Session{id: 1} })
+		child.RequireInvoke(func(*Session) {})
+
+		require.NoError(t, c.ImportValues(map[reflect.Type]reflect.Value{
+			sessionType: reflect.ValueOf(&Session{id: 2}),
+		}))
+
+		err := child.Promote(sessionType, "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `cannot promote *dig_test.Session: Scope "" already has a value`)
+
+		child.RequireInvoke(func(s *Session) {
+			assert.Equal(t, 1, s.id, "child must keep its value")
+		})
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		child := c.Scope("child")
+		child.RequireProvide(func() *Session { return &Session{} })
+		child.RequireDecorate(func(s *Session) *Session { return s })
+		unused := c.Scope("unused")
+		unused.RequireProvide(func() *Session { return &Session{} })
+		grandchild := child.Scope("grandchild")
+		grandchild.RequireInvoke(func(*Session) {})
+
+		tests := []struct {
+			desc string
+			err  error
+			want string
+		}{
+			{
+				desc: "not built",
+				err:  unused.Promote(sessionType, ""),
+				want: `cannot promote *dig_test.Session: no value was built in Scope "unused"`,
+			},
+			{
+				desc: "built in parent",
+				err:  grandchild.Promote(sessionType, ""),
+				want: `cannot promote *dig_test.Session: no value was built in Scope "grandchild"`,
+			},
+			{
+				desc: "decorated",
+				err:  child.Promote(sessionType, ""),
+				want: `cannot promote *dig_test.Session: it was decorated in Scope "child"`,
+			},
+			{
+				desc: "nil type",
+				err:  child.Promote(nil, ""),
+				want: "untyped nil",
+			},
+		}
+
+		for _, tt := range tests {
+			require.Error(t, tt.err, tt.desc)
+			assert.Contains(t, tt.err.Error(), tt.want, tt.desc)
+		}
+	})
+}
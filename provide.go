@@ -24,6 +24,7 @@ import (
 	"bytes"
 	"fmt"
 	"reflect"
+	"runtime"
 	"strings"
 
 	"go.uber.org/dig/internal/digreflect"
@@ -37,12 +38,33 @@ type ProvideOption interface {
 }
 
 type provideOptions struct {
-	Name     string
-	Group    string
-	Info     *ProvideInfo
-	As       []interface{}
-	Location *digreflect.Func
-	Exported bool
+	Name        string
+	NameFunc    func(reflect.Type) string
+	Group       string
+	Info        *ProvideInfo
+	As          []interface{}
+	Location    *digreflect.Func
+	Exported    bool
+	PrivateTo   *Scope
+	ResolveFrom *Scope
+	ParamTags   []string
+	ResultTags  []string
+	ErrorGroup  string
+	Tags        map[string]string
+
+	GroupNamespace string
+	GroupTag       string
+	GroupLabels    []string
+	Weight         *int
+	Eager          bool
+	AllowNoResults bool
+	AutoCleanup    bool
+	AutoClose      bool
+	IfNotProvided  bool
+	AsImplemented  bool
+	GroupUnless    func([]reflect.Value) bool
+
+	OmitNilFromGroup bool
 }
 
 func (o *provideOptions) Validate() error {
@@ -53,6 +75,38 @@ func (o *provideOptions) Validate() error {
 		}
 	}
 
+	if o.NameFunc != nil {
+		if len(o.Name) > 0 {
+			return newErrInvalidInput("cannot use dig.NameFunc with dig.Name", nil)
+		}
+		if len(o.Group) > 0 {
+			return newErrInvalidInput(
+				fmt.Sprintf("cannot use named values with value groups: dig.NameFunc provided with group:%q", o.Group), nil)
+		}
+		if len(o.ResultTags) > 0 {
+			return newErrInvalidInput(
+				"cannot use dig.ResultTags with dig.NameFunc: specify the name in the result tags instead", nil)
+		}
+	}
+
+	if o.IfNotProvided && len(o.Group) > 0 {
+		return newErrInvalidInput(
+			fmt.Sprintf("cannot use dig.IfNotProvided with value groups: group:%q provided", o.Group), nil)
+	}
+
+	if o.Exported && o.PrivateTo != nil {
+		return newErrInvalidInput("cannot use dig.Export with dig.PrivateTo", nil)
+	}
+
+	if o.Exported && o.ResolveFrom != nil {
+		return newErrInvalidInput("cannot use dig.Export with dig.ResolveFrom", nil)
+	}
+
+	if len(o.ResultTags) > 0 && (len(o.Name) > 0 || len(o.Group) > 0) {
+		return newErrInvalidInput(
+			"cannot use dig.ResultTags with dig.Name or dig.Group: specify the name or group in the result tags instead", nil)
+	}
+
 	// Names must be representable inside a backquoted string. The only
 	// limitation for raw string literals as per
 	// https://golang.org/ref/spec#raw_string_lit is that they cannot contain
@@ -65,6 +119,27 @@ func (o *provideOptions) Validate() error {
 		return newErrInvalidInput(
 			fmt.Sprintf("invalid dig.Group(%q): group names cannot contain backquotes", o.Group), nil)
 	}
+	if err := validateGroupNamespace(o.GroupNamespace); err != nil {
+		return err
+	}
+	if _, ok := o.Tags[""]; ok {
+		return newErrInvalidInput("invalid dig.Tags: keys cannot be empty", nil)
+	}
+
+	if o.Weight != nil && *o.Weight <= 0 {
+		return newErrInvalidInput(fmt.Sprintf("invalid dig.Weight(%d): weights must be positive", *o.Weight), nil)
+	}
+
+	for _, l := range o.GroupLabels {
+		if err := validateGroupLabel(l); err != nil {
+			return newErrInvalidInput(fmt.Sprintf("invalid dig.GroupLabels(%q)", o.GroupLabels), err)
+		}
+	}
+
+	if strings.ContainsRune(o.ErrorGroup, '`') {
+		return newErrInvalidInput(
+			fmt.Sprintf("invalid dig.ReportErrorsToGroup(%q): group names cannot contain backquotes", o.ErrorGroup), nil)
+	}
 
 	for _, i := range o.As {
 		t := reflect.TypeOf(i)
@@ -118,6 +193,34 @@ func (o provideNameOption) applyProvideOption(opt *provideOptions) {
 	opt.Name = string(o)
 }
 
+// NameFunc is a ProvideOption that names each value produced by a
+// constructor with the name that f returns for its type. This is useful to
+// provide many similarly named values programmatically.
+//
+//	shardName := func(t reflect.Type) string {
+//	  return "shard-" + strconv.Itoa(i) + "-" + t.Name()
+//	}
+//	c.Provide(NewShard(i), dig.NameFunc(shardName))
+//
+// Values for which f returns an empty string are not named. Fields of
+// result objects are named this way too, unless they have a name or group
+// tag. This option cannot be combined with Name, Group, or ResultTags.
+func NameFunc(f func(reflect.Type) string) ProvideOption {
+	return provideNameFuncOption{f: f}
+}
+
+type provideNameFuncOption struct {
+	f func(reflect.Type) string
+}
+
+func (o provideNameFuncOption) String() string {
+	return fmt.Sprintf("NameFunc(%p)", o.f)
+}
+
+func (o provideNameFuncOption) applyProvideOption(opt *provideOptions) {
+	opt.NameFunc = o.f
+}
+
 // Group is a ProvideOption that specifies that all values produced by a
 // constructor should be added to the specified group. See also the package
 // documentation about Value Groups.
@@ -149,6 +252,9 @@ type ProvideInfo struct {
 	ID      ID
 	Inputs  []*Input
 	Outputs []*Output
+
+	// Metadata attached to the constructor with the Tags option, if any.
+	Tags map[string]string
 }
 
 // Input contains information on an input parameter of a function.
@@ -318,6 +424,15 @@ func (o provideLocationOption) applyProvideOption(opts *provideOptions) {
 // With Export, you can make this constructor available to all the Scopes:
 //
 //	s1.Provide(func() *bytes.Buffer { ... }, Export(true))
+//
+// The dependencies of the constructor are still resolved in the Scope it
+// was provided to. This allows, for example, a plugin Scope to add values
+// built from its own dependencies to a value group consumed by the root:
+//
+//	plugin.Provide(func(cfg *PluginConfig) Codec { ... }, Group("codecs"), Export(true))
+//
+// Without Export, values added to a value group from a Scope are only
+// visible to that Scope and its descendants.
 func Export(export bool) ProvideOption {
 	return provideExportOption{exported: export}
 }
@@ -332,6 +447,244 @@ func (o provideExportOption) applyProvideOption(opts *provideOptions) {
 	opts.Exported = o.exported
 }
 
+// PrivateTo is a ProvideOption which specifies that the provided function
+// should be registered with the given Scope rather than the Scope it was
+// provided to. Its results are then only available to that Scope and its
+// descendants, and its dependencies are resolved from that Scope.
+//
+// The given Scope must be the Scope the function is provided to, or one of
+// its descendants. For example,
+//
+//	c := New()
+//	s := c.Scope("http")
+//	c.Provide(func() *bytes.Buffer { ... }, PrivateTo(s))
+//
+// makes *bytes.Buffer available to s and its child Scopes, but not to c.
+//
+// PrivateTo cannot be used together with Export.
+func PrivateTo(s *Scope) ProvideOption {
+	return providePrivateToOption{scope: s}
+}
+
+type providePrivateToOption struct{ scope *Scope }
+
+func (o providePrivateToOption) String() string {
+	return fmt.Sprintf("PrivateTo(%q)", o.scope.name)
+}
+
+func (o providePrivateToOption) applyProvideOption(opts *provideOptions) {
+	opts.PrivateTo = o.scope
+}
+
+// ResolveFrom is a ProvideOption which specifies that the dependencies of
+// the provided function should be resolved from the given Scope rather
+// than the Scope it was provided to. The function is still registered with
+// the Scope it was provided to, so its results are available to that Scope
+// and its descendants.
+//
+// The given Scope must be the Scope the function is provided to, or one of
+// its descendants. For example,
+//
+//	c := New()
+//	req := c.Scope("request")
+//	req.Provide(func() *http.Request { ... })
+//	c.Provide(func(r *http.Request) *Handler { ... }, ResolveFrom(req))
+//
+// makes *Handler available to c, built from the *http.Request of req.
+//
+// Cycles are checked from the point of view of every Scope that can see
+// the function, including the given Scope, so a cycle through the
+// dependencies of the given Scope is reported when the function is
+// provided. Because the function is also checked against the dependencies
+// of the Scope it was provided to, a cycle may be reported there even if
+// the given Scope breaks it, for example by providing a different
+// constructor for one of the types in the cycle.
+//
+// ResolveFrom cannot be used together with Export.
+func ResolveFrom(s *Scope) ProvideOption {
+	return provideResolveFromOption{scope: s}
+}
+
+type provideResolveFromOption struct{ scope *Scope }
+
+func (o provideResolveFromOption) String() string {
+	return fmt.Sprintf("ResolveFrom(%q)", o.scope.name)
+}
+
+func (o provideResolveFromOption) applyProvideOption(opts *provideOptions) {
+	opts.ResolveFrom = o.scope
+}
+
+// ParamTags is a ProvideOption that annotates the positional parameters of
+// a constructor with struct tags, as if they were fields of a dig.In struct.
+// The i-th tag applies to the i-th parameter. Empty tags leave a parameter
+// unchanged.
+//
+// For example, the following marks the first parameter of NewServer as
+// optional and reads its second parameter from the value named "ro".
+//
+//	c.Provide(NewServer, dig.ParamTags(`optional:"true"`, `name:"ro"`))
+//
+// This is equivalent to the following.
+//
+//	type ServerParams struct {
+//	  dig.In
+//
+//	  Options *ServerOptions `optional:"true"`
+//	  Conn    *sql.DB        `name:"ro"`
+//	}
+//
+// Tags cannot be applied to parameters that are dig.In structs.
+func ParamTags(tags ...string) ProvideOption {
+	return provideParamTagsOption(tags)
+}
+
+type provideParamTagsOption []string
+
+func (o provideParamTagsOption) String() string {
+	return fmt.Sprintf("ParamTags(%q)", []string(o))
+}
+
+func (o provideParamTagsOption) applyProvideOption(opts *provideOptions) {
+	opts.ParamTags = o
+}
+
+// ResultTags is a ProvideOption that annotates the non-error results of a
+// constructor with struct tags, as if they were fields of a dig.Out struct.
+// The i-th tag applies to the i-th non-error result. Empty tags leave a
+// result unchanged.
+//
+// For example, the following provides two connections to the container: one
+// under the name "ro" and the other under the name "rw".
+//
+//	c.Provide(NewConns, dig.ResultTags(`name:"ro"`, `name:"rw"`))
+//
+// This is equivalent to the following.
+//
+//	type Conns struct {
+//	  dig.Out
+//
+//	  RO *sql.DB `name:"ro"`
+//	  RW *sql.DB `name:"rw"`
+//	}
+//
+// Tags cannot be applied to results that are dig.Out structs, and this
+// option cannot be combined with Name or Group.
+func ResultTags(tags ...string) ProvideOption {
+	return provideResultTagsOption(tags)
+}
+
+type provideResultTagsOption []string
+
+func (o provideResultTagsOption) String() string {
+	return fmt.Sprintf("ResultTags(%q)", []string(o))
+}
+
+func (o provideResultTagsOption) applyProvideOption(opts *provideOptions) {
+	opts.ResultTags = o
+}
+
+// Tags is a ProvideOption that attaches arbitrary key/value metadata to a
+// constructor. The metadata does not affect how the constructor is called,
+// but it is reported by FillProvideInfo and included in the output of
+// Visualize, which allows tooling to categorize the constructors of a
+// container, for example by owner.
+//
+//	c.Provide(NewCharger, dig.Tags(map[string]string{"team": "payments"}))
+//
+// If Tags is used more than once, the metadata is merged, with later values
+// taking precedence for the same key.
+func Tags(tags map[string]string) ProvideOption {
+	return provideTagsOption(tags)
+}
+
+type provideTagsOption map[string]string
+
+func (o provideTagsOption) String() string {
+	return fmt.Sprintf("Tags(%v)", map[string]string(o))
+}
+
+func (o provideTagsOption) applyProvideOption(opts *provideOptions) {
+	if opts.Tags == nil {
+		opts.Tags = make(map[string]string, len(o))
+	}
+	for k, v := range o {
+		opts.Tags[k] = v
+	}
+}
+
+// Eager is a ProvideOption that makes the constructor run the next time
+// Invoke is called on the Scope it was provided to, or on one of its
+// descendants, even if nothing depends on its results. Eager constructors
+// run before the arguments of the invoked function are built, in the order
+// they were provided, and Invoke fails if any of them fail. A constructor
+// that failed runs again at the next Invoke.
+//
+// Combine it with AllowNoResults to register initialization functions that
+// only have side effects.
+//
+//	c.Provide(func(db *sql.DB) error {
+//	  return migrate(db)
+//	}, dig.Eager(), dig.AllowNoResults())
+func Eager() ProvideOption {
+	return provideEagerOption{}
+}
+
+type provideEagerOption struct{}
+
+func (provideEagerOption) String() string {
+	return "Eager()"
+}
+
+func (provideEagerOption) applyProvideOption(opts *provideOptions) {
+	opts.Eager = true
+}
+
+// AllowNoResults is a ProvideOption that allows providing a function that
+// returns nothing, or only an error. Such a function provides no values, so
+// nothing can depend on it, and it is never called unless it is also
+// provided with the Eager option.
+func AllowNoResults() ProvideOption {
+	return provideAllowNoResultsOption{}
+}
+
+type provideAllowNoResultsOption struct{}
+
+func (provideAllowNoResultsOption) String() string {
+	return "AllowNoResults()"
+}
+
+func (provideAllowNoResultsOption) applyProvideOption(opts *provideOptions) {
+	opts.AllowNoResults = true
+}
+
+// IfNotProvided is a ProvideOption that provides the constructor only if
+// none of the values it produces are provided yet, by the Scope it is
+// provided to or by one of its ancestors. Otherwise, Provide does nothing
+// and does not fail. This allows reusable modules to provide defaults that
+// applications may replace by providing their own values first.
+//
+//	c.Provide(newApplicationLogger)
+//	c.Provide(newDefaultLogger, dig.IfNotProvided()) // skipped
+//
+// Provide fails if only some of the values are already provided. Values
+// that the constructor adds to value groups are not considered, and
+// IfNotProvided cannot be combined with Group. If the constructor is
+// skipped, the ProvideInfo of FillProvideInfo is left as-is.
+func IfNotProvided() ProvideOption {
+	return provideIfNotProvidedOption{}
+}
+
+type provideIfNotProvidedOption struct{}
+
+func (provideIfNotProvidedOption) String() string {
+	return "IfNotProvided()"
+}
+
+func (provideIfNotProvidedOption) applyProvideOption(opts *provideOptions) {
+	opts.IfNotProvided = true
+}
+
 // provider encapsulates a user-provided constructor.
 type provider interface {
 	// ID is a unique numerical identifier for this provider.
@@ -362,6 +715,11 @@ type provider interface {
 	CType() reflect.Type
 
 	OrigScope() *Scope
+
+	// Conditional reports whether this constructor contributes to its
+	// value group depending on the values of the group. Such constructors
+	// are called after the other constructors of the group.
+	Conditional() bool
 }
 
 // Provide teaches the container how to build values of one or more types and
@@ -402,6 +760,10 @@ func (c *Container) Provide(constructor interface{}, opts ...ProvideOption) erro
 // To provide a constructor to all the Scopes available, provide it to
 // Container, which is the root Scope.
 func (s *Scope) Provide(constructor interface{}, opts ...ProvideOption) error {
+	if err := s.checkReentrant("Provide"); err != nil {
+		return err
+	}
+
 	ctype := reflect.TypeOf(constructor)
 	if ctype == nil {
 		return newErrInvalidInput("can't provide an untyped nil", nil)
@@ -415,16 +777,16 @@ func (s *Scope) Provide(constructor interface{}, opts ...ProvideOption) error {
 	for _, o := range opts {
 		o.applyProvideOption(&options)
 	}
-	if err := options.Validate(); err != nil {
-		return err
+	err := options.Validate()
+	if err == nil {
+		err = s.provide(constructor, options)
 	}
-
-	if err := s.provide(constructor, options); err != nil {
-		var errFunc *digreflect.Func
-		if options.Location == nil {
+	if err != nil {
+		// Errors refer to the location given by LocationForPC, if any, so
+		// that generated constructors are reported consistently.
+		errFunc := options.Location
+		if errFunc == nil {
 			errFunc = digreflect.InspectFunc(constructor)
-		} else {
-			errFunc = options.Location
 		}
 
 		return errProvide{
@@ -435,6 +797,51 @@ func (s *Scope) Provide(constructor interface{}, opts ...ProvideOption) error {
 	return nil
 }
 
+// ProvideValue adds the given value to the Container. See
+// Scope.ProvideValue for details.
+func (c *Container) ProvideValue(v reflect.Value, opts ...ProvideOption) error {
+	return c.scope.provideValue(v, callerPC(), opts)
+}
+
+// ProvideValue adds the given value to the Scope as if it were returned by
+// a constructor with no dependencies. It is intended for code that works
+// with reflection and already holds the value as a reflect.Value.
+//
+// The value is provided under its reflect.Type. Unlike passing
+// v.Interface() to a constructor, this preserves interface types: a
+// reflect.Value of type io.Reader is provided as an io.Reader, not as its
+// concrete type.
+//
+//	v := reflect.ValueOf(&r).Elem() // r is an io.Reader
+//	err := s.ProvideValue(v, dig.Name("input"))
+//
+// All ProvideOptions are honored. Errors refer to the line that called
+// ProvideValue unless the LocationForPC option is given.
+func (s *Scope) ProvideValue(v reflect.Value, opts ...ProvideOption) error {
+	return s.provideValue(v, callerPC(), opts)
+}
+
+func (s *Scope) provideValue(v reflect.Value, pc uintptr, opts []ProvideOption) error {
+	if !v.IsValid() {
+		return newErrInvalidInput("can't provide an invalid reflect.Value", nil)
+	}
+
+	ctor := reflect.MakeFunc(
+		reflect.FuncOf(nil, []reflect.Type{v.Type()}, false),
+		func([]reflect.Value) []reflect.Value {
+			return []reflect.Value{v}
+		},
+	)
+	return s.Provide(ctor.Interface(), append([]ProvideOption{LocationForPC(pc)}, opts...)...)
+}
+
+// callerPC returns the program counter address of the caller of the
+// function that called callerPC.
+func callerPC() uintptr {
+	pc, _, _, _ := runtime.Caller(2)
+	return pc
+}
+
 func (s *Scope) provide(ctor interface{}, opts provideOptions) (err error) {
 	// If Export option is provided to the constructor, this should be injected to the
 	// root-level Scope (Container) to allow it to propagate to all other Scopes.
@@ -443,47 +850,131 @@ func (s *Scope) provide(ctor interface{}, opts provideOptions) (err error) {
 		s = s.rootScope()
 	}
 
+	// If PrivateTo option is provided, the constructor belongs entirely to
+	// the given Scope, which must be this Scope or one of its descendants.
+	if ps := opts.PrivateTo; ps != nil {
+		if !ps.isDescendantOf(s) {
+			return newErrInvalidInput(fmt.Sprintf(
+				"cannot provide to scope %q privately: it is not %q or one of its descendants", ps.name, s.name), nil)
+		}
+		s, origScope = ps, ps
+	}
+
+	// If ResolveFrom option is provided, the constructor stays in this
+	// Scope, but its dependencies are resolved from the given Scope, which
+	// must be able to see it.
+	if rs := opts.ResolveFrom; rs != nil {
+		if !rs.isDescendantOf(s) {
+			return newErrInvalidInput(fmt.Sprintf(
+				"cannot resolve from scope %q: it is not %q or one of its descendants", rs.name, s.name), nil)
+		}
+		origScope = rs
+	}
+
+	root := s.rootScope()
+	if root.maxProviders > 0 && root.numProviders >= root.maxProviders {
+		return errMaxProviders{Limit: root.maxProviders}
+	}
+
 	// For all scopes affected by this change,
 	// take a snapshot of the current graph state before
 	// we start making changes to it as we may need to
 	// undo them upon encountering errors.
 	allScopes := s.appendSubscopes(nil)
+	var skipped bool
 	for _, s := range allScopes {
 		s := s
 		s.gh.Snapshot()
 		defer func() {
-			if err != nil {
+			if err != nil || skipped {
 				s.gh.Rollback()
 			}
 		}()
 	}
 
+	errorGroup := qualifyGroup(opts.GroupNamespace, opts.ErrorGroup)
 	n, err := newConstructorNode(
 		ctor,
 		s,
 		origScope,
 		constructorOptions{
-			ResultName:  opts.Name,
-			ResultGroup: opts.Group,
-			ResultAs:    opts.As,
-			Location:    opts.Location,
+			ResultName:     opts.Name,
+			ResultNameFunc: opts.NameFunc,
+			ResultGroup:    opts.Group,
+			ResultAs:       opts.As,
+			AsImplemented:  opts.AsImplemented,
+			Location:       opts.Location,
+			ParamTags:      opts.ParamTags,
+			ResultTags:     opts.ResultTags,
+			ErrorGroup:     errorGroup,
+			Tags:           opts.Tags,
+			GroupNamespace: opts.GroupNamespace,
+			GroupTag:       opts.GroupTag,
+			GroupLabels:    opts.GroupLabels,
+			GroupWeight:    weightOrDefault(opts.Weight),
+			Eager:          opts.Eager,
+			AutoCleanup:    opts.AutoCleanup,
+			AutoClose:      opts.AutoClose,
+
+			OmitNilFromGroup: opts.OmitNilFromGroup,
 		},
 	)
 	if err != nil {
 		return err
 	}
 
+	n.provideOpts = opts
+
+	if opts.IfNotProvided {
+		if skipped, err = s.alreadyProvided(n); skipped || err != nil {
+			return err
+		}
+	}
+
+	// Dependencies are resolved from the Scope that the constructor was
+	// provided to, even if it was exported, or from the Scope given to
+	// ResolveFrom.
+	if root.eagerDependencyCheck {
+		if err := shallowCheckDependencies(origScope, n.paramList); err != nil {
+			return err
+		}
+	}
+
 	keys, err := s.findAndValidateResults(n.ResultList())
 	if err != nil {
 		return err
 	}
+	if opts.GroupUnless != nil {
+		if n.groupUnlessKey, err = groupUnlessKey(keys); err != nil {
+			return err
+		}
+		n.groupUnless = opts.GroupUnless
+	}
+	if root.strictSliceGroups {
+		if err := s.checkSliceGroups(n, keys); err != nil {
+			return err
+		}
+	}
 
 	ctype := reflect.TypeOf(ctor)
-	if len(keys) == 0 {
+	if len(keys) == 0 && !opts.AllowNoResults && !n.resultList.returnsNamedResults() {
 		return newErrInvalidInput(
 			fmt.Sprintf("%v must provide at least one non-error type", ctype), nil)
 	}
 
+	// Constructors that report their errors to a group provide to that
+	// group as well.
+	if len(errorGroup) > 0 {
+		keys[key{group: errorGroup, t: _errType}] = struct{}{}
+	}
+
+	// Constructors and decorators that were already called may have
+	// consumed values that this constructor changes.
+	stale := s.findStaleConsumers(keys)
+	if !stale.empty() && !root.allowRebuild {
+		return newErrStaleValues(keys, stale)
+	}
+
 	oldProviders := make(map[key][]*constructorNode)
 	for k := range keys {
 		// Cache old providers before running cycle detection.
@@ -491,53 +982,127 @@ func (s *Scope) provide(ctor interface{}, opts provideOptions) (err error) {
 		s.providers[k] = append(s.providers[k], n)
 	}
 
-	for _, s := range allScopes {
-		s.isVerifiedAcyclic = false
-		if s.deferAcyclicVerification {
+	// A constructor without parameters has no outgoing edges, so it cannot
+	// introduce a cycle. Leave the verification state of all graphs as-is.
+	if len(n.paramList.Params) == 0 {
+		allScopes = nil
+	}
+	for _, as := range allScopes {
+		wasAcyclic := as.isVerifiedAcyclic
+		as.isVerifiedAcyclic = false
+		as.acyclicNodes = nil
+		if as.deferAcyclicVerification {
 			continue
 		}
-		if ok, cycle := graph.IsAcyclic(s.gh); !ok {
+
+		// If the graph was already known to be acyclic, any cycle must
+		// go through the new node, so only the nodes reachable from it
+		// need to be checked.
+		var (
+			ok    bool
+			cycle []int
+		)
+		g := as.gh.Clone()
+		if wasAcyclic {
+			ok, cycle = graph.IsAcyclicFrom(g, n.Order(as))
+		} else {
+			ok, cycle = graph.IsAcyclic(g)
+		}
+		if !ok {
 			// When a cycle is detected, recover the old providers to reset
 			// the providers map back to what it was before this node was
-			// introduced.
+			// introduced. The cycle may be found in the graph of a
+			// descendant, but the node was added to this Scope.
 			for k, ops := range oldProviders {
 				s.providers[k] = ops
 			}
 
-			return newErrInvalidInput("this function introduces a cycle", s.cycleDetectedError(cycle))
+			return newErrInvalidInput("this function introduces a cycle", as.cycleDetectedError(g, cycle))
 		}
-		s.isVerifiedAcyclic = true
+		as.isVerifiedAcyclic = true
 	}
 
 	s.nodes = append(s.nodes, n)
+	if n.resultList.returnsNamedResults() {
+		s.namedResultsCtors = append(s.namedResultsCtors, n)
+	}
+	root.numProviders++
+	root.generation++
+	stale.rebuild()
 
 	// Record introspection info for caller if Info option is specified
 	if info := opts.Info; info != nil {
-		params := n.ParamList().DotParam()
-		results := n.ResultList().DotResult()
-
-		info.ID = (ID)(n.id)
-		info.Inputs = make([]*Input, len(params))
-		info.Outputs = make([]*Output, len(results))
-
-		for i, param := range params {
-			info.Inputs[i] = &Input{
-				t:        param.Type,
-				optional: param.Optional,
-				name:     param.Name,
-				group:    param.Group,
-			}
+		n.fillProvideInfo(info)
+	}
+	return nil
+}
+
+// fillProvideInfo writes information about the inputs and outputs of the
+// given constructor to info.
+func (n *constructorNode) fillProvideInfo(info *ProvideInfo) {
+	params := n.ParamList().DotParam()
+	results := n.ResultList().DotResult()
+
+	info.ID = (ID)(n.id)
+	info.Tags = n.Tags()
+	info.Inputs = make([]*Input, len(params))
+	info.Outputs = make([]*Output, len(results))
+
+	for i, param := range params {
+		info.Inputs[i] = &Input{
+			t:        param.Type,
+			optional: param.Optional,
+			name:     param.Name,
+			group:    param.Group,
 		}
+	}
 
-		for i, res := range results {
-			info.Outputs[i] = &Output{
-				t:     res.Type,
-				name:  res.Name,
-				group: res.Group,
-			}
+	for i, res := range results {
+		info.Outputs[i] = &Output{
+			t:     res.Type,
+			name:  res.Name,
+			group: res.Group,
 		}
 	}
-	return nil
+}
+
+// ProviderSignature returns information about the inputs and outputs of
+// the constructor with the given ID, as reported by FillProvideInfo. This
+// allows tools that kept the ID from a ProvideInfo to inspect the
+// constructor later.
+//
+// Constructors provided to any Scope of the Container are found.
+func (c *Container) ProviderSignature(id ID) (*ProvideInfo, error) {
+	n := c.scope.findConstructor(id)
+	if n == nil {
+		return nil, newErrInvalidInput(fmt.Sprintf("no constructor with ID %v was provided", id), nil)
+	}
+	var info ProvideInfo
+	n.fillProvideInfo(&info)
+	return &info, nil
+}
+
+// alreadyProvided reports whether all the values produced by the given
+// constructor, except for value groups, are already provided to this Scope
+// or one of its ancestors. It fails if only some of them are.
+func (s *Scope) alreadyProvided(n *constructorNode) (bool, error) {
+	var provided, missing []key
+	for _, r := range n.ResultList().DotResult() {
+		if r.Group != "" {
+			continue
+		}
+		k := key{t: r.Type, name: r.Name}
+		if len(s.getAllProviders(k)) > 0 {
+			provided = append(provided, k)
+		} else {
+			missing = append(missing, k)
+		}
+	}
+	if len(provided) == 0 || len(missing) == 0 {
+		return len(provided) > 0, nil
+	}
+	return false, newErrInvalidInput(fmt.Sprintf(
+		"cannot use dig.IfNotProvided: %v already provided, but %v not provided", provided, missing), nil)
 }
 
 // Builds a collection of all result types produced by this constructor.
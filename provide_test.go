@@ -52,6 +52,81 @@ func TestProvideOptionStrings(t *testing.T) {
 			give: As(new(io.Reader), new(io.Writer)),
 			want: `As(io.Reader, io.Writer)`,
 		},
+		{
+			desc: "ParamTags",
+			give: ParamTags(`optional:"true"`, ""),
+			want: `ParamTags(["optional:\"true\"" ""])`,
+		},
+		{
+			desc: "Eager",
+			give: Eager(),
+			want: `Eager()`,
+		},
+		{
+			desc: "AllowNoResults",
+			give: AllowNoResults(),
+			want: `AllowNoResults()`,
+		},
+		{
+			desc: "IfNotProvided",
+			give: IfNotProvided(),
+			want: `IfNotProvided()`,
+		},
+		{
+			desc: "GroupNamespace",
+			give: GroupNamespace("payments"),
+			want: `GroupNamespace("payments")`,
+		},
+		{
+			desc: "GroupTag",
+			give: GroupTag("admin"),
+			want: `GroupTag("admin")`,
+		},
+		{
+			desc: "GroupLabels",
+			give: GroupLabels("admin", "v2"),
+			want: `GroupLabels(["admin" "v2"])`,
+		},
+		{
+			desc: "Weight",
+			give: Weight(3),
+			want: `Weight(3)`,
+		},
+		{
+			desc: "OmitNilFromGroup",
+			give: OmitNilFromGroup(),
+			want: "OmitNilFromGroup()",
+		},
+		{
+			desc: "Tags",
+			give: Tags(map[string]string{"team": "payments", "tier": "1"}),
+			want: `Tags(map[team:payments tier:1])`,
+		},
+		{
+			desc: "ResultTags",
+			give: ResultTags(`name:"ro"`, ""),
+			want: `ResultTags(["name:\"ro\"" ""])`,
+		},
+		{
+			desc: "PrivateTo",
+			give: PrivateTo(New().Scope("child")),
+			want: `PrivateTo("child")`,
+		},
+		{
+			desc: "ResolveFrom",
+			give: ResolveFrom(New().Scope("child")),
+			want: `ResolveFrom("child")`,
+		},
+		{
+			desc: "AsImplementedInterfaces",
+			give: AsImplementedInterfaces(),
+			want: "AsImplementedInterfaces()",
+		},
+		{
+			desc: "ReportErrorsToGroup",
+			give: ReportErrorsToGroup("init-errors"),
+			want: `ReportErrorsToGroup("init-errors")`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -91,3 +166,50 @@ func TestExportString(t *testing.T) {
 	assert.Equal(t, fmt.Sprint(Export(true)), "Export(true)")
 	assert.Equal(t, fmt.Sprint(Export(false)), "Export(false)")
 }
+
+func TestNameFuncString(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "NameFunc(0x0)", fmt.Sprint(NameFunc(nil)))
+	})
+
+	t.Run("not nil", func(t *testing.T) {
+		t.Parallel()
+
+		opt := NameFunc(func(reflect.Type) string { return "" })
+		assert.NotEqual(t, fmt.Sprint(opt), "NameFunc(0x0)")
+		assert.Contains(t, fmt.Sprint(opt), "NameFunc(0x")
+	})
+}
+
+func TestGroupUnlessString(t *testing.T) {
+	t.Parallel()
+
+	opt := GroupUnless(func([]reflect.Value) bool { return false })
+	assert.Contains(t, fmt.Sprint(opt), "GroupUnless(0x")
+}
+
+func TestProvideLeafConstructorKeepsAcyclic(t *testing.T) {
+	t.Parallel()
+
+	type A struct{}
+	type B struct{}
+
+	c := New()
+	s := c.Scope("child")
+	assert.NoError(t, c.Provide(func(A) B { return B{} }))
+	assert.NoError(t, c.Invoke(func() {}))
+	assert.True(t, c.scope.isVerifiedAcyclic)
+	assert.True(t, s.isVerifiedAcyclic)
+
+	// A has no dependencies, so it cannot introduce a cycle and the
+	// graphs need not be checked again.
+	assert.NoError(t, c.Provide(func() A { return A{} }))
+	assert.True(t, c.scope.isVerifiedAcyclic, "root must remain verified")
+	assert.True(t, s.isVerifiedAcyclic, "child must remain verified")
+
+	assert.NoError(t, c.Invoke(func(B) {}))
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+
+	"go.uber.org/dig/internal/digreflect"
+)
+
+// ProvideJSON decodes JSON from r and adds the result to the Container.
+// See Scope.ProvideJSON for details.
+func (c *Container) ProvideJSON(r io.Reader, target interface{}, opts ...ProvideOption) error {
+	return c.scope.provideJSON(r, target, callerPC(), opts)
+}
+
+// ProvideJSON decodes a JSON document from r into a new value of the type
+// of target, and adds that value to the Scope as if it were returned by a
+// constructor with no dependencies. target is only used for its type: if it
+// is a pointer, the decoded value is provided as a pointer to a new value.
+//
+//	f, err := os.Open("config.json")
+//	// ...
+//	err = s.ProvideJSON(f, (*Config)(nil), dig.Name("primary"))
+//
+// All ProvideOptions are honored, as with ProvideValue. Only the first JSON
+// document in r is read. If it cannot be decoded, nothing is provided and
+// the error refers to the line that called ProvideJSON.
+func (s *Scope) ProvideJSON(r io.Reader, target interface{}, opts ...ProvideOption) error {
+	return s.provideJSON(r, target, callerPC(), opts)
+}
+
+func (s *Scope) provideJSON(r io.Reader, target interface{}, pc uintptr, opts []ProvideOption) error {
+	t := reflect.TypeOf(target)
+	if t == nil {
+		return newErrInvalidInput("can't provide JSON into an untyped nil", nil)
+	}
+
+	ptr := t
+	if t.Kind() != reflect.Ptr {
+		ptr = reflect.PtrTo(t)
+	}
+	v := reflect.New(ptr.Elem())
+	if err := json.NewDecoder(r).Decode(v.Interface()); err != nil {
+		return errProvide{
+			Func:   digreflect.InspectFuncPC(pc),
+			Reason: newErrInvalidInput(fmt.Sprintf("cannot decode JSON into %v", t), err),
+		}
+	}
+
+	if t.Kind() != reflect.Ptr {
+		v = v.Elem()
+	}
+	return s.provideValue(v, pc, opts)
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestProvideJSON(t *testing.T) {
+	t.Parallel()
+
+	type Config struct {
+		Addr string `json:"addr"`
+		Port int    `json:"port"`
+	}
+
+	t.Run("pointer target", func(t *testing.T) {
+		c := digtest.New(t)
+		require.NoError(t, c.ProvideJSON(
+			strings.NewReader(`{"addr": "localhost", "port": 8080}`), (*Config)(nil)))
+
+		c.RequireInvoke(func(cfg *Config) {
+			assert.Equal(t, &Config{Addr: "localhost", Port: 8080}, cfg)
+		})
+	})
+
+	t.Run("value target", func(t *testing.T) {
+		c := digtest.New(t)
+		require.NoError(t, c.ProvideJSON(strings.NewReader(`{"port": 80}`), Config{}))
+
+		c.RequireInvoke(func(cfg Config) {
+			assert.Equal(t, Config{Port: 80}, cfg)
+		})
+		assert.Error(t, c.Invoke(func(*Config) {}), "must not provide the pointer type")
+	})
+
+	t.Run("name and group", func(t *testing.T) {
+		type params struct {
+			dig.In
+
+			Primary Config   `name:"primary"`
+			All     []Config `group:"configs"`
+		}
+
+		c := digtest.New(t)
+		require.NoError(t, c.ProvideJSON(strings.NewReader(`{"port": 1}`), Config{}, dig.Name("primary")))
+		require.NoError(t, c.ProvideJSON(strings.NewReader(`{"port": 2}`), Config{}, dig.Group("configs")))
+		require.NoError(t, c.ProvideJSON(strings.NewReader(`{"port": 3}`), Config{}, dig.Group("configs")))
+
+		c.RequireInvoke(func(p params) {
+			assert.Equal(t, 1, p.Primary.Port)
+			assert.ElementsMatch(t, []Config{{Port: 2}, {Port: 3}}, p.All)
+		})
+	})
+
+	t.Run("scope", func(t *testing.T) {
+		c := dig.New()
+		s := c.Scope("child")
+		require.NoError(t, s.ProvideJSON(strings.NewReader(`{"port": 1}`), Config{}))
+		assert.NoError(t, s.Invoke(func(Config) {}))
+		assert.Error(t, c.Invoke(func(Config) {}), "value must not be visible to the parent")
+	})
+
+	t.Run("decode error", func(t *testing.T) {
+		c := digtest.New(t)
+		err := c.ProvideJSON(strings.NewReader(`{"port": "http"}`), (*Config)(nil))
+		require.Error(t, err)
+		assert.Regexp(t, `TestProvideJSON\S+ \(\S+providejson_test.go:\d+\)`, err.Error())
+		assert.Contains(t, err.Error(), "cannot decode JSON into *dig_test.Config")
+		assert.Error(t, c.Invoke(func(*Config) {}), "nothing must be provided")
+	})
+
+	t.Run("untyped nil target", func(t *testing.T) {
+		c := digtest.New(t)
+		err := c.ProvideJSON(strings.NewReader(`{}`), nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "can't provide JSON into an untyped nil")
+	})
+}
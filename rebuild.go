@@ -0,0 +1,285 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"go.uber.org/dig/internal/digreflect"
+)
+
+// AllowRebuild is an [Option] that allows constructors to be provided
+// after values that they would change were already built.
+//
+// A constructor provided after Invoke may add to a value group that was
+// already consumed, or provide an optional value that was already found
+// missing. By default, Provide fails in these cases, because the cached
+// values that consumed the group or the missing value would not reflect
+// the new constructor. With this option, Provide instead discards those
+// cached values, along with all cached values that depend on them, so that
+// they are built again the next time they are needed.
+//
+// Values that were already passed to functions outside of dig, such as
+// those given to Invoke, are not affected.
+func AllowRebuild() Option {
+	return allowRebuildOption{}
+}
+
+type allowRebuildOption struct{}
+
+func (allowRebuildOption) String() string {
+	return "AllowRebuild()"
+}
+
+func (allowRebuildOption) applyOption(c *Container) {
+	c.scope.allowRebuild = true
+}
+
+// staleConsumers are the constructors and decorators that were already
+// called and consumed keys whose values a new constructor changes.
+type staleConsumers struct {
+	Ctors []*constructorNode
+	Dcors []*decoratorNode
+}
+
+func (sc staleConsumers) empty() bool {
+	return len(sc.Ctors) == 0 && len(sc.Dcors) == 0
+}
+
+// findStaleConsumers finds the constructors and decorators of this Scope
+// and its descendants that were already called and consumed any of the
+// given keys.
+func (s *Scope) findStaleConsumers(keys map[key]struct{}) staleConsumers {
+	var sc staleConsumers
+	for _, scope := range s.appendSubscopes(nil) {
+		for _, n := range scope.nodes {
+			// Check the parameters first: it does not need the lock and
+			// rules out constructors without dependencies right away.
+			if consumesAny(keys, n.paramList.Params...) && n.s.wasCalled(n) {
+				sc.Ctors = append(sc.Ctors, n)
+			}
+		}
+
+		// Decorators are stored in a map, so sort them to report them in
+		// a consistent order.
+		var dcors []*decoratorNode
+		for _, d := range scope.decorators {
+			if d.state == decoratorCalled && consumesAny(keys, d.params.Params...) {
+				dcors = append(dcors, d)
+			}
+		}
+		sort.Slice(dcors, func(i, j int) bool {
+			return dcors[i].location.String() < dcors[j].location.String()
+		})
+		sc.Dcors = append(sc.Dcors, dcors...)
+	}
+	return sc
+}
+
+// consumesAny reports whether any of the given params consume one of the
+// given keys. Dynamic params are resolved on every call, so they never
+// consume a stale value.
+func consumesAny(keys map[key]struct{}, params ...param) bool {
+	for _, p := range params {
+		var k key
+		switch p := p.(type) {
+		case paramSingle:
+			k = key{t: p.Type, name: p.Name}
+		case paramGroupedSlice:
+			k = key{t: p.Type.Elem(), group: p.Group}
+		case paramNamedMap:
+			for k := range keys {
+				if k.t == p.Type.Elem() && k.name != "" {
+					return true
+				}
+			}
+			continue
+		case paramObject:
+			for _, f := range p.Fields {
+				if consumesAny(keys, f.Param) {
+					return true
+				}
+			}
+			continue
+		default:
+			continue
+		}
+
+		if _, ok := keys[k]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// rebuild discards the values cached by the given constructors and
+// decorators, and by everything that consumed those values, so that they
+// are built again when they are next requested.
+func (sc staleConsumers) rebuild() {
+	for _, n := range sc.Ctors {
+		n.invalidate()
+	}
+	for _, d := range sc.Dcors {
+		d.invalidate()
+	}
+}
+
+func (n *constructorNode) invalidate() {
+	if !n.s.wasCalled(n) {
+		return
+	}
+	delete(n.s.calledCtors, n)
+
+	keys := resultKeys(n.resultList)
+	if len(n.errorGroup) > 0 {
+		keys[key{group: n.errorGroup, t: _errType}] = struct{}{}
+	}
+	for _, k := range n.s.namedResults[n] {
+		keys[k] = struct{}{}
+	}
+	delete(n.s.namedResults, n)
+	for k := range keys {
+		if k.group == "" {
+			delete(n.s.values, k)
+			continue
+		}
+
+		// Only drop the values that this constructor contributed to the
+		// group.
+		values, members := n.s.groups[k], n.s.groupMembers[k]
+		var (
+			keptValues  = values[:0]
+			keptMembers = members[:0]
+		)
+		for i, m := range members {
+			if m.Source != n.location {
+				keptValues = append(keptValues, values[i])
+				keptMembers = append(keptMembers, m)
+			}
+		}
+		n.s.groups[k], n.s.groupMembers[k] = keptValues, keptMembers
+
+		omissions := n.s.groupOmissions[k]
+		keptOmissions := omissions[:0]
+		for _, m := range omissions {
+			if m.Source != n.location {
+				keptOmissions = append(keptOmissions, m)
+			}
+		}
+		n.s.groupOmissions[k] = keptOmissions
+	}
+
+	n.s.findStaleConsumers(keys).rebuild()
+}
+
+func (d *decoratorNode) invalidate() {
+	if d.state != decoratorCalled {
+		return
+	}
+	d.state = decoratorReady
+
+	keys := resultKeys(d.results)
+	for k := range keys {
+		if k.group == "" {
+			delete(d.s.decoratedValues, k)
+		} else {
+			delete(d.s.decoratedGroups, k)
+		}
+	}
+
+	d.s.findStaleConsumers(keys).rebuild()
+}
+
+// resultKeys returns the keys of all values produced by the given results.
+func resultKeys(rl resultList) map[key]struct{} {
+	keys := make(map[key]struct{})
+	for _, r := range rl.DotResult() {
+		keys[key{t: r.Type, name: r.Name, group: r.Group}] = struct{}{}
+	}
+	return keys
+}
+
+// errStaleValues is returned by Provide when the new constructor changes
+// values that were already consumed by constructors or decorators that were
+// called, and the AllowRebuild option is not set.
+type errStaleValues struct {
+	// Keys produced by the new constructor that were already consumed.
+	Keys []key
+
+	// Functions that consumed them, in the order they were found.
+	Consumers []*digreflect.Func
+}
+
+var _ digError = errStaleValues{}
+
+func newErrStaleValues(keys map[key]struct{}, sc staleConsumers) errStaleValues {
+	var params []param
+	for _, n := range sc.Ctors {
+		params = append(params, n.paramList.Params...)
+	}
+	for _, d := range sc.Dcors {
+		params = append(params, d.params.Params...)
+	}
+
+	var e errStaleValues
+	for k := range keys {
+		if consumesAny(map[key]struct{}{k: {}}, params...) {
+			e.Keys = append(e.Keys, k)
+		}
+	}
+	sort.Slice(e.Keys, func(i, j int) bool {
+		return e.Keys[i].String() < e.Keys[j].String()
+	})
+
+	for _, n := range sc.Ctors {
+		e.Consumers = append(e.Consumers, n.location)
+	}
+	for _, d := range sc.Dcors {
+		e.Consumers = append(e.Consumers, d.location)
+	}
+	return e
+}
+
+func (e errStaleValues) Error() string { return fmt.Sprint(e) }
+
+func (e errStaleValues) writeMessage(w io.Writer, verb string) {
+	io.WriteString(w, "cannot provide ")
+	for i, k := range e.Keys {
+		if i > 0 {
+			io.WriteString(w, ", ")
+		}
+		fmt.Fprint(w, k)
+	}
+	io.WriteString(w, ": already consumed by functions that were called: ")
+	for i, f := range e.Consumers {
+		if i > 0 {
+			io.WriteString(w, "; ")
+		}
+		fmt.Fprintf(w, verb, f)
+	}
+	io.WriteString(w, "; use the AllowRebuild option to rebuild their values instead")
+}
+
+func (e errStaleValues) Format(w fmt.State, c rune) {
+	formatError(e, w, c)
+}
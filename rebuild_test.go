@@ -0,0 +1,244 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestProvideAfterInvoke(t *testing.T) {
+	t.Parallel()
+
+	type Handler string
+	type Mux struct{ Handlers []Handler }
+	type Server struct{ Mux *Mux }
+
+	type muxParams struct {
+		dig.In
+
+		Handlers []Handler `group:"handlers"`
+	}
+
+	type muxResults struct {
+		dig.Out
+
+		Handlers []Handler `group:"handlers"`
+	}
+
+	newMux := func(p muxParams) *Mux { return &Mux{Handlers: p.Handlers} }
+
+	t.Run("unrelated values", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() int { return 1 })
+		c.RequireInvoke(func(int) {})
+		c.RequireProvide(func(i int) string { return "ok" })
+		c.RequireInvoke(func(s string) {
+			assert.Equal(t, "ok", s)
+		})
+	})
+
+	t.Run("group already consumed", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() Handler { return "a" }, dig.Group("handlers"))
+		c.RequireProvide(newMux)
+		c.RequireInvoke(func(*Mux) {})
+
+		err := c.Provide(func() Handler { return "b" }, dig.Group("handlers"))
+		require.Error(t, err)
+		assert.Regexp(t,
+			`cannot provide dig_test.Handler\[group="handlers"\]: already consumed by functions that were called: `+
+				`.*TestProvideAfterInvoke.func1 \(.*rebuild_test.go:\d+\); `+
+				`use the AllowRebuild option to rebuild their values instead`,
+			err.Error())
+
+		// The container is left unchanged.
+		c.RequireInvoke(func(m *Mux) {
+			assert.Equal(t, []Handler{"a"}, m.Handlers)
+		})
+	})
+
+	t.Run("group not yet consumed", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() Handler { return "a" }, dig.Group("handlers"))
+		c.RequireProvide(newMux)
+		c.RequireInvoke(func(muxParams) {})
+		c.RequireProvide(func() Handler { return "b" }, dig.Group("handlers"))
+		c.RequireInvoke(func(m *Mux) {
+			assert.ElementsMatch(t, []Handler{"a", "b"}, m.Handlers)
+		})
+	})
+
+	t.Run("optional value already found missing", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			Name string `optional:"true"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func(p params) Handler { return Handler(p.Name) })
+		c.RequireInvoke(func(Handler) {})
+
+		err := c.Provide(func() string { return "name" })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot provide string: already consumed by functions that were called")
+	})
+
+	t.Run("rebuild", func(t *testing.T) {
+		t.Parallel()
+
+		var handlerCalls, muxCalls, serverCalls, otherCalls int
+		c := digtest.New(t, dig.AllowRebuild())
+		c.RequireProvide(func() Handler {
+			handlerCalls++
+			return "a"
+		}, dig.Group("handlers"))
+		c.RequireProvide(func(p muxParams) *Mux {
+			muxCalls++
+			return newMux(p)
+		})
+		c.RequireProvide(func(m *Mux) *Server {
+			serverCalls++
+			return &Server{Mux: m}
+		})
+		c.RequireProvide(func() int {
+			otherCalls++
+			return 42
+		})
+		c.RequireInvoke(func(*Server, int) {})
+
+		c.RequireProvide(func() Handler { return "b" }, dig.Group("handlers"))
+		c.RequireInvoke(func(s *Server, _ int) {
+			assert.ElementsMatch(t, []Handler{"a", "b"}, s.Mux.Handlers)
+		})
+		assert.Equal(t, 1, handlerCalls, "existing group members must not be rebuilt")
+		assert.Equal(t, 2, muxCalls, "direct consumer must be rebuilt")
+		assert.Equal(t, 2, serverCalls, "transitive consumer must be rebuilt")
+		assert.Equal(t, 1, otherCalls, "unrelated values must not be rebuilt")
+	})
+
+	t.Run("rebuild drops group values of rebuilt constructors", func(t *testing.T) {
+		t.Parallel()
+
+		type Route int
+		type routeParams struct {
+			dig.In
+
+			Routes []Route `group:"routes"`
+		}
+
+		c := digtest.New(t, dig.AllowRebuild())
+		c.RequireProvide(func(p muxParams) Route {
+			return Route(len(p.Handlers))
+		}, dig.Group("routes"))
+		c.RequireInvoke(func(p routeParams) {
+			assert.Equal(t, []Route{0}, p.Routes)
+		})
+
+		c.RequireProvide(func() Handler { return "a" }, dig.Group("handlers"))
+		c.RequireInvoke(func(p routeParams) {
+			assert.Equal(t, []Route{1}, p.Routes)
+		})
+	})
+
+	t.Run("rebuild decorated values", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.AllowRebuild())
+		c.RequireProvide(func() Handler { return "a" }, dig.Group("handlers"))
+		c.RequireProvide(newMux)
+		c.RequireDecorate(func(p muxParams) muxResults {
+			return muxResults{Handlers: append(p.Handlers, "decorated")}
+		})
+		c.RequireInvoke(func(m *Mux) {
+			assert.ElementsMatch(t, []Handler{"a", "decorated"}, m.Handlers)
+		})
+
+		c.RequireProvide(func() Handler { return "b" }, dig.Group("handlers"))
+		c.RequireInvoke(func(m *Mux) {
+			assert.ElementsMatch(t, []Handler{"a", "b", "decorated"}, m.Handlers)
+		})
+	})
+
+	t.Run("rebuild in child scope", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.AllowRebuild())
+		c.RequireProvide(func() Handler { return "a" }, dig.Group("handlers"))
+		c.RequireProvide(newMux)
+		c.RequireInvoke(func(*Mux) {})
+
+		child := c.Scope("child")
+		child.RequireProvide(func(m *Mux) *Server { return &Server{Mux: m} })
+		child.RequireInvoke(func(*Server) {})
+
+		c.RequireProvide(func() Handler { return "b" }, dig.Group("handlers"))
+		child.RequireInvoke(func(s *Server) {
+			assert.ElementsMatch(t, []Handler{"a", "b"}, s.Mux.Handlers)
+		})
+	})
+
+	t.Run("rebuild reported errors", func(t *testing.T) {
+		t.Parallel()
+
+		type errParams struct {
+			dig.In
+
+			Errors []error `group:"errors"`
+		}
+
+		var calls int
+		c := digtest.New(t, dig.AllowRebuild())
+		c.RequireProvide(func(p muxParams) (*Mux, error) {
+			calls++
+			if len(p.Handlers) == 0 {
+				return nil, errors.New("no handlers")
+			}
+			return newMux(p), nil
+		}, dig.ReportErrorsToGroup("errors"))
+		c.RequireInvoke(func(p errParams) {
+			assert.Len(t, p.Errors, 1)
+		})
+
+		c.RequireProvide(func() Handler { return "a" }, dig.Group("handlers"))
+		c.RequireInvoke(func(p errParams) {
+			assert.Empty(t, p.Errors)
+		})
+		c.RequireInvoke(func(m *Mux) {
+			assert.Equal(t, []Handler{"a"}, m.Handlers)
+		})
+		assert.Equal(t, 2, calls)
+	})
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"go.uber.org/dig/internal/digreflect"
+)
+
+// ReentrantInvokeError is returned when a constructor calls Invoke or
+// Provide on its Container or Scope while it is being called. The graph is
+// being resolved at that point, so constructors that need to resolve other
+// values dynamically must depend on a Resolver instead:
+//
+//	c.Provide(func(r dig.Resolver) (*Plugin, error) {
+//	  var p Plugin
+//	  err := r.Invoke(func(cfg *Config) { p.cfg = cfg })
+//	  return &p, err
+//	})
+type ReentrantInvokeError struct {
+	// Op is the name of the method that the constructor called: "Invoke"
+	// or "Provide".
+	Op string
+
+	// The constructor that called Op.
+	ctor *digreflect.Func
+
+	// The function passed to the Invoke that is calling the constructor,
+	// if known.
+	invoke *digreflect.Func
+}
+
+var _ digError = ReentrantInvokeError{}
+
+func (e ReentrantInvokeError) Error() string { return fmt.Sprint(e) }
+
+func (e ReentrantInvokeError) writeMessage(w io.Writer, verb string) {
+	fmt.Fprintf(w, "constructor "+verb+" called %v", e.ctor, e.Op)
+	if e.invoke != nil {
+		fmt.Fprintf(w, " while it was being called to Invoke "+verb, e.invoke)
+	}
+	fmt.Fprint(w, ": depend on a dig.Resolver to resolve values from a constructor")
+}
+
+func (e ReentrantInvokeError) Format(w fmt.State, c rune) {
+	formatError(e, w, c)
+}
+
+// _digPackage is the import path of this package, which prefixes the names
+// of its functions in stack traces.
+var _digPackage = reflect.TypeOf(Scope{}).PkgPath()
+
+// Functions of this package that delimit a reentrant call in a stack trace.
+var (
+	_constructorCallFunc = _digPackage + ".(*constructorNode).call"
+	_invokerInvokeFunc   = _digPackage + ".(*Invoker).invoke"
+	_resolverInvokeFunc  = _digPackage + ".scopeResolver.Invoke"
+)
+
+// trackCall records that a constructor owned by a Scope of this root Scope
+// is being called for the given invocation, and returns a function that
+// records that the call completed.
+func (s *Scope) trackCall(inv *invocation) (end func()) {
+	unlock := s.lock()
+	if s.activeCalls == nil {
+		s.activeCalls = make(map[*invocation]int)
+	}
+	s.activeCalls[inv]++
+	unlock()
+	return func() {
+		defer s.lock()()
+		if s.activeCalls[inv]--; s.activeCalls[inv] == 0 {
+			delete(s.activeCalls, inv)
+		}
+	}
+}
+
+// checkReentrant fails if the caller of the function calling checkReentrant
+// is a constructor that is being called by this package. op names the
+// calling function in the error.
+//
+// dig calls constructors on the goroutine that called Invoke, so the stack
+// of the current goroutine tells whether it is running inside a
+// constructor. The stack is only inspected while some constructor of the
+// root Scope is being called, and the constructor found on it is only
+// reported if it is one of those being called: it may belong to another
+// Container. The function passed to Invoke is only reported if a single
+// Invoke is calling constructors.
+func (s *Scope) checkReentrant(op string) error {
+	root := s.rootScope()
+	unlock := s.lock()
+	calls := len(root.activeCalls)
+	var invoke *digreflect.Func
+	if calls == 1 {
+		for inv := range root.activeCalls {
+			invoke = inv.fn
+		}
+	}
+	unlock()
+	if calls == 0 {
+		return nil
+	}
+
+	frames := runtime.CallersFrames(callers())
+
+	// The innermost function outside this package that was called through
+	// reflection: the constructor, if the stack leads to a constructor call.
+	var caller, ctor *runtime.Frame
+	for {
+		frame, more := frames.Next()
+		switch fn := frame.Function; {
+		case fn == _constructorCallFunc:
+			if ctor == nil || !root.isCallingFunc(ctor.Entry) {
+				return nil
+			}
+			return ReentrantInvokeError{
+				Op:     op,
+				ctor:   inspectFrame(ctor),
+				invoke: invoke,
+			}
+		case fn == _invokerInvokeFunc, fn == _resolverInvokeFunc:
+			// Called from a function passed to Invoke, or through a
+			// Resolver.
+			return nil
+		case strings.HasPrefix(fn, "reflect."):
+			if ctor == nil {
+				ctor = caller
+			}
+		case strings.HasPrefix(fn, _digPackage+"."), strings.HasPrefix(fn, "runtime."):
+		default:
+			f := frame
+			caller = &f
+		}
+		if !more {
+			return nil
+		}
+	}
+}
+
+// callers returns the program counters of the whole stack of the caller of
+// checkReentrant, which a constructor may have called from deep within
+// its own calls.
+func callers() []uintptr {
+	pcs := make([]uintptr, 64)
+	for {
+		// Skip runtime.Callers, callers, checkReentrant, and its caller.
+		n := runtime.Callers(4, pcs)
+		if n < len(pcs) {
+			return pcs[:n]
+		}
+		pcs = make([]uintptr, 2*len(pcs))
+	}
+}
+
+// isCallingFunc reports whether a constructor of a Scope of this root
+// Scope whose function starts at the given address is being called.
+func (s *Scope) isCallingFunc(entry uintptr) bool {
+	defer s.lock()()
+	for _, s := range s.appendSubscopes(nil) {
+		for n := range s.inflightCtors {
+			if n.fn == entry {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// inspectFrame returns information about the function running in the given
+// stack frame.
+func inspectFrame(frame *runtime.Frame) *digreflect.Func {
+	if f := digreflect.InspectFuncPC(frame.Entry); f != nil {
+		return f
+	}
+	return &digreflect.Func{Name: frame.Function, File: frame.File, Line: frame.Line}
+}
@@ -0,0 +1,210 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestReentrantInvoke(t *testing.T) {
+	t.Parallel()
+
+	type A struct{}
+	type B struct{}
+
+	t.Run("invoke from constructor", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *B { return &B{} })
+
+		var innerErr error
+		newA := func() *A {
+			innerErr = c.Invoke(func(*B) {})
+			return &A{}
+		}
+		c.RequireProvide(newA)
+		run := func(*A) {}
+		c.RequireInvoke(run)
+
+		var rerr dig.ReentrantInvokeError
+		require.True(t, errors.As(innerErr, &rerr), "expected a ReentrantInvokeError")
+		assert.Equal(t, "Invoke", rerr.Op)
+		var derr dig.Error
+		assert.True(t, errors.As(innerErr, &derr), "expected a dig.Error")
+		assert.Regexp(t,
+			`constructor "go.uber.org/dig_test".TestReentrantInvoke.func1.2 \(\S+:\d+\) called Invoke`+
+				` while it was being called to Invoke "go.uber.org/dig_test".TestReentrantInvoke.func1.3 \(\S+:\d+\)`+
+				`: depend on a dig.Resolver to resolve values from a constructor`,
+			innerErr.Error())
+	})
+
+	t.Run("invoke from helper of constructor", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *B { return &B{} })
+
+		resolve := func() error {
+			return c.Invoke(func(*B) {})
+		}
+		c.RequireProvide(func() (*A, error) {
+			return &A{}, resolve()
+		})
+
+		err := c.Invoke(func(*A) {})
+		require.Error(t, err)
+		var rerr dig.ReentrantInvokeError
+		require.True(t, errors.As(err, &rerr), "expected a ReentrantInvokeError")
+		assert.Contains(t, rerr.Error(), "TestReentrantInvoke.func2.3")
+	})
+
+	t.Run("invoke from deep within constructor", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *B { return &B{} })
+
+		// Calls Invoke below more stack frames than are read at once.
+		var resolve func(depth int) error
+		resolve = func(depth int) error {
+			if depth == 0 {
+				return c.Invoke(func(*B) {})
+			}
+			return resolve(depth - 1)
+		}
+		c.RequireProvide(func() (*A, error) {
+			return &A{}, resolve(100)
+		})
+
+		err := c.Invoke(func(*A) {})
+		require.Error(t, err)
+		var rerr dig.ReentrantInvokeError
+		assert.True(t, errors.As(err, &rerr), "expected a ReentrantInvokeError")
+	})
+
+	t.Run("constructor of another container", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			other   = digtest.New(t)
+			started = make(chan struct{})
+			release = make(chan struct{})
+		)
+		other.RequireProvide(func() *B {
+			close(started)
+			<-release
+			return &B{}
+		})
+		done := make(chan error)
+		go func() { done <- other.Invoke(func(*B) {}) }()
+		<-started
+		defer func() {
+			close(release)
+			assert.NoError(t, <-done)
+		}()
+
+		// other is calling a constructor, but not this one.
+		c := digtest.New(t)
+		c.RequireProvide(func() *A {
+			other.RequireProvide(func() string { return "" })
+			return &A{}
+		})
+		c.RequireInvoke(func(*A) {})
+	})
+
+	t.Run("provide during invoke", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+
+		var innerErr error
+		c.RequireProvide(func() *A {
+			innerErr = c.Provide(func() *B { return &B{} })
+			return &A{}
+		})
+		c.RequireInvoke(func(*A) {})
+
+		var rerr dig.ReentrantInvokeError
+		require.True(t, errors.As(innerErr, &rerr), "expected a ReentrantInvokeError")
+		assert.Equal(t, "Provide", rerr.Op)
+		assert.ErrorContains(t, innerErr, "called Provide while it was being called to Invoke")
+
+		ok, err := c.PeekValue(new(*B))
+		require.NoError(t, err)
+		assert.False(t, ok, "*B must not be provided")
+	})
+
+	t.Run("scope", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		s := c.Scope("child")
+
+		var innerErr error
+		s.RequireProvide(func() *A {
+			innerErr = s.Invoke(func() {})
+			return &A{}
+		})
+		s.RequireInvoke(func(*A) {})
+
+		var rerr dig.ReentrantInvokeError
+		assert.True(t, errors.As(innerErr, &rerr), "expected a ReentrantInvokeError")
+	})
+
+	t.Run("resolver", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *B { return &B{} })
+		c.RequireProvide(func(r dig.Resolver) (*A, error) {
+			return &A{}, r.Invoke(func(*B) {})
+		})
+		c.RequireInvoke(func(*A) {})
+	})
+
+	t.Run("invoke from invoked function", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{} })
+		c.RequireProvide(func() *B { return &B{} })
+		c.RequireInvoke(func(*A) {
+			c.RequireInvoke(func(*B) {})
+			c.RequireProvide(func() string { return "" })
+		})
+	})
+
+	t.Run("provide after invoke", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{} })
+		c.RequireInvoke(func(*A) {})
+		c.RequireProvide(func() *B { return &B{} })
+	})
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"go.uber.org/dig/internal/digreflect"
+)
+
+// Remove removes the constructor identified by the given ProvideInfo, as
+// filled by the FillProvideInfo option, from the Container. This allows
+// applications that load modules at runtime to unload them.
+//
+//	var info dig.ProvideInfo
+//	c.Provide(NewPlugin, dig.FillProvideInfo(&info))
+//	...
+//	err := c.Remove(info)
+//
+// The values that the constructor built are discarded, including its
+// contributions to value groups, along with the values built by the
+// constructors and decorators that consumed them, so that they are built
+// again the next time they are needed. The constructor may then be
+// replaced by providing another one for the same types.
+//
+// Remove fails and leaves the Container unchanged if another constructor
+// or decorator requires a value that no other constructor provides.
+// Constructors provided to any Scope of the Container may be removed. Each
+// call to Provide creates a constructor with its own ID, so a function that
+// was provided more than once is removed one constructor at a time.
+func (c *Container) Remove(info ProvideInfo) error {
+	n := c.scope.findConstructor(info.ID)
+	if n == nil {
+		return newErrInvalidInput(fmt.Sprintf("cannot remove constructor with ID %v: it was not provided", info.ID), nil)
+	}
+
+	if orphans := n.findOrphans(); len(orphans) > 0 {
+		return errOrphanedConsumers{Func: n.location, Orphans: orphans}
+	}
+
+	// Discard values built from this constructor while it can still be
+	// found as the provider of its values.
+	n.invalidate()
+
+	s := n.s
+	for k, nodes := range s.providers {
+		s.providers[k] = removeConstructor(nodes, n)
+		if len(s.providers[k]) == 0 {
+			delete(s.providers, k)
+		}
+	}
+	s.nodes = removeConstructor(s.nodes, n)
+	s.namedResultsCtors = removeConstructor(s.namedResultsCtors, n)
+
+	root := s.rootScope()
+	for _, scope := range root.appendSubscopes(nil) {
+		scope.gh.Remove(n)
+	}
+	root.numProviders--
+	root.generation++
+	return nil
+}
+
+// findConstructor returns the constructor with the given ID provided to this
+// Scope or any of its descendants, or nil if there is none.
+func (s *Scope) findConstructor(id ID) *constructorNode {
+	for _, s := range s.appendSubscopes(nil) {
+		for _, n := range s.nodes {
+			if ID(n.id) == id {
+				return n
+			}
+		}
+	}
+	return nil
+}
+
+// removeConstructor returns the given constructors without n.
+func removeConstructor(nodes []*constructorNode, n *constructorNode) []*constructorNode {
+	kept := make([]*constructorNode, 0, len(nodes))
+	for _, other := range nodes {
+		if other != n {
+			kept = append(kept, other)
+		}
+	}
+	return kept
+}
+
+// orphanedConsumer is a function that requires a value that would have no
+// provider if a constructor was removed.
+type orphanedConsumer struct {
+	Key      key
+	Consumer *digreflect.Func
+}
+
+// findOrphans returns the constructors and decorators that require a value
+// that only this constructor provides.
+func (n *constructorNode) findOrphans() []orphanedConsumer {
+	keys := resultKeys(n.resultList)
+
+	var orphans []orphanedConsumer
+	for _, scope := range n.s.appendSubscopes(nil) {
+		orphaned := func(consumer *digreflect.Func, params []param) {
+			for _, ps := range requiredValues(params) {
+				k := key{t: ps.Type, name: ps.Name}
+				if _, ok := keys[k]; !ok {
+					continue
+				}
+				if len(removeProvider(scope.getAllValueProviders(k.name, k.t), n)) == 0 {
+					orphans = append(orphans, orphanedConsumer{Key: k, Consumer: consumer})
+				}
+			}
+		}
+
+		for _, m := range scope.nodes {
+			if m != n {
+				orphaned(m.location, m.paramList.Params)
+			}
+		}
+		for _, d := range scope.decorators {
+			orphaned(d.location, d.params.Params)
+		}
+	}
+	return orphans
+}
+
+// removeProvider returns the given providers without n.
+func removeProvider(providers []provider, n *constructorNode) []provider {
+	kept := providers[:0:0]
+	for _, p := range providers {
+		if p != provider(n) {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// requiredValues returns the single values that the given params require.
+func requiredValues(params []param) []paramSingle {
+	var values []paramSingle
+	for _, p := range params {
+		switch p := p.(type) {
+		case paramSingle:
+			if !p.Optional {
+				values = append(values, p)
+			}
+		case paramDynamic:
+			values = append(values, requiredValues([]param{p.Value})...)
+		case paramObject:
+			for _, f := range p.Fields {
+				values = append(values, requiredValues([]param{f.Param})...)
+			}
+		}
+	}
+	return values
+}
+
+// errOrphanedConsumers is returned by Remove when other functions require
+// values that only the removed constructor provides.
+type errOrphanedConsumers struct {
+	Func    *digreflect.Func
+	Orphans []orphanedConsumer
+}
+
+var _ digError = errOrphanedConsumers{}
+
+func (e errOrphanedConsumers) Error() string { return fmt.Sprint(e) }
+
+func (e errOrphanedConsumers) writeMessage(w io.Writer, verb string) {
+	orphans := make([]string, len(e.Orphans))
+	for i, o := range e.Orphans {
+		orphans[i] = fmt.Sprintf("%v required by "+verb, o.Key, o.Consumer)
+	}
+	fmt.Fprintf(w, "cannot remove "+verb+": no other function provides %v",
+		e.Func, strings.Join(orphans, "; "))
+}
+
+func (e errOrphanedConsumers) Format(w fmt.State, c rune) {
+	formatError(e, w, c)
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestRemove(t *testing.T) {
+	t.Parallel()
+
+	type Plugin struct{ Name string }
+	type Registry struct{ Plugins []string }
+
+	t.Run("replace a constructor", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		var info dig.ProvideInfo
+		c.RequireProvide(func() *Plugin { return &Plugin{Name: "old"} }, dig.FillProvideInfo(&info))
+		c.RequireInvoke(func(p *Plugin) {
+			assert.Equal(t, "old", p.Name)
+		})
+
+		require.NoError(t, c.Remove(info))
+		assert.Error(t, c.Invoke(func(*Plugin) {}), "the value must be gone")
+
+		c.RequireProvide(func() *Plugin { return &Plugin{Name: "new"} })
+		c.RequireInvoke(func(p *Plugin) {
+			assert.Equal(t, "new", p.Name)
+		})
+	})
+
+	t.Run("downstream values are rebuilt", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			Plugins []*Plugin `group:"plugins"`
+		}
+
+		c := digtest.New(t)
+		var info dig.ProvideInfo
+		c.RequireProvide(func() *Plugin { return &Plugin{Name: "a"} }, dig.Group("plugins"), dig.FillProvideInfo(&info))
+		c.RequireProvide(func() *Plugin { return &Plugin{Name: "b"} }, dig.Group("plugins"))
+		calls := 0
+		c.RequireProvide(func(p params) *Registry {
+			calls++
+			r := &Registry{}
+			for _, p := range p.Plugins {
+				r.Plugins = append(r.Plugins, p.Name)
+			}
+			return r
+		})
+
+		c.RequireInvoke(func(r *Registry) {
+			assert.ElementsMatch(t, []string{"a", "b"}, r.Plugins)
+		})
+
+		require.NoError(t, c.Remove(info))
+		c.RequireInvoke(func(r *Registry) {
+			assert.Equal(t, []string{"b"}, r.Plugins)
+		})
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("required by other constructors", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		var info dig.ProvideInfo
+		c.RequireProvide(func() *Plugin { return &Plugin{Name: "old"} }, dig.FillProvideInfo(&info))
+		c.RequireProvide(func(*Plugin) *Registry { return &Registry{} })
+		c.RequireProvide(func(struct {
+			dig.In
+
+			Plugin *Plugin `optional:"true"`
+		}) string {
+			return ""
+		})
+		c.RequireInvoke(func(*Registry) {})
+
+		err := c.Remove(info)
+		require.Error(t, err)
+		assert.Regexp(t, `cannot remove .*TestRemove.func3.1 .*: no other function provides \*dig_test.Plugin required by .*TestRemove.func3.2`, err.Error())
+		assert.NotContains(t, err.Error(), "func3.3", "optional consumers are not affected")
+
+		// The container is unchanged.
+		c.RequireInvoke(func(p *Plugin, _ *Registry) {
+			assert.Equal(t, "old", p.Name)
+		})
+
+		// Constructors that have other providers for their values may be
+		// removed.
+		child := c.Scope("child")
+		var childInfo dig.ProvideInfo
+		child.RequireProvide(func() *Plugin { return &Plugin{Name: "child"} }, dig.FillProvideInfo(&childInfo))
+		child.RequireProvide(func(*Plugin) int { return 0 })
+		require.NoError(t, c.Remove(childInfo))
+		child.RequireInvoke(func(p *Plugin) {
+			assert.Equal(t, "old", p.Name)
+		})
+	})
+
+	t.Run("unknown constructor", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		var info dig.ProvideInfo
+		c.RequireProvide(func() *Plugin { return nil }, dig.FillProvideInfo(&info))
+		require.NoError(t, c.Remove(info))
+
+		err := c.Remove(info)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot remove constructor with ID")
+		assert.Contains(t, err.Error(), "it was not provided")
+	})
+
+	t.Run("cycle detection ignores removed constructors", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		var info dig.ProvideInfo
+		c.RequireProvide(func(*Registry) *Plugin { return &Plugin{} }, dig.FillProvideInfo(&info))
+		require.NoError(t, c.Remove(info))
+		c.RequireProvide(func(*Plugin) *Registry { return &Registry{} })
+		c.RequireProvide(func() *Plugin { return &Plugin{Name: "leaf"} })
+		c.RequireInvoke(func(r *Registry) {
+			assert.NotNil(t, r)
+		})
+	})
+
+	t.Run("provided values", func(t *testing.T) {
+		t.Parallel()
+
+		// Both constructors are built with reflect.MakeFunc, so they share
+		// the address of their function.
+		c := digtest.New(t)
+		var name, count dig.ProvideInfo
+		require.NoError(t, c.ProvideValue(reflect.ValueOf("plugin"), dig.FillProvideInfo(&name)))
+		require.NoError(t, c.ProvideValue(reflect.ValueOf(42), dig.FillProvideInfo(&count)))
+		assert.NotEqual(t, name.ID, count.ID)
+
+		require.NoError(t, c.Remove(name))
+		assert.Error(t, c.Invoke(func(string) {}), "the string must be gone")
+		c.RequireInvoke(func(i int) {
+			assert.Equal(t, 42, i)
+		})
+	})
+
+	t.Run("max providers", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.MaxProviders(1))
+		var info dig.ProvideInfo
+		c.RequireProvide(func() *Plugin { return nil }, dig.FillProvideInfo(&info))
+		require.NoError(t, c.Remove(info))
+		c.RequireProvide(func() *Registry { return nil })
+	})
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import "fmt"
+
+// ReportErrorsToGroup is a ProvideOption that makes failures of the
+// constructor non-fatal. If the constructor, or any of its dependencies,
+// fails, the error is added to the value group with the given name as a
+// ReportedError instead of being returned, and none of the constructor's
+// results are added to the container.
+//
+// This allows optional subsystems to fail without failing the whole
+// application, while still reporting what failed.
+//
+//	c.Provide(NewMetrics, dig.ReportErrorsToGroup("init-errors"))
+//
+//	type Params struct {
+//	  dig.In
+//
+//	  Metrics *Metrics `optional:"true"`
+//	  Errs    []error  `group:"init-errors"`
+//	}
+//
+// Consumers that depend on the results of a failed constructor fail unless
+// those dependencies are optional.
+func ReportErrorsToGroup(group string) ProvideOption {
+	return provideErrorGroupOption(group)
+}
+
+type provideErrorGroupOption string
+
+func (o provideErrorGroupOption) String() string {
+	return fmt.Sprintf("ReportErrorsToGroup(%q)", string(o))
+}
+
+func (o provideErrorGroupOption) applyProvideOption(opts *provideOptions) {
+	opts.ErrorGroup = string(o)
+}
+
+// ReportedError is added to a value group when a constructor provided with
+// the ReportErrorsToGroup option fails. It records where the constructor
+// was defined.
+type ReportedError struct {
+	// Package, name, file, and line of the constructor that failed.
+	Package  string
+	Function string
+	File     string
+	Line     int
+
+	// Err is the reason the constructor failed.
+	Err error
+}
+
+// Error returns the message of the underlying error, which names the
+// constructor that failed.
+func (e ReportedError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the reason the constructor failed.
+func (e ReportedError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestReportErrorsToGroup(t *testing.T) {
+	t.Parallel()
+
+	type Metrics struct{}
+	type Tracer struct{}
+
+	type params struct {
+		dig.In
+
+		Metrics *Metrics `optional:"true"`
+		Tracer  *Tracer  `optional:"true"`
+		Errs    []error  `group:"init-errors"`
+	}
+
+	t.Run("failures are collected", func(t *testing.T) {
+		c := digtest.New(t)
+		giveErr := errors.New("great sadness")
+		c.RequireProvide(func() (*Metrics, error) {
+			return nil, giveErr
+		}, dig.ReportErrorsToGroup("init-errors"))
+		c.RequireProvide(func() (*Tracer, error) {
+			return &Tracer{}, nil
+		}, dig.ReportErrorsToGroup("init-errors"))
+
+		c.RequireInvoke(func(p params) {
+			assert.Nil(t, p.Metrics)
+			assert.NotNil(t, p.Tracer)
+			require.Len(t, p.Errs, 1)
+
+			var rerr dig.ReportedError
+			require.True(t, errors.As(p.Errs[0], &rerr))
+			assert.Equal(t, "go.uber.org/dig_test", rerr.Package)
+			assert.Contains(t, rerr.Function, "TestReportErrorsToGroup")
+			assert.Contains(t, rerr.File, "report_test.go")
+			assert.Equal(t, giveErr, dig.RootCause(rerr))
+			assert.True(t, errors.Is(p.Errs[0], giveErr))
+			assert.Contains(t, p.Errs[0].Error(), "received non-nil error from function")
+		})
+	})
+
+	t.Run("dependency failures are collected", func(t *testing.T) {
+		type Exporter struct{}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (*Metrics, error) {
+			return nil, errors.New("great sadness")
+		})
+		c.RequireProvide(func(*Metrics) *Exporter {
+			return &Exporter{}
+		}, dig.ReportErrorsToGroup("init-errors"))
+
+		type in struct {
+			dig.In
+
+			Exporter *Exporter `optional:"true"`
+			Errs     []error   `group:"init-errors"`
+		}
+		c.RequireInvoke(func(p in) {
+			assert.Nil(t, p.Exporter)
+			require.Len(t, p.Errs, 1)
+			assert.Contains(t, p.Errs[0].Error(), "could not build arguments for function")
+		})
+	})
+
+	t.Run("constructor is called once", func(t *testing.T) {
+		c := digtest.New(t)
+
+		var calls int
+		c.RequireProvide(func() (*Metrics, error) {
+			calls++
+			return nil, errors.New("great sadness")
+		}, dig.ReportErrorsToGroup("init-errors"))
+
+		c.RequireInvoke(func(params) {})
+		c.RequireInvoke(func(params) {})
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("required consumers fail", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(func() (*Metrics, error) {
+			return nil, errors.New("great sadness")
+		}, dig.ReportErrorsToGroup("init-errors"))
+
+		err := c.Invoke(func(*Metrics) {})
+		require.Error(t, err)
+		dig.AssertErrorMatches(t, err,
+			`could not build arguments for function "go.uber.org/dig_test".TestReportErrorsToGroup\S+`,
+			`report_test.go:\d+`,
+			`failed to build \*dig_test.Metrics:`,
+			`received non-nil error from function "go.uber.org/dig_test".TestReportErrorsToGroup\S+`,
+			`report_test.go:\d+`,
+			"great sadness",
+		)
+	})
+
+	t.Run("no failures", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(func() *Metrics { return &Metrics{} }, dig.ReportErrorsToGroup("init-errors"))
+
+		c.RequireInvoke(func(p params) {
+			assert.NotNil(t, p.Metrics)
+			assert.Empty(t, p.Errs)
+		})
+	})
+
+	t.Run("invalid group", func(t *testing.T) {
+		c := digtest.New(t)
+		err := c.Provide(func() *Metrics { return nil }, dig.ReportErrorsToGroup("foo`bar"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid dig.ReportErrorsToGroup(\"foo`bar\"): group names cannot contain backquotes")
+	})
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"reflect"
+
+	"go.uber.org/dig/internal/dot"
+)
+
+// Resolver is a read-only view of a Scope or Container. It can resolve
+// values, but cannot provide or decorate them.
+//
+// Constructors, decorators, and invoked functions may depend on a Resolver
+// to look up values dynamically, for example in plugins that should not be
+// able to change the container they are loaded into.
+//
+//	c.Provide(func(r dig.Resolver) (*Plugin, error) {
+//	  var p Plugin
+//	  err := r.Invoke(func(cfg *Config) { p.cfg = cfg })
+//	  return &p, err
+//	})
+//
+// The Resolver resolves values from the Scope that the function was
+// provided to, or the Scope that invoked it. A constructor must not use
+// its Resolver to request the values that it is building: Invoke fails
+// with an error for which IsCycleDetected returns true if it would need to
+// call the constructor, or a constructor that depends on it, again.
+//
+// Constructors must use a Resolver rather than the Container or Scope they
+// were provided to: Invoke and Provide fail with a ReentrantInvokeError
+// when called by a constructor.
+type Resolver interface {
+	// Invoke runs the given function after instantiating its
+	// dependencies. See Scope.Invoke.
+	Invoke(function interface{}, opts ...InvokeOption) error
+
+	// PeekValue reads a value that was already built without calling any
+	// constructors. See Scope.PeekValue.
+	PeekValue(target interface{}, opts ...ResolveOption) (bool, error)
+}
+
+var (
+	_ Resolver = (*Container)(nil)
+	_ Resolver = (*Scope)(nil)
+)
+
+// _resolverType is the type of the Resolver that functions may depend on.
+var _resolverType = reflect.TypeOf((*Resolver)(nil)).Elem()
+
+// scopeResolver is the Resolver given to functions. It wraps their Scope so
+// that it cannot be type-asserted back to a *Scope, and the call that the
+// function was given to, so that Invokes through it are nested in that call.
+type scopeResolver struct {
+	s     *Scope
+	frame *callFrame
+}
+
+func (r scopeResolver) Invoke(function interface{}, opts ...InvokeOption) error {
+	options := newInvokeOptions(opts)
+	options.Caller = r.frame
+	return r.s.invoke(function, options)
+}
+
+func (r scopeResolver) PeekValue(target interface{}, opts ...ResolveOption) (bool, error) {
+	return r.s.PeekValue(target, opts...)
+}
+
+// paramResolver is a dependency on a Resolver for the Scope that builds the
+// function's parameters, which the container provides itself.
+type paramResolver struct{}
+
+var _ param = paramResolver{}
+
+func (paramResolver) String() string {
+	return _resolverType.String()
+}
+
+// DotParam returns nothing: the Resolver is not part of the graph.
+func (paramResolver) DotParam() []*dot.Param {
+	return nil
+}
+
+func (paramResolver) Build(c containerStore) (reflect.Value, error) {
+	s := c.storesToRoot()[0].(*Scope)
+	var r Resolver = scopeResolver{s: s, frame: c.callFrame()}
+	return reflect.ValueOf(&r).Elem(), nil
+}
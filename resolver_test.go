@@ -0,0 +1,176 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestResolver(t *testing.T) {
+	t.Parallel()
+
+	type config struct{ name string }
+	type plugin struct{ cfg *config }
+
+	t.Run("constructor", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *config { return &config{name: "foo"} })
+		c.RequireProvide(func(r dig.Resolver) (*plugin, error) {
+			var p plugin
+			err := r.Invoke(func(cfg *config) { p.cfg = cfg })
+			return &p, err
+		})
+		c.RequireInvoke(func(p *plugin) {
+			assert.Equal(t, "foo", p.cfg.name)
+		})
+	})
+
+	t.Run("parameter object", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			Resolver dig.Resolver
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *config { return &config{name: "bar"} })
+		c.RequireInvoke(func(p params) {
+			var cfg *config
+			ok, err := p.Resolver.PeekValue(&cfg)
+			require.NoError(t, err)
+			assert.False(t, ok, "config must not be built yet")
+
+			require.NoError(t, p.Resolver.Invoke(func(*config) {}))
+			ok, err = p.Resolver.PeekValue(&cfg)
+			require.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, "bar", cfg.name)
+		})
+	})
+
+	t.Run("cannot provide", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireInvoke(func(r dig.Resolver) {
+			_, ok := r.(*dig.Scope)
+			assert.False(t, ok, "resolver must not expose the scope")
+			_, ok = r.(*dig.Container)
+			assert.False(t, ok, "resolver must not expose the container")
+		})
+	})
+
+	t.Run("wraps the providing scope", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		child := c.Scope("child")
+		child.RequireProvide(func() *config { return &config{name: "child"} })
+		child.RequireProvide(func(r dig.Resolver) (*plugin, error) {
+			var p plugin
+			err := r.Invoke(func(cfg *config) { p.cfg = cfg })
+			return &p, err
+		})
+		child.RequireInvoke(func(p *plugin) {
+			assert.Equal(t, "child", p.cfg.name)
+		})
+
+		err := c.Invoke(func(r dig.Resolver) error {
+			return r.Invoke(func(*config) {})
+		})
+		require.Error(t, err, "root resolver must not see child values")
+		assert.Contains(t, err.Error(), "missing type: *dig_test.config")
+	})
+
+	t.Run("constructor resolves its own result", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func(r dig.Resolver) (*plugin, error) {
+			err := r.Invoke(func(*plugin) {})
+			return &plugin{}, err
+		})
+
+		err := c.Invoke(func(*plugin) {})
+		require.Error(t, err)
+		assert.True(t, dig.IsCycleDetected(err))
+		dig.AssertErrorMatches(t, err,
+			`could not build arguments for function "go.uber.org/dig_test".TestResolver\S+`,
+			`failed to build \*dig_test.plugin`,
+			`received non-nil error from function "go.uber.org/dig_test".TestResolver\S+`,
+			`could not build arguments for function "go.uber.org/dig_test".TestResolver\S+`,
+			`failed to build \*dig_test.plugin:`,
+			`func\(dig.Resolver\) \(\*dig_test.plugin, error\) provided by "go.uber.org/dig_test".TestResolver\S+`,
+			`depends on func\(dig.Resolver\) \(\*dig_test.plugin, error\) provided by "go.uber.org/dig_test".TestResolver\S+`,
+		)
+	})
+
+	t.Run("dependency resolves a dependent", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func(r dig.Resolver) (*config, error) {
+			err := r.Invoke(func(*plugin) {})
+			return &config{}, err
+		})
+		c.RequireProvide(func(cfg *config) *plugin { return &plugin{cfg: cfg} })
+
+		err := c.Invoke(func(*plugin) {})
+		require.Error(t, err)
+		assert.True(t, dig.IsCycleDetected(err))
+		assert.Contains(t, err.Error(),
+			"func(*dig_test.config) *dig_test.plugin provided by")
+		assert.Contains(t, err.Error(),
+			"depends on func(dig.Resolver) (*dig_test.config, error) provided by")
+	})
+
+	t.Run("kept after the constructor returns", func(t *testing.T) {
+		t.Parallel()
+
+		type in struct {
+			dig.In
+
+			Plugins []*plugin `group:"plugins"`
+		}
+
+		var resolver dig.Resolver
+		c := digtest.New(t)
+		c.RequireProvide(func(r dig.Resolver) *plugin {
+			resolver = r
+			return &plugin{}
+		}, dig.Group("plugins"))
+
+		var first []*plugin
+		c.RequireInvoke(func(p in) { first = p.Plugins })
+		require.NoError(t, resolver.Invoke(func(p in) {
+			assert.Equal(t, first, p.Plugins)
+		}))
+	})
+}
@@ -23,6 +23,7 @@ package dig
 import (
 	"fmt"
 	"reflect"
+	"strings"
 
 	"go.uber.org/dig/internal/digerror"
 	"go.uber.org/dig/internal/dot"
@@ -63,16 +64,66 @@ type resultOptions struct {
 	Name  string
 	Group string
 	As    []interface{}
+
+	// Whether values are also provided as the interfaces registered with
+	// RegisterInterface that they implement.
+	AsImplemented bool
+
+	// If set, computes the names of results that have no name. For Result
+	// Objects, this applies to the fields without name or group tags.
+	NameFunc func(reflect.Type) string
+
+	// Struct tag keys to read from the fields of result objects.
+	Tags tagKeys
+
+	// If specified, struct tags to apply to the non-error results of the
+	// constructor, in order.
+	ResultTags []string
+
+	// If specified, namespace of all value groups in the results.
+	GroupNamespace string
+
+	// Whether results may be cleanup functions rather than values. Only
+	// constructors return cleanups.
+	Cleanups bool
+
+	// Whether a func() or func(context.Context) error result is a cleanup
+	// function. Requires Cleanups.
+	AutoCleanup bool
+
+	// Whether an io.Closer result is a cleanup function. Requires Cleanups.
+	AutoClose bool
+
+	// Whether NamedResults may be returned. Only constructors return
+	// NamedResults.
+	NamedResults bool
+
+	// Whether unexported fields of result objects are skipped. Nested
+	// result objects inherit this unless their dig.Out embed sets the
+	// ignore-unexported tag itself.
+	IgnoreUnexported bool
+
+	// Whether nil values are left out of the value groups that results
+	// are added to. Set with OmitNilFromGroup.
+	OmitNilFromGroup bool
 }
 
 // newResult builds a result from the given type.
 func newResult(t reflect.Type, opts resultOptions) (result, error) {
 	switch {
-	case IsIn(t) || (t.Kind() == reflect.Ptr && IsIn(t.Elem())) || embedsType(t, _inPtrType):
-		return nil, newErrInvalidInput(fmt.Sprintf(
-			"cannot provide parameter objects: %v embeds a dig.In", t), nil)
+	case isParamObject(t):
+		return nil, errMisplacedObject{Type: t, Marker: _inType}
 	case isError(t):
 		return nil, newErrInvalidInput("cannot return an error here, return it from the constructor instead", nil)
+	case t == _cleanupFuncType:
+		return nil, newErrInvalidInput(fmt.Sprintf(
+			"cannot provide %v: it is provided by the container to register cleanup functions", t), nil)
+	case t == _runModeType:
+		return nil, newErrInvalidInput(fmt.Sprintf(
+			"cannot provide %v: it is provided by the container to report whether it is in a dry run", t), nil)
+	case t == _namedResultsType:
+		return nil, newErrInvalidInput(fmt.Sprintf(
+			"cannot provide %v here: it must be the only non-error result of a constructor", t), nil)
 	case IsOut(t):
 		return newResultObject(t, opts)
 	case embedsType(t, _outPtrType):
@@ -81,16 +132,18 @@ func newResult(t reflect.Type, opts resultOptions) (result, error) {
 	case t.Kind() == reflect.Ptr && IsOut(t.Elem()):
 		return nil, newErrInvalidInput(fmt.Sprintf(
 			"cannot return a pointer to a result object, use a value instead: %v is a pointer to a struct that embeds dig.Out", t), nil)
+	case wrapsOut(t):
+		return nil, newErrWrappedResultObject(t, 0)
 	case len(opts.Group) > 0:
 		g, err := parseGroupString(opts.Group)
 		if err != nil {
 			return nil, newErrInvalidInput(
 				fmt.Sprintf("cannot parse group %q", opts.Group), err)
 		}
-		rg := resultGrouped{Type: t, Group: g.Name, Flatten: g.Flatten}
-		if len(opts.As) > 0 {
+		rg := resultGrouped{Type: t, Group: qualifyGroup(opts.GroupNamespace, g.Name), Flatten: g.Flatten}
+		if as := opts.asFor(t); len(as) > 0 {
 			var asTypes []reflect.Type
-			for _, as := range opts.As {
+			for _, as := range as {
 				ifaceType := reflect.TypeOf(as).Elem()
 				if ifaceType == t {
 					continue
@@ -110,6 +163,10 @@ func newResult(t reflect.Type, opts resultOptions) (result, error) {
 			return nil, newErrInvalidInput(fmt.Sprintf(
 				"cannot use soft with result value groups: soft was used with group:%q", g.Name), nil)
 		}
+		if g.Tag != "" {
+			return nil, newErrInvalidInput(fmt.Sprintf(
+				"cannot use tag with result value groups: tag %q was used with group:%q, use dig.GroupTag instead", g.Tag, g.Name), nil)
+		}
 		if g.Flatten {
 			if t.Kind() != reflect.Slice {
 				return nil, newErrInvalidInput(fmt.Sprintf(
@@ -117,12 +174,57 @@ func newResult(t reflect.Type, opts resultOptions) (result, error) {
 			}
 			rg.Type = rg.Type.Elem()
 		}
+		if rg.OmitNil, err = omitsNil(g, t, opts); err != nil {
+			return nil, err
+		}
 		return rg, nil
 	default:
 		return newResultSingle(t, opts)
 	}
 }
 
+// wrapsOut reports whether t is a pointer, slice, array, map, or channel
+// that contains a dig.Out struct at any depth.
+func wrapsOut(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Map:
+		return IsOut(t.Key()) || wrapsOut(t.Key()) || IsOut(t.Elem()) || wrapsOut(t.Elem())
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Chan:
+		return IsOut(t.Elem()) || wrapsOut(t.Elem())
+	default:
+		return false
+	}
+}
+
+// newErrWrappedResultObject builds the error returned when a dig.Out struct
+// is returned inside another type. t MUST satisfy wrapsOut. position is the
+// 1-based index of t among the results of the constructor, or 0 if t is not
+// one of them, such as the type of a field of a result object.
+func newErrWrappedResultObject(t reflect.Type, position int) error {
+	subject := fmt.Sprint(t)
+	if position > 0 {
+		subject = fmt.Sprintf("result %d of type %v", position, t)
+	}
+
+	var kind string
+	switch t.Kind() {
+	case reflect.Ptr:
+		return newErrInvalidInput(fmt.Sprintf(
+			"cannot return a pointer to a pointer to a result object, return the result object by value instead: %v contains a struct that embeds dig.Out", subject), nil)
+	case reflect.Slice:
+		kind = "a slice"
+	case reflect.Array:
+		kind = "an array"
+	case reflect.Map:
+		kind = "a map"
+	case reflect.Chan:
+		kind = "a channel"
+	}
+	return newErrInvalidInput(fmt.Sprintf(
+		"cannot return %v of result objects, return a single result object with a field for each value, "+
+			"or add each value to a value group with a `group:\"..\"` tag instead: %v contains a struct that embeds dig.Out", kind, subject), nil)
+}
+
 // resultVisitor visits every result in a result tree, allowing tracking state
 // at each level.
 type resultVisitor interface {
@@ -170,7 +272,7 @@ func walkResult(r result, v resultVisitor) {
 	}
 
 	switch res := r.(type) {
-	case resultSingle, resultGrouped:
+	case resultSingle, resultGrouped, resultNamedResults:
 		// No sub-results
 	case resultObject:
 		w := v
@@ -199,8 +301,12 @@ type resultList struct {
 
 	// For each item at index i returned by the constructor, resultIndexes[i]
 	// is the index in .Results for the corresponding result object.
-	// resultIndexes[i] is -1 for errors returned by constructors.
+	// resultIndexes[i] is -1 for errors and cleanup functions returned by
+	// constructors.
 	resultIndexes []int
+
+	// Index of the cleanup function returned by the constructor, or -1.
+	cleanupIndex int
 }
 
 func (rl resultList) DotResult() []*dot.Result {
@@ -217,17 +323,58 @@ func newResultList(ctype reflect.Type, opts resultOptions) (resultList, error) {
 		ctype:         ctype,
 		Results:       make([]result, 0, numOut),
 		resultIndexes: make([]int, numOut),
+		cleanupIndex:  -1,
+	}
+
+	for i := 0; i < numOut; i++ {
+		if !isCleanupResult(ctype.Out(i), opts) {
+			continue
+		}
+		if rl.cleanupIndex >= 0 {
+			return rl, newErrInvalidInput(fmt.Sprintf(
+				"cannot return more than one cleanup function: results %d (%v) and %d (%v) of %v are both cleanup functions",
+				rl.cleanupIndex+1, ctype.Out(rl.cleanupIndex), i+1, ctype.Out(i), ctype), nil)
+		}
+		rl.cleanupIndex = i
+	}
+
+	if len(opts.ResultTags) > 0 {
+		var numResults int
+		for i := 0; i < numOut; i++ {
+			if t := ctype.Out(i); !isError(t) && !isCleanupResult(t, opts) {
+				numResults++
+			}
+		}
+		if len(opts.ResultTags) > numResults {
+			return rl, newErrInvalidInput(fmt.Sprintf(
+				"cannot apply %d result tags to %v: it has %d non-error results", len(opts.ResultTags), ctype, numResults), nil)
+		}
 	}
 
 	resultIdx := 0
 	for i := 0; i < numOut; i++ {
 		t := ctype.Out(i)
-		if isError(t) {
+		if isError(t) || i == rl.cleanupIndex {
 			rl.resultIndexes[i] = -1
 			continue
 		}
 
-		r, err := newResult(t, opts)
+		var (
+			r   result
+			err error
+		)
+		switch {
+		case t == _namedResultsType:
+			r, err = newResultNamedResults(ctype, opts)
+		case wrapsOut(t) && !(t.Kind() == reflect.Ptr && IsOut(t.Elem())):
+			// Pointers to result objects are rejected by newResult with
+			// their own error.
+			err = newErrWrappedResultObject(t, i+1)
+		case resultIdx < len(opts.ResultTags) && opts.ResultTags[resultIdx] != "":
+			r, err = newTaggedResult(i, t, opts.ResultTags[resultIdx], opts)
+		default:
+			r, err = newResult(t, opts)
+		}
 		if err != nil {
 			return rl, newErrInvalidInput(fmt.Sprintf("bad result %d", i+1), err)
 		}
@@ -240,14 +387,47 @@ func newResultList(ctype reflect.Type, opts resultOptions) (resultList, error) {
 	return rl, nil
 }
 
+// newTaggedResult builds a result for the i-th return value of a
+// constructor, of type t, as if it were a field of a dig.Out struct with the
+// given tag.
+func newTaggedResult(i int, t reflect.Type, tag string, opts resultOptions) (result, error) {
+	if IsOut(t) {
+		return nil, newErrInvalidInput(fmt.Sprintf(
+			"cannot apply tags to a result object: %v embeds dig.Out", t), nil)
+	}
+
+	f := reflect.StructField{
+		Name: fmt.Sprintf("Result%d", i+1),
+		Type: t,
+		Tag:  reflect.StructTag(tag),
+	}
+	rof, err := newResultObjectField(i, f, opts)
+	if err != nil {
+		return nil, err
+	}
+	return rof.Result, nil
+}
+
 func (resultList) Extract(containerWriter, bool, reflect.Value) {
 	digerror.BugPanicf("resultList.Extract() must never be called")
 }
 
 func (rl resultList) ExtractList(cw containerWriter, decorated bool, values []reflect.Value) error {
+	// Invalid NamedResults are reported only if the constructor did not
+	// fail.
+	var namedErr error
 	for i, v := range values {
 		if resultIdx := rl.resultIndexes[i]; resultIdx >= 0 {
-			rl.Results[resultIdx].Extract(cw, decorated, v)
+			r := rl.Results[resultIdx]
+			if rn, ok := r.(resultNamedResults); ok {
+				if namedErr = rn.check(v); namedErr != nil {
+					continue
+				}
+			}
+			r.Extract(cw, decorated, v)
+			continue
+		}
+		if i == rl.cleanupIndex {
 			continue
 		}
 
@@ -256,7 +436,7 @@ func (rl resultList) ExtractList(cw containerWriter, decorated bool, values []re
 		}
 	}
 
-	return nil
+	return namedErr
 }
 
 // resultSingle is an explicit value produced by a constructor, optionally
@@ -270,6 +450,11 @@ type resultSingle struct {
 	// If specified, this is a list of types which the value will be made
 	// available as, in addition to its own type.
 	As []reflect.Type
+
+	// Optional results are not submitted to the container if they hold
+	// the zero value of their type. Only fields of dig.Out structs may be
+	// optional.
+	Optional bool
 }
 
 func newResultSingle(t reflect.Type, opts resultOptions) (resultSingle, error) {
@@ -277,10 +462,17 @@ func newResultSingle(t reflect.Type, opts resultOptions) (resultSingle, error) {
 		Type: t,
 		Name: opts.Name,
 	}
+	if len(r.Name) == 0 && opts.NameFunc != nil {
+		r.Name = opts.NameFunc(t)
+		if strings.ContainsRune(r.Name, '`') {
+			return r, newErrInvalidInput(fmt.Sprintf(
+				"invalid name %q for %v returned by dig.NameFunc: names cannot contain backquotes", r.Name, t), nil)
+		}
+	}
 
 	var asTypes []reflect.Type
 
-	for _, as := range opts.As {
+	for _, as := range opts.asFor(t) {
 		ifaceType := reflect.TypeOf(as).Elem()
 		if ifaceType == t {
 			// Special case:
@@ -329,6 +521,9 @@ func (rs resultSingle) Extract(cw containerWriter, decorated bool, v reflect.Val
 		cw.setDecoratedValue(rs.Name, rs.Type, v)
 		return
 	}
+	if rs.Optional && v.IsZero() {
+		return
+	}
 	cw.setValue(rs.Name, rs.Type, v)
 
 	for _, asType := range rs.As {
@@ -365,14 +560,37 @@ func newResultObject(t reflect.Type, opts resultOptions) (resultObject, error) {
 			"cannot specify a group for result objects: %v embeds dig.Out", t), nil)
 	}
 
+	// Check if the Out type overrides ignoring unexported fields.
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Type != _outType {
+			continue
+		}
+		if _, ok := f.Tag.Lookup(_ignoreUnexportedTag); ok {
+			var err error
+			opts.IgnoreUnexported, err = isIgnoreUnexportedSet(f)
+			if err != nil {
+				return ro, newErrInvalidInput(fmt.Sprintf("bad field %q of %v", f.Name, t), err)
+			}
+		}
+		break
+	}
+
 	for i := 0; i < t.NumField(); i++ {
 		f := t.Field(i)
 		if f.Type == _outType {
 			// Skip over the dig.Out embed.
 			continue
 		}
+		if f.PkgPath != "" && opts.IgnoreUnexported {
+			// Skip over an unexported field if it is allowed.
+			continue
+		}
 
 		rof, err := newResultObjectField(i, f, opts)
+		if e, ok := err.(errMisplacedObject); ok {
+			return ro, e.inField(t, f.Name)
+		}
 		if err != nil {
 			return ro, newErrInvalidInput(fmt.Sprintf("bad field %q of %v", f.Name, t), err)
 		}
@@ -414,6 +632,9 @@ func newResultObjectField(idx int, f reflect.StructField, opts resultOptions) (r
 		FieldName:  f.Name,
 		FieldIndex: idx,
 	}
+	if err := checkGroupLabels(f, opts.Tags); err != nil {
+		return rof, err
+	}
 
 	var r result
 	switch {
@@ -421,16 +642,17 @@ func newResultObjectField(idx int, f reflect.StructField, opts resultOptions) (r
 		return rof, newErrInvalidInput(
 			fmt.Sprintf("unexported fields not allowed in dig.Out, did you mean to export %q (%v)?", f.Name, f.Type), nil)
 
-	case f.Tag.Get(_groupTag) != "":
-		var err error
-		r, err = newResultGrouped(f)
+	case f.Tag.Get(opts.Tags.group()) != "":
+		rg, err := newResultGrouped(f, opts)
 		if err != nil {
 			return rof, err
 		}
+		rg.Group = qualifyGroup(opts.GroupNamespace, rg.Group)
+		r = rg
 
 	default:
 		var err error
-		if name := f.Tag.Get(_nameTag); len(name) > 0 {
+		if name := f.Tag.Get(opts.Tags.name()); len(name) > 0 {
 			// can modify in-place because options are passed-by-value.
 			opts.Name = name
 		}
@@ -438,6 +660,20 @@ func newResultObjectField(idx int, f reflect.StructField, opts resultOptions) (r
 		if err != nil {
 			return rof, err
 		}
+
+		optional, err := isFieldOptional(f)
+		if err != nil {
+			return rof, err
+		}
+		if optional {
+			rs, ok := r.(resultSingle)
+			if !ok {
+				return rof, newErrInvalidInput(fmt.Sprintf(
+					"optional can be applied to single values only: field %q (%v) is a result object", f.Name, f.Type), nil)
+			}
+			rs.Optional = true
+			r = rs
+		}
 	}
 
 	rof.Result = r
@@ -463,6 +699,13 @@ type resultGrouped struct {
 	// If specified, this is a list of types which the value will be made
 	// available as, in addition to its own type.
 	As []reflect.Type
+
+	// Labels of the values as specified in the `labels:".."` tag.
+	Labels []string
+
+	// Whether nil values are left out of the group, as requested with the
+	// omitnil option or OmitNilFromGroup. Never set with Flatten.
+	OmitNil bool
 }
 
 func (rt resultGrouped) DotResult() []*dot.Result {
@@ -482,9 +725,16 @@ func (rt resultGrouped) DotResult() []*dot.Result {
 	return dotResults
 }
 
-// newResultGrouped(f) builds a new resultGrouped from the provided field.
-func newResultGrouped(f reflect.StructField) (resultGrouped, error) {
-	g, err := parseGroupString(f.Tag.Get(_groupTag))
+// newResultGrouped(f, opts) builds a new resultGrouped from the provided
+// field.
+func newResultGrouped(f reflect.StructField, opts resultOptions) (resultGrouped, error) {
+	tags := opts.Tags
+	g, err := parseGroupString(f.Tag.Get(tags.group()))
+	if err != nil {
+		return resultGrouped{}, newErrInvalidInput(
+			fmt.Sprintf("cannot parse group %q of field %q", f.Tag.Get(tags.group()), f.Name), err)
+	}
+	labels, err := parseGroupLabels(f)
 	if err != nil {
 		return resultGrouped{}, err
 	}
@@ -492,22 +742,42 @@ func newResultGrouped(f reflect.StructField) (resultGrouped, error) {
 		Group:   g.Name,
 		Flatten: g.Flatten,
 		Type:    f.Type,
+		Labels:  labels,
 	}
-	name := f.Tag.Get(_nameTag)
+	name := f.Tag.Get(tags.name())
 	optional, _ := isFieldOptional(f)
 	switch {
+	case isParamObject(f.Type) || (g.Flatten && f.Type.Kind() == reflect.Slice && isParamObject(f.Type.Elem())):
+		t := f.Type
+		if g.Flatten && t.Kind() == reflect.Slice {
+			t = t.Elem()
+		}
+		return rg, errMisplacedObject{Type: t, Marker: _inType, Group: true}
+	case IsOut(f.Type) || wrapsOut(f.Type):
+		return rg, newErrInvalidInput(fmt.Sprintf(
+			"cannot add result objects to value groups: field %q (%v) contains a struct that embeds dig.Out, "+
+				"tag the fields of the result object with `group:%q` instead", f.Name, f.Type, rg.Group), nil)
 	case g.Flatten && f.Type.Kind() != reflect.Slice:
 		return rg, newErrInvalidInput(fmt.Sprintf(
 			"flatten can be applied to slices only: field %q (%v) is not a slice", f.Name, f.Type), nil)
 	case g.Soft:
 		return rg, newErrInvalidInput(fmt.Sprintf(
 			"cannot use soft with result value groups: soft was used with group %q", rg.Group), nil)
+	case g.Tag != "":
+		return rg, newErrInvalidInput(fmt.Sprintf(
+			"cannot use tag with result value groups: tag %q was used with group %q, use dig.GroupTag instead", g.Tag, rg.Group), nil)
+	case g.Select != "":
+		return rg, newErrInvalidInput(fmt.Sprintf(
+			"cannot use select with result value groups: select was used with group %q", rg.Group), nil)
 	case name != "":
 		return rg, newErrInvalidInput(fmt.Sprintf(
 			"cannot use named values with value groups: name:%q provided with group:%q", name, rg.Group), nil)
 	case optional:
 		return rg, newErrInvalidInput("value groups cannot be optional", nil)
 	}
+	if rg.OmitNil, err = omitsNil(g, f.Type, opts); err != nil {
+		return rg, newErrInvalidInput(fmt.Sprintf("invalid group of field %q", f.Name), err)
+	}
 	if g.Flatten {
 		rg.Type = f.Type.Elem()
 	}
@@ -516,11 +786,16 @@ func newResultGrouped(f reflect.StructField) (resultGrouped, error) {
 }
 
 func (rt resultGrouped) Extract(cw containerWriter, decorated bool, v reflect.Value) {
+	if !decorated && rt.OmitNil && isNilValue(v) {
+		cw.omitGroupedValue(rt.Group, rt.Type)
+		return
+	}
+
 	// Decorated values are always flattened.
 	if !decorated && !rt.Flatten {
-		cw.submitGroupedValue(rt.Group, rt.Type, v)
+		cw.submitGroupedValue(rt.Group, rt.Type, v, rt.Labels)
 		for _, asType := range rt.As {
-			cw.submitGroupedValue(rt.Group, asType, v)
+			cw.submitGroupedValue(rt.Group, asType, v, rt.Labels)
 		}
 		return
 	}
@@ -530,6 +805,6 @@ func (rt resultGrouped) Extract(cw containerWriter, decorated bool, v reflect.Va
 		return
 	}
 	for i := 0; i < v.Len(); i++ {
-		cw.submitGroupedValue(rt.Group, rt.Type, v.Index(i))
+		cw.submitGroupedValue(rt.Group, rt.Type, v.Index(i), rt.Labels)
 	}
 }
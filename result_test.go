@@ -103,6 +103,34 @@ func TestNewResultErrors(t *testing.T) {
 			give: inOut{},
 			err:  "cannot provide parameter objects: dig.inOut embeds a dig.In",
 		},
+		{
+			give: (**out)(nil),
+			err:  "cannot return a pointer to a pointer to a result object, return the result object by value instead: **dig.out contains a struct that embeds dig.Out",
+		},
+		{
+			give: []out{},
+			err:  "cannot return a slice of result objects, return a single result object with a field for each value, or add each value to a value group",
+		},
+		{
+			give: []*out{},
+			err:  "cannot return a slice of result objects",
+		},
+		{
+			give: [2]out{},
+			err:  "cannot return an array of result objects",
+		},
+		{
+			give: map[string]out{},
+			err:  "cannot return a map of result objects",
+		},
+		{
+			give: map[out]string{},
+			err:  "cannot return a map of result objects",
+		},
+		{
+			give: make(chan out),
+			err:  "cannot return a channel of result objects",
+		},
 	}
 
 	for _, tt := range tests {
@@ -211,6 +239,39 @@ func TestNewResultObjectErrors(t *testing.T) {
 			}{},
 			err: `unexported fields not allowed in dig.Out, did you mean to export "writer" (io.Writer)`,
 		},
+		{
+			desc: "unexported fields with empty ignore-unexported",
+			give: struct {
+				Out `ignore-unexported:""`
+
+				writer io.Writer
+			}{},
+			err: `unexported fields not allowed in dig.Out, did you mean to export "writer" (io.Writer)`,
+		},
+		{
+			desc: "invalid ignore-unexported",
+			give: struct {
+				Out `ignore-unexported:"foo"`
+
+				Writer io.Writer
+			}{},
+			err: `bad field "Out" of struct { dig.Out "ignore-unexported:\"foo\""; Writer io.Writer }: ` +
+				`invalid value "foo" for "ignore-unexported" tag on field Out: ` +
+				`strconv.ParseBool: parsing "foo": invalid syntax`,
+		},
+		{
+			desc: "nested result object disables ignore-unexported",
+			give: struct {
+				Out `ignore-unexported:"true"`
+
+				Nested struct {
+					Out `ignore-unexported:"false"`
+
+					writer io.Writer
+				}
+			}{},
+			err: `bad field "Nested"`,
+		},
 		{
 			desc: "error field",
 			give: struct {
@@ -227,7 +288,9 @@ func TestNewResultObjectErrors(t *testing.T) {
 
 				Nested struct{ In }
 			}{},
-			err: `bad field "Nested"`,
+			err: "cannot provide parameter objects: struct { dig.In } embeds a dig.In, " +
+				"returned by field struct { dig.Out; Nested struct { dig.In } }.Nested; " +
+				"accept it as a parameter of the function instead",
 		},
 		{
 			desc: "group with name should fail",
@@ -298,6 +361,70 @@ func TestNewResultObjectErrors(t *testing.T) {
 			}{},
 			err: "cannot use soft with result value groups",
 		},
+		{
+			desc: "result object in value group",
+			give: struct {
+				Out
+
+				Nested struct {
+					Out
+
+					Reader io.Reader
+				} `group:"readers"`
+			}{},
+			err: "cannot add result objects to value groups: " +
+				`field "Nested" (struct { dig.Out; Reader io.Reader }) contains a struct that embeds dig.Out, ` +
+				"tag the fields of the result object with `group:\"readers\"` instead",
+		},
+		{
+			desc: "slice of result objects in value group",
+			give: struct {
+				Out
+
+				Nested []struct {
+					Out
+
+					Reader io.Reader
+				} `group:"readers,flatten"`
+			}{},
+			err: "cannot add result objects to value groups",
+		},
+		{
+			desc: "slice of result objects",
+			give: struct {
+				Out
+
+				Nested []struct {
+					Out
+
+					Reader io.Reader
+				}
+			}{},
+			err: `bad field "Nested" of struct { dig.Out; Nested []struct { dig.Out; Reader io.Reader } }: ` +
+				"cannot return a slice of result objects",
+		},
+		{
+			desc: "only error fields",
+			give: struct {
+				Out
+
+				Err error
+			}{},
+			err: "cannot return an error here, return it from the constructor instead",
+		},
+		{
+			desc: "optional result object",
+			give: struct {
+				Out
+
+				Nested struct {
+					Out
+
+					Reader io.Reader
+				} `optional:"true"`
+			}{},
+			err: "optional can be applied to single values only",
+		},
 	}
 
 	for _, tt := range tests {
@@ -309,6 +436,38 @@ func TestNewResultObjectErrors(t *testing.T) {
 	}
 }
 
+func TestNewResultObjectIgnoreUnexported(t *testing.T) {
+	type nested struct {
+		Out
+
+		Reader io.Reader
+		reader io.Reader
+	}
+
+	type out struct {
+		Out `ignore-unexported:"true"`
+
+		Writer io.Writer
+		writer io.Writer
+
+		Nested nested
+	}
+
+	_ = out{}.writer // unused
+	_ = nested{}.reader
+
+	ro, err := newResultObject(reflect.TypeOf(out{}), resultOptions{})
+	require.NoError(t, err)
+	require.Len(t, ro.Fields, 2)
+	assert.Equal(t, "Writer", ro.Fields[0].FieldName)
+	assert.Equal(t, "Nested", ro.Fields[1].FieldName)
+
+	inner, ok := ro.Fields[1].Result.(resultObject)
+	require.True(t, ok, "Nested must be a resultObject")
+	require.Len(t, inner.Fields, 1, "nested result objects inherit ignore-unexported")
+	assert.Equal(t, "Reader", inner.Fields[0].FieldName)
+}
+
 type fakeResultVisit struct {
 	Visit                result
 	AnnotateWithField    *resultObjectField
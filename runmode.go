@@ -0,0 +1,123 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"reflect"
+	"strconv"
+
+	"go.uber.org/dig/internal/dot"
+)
+
+// RunMode reports how a container runs the functions given to it.
+//
+// Constructors, decorators, and invoked functions may depend on a RunMode,
+// which the container provides itself. A function passed to Invoke that
+// depends on a RunMode is called even in a dry run, so that it can
+// pretend to run, for example by skipping side effects. Other functions
+// are not called in a dry run.
+//
+//	c.Invoke(func(mode dig.RunMode, db *sql.DB) error {
+//	  if mode == dig.DryRunMode {
+//	    return nil
+//	  }
+//	  return migrate(db)
+//	})
+type RunMode int
+
+const (
+	// RealRunMode is the RunMode of containers that call the functions
+	// given to them.
+	RealRunMode RunMode = iota
+
+	// DryRunMode is the RunMode of containers created with DryRun(true).
+	DryRunMode
+)
+
+func (m RunMode) String() string {
+	switch m {
+	case RealRunMode:
+		return "RealRunMode"
+	case DryRunMode:
+		return "DryRunMode"
+	default:
+		return "RunMode(" + strconv.Itoa(int(m)) + ")"
+	}
+}
+
+// _runModeType is the type of the RunMode that functions may depend on.
+var _runModeType = reflect.TypeOf(RealRunMode)
+
+// IsDryRun reports whether the Container was created with DryRun(true).
+func (c *Container) IsDryRun() bool {
+	return c.scope.IsDryRun()
+}
+
+// IsDryRun reports whether the Container of this Scope was created with
+// DryRun(true).
+func (s *Scope) IsDryRun() bool {
+	return s.rootScope().dryRun
+}
+
+func (s *Scope) runMode() RunMode {
+	if s.IsDryRun() {
+		return DryRunMode
+	}
+	return RealRunMode
+}
+
+// paramRunMode is a dependency on the RunMode of the container, which the
+// container provides itself.
+type paramRunMode struct{}
+
+var _ param = paramRunMode{}
+
+func (paramRunMode) String() string {
+	return _runModeType.String()
+}
+
+// DotParam returns nothing: the RunMode is not part of the graph.
+func (paramRunMode) DotParam() []*dot.Param {
+	return nil
+}
+
+func (paramRunMode) Build(c containerStore) (reflect.Value, error) {
+	s := c.storesToRoot()[0].(*Scope)
+	return reflect.ValueOf(s.runMode()), nil
+}
+
+// dependsOnRunMode reports whether any of the given params, or the fields
+// of parameter objects among them, is a RunMode.
+func dependsOnRunMode(params []param) bool {
+	for _, p := range params {
+		switch p := p.(type) {
+		case paramRunMode:
+			return true
+		case paramObject:
+			for _, f := range p.Fields {
+				if dependsOnRunMode([]param{f.Param}) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
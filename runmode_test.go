@@ -0,0 +1,115 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestRunMode(t *testing.T) {
+	t.Parallel()
+
+	type A struct{}
+
+	t.Run("real", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		assert.False(t, c.IsDryRun())
+		assert.False(t, c.Scope("child").IsDryRun())
+
+		c.RequireProvide(func(mode dig.RunMode) *A {
+			assert.Equal(t, dig.RealRunMode, mode)
+			return &A{}
+		})
+
+		called := false
+		c.RequireInvoke(func(mode dig.RunMode, _ *A) {
+			assert.Equal(t, dig.RealRunMode, mode)
+			called = true
+		})
+		assert.True(t, called)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.DryRun(true))
+		assert.True(t, c.IsDryRun())
+
+		child := c.Scope("child")
+		assert.True(t, child.IsDryRun())
+
+		c.RequireProvide(func(dig.RunMode) *A {
+			t.Fatal("constructor must not be called in a dry run")
+			return nil
+		})
+
+		var got dig.RunMode
+		child.RequireInvoke(func(mode dig.RunMode, a *A) {
+			assert.NotNil(t, a, "dependencies must be placeholders")
+			got = mode
+		})
+		assert.Equal(t, dig.DryRunMode, got)
+
+		c.RequireInvoke(func(*A) {
+			t.Fatal("functions that do not depend on RunMode must not be called")
+		})
+	})
+
+	t.Run("parameter object", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			Mode dig.RunMode
+		}
+
+		c := digtest.New(t, dig.DryRun(true))
+		var got dig.RunMode
+		c.RequireInvoke(func(p params) { got = p.Mode })
+		assert.Equal(t, dig.DryRunMode, got)
+	})
+
+	t.Run("cannot be provided", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Provide(func() dig.RunMode { return dig.DryRunMode })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			"cannot provide dig.RunMode: it is provided by the container to report whether it is in a dry run")
+	})
+
+	t.Run("String", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "RealRunMode", dig.RealRunMode.String())
+		assert.Equal(t, "DryRunMode", dig.DryRunMode.String())
+		assert.Equal(t, "RunMode(42)", dig.RunMode(42).String())
+	})
+}
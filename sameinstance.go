@@ -0,0 +1,281 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+
+	"go.uber.org/dig/internal/digreflect"
+)
+
+// A SameInstanceOption modifies the default behavior of AssertSameInstance.
+type SameInstanceOption interface {
+	applySameInstanceOption(*sameInstanceOptions)
+}
+
+type sameInstanceOptions struct {
+	NameA, NameB string
+}
+
+// SameInstanceNames is a SameInstanceOption that compares the values with
+// the given names, as provided with the Name option or a `name:".."` tag.
+// An empty name selects the unnamed value.
+func SameInstanceNames(a, b string) SameInstanceOption {
+	return sameInstanceNamesOption{a: a, b: b}
+}
+
+type sameInstanceNamesOption struct{ a, b string }
+
+func (o sameInstanceNamesOption) String() string {
+	return fmt.Sprintf("SameInstanceNames(%q, %q)", o.a, o.b)
+}
+
+func (o sameInstanceNamesOption) applySameInstanceOption(opts *sameInstanceOptions) {
+	opts.NameA, opts.NameB = o.a, o.b
+}
+
+// AssertSameInstance builds two values from the Container and reports an
+// error if they are not the same instance. See Scope.AssertSameInstance
+// for details.
+func (c *Container) AssertSameInstance(a, b interface{}, opts ...SameInstanceOption) error {
+	return c.scope.AssertSameInstance(a, b, opts...)
+}
+
+// AssertSameInstance builds the values of the element types of a and b,
+// which must be pointers, and reports an error naming the constructors of
+// both values if they are not the same instance. It is meant to be used in
+// tests to catch types that are expected to share a value but have
+// separate constructors.
+//
+//	err := s.AssertSameInstance((*io.Reader)(nil), (**os.File)(nil))
+//
+// Values of interfaces are compared by the values that they wrap. Pointers,
+// maps, slices, channels, and functions are the same instance if they point
+// to the same memory. Other values are the same instance if they are equal.
+//
+// Values are built with the same rules as Invoke, so constructors that
+// were not called yet are called.
+func (s *Scope) AssertSameInstance(a, b interface{}, opts ...SameInstanceOption) error {
+	var options sameInstanceOptions
+	for _, o := range opts {
+		o.applySameInstanceOption(&options)
+	}
+
+	ka, err := sameInstanceKey(a, options.NameA)
+	if err != nil {
+		return err
+	}
+	kb, err := sameInstanceKey(b, options.NameB)
+	if err != nil {
+		return err
+	}
+
+	if err := s.verifyAcyclic(); err != nil {
+		return err
+	}
+	va, err := paramSingle{Name: ka.name, Type: ka.t}.Build(s)
+	if err != nil {
+		return err
+	}
+	vb, err := paramSingle{Name: kb.name, Type: kb.t}.Build(s)
+	if err != nil {
+		return err
+	}
+
+	same, err := sameInstance(va, vb)
+	if err != nil || same {
+		return err
+	}
+	return errNotSameInstance{
+		A:     ka,
+		B:     kb,
+		FuncA: s.valueProviderLocation(ka),
+		FuncB: s.valueProviderLocation(kb),
+	}
+}
+
+// sameInstanceKey returns the key of the value that the pointer target
+// stands for.
+func sameInstanceKey(target interface{}, name string) (key, error) {
+	t := reflect.TypeOf(target)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return key{}, newErrInvalidInput(
+			fmt.Sprintf("cannot compare instances of %T: must be a pointer to the type of the value", target), nil)
+	}
+	return key{t: t.Elem(), name: name}, nil
+}
+
+// sameInstance reports whether a and b hold the same instance.
+func sameInstance(a, b reflect.Value) (bool, error) {
+	if a.Kind() == reflect.Interface {
+		a = a.Elem()
+	}
+	if b.Kind() == reflect.Interface {
+		b = b.Elem()
+	}
+	if !a.IsValid() || !b.IsValid() {
+		return !a.IsValid() && !b.IsValid(), nil
+	}
+	if a.Type() != b.Type() {
+		return false, nil
+	}
+
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return a.Pointer() == b.Pointer(), nil
+	}
+	if !a.Type().Comparable() {
+		return false, newErrInvalidInput(
+			fmt.Sprintf("cannot compare instances of %v: values of this type are not comparable", a.Type()), nil)
+	}
+	return a.Interface() == b.Interface(), nil
+}
+
+// valueProviderLocation returns the location of the constructor that
+// provides the value with the given key to this Scope, or nil if none
+// does.
+func (s *Scope) valueProviderLocation(k key) *digreflect.Func {
+	for _, c := range s.storesToRoot() {
+		if ps := c.getValueProviders(k.name, k.t); len(ps) > 0 {
+			return ps[len(ps)-1].Location()
+		}
+	}
+	return nil
+}
+
+// errNotSameInstance is returned by AssertSameInstance when two values are
+// different instances.
+type errNotSameInstance struct {
+	A, B         key
+	FuncA, FuncB *digreflect.Func // nil if unknown
+}
+
+var _ digError = errNotSameInstance{}
+
+func (e errNotSameInstance) Error() string { return fmt.Sprint(e) }
+
+func (e errNotSameInstance) writeMessage(w io.Writer, verb string) {
+	fmt.Fprintf(w, "%v and %v are different instances: ", e.A, e.B)
+	writeProvidedBy(w, verb, e.A, e.FuncA)
+	io.WriteString(w, ", ")
+	writeProvidedBy(w, verb, e.B, e.FuncB)
+}
+
+func writeProvidedBy(w io.Writer, verb string, k key, f *digreflect.Func) {
+	if f == nil {
+		fmt.Fprintf(w, "%v has no constructor", k)
+		return
+	}
+	fmt.Fprintf(w, "%v provided by "+verb, k, f)
+}
+
+func (e errNotSameInstance) Format(w fmt.State, c rune) {
+	formatError(e, w, c)
+}
+
+// DivergentInterfaceProvider is an interface and a type that implements it
+// which are provided by different constructors. This is usually a mistake:
+// consumers of the interface and of the type receive separate values where
+// one was expected, for example two different open files.
+type DivergentInterfaceProvider struct {
+	// Interface and the constructor that provides it.
+	Interface         reflect.Type
+	InterfaceProvider string
+
+	// Type that implements Interface, and the constructor that provides
+	// it.
+	Type         reflect.Type
+	TypeProvider string
+
+	// Name of both values, if any.
+	Name string
+}
+
+func (p DivergentInterfaceProvider) String() string {
+	return fmt.Sprintf("%v is provided by %v, but %v, which implements it, is provided by %v",
+		key{t: p.Interface, name: p.Name}, p.InterfaceProvider,
+		key{t: p.Type, name: p.Name}, p.TypeProvider)
+}
+
+// DivergentInterfaceProviders reports the interfaces provided to the
+// Container, or to any of its Scopes, that are provided by a different
+// constructor than a type with the same name that implements them. Use
+// AssertSameInstance to check whether such values are meant to be the
+// same. Interfaces without methods and value groups are not reported.
+func (c *Container) DivergentInterfaceProviders() []DivergentInterfaceProvider {
+	return c.scope.DivergentInterfaceProviders()
+}
+
+// DivergentInterfaceProviders reports the interfaces provided to this
+// Scope, or to any of its descendants, that are provided by a different
+// constructor than a type that implements them. See
+// Container.DivergentInterfaceProviders for details.
+func (s *Scope) DivergentInterfaceProviders() []DivergentInterfaceProvider {
+	providers := make(map[key][]*constructorNode)
+	for _, scope := range s.appendSubscopes(nil) {
+		for k, ps := range scope.providers {
+			if k.group == "" {
+				providers[k] = append(providers[k], ps...)
+			}
+		}
+	}
+
+	var divergent []DivergentInterfaceProvider
+	for ik, ips := range providers {
+		if ik.t.Kind() != reflect.Interface || ik.t.NumMethod() == 0 {
+			continue
+		}
+		for tk, tps := range providers {
+			if tk.name != ik.name || tk.t.Kind() == reflect.Interface || !tk.t.Implements(ik.t) {
+				continue
+			}
+			if shareProvider(ips, tps) {
+				continue
+			}
+			divergent = append(divergent, DivergentInterfaceProvider{
+				Interface:         ik.t,
+				InterfaceProvider: fmt.Sprint(ips[len(ips)-1].Location()),
+				Type:              tk.t,
+				TypeProvider:      fmt.Sprint(tps[len(tps)-1].Location()),
+				Name:              ik.name,
+			})
+		}
+	}
+	sort.Slice(divergent, func(i, j int) bool {
+		return divergent[i].String() < divergent[j].String()
+	})
+	return divergent
+}
+
+// shareProvider reports whether a and b have a provider in common.
+func shareProvider(a, b []*constructorNode) bool {
+	for _, pa := range a {
+		for _, pb := range b {
+			if pa == pb {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+type sameInstanceFile struct{ name string }
+
+func (*sameInstanceFile) Read([]byte) (int, error) { return 0, io.EOF }
+
+func TestAssertSameInstance(t *testing.T) {
+	t.Parallel()
+
+	t.Run("same instance", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (*sameInstanceFile, io.Reader) {
+			f := &sameInstanceFile{}
+			return f, f
+		})
+		assert.NoError(t, c.AssertSameInstance((*io.Reader)(nil), (**sameInstanceFile)(nil)))
+	})
+
+	t.Run("different instances", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *sameInstanceFile { return &sameInstanceFile{} })
+		c.RequireProvide(func() io.Reader { return &sameInstanceFile{} })
+
+		err := c.AssertSameInstance((*io.Reader)(nil), (**sameInstanceFile)(nil))
+		require.Error(t, err)
+		dig.AssertErrorMatches(t, err,
+			`io.Reader and \*dig_test.sameInstanceFile are different instances: `+
+				`io.Reader provided by "go.uber.org/dig_test".TestAssertSameInstance\S+`,
+			`sameinstance_test.go:\d+\)?, `+ // file:line
+				`\*dig_test.sameInstanceFile provided by "go.uber.org/dig_test".TestAssertSameInstance\S+`,
+			`sameinstance_test.go:\d+`, // file:line
+		)
+	})
+
+	t.Run("names", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *sameInstanceFile { return &sameInstanceFile{name: "primary"} }, dig.Name("primary"))
+		c.RequireProvide(func(f *sameInstanceFile) io.Reader { return f },
+			dig.Name("reader"), dig.ParamTags(`name:"primary"`))
+		c.RequireProvide(func() *sameInstanceFile { return &sameInstanceFile{name: "other"} })
+
+		assert.NoError(t, c.AssertSameInstance((*io.Reader)(nil), (**sameInstanceFile)(nil),
+			dig.SameInstanceNames("reader", "primary")))
+
+		err := c.AssertSameInstance((*io.Reader)(nil), (**sameInstanceFile)(nil),
+			dig.SameInstanceNames("reader", ""))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `io.Reader[name="reader"] and *dig_test.sameInstanceFile are different instances`)
+	})
+
+	t.Run("comparable values", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "foo" })
+		c.RequireProvide(func() string { return "foo" }, dig.Name("copy"))
+		assert.NoError(t, c.AssertSameInstance((*string)(nil), (*string)(nil), dig.SameInstanceNames("", "copy")))
+	})
+
+	t.Run("values that cannot be compared", func(t *testing.T) {
+		t.Parallel()
+
+		type config struct{ Hosts []string }
+
+		c := digtest.New(t)
+		c.RequireProvide(func() config { return config{} })
+		err := c.AssertSameInstance((*config)(nil), (*config)(nil))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot compare instances of dig_test.config: values of this type are not comparable")
+	})
+
+	t.Run("invalid target", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.AssertSameInstance(sameInstanceFile{}, (*io.Reader)(nil))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot compare instances of dig_test.sameInstanceFile: must be a pointer to the type of the value")
+	})
+
+	t.Run("missing value", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.AssertSameInstance((*io.Reader)(nil), (**sameInstanceFile)(nil))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: io.Reader")
+	})
+
+	t.Run("option string", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, `SameInstanceNames("a", "")`, fmt.Sprint(dig.SameInstanceNames("a", "")))
+	})
+}
+
+func TestDivergentInterfaceProviders(t *testing.T) {
+	t.Parallel()
+
+	t.Run("separate constructors", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *sameInstanceFile { return &sameInstanceFile{} })
+		c.Scope("child").RequireProvide(func() io.Reader { return &sameInstanceFile{} })
+		c.RequireProvide(func() interface{} { return nil })
+
+		got := c.DivergentInterfaceProviders()
+		require.Len(t, got, 1)
+		assert.Equal(t, reflect.TypeOf((*io.Reader)(nil)).Elem(), got[0].Interface)
+		assert.Equal(t, reflect.TypeOf(&sameInstanceFile{}), got[0].Type)
+		assert.Empty(t, got[0].Name)
+		assert.Regexp(t, `^io.Reader is provided by "go.uber.org/dig_test".TestDivergentInterfaceProviders\S+ \(\S+\), `+
+			`but \*dig_test.sameInstanceFile, which implements it, is provided by \S+`, got[0].String())
+	})
+
+	t.Run("same constructor", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (*sameInstanceFile, io.Reader) {
+			f := &sameInstanceFile{}
+			return f, f
+		})
+		assert.Empty(t, c.DivergentInterfaceProviders())
+	})
+
+	t.Run("names must match", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *sameInstanceFile { return &sameInstanceFile{} }, dig.Name("a"))
+		c.RequireProvide(func() io.Reader { return &sameInstanceFile{} }, dig.Name("b"))
+		assert.Empty(t, c.DivergentInterfaceProviders())
+
+		c.RequireProvide(func() io.Reader { return &sameInstanceFile{} }, dig.Name("a"))
+		got := c.DivergentInterfaceProviders()
+		require.Len(t, got, 1)
+		assert.Equal(t, "a", got[0].Name)
+	})
+}
@@ -22,10 +22,12 @@ package dig
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"math/rand"
 	"reflect"
 	"sort"
+	"sync"
 	"time"
 )
 
@@ -38,9 +40,27 @@ type ScopeOption interface {
 // Scope is a scoped DAG of types and their dependencies.
 // A Scope may also have one or more child Scopes that inherit
 // from it.
+//
+// Values built by a constructor are cached in the Scope that the
+// constructor was provided to. When a Scope resolves a single value, it
+// walks from itself up to the root and uses the nearest Scope that either
+// has that value cached or has a constructor for it. As a result, a
+// constructor provided to a Scope takes precedence over values that were
+// already built by its ancestors, and values built by a Scope are never
+// visible to its ancestors.
+//
+// Value groups are not resolved this way: a Scope sees the values of a
+// group contributed by itself and all of its ancestors.
 type Scope struct {
 	// This implements containerStore interface.
 
+	// Guards the values built by all Scopes of the Container, and the
+	// other state that changes while functions are invoked, so that several
+	// goroutines may call Invoke concurrently. It is only held while that
+	// state is read or written, never while user-provided functions run.
+	// Only used on the root Scope; see lock.
+	mu sync.Mutex
+
 	// Name of the Scope
 	name string
 	// Mapping from key to all the constructor node that can provide a value for that
@@ -54,6 +74,11 @@ type Scope struct {
 	// any nodes that were provided to the parent Scope this inherited from.
 	nodes []*constructorNode
 
+	// Constructors provided to this Scope that return NamedResults, and
+	// the keys of the values that those that were called provided.
+	namedResultsCtors []*constructorNode
+	namedResults      map[*constructorNode][]key
+
 	// Values that generated via decorators in the Scope.
 	decoratedValues map[key]reflect.Value
 
@@ -63,21 +88,161 @@ type Scope struct {
 	// Values groups that generated directly in the Scope.
 	groups map[key][]reflect.Value
 
+	// Information about the providers of each value in groups, at the
+	// same index.
+	groupMembers map[key][]groupMember
+
+	// Information about the providers that left a nil value out of each
+	// value group under OmitNilFromGroup or the omitnil option.
+	groupOmissions map[key][]groupMember
+
+	// Functions called when values are added to value groups, set with the
+	// OnGroupSubmit option. Only set on the root Scope.
+	groupSubmitHooks []func(group string, t reflect.Type, v reflect.Value)
+
+	// Middleware wrapping the resolution of values, set with the
+	// WithParamResolver option. Only set on the root Scope.
+	paramResolvers []func(ResolveFunc) ResolveFunc
+
 	// Values groups that generated via decoraters in the Scope.
 	decoratedGroups map[key]reflect.Value
 
+	// Constructors owned by this Scope that were already called. The values
+	// they produced are cached in this Scope. Constructors that failed and
+	// reported their error to a value group map to that error.
+	calledCtors map[*constructorNode]error
+
+	// Calls of constructors owned by this Scope that are in progress.
+	// Concurrent calls of the same constructor wait for them rather than
+	// calling it again.
+	inflightCtors map[*constructorNode]*inflightCall
+
+	// Constructors owned by this Scope that failed under CacheFailures,
+	// mapped to their errors.
+	failedCtors map[*constructorNode]error
+
 	// Source of randomness.
 	rand *rand.Rand
 
 	// Flag indicating whether the graph has been checked for cycles.
 	isVerifiedAcyclic bool
 
+	// Nodes of the graph, by order, that were found to reach no cycles
+	// since the graph was last changed, if isVerifiedAcyclic is not set.
+	acyclicNodes []bool
+
 	// Defer acyclic check on provide until Invoke.
 	deferAcyclicVerification bool
 
+	// Reject slice types that are both provided and consumed from value
+	// groups. Only set on the root Scope.
+	strictSliceGroups bool
+
 	// Recover from panics in user-provided code and wrap in an exported error type.
 	recoverFromPanics bool
 
+	// Reject functions passed to Invoke that return non-error values.
+	strictInvoke bool
+
+	// Whether the container was created with DryRun(true).
+	// Only set on the root Scope.
+	dryRun bool
+
+	// Reject interface values that wrap nil values.
+	// Only set on the root Scope.
+	checkNilInterfaces bool
+
+	// Resolve optional dependencies only if their values were already
+	// built. Only set on the root Scope.
+	lazyOptionals bool
+
+	// Reject constructors whose required dependencies have no provider.
+	// Only set on the root Scope.
+	eagerDependencyCheck bool
+
+	// Remember the failures of constructors rather than calling them
+	// again. Only set on the root Scope.
+	cacheFailures bool
+
+	// Satisfy interfaces without providers with values of types that
+	// implement them. Only set on the root Scope.
+	preferMostDerived bool
+
+	// Types of the values that satisfied interfaces requested from this
+	// Scope under PreferMostDerived.
+	derived map[key]reflect.Type
+
+	// Give functions their own copies of slices and maps.
+	// Only set on the root Scope.
+	copySharedValues bool
+
+	// Report slices and maps that were modified after they were passed to
+	// functions. Only set on the root Scope.
+	detectSharedMutations bool
+
+	// Slices and maps passed to functions under DetectSharedMutations.
+	// Only set on the root Scope.
+	sharedDeliveries map[sharedIdentity]*sharedDelivery
+
+	// Discard cached values that a new constructor would change instead of
+	// rejecting the constructor. Only set on the root Scope.
+	allowRebuild bool
+
+	// Context keys registered with the ContextKey option, by name.
+	// Only set on the root Scope.
+	contextKeys map[string]interface{}
+
+	// Limits set by the MaxProviders and MaxResolutionDepth options.
+	// Zero means unlimited. Only set on the root Scope.
+	maxProviders       int
+	maxResolutionDepth int
+
+	// Number of constructors provided to this Scope and its descendants.
+	// Only tracked on the root Scope.
+	numProviders int
+
+	// Incremented whenever constructors are provided or removed, so that
+	// checks of dependencies can tell whether they are still valid. Only
+	// tracked on the root Scope.
+	generation uint64
+
+	// Parameters of the functions invoked in this Scope, by function type.
+	invokeParams map[reflect.Type]invokeParams
+
+	// Number of constructors created so far, used to number them in the
+	// order they are provided. Only tracked on the root Scope.
+	provideSeq uint64
+
+	// Whether value groups are given in a fixed order rather than
+	// shuffled. Only set on the root Scope.
+	deterministicGroupOrder bool
+
+	// Number of constructor calls currently in progress for each
+	// invocation that has any. Only tracked on the root Scope.
+	activeCalls map[*invocation]int
+
+	// Reject functions that depend on a CallInfo.
+	// Only set on the root Scope.
+	forbidCallInfo bool
+
+	// Number of calls of Invoke started so far, and number of
+	// constructor calls given a CallInfo so far. Only tracked on the root
+	// Scope.
+	invokeSeq uint64
+	callSeq   uint64
+
+	// Struct tag keys set by the WithNameTag and WithGroupTag options.
+	// Only set on the root Scope.
+	tags tagKeys
+
+	// Most recent error encountered while building each value, including
+	// errors swallowed by optional dependencies. Only set on the root Scope.
+	buildErrors map[key]error
+
+	// Cleanup functions registered through func(func()) parameters or
+	// returned by constructors. Only set on the root Scope.
+	cleanups []func(context.Context) error
+
 	// invokerFn calls a function with arguments provided to Provide or Invoke.
 	invokerFn invokerFn
 
@@ -99,7 +264,11 @@ func newScope() *Scope {
 		values:          make(map[key]reflect.Value),
 		decoratedValues: make(map[key]reflect.Value),
 		groups:          make(map[key][]reflect.Value),
+		groupMembers:    make(map[key][]groupMember),
+		groupOmissions:  make(map[key][]groupMember),
 		decoratedGroups: make(map[key]reflect.Value),
+		calledCtors:     make(map[*constructorNode]error),
+		inflightCtors:   make(map[*constructorNode]*inflightCall),
 		invokerFn:       defaultInvoker,
 		rand:            rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
@@ -113,22 +282,67 @@ func newScope() *Scope {
 // However, no modifications made to the child scope being created will be propagated
 // to the parent Scope.
 func (s *Scope) Scope(name string, opts ...ScopeOption) *Scope {
+	child := s.newChildScope(name)
+
+	for _, opt := range opts {
+		opt.noScopeOption()
+	}
+
+	// child copies the parent's graph nodes. Both happen under the lock
+	// so that nodes added by concurrent Invokes reach the child exactly
+	// once.
+	defer s.lock()()
+	child.gh.nodes = append(child.gh.nodes, s.gh.nodes...)
+	s.childScopes = append(s.childScopes, child)
+	return child
+}
+
+// newChildScope builds a Scope that inherits the options of this Scope. It
+// is not added to the children of this Scope, so constructors provided to
+// this Scope later are not propagated to it.
+func (s *Scope) newChildScope(name string) *Scope {
 	child := newScope()
 	child.name = name
 	child.parentScope = s
 	child.invokerFn = s.invokerFn
 	child.deferAcyclicVerification = s.deferAcyclicVerification
 	child.recoverFromPanics = s.recoverFromPanics
+	child.strictInvoke = s.strictInvoke
+	return child
+}
 
-	// child copies the parent's graph nodes.
-	child.gh.nodes = append(child.gh.nodes, s.gh.nodes...)
+// ScopeNode describes a Scope and its descendants in a ScopeTree.
+type ScopeNode struct {
+	// Name of the Scope. The name of the root Scope is empty.
+	Name string
 
-	for _, opt := range opts {
-		opt.noScopeOption()
-	}
+	// Number of constructors provided to the Scope, not counting those
+	// provided to its ancestors. Constructors provided with the Export
+	// option are counted in the root Scope.
+	Providers int
 
-	s.childScopes = append(s.childScopes, child)
-	return child
+	// Child Scopes of the Scope, in the order they were created.
+	Children []*ScopeNode
+}
+
+// ScopeTree returns the hierarchy of all Scopes of the Container, starting
+// at the Container itself.
+func (c *Container) ScopeTree() *ScopeNode {
+	return c.scope.ScopeTree()
+}
+
+// ScopeTree returns the hierarchy of this Scope and all its descendants.
+func (s *Scope) ScopeTree() *ScopeNode {
+	defer s.lock()()
+	return s.scopeTree()
+}
+
+func (s *Scope) scopeTree() *ScopeNode {
+	n := &ScopeNode{Name: s.name, Providers: len(s.nodes)}
+	for _, cs := range s.childScopes {
+		n.Children = append(n.Children, cs.scopeTree())
+	}
+	return n
 }
 
 // ancestors returns a list of scopes of ancestors of this scope up to the
@@ -141,6 +355,17 @@ func (s *Scope) ancestors() []*Scope {
 	return scopes
 }
 
+// isDescendantOf reports whether this scope is the given scope or one of
+// its descendants.
+func (s *Scope) isDescendantOf(other *Scope) bool {
+	for _, a := range s.ancestors() {
+		if a == other {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *Scope) appendSubscopes(dest []*Scope) []*Scope {
 	dest = append(dest, s)
 	for _, cs := range s.childScopes {
@@ -173,48 +398,186 @@ func (s *Scope) knownTypes() []reflect.Type {
 }
 
 func (s *Scope) getValue(name string, t reflect.Type) (v reflect.Value, ok bool) {
+	defer s.lock()()
 	v, ok = s.values[key{name: name, t: t}]
 	return
 }
 
 func (s *Scope) getDecoratedValue(name string, t reflect.Type) (v reflect.Value, ok bool) {
+	defer s.lock()()
 	v, ok = s.decoratedValues[key{name: name, t: t}]
 	return
 }
 
 func (s *Scope) setValue(name string, t reflect.Type, v reflect.Value) {
+	defer s.lock()()
 	s.values[key{name: name, t: t}] = v
 }
 
 func (s *Scope) setDecoratedValue(name string, t reflect.Type, v reflect.Value) {
+	defer s.lock()()
 	s.decoratedValues[key{name: name, t: t}] = v
 }
 
 func (s *Scope) getValueGroup(name string, t reflect.Type) []reflect.Value {
-	items := s.groups[key{group: name, t: t}]
+	defer s.lock()()
+	k := key{group: name, t: t}
+	items := s.groups[k]
+	if s.rootScope().deterministicGroupOrder {
+		return orderedCopy(items, s.groupMembers[k])
+	}
 	// shuffle the list so users don't rely on the ordering of grouped values
 	return shuffledCopy(s.rand, items)
 }
 
+func (s *Scope) getValueGroupEntries(name string, t reflect.Type) []groupEntry {
+	defer s.lock()()
+	k := key{group: name, t: t}
+	items, members := s.groups[k], s.groupMembers[k]
+	entries := make([]groupEntry, len(items))
+	if s.rootScope().deterministicGroupOrder {
+		for i, j := range groupOrder(members) {
+			entries[i] = groupEntry{Value: items[j], groupMember: members[j]}
+		}
+		return entries
+	}
+	// shuffle the list so users don't rely on the ordering of grouped values
+	for i, j := range s.rand.Perm(len(items)) {
+		entries[i] = groupEntry{Value: items[j], groupMember: members[j]}
+	}
+	return entries
+}
+
 func (s *Scope) getDecoratedValueGroup(name string, t reflect.Type) (reflect.Value, bool) {
+	defer s.lock()()
 	items, ok := s.decoratedGroups[key{group: name, t: t}]
 	return items, ok
 }
 
-func (s *Scope) submitGroupedValue(name string, t reflect.Type, v reflect.Value) {
+func (s *Scope) submitGroupedValue(name string, t reflect.Type, v reflect.Value, labels []string) {
+	s.submitGroupedValueFrom(name, t, v, groupMember{Labels: labels})
+}
+
+func (s *Scope) submitGroupedValueFrom(name string, t reflect.Type, v reflect.Value, m groupMember) {
+	unlock := s.lock()
 	k := key{group: name, t: t}
 	s.groups[k] = append(s.groups[k], v)
+	s.groupMembers[k] = append(s.groupMembers[k], m)
+	unlock()
+
+	for _, f := range s.rootScope().groupSubmitHooks {
+		f(name, t, v)
+	}
 }
 
 func (s *Scope) submitDecoratedGroupedValue(name string, t reflect.Type, v reflect.Value) {
+	defer s.lock()()
 	k := key{group: name, t: t}
 	s.decoratedGroups[k] = v
 }
 
+func (s *Scope) omitGroupedValue(name string, t reflect.Type) {
+	s.omitGroupedValueFrom(name, t, groupMember{})
+}
+
+func (s *Scope) omitGroupedValueFrom(name string, t reflect.Type, m groupMember) {
+	defer s.lock()()
+	k := key{group: name, t: t}
+	s.groupOmissions[k] = append(s.groupOmissions[k], m)
+}
+
+// wasCalled reports whether the given constructor, which must be owned by
+// this Scope, was already called.
+func (s *Scope) wasCalled(n *constructorNode) bool {
+	defer s.lock()()
+	_, ok := s.calledCtors[n]
+	return ok
+}
+
+// beginCall starts a call of the given constructor, which must be owned by
+// this Scope, and reports whether the caller should call it. If it should
+// not, beginCall returns the call in progress to wait for, or nil if the
+// constructor was already called.
+func (s *Scope) beginCall(n *constructorNode) (fl *inflightCall, start bool) {
+	defer s.lock()()
+	if _, ok := s.calledCtors[n]; ok {
+		return nil, false
+	}
+	if fl := s.inflightCtors[n]; fl != nil {
+		return fl, false
+	}
+	fl = &inflightCall{done: make(chan struct{})}
+	s.inflightCtors[n] = fl
+	return fl, true
+}
+
+// isCalling reports whether the given constructor, which must be owned by
+// this Scope, is being called.
+func (s *Scope) isCalling(n *constructorNode) bool {
+	defer s.lock()()
+	return s.inflightCtors[n] != nil
+}
+
+// endCall completes the given call of a constructor started by beginCall.
+func (s *Scope) endCall(n *constructorNode, fl *inflightCall) {
+	unlock := s.lock()
+	delete(s.inflightCtors, n)
+	unlock()
+	close(fl.done)
+}
+
+// markCalled records that the given constructor, which must be owned by
+// this Scope, was called and its values were cached in this Scope.
+func (s *Scope) markCalled(n *constructorNode) {
+	defer s.lock()()
+	s.calledCtors[n] = nil
+}
+
+// markFailed records that the given constructor, which must be owned by
+// this Scope, was called and failed with an error that was reported to its
+// error group. None of its values were cached.
+func (s *Scope) markFailed(n *constructorNode, err error) {
+	defer s.lock()()
+	s.calledCtors[n] = err
+}
+
+// reportedError returns the error that the given constructor, which must be
+// owned by this Scope, reported to its error group, if any.
+func (s *Scope) reportedError(n *constructorNode) error {
+	defer s.lock()()
+	return s.calledCtors[n]
+}
+
+// callFrame returns nil: a Scope builds values outside of any call.
+// frameStore carries the frame of a call.
+func (s *Scope) callFrame() *callFrame {
+	return nil
+}
+
+// lock locks the state of the Container that changes while functions are
+// invoked, and returns a function that unlocks it.
+//
+//	defer s.lock()()
+func (s *Scope) lock() (unlock func()) {
+	mu := &s.rootScope().mu
+	mu.Lock()
+	return mu.Unlock
+}
+
 func (s *Scope) getValueProviders(name string, t reflect.Type) []provider {
 	return s.getProviders(key{name: name, t: t})
 }
 
+func (s *Scope) getValueNames(t reflect.Type) []string {
+	var names []string
+	for k, nodes := range s.providers {
+		if k.t == t && k.name != "" && len(nodes) > 0 {
+			names = append(names, k.name)
+		}
+	}
+	return names
+}
+
 func (s *Scope) getGroupProviders(name string, t reflect.Type) []provider {
 	return s.getProviders(key{group: name, t: t})
 }
@@ -262,19 +625,31 @@ func (s *Scope) invoker() invokerFn {
 	return s.invokerFn
 }
 
+func (s *Scope) tagKeys() tagKeys {
+	return s.rootScope().tags
+}
+
 // adds a new graphNode to this Scope and all of its descendent
 // scope.
 func (s *Scope) newGraphNode(wrapped interface{}, orders map[*Scope]int) {
+	defer s.lock()()
+	s.addGraphNode(wrapped, orders)
+}
+
+// addGraphNode is newGraphNode for callers that hold the lock.
+func (s *Scope) addGraphNode(wrapped interface{}, orders map[*Scope]int) {
 	orders[s] = s.gh.NewNode(wrapped)
 	for _, cs := range s.childScopes {
-		cs.newGraphNode(wrapped, orders)
+		cs.addGraphNode(wrapped, orders)
 	}
 }
 
-func (s *Scope) cycleDetectedError(cycle []int) error {
+// cycleDetectedError returns the error for a cycle of the given snapshot of
+// the graph of this Scope.
+func (s *Scope) cycleDetectedError(g *graphHolder, cycle []int) error {
 	var path []cycleErrPathEntry
 	for _, n := range cycle {
-		if n, ok := s.gh.Lookup(n).(*constructorNode); ok {
+		if n, ok := g.Lookup(n).(*constructorNode); ok {
 			path = append(path, cycleErrPathEntry{
 				Key: key{
 					t: n.CType(),
@@ -297,6 +672,8 @@ func (s *Scope) rootScope() *Scope {
 
 // String representation of the entire Scope
 func (s *Scope) String() string {
+	defer s.lock()()
+
 	b := &bytes.Buffer{}
 	fmt.Fprintln(b, "nodes: {")
 	for k, vs := range s.providers {
@@ -317,5 +694,13 @@ func (s *Scope) String() string {
 	}
 	fmt.Fprintln(b, "}")
 
+	if len(s.derived) > 0 {
+		fmt.Fprintln(b, "derived: {")
+		for k, t := range s.derived {
+			fmt.Fprintln(b, "\t", k, "<-", t)
+		}
+		fmt.Fprintln(b, "}")
+	}
+
 	return b.String()
 }
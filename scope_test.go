@@ -24,6 +24,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/dig"
 	"go.uber.org/dig/internal/digtest"
 )
@@ -140,6 +141,102 @@ func TestScopedOperations(t *testing.T) {
 		}
 	})
 
+	t.Run("provide with PrivateTo", func(t *testing.T) {
+		type A struct{}
+		// Scope tree:
+		//     root  <-- Provide(func() *A, PrivateTo(child))
+		//      |
+		//     child
+		//      |
+		//      gc
+		root := dig.New()
+		child := root.Scope("child")
+		gc := child.Scope("grandchild")
+
+		require.NoError(t, root.Provide(func() *A { return &A{} }, dig.PrivateTo(child)))
+
+		err := root.Invoke(func(a *A) {})
+		require.Error(t, err, "expected Invoke in root container on private type to fail")
+		assert.Contains(t, err.Error(), "missing type: *dig_test.A")
+
+		assert.NoError(t, child.Invoke(func(a *A) {}))
+		assert.NoError(t, gc.Invoke(func(a *A) {}))
+	})
+
+	t.Run("PrivateTo resolves dependencies from the target scope", func(t *testing.T) {
+		type A struct{ name string }
+		type B struct{ a *A }
+
+		root := dig.New()
+		child := root.Scope("child")
+
+		require.NoError(t, child.Provide(func() *A { return &A{name: "child"} }))
+		require.NoError(t, root.Provide(func(a *A) *B { return &B{a: a} }, dig.PrivateTo(child)))
+
+		assert.NoError(t, child.Invoke(func(b *B) {
+			assert.Equal(t, "child", b.a.name)
+		}))
+	})
+
+	t.Run("ResolveFrom resolves dependencies from the given scope", func(t *testing.T) {
+		type A struct{ name string }
+		type B struct{ a *A }
+
+		root := dig.New()
+		child := root.Scope("child")
+
+		require.NoError(t, root.Provide(func() *A { return &A{name: "root"} }))
+		require.NoError(t, child.Provide(func() *A { return &A{name: "child"} }))
+		require.NoError(t, root.Provide(func(a *A) *B { return &B{a: a} }, dig.ResolveFrom(child)))
+
+		assert.NoError(t, root.Invoke(func(b *B) {
+			assert.Equal(t, "child", b.a.name)
+		}))
+		assert.NoError(t, root.Invoke(func(a *A) {
+			assert.Equal(t, "root", a.name, "root must not see values of the child")
+		}))
+
+		snap := dig.DebugSnapshot(root)
+		require.Len(t, snap.Root.Providers, 2)
+		assert.False(t, snap.Root.Providers[1].Exported)
+		require.Len(t, snap.Root.Children, 1)
+		assert.Len(t, snap.Root.Children[0].Providers, 1)
+	})
+
+	t.Run("ResolveFrom detects cycles through the given scope", func(t *testing.T) {
+		type A struct{}
+		type B struct{}
+
+		root := dig.New()
+		child := root.Scope("child")
+
+		require.NoError(t, child.Provide(func(*B) *A { return &A{} }))
+		err := root.Provide(func(*A) *B { return &B{} }, dig.ResolveFrom(child))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `this function introduces a cycle: [scope "child"]`)
+
+		err = root.Invoke(func(*B) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: *dig_test.B")
+	})
+
+	t.Run("exported constructor that introduces a cycle in its scope", func(t *testing.T) {
+		type A struct{}
+		type B struct{}
+
+		root := dig.New()
+		child := root.Scope("child")
+
+		require.NoError(t, child.Provide(func(*B) *A { return &A{} }))
+		err := child.Provide(func(*A) *B { return &B{} }, dig.Export(true))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `this function introduces a cycle: [scope "child"]`)
+
+		err = root.Invoke(func(*B) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: *dig_test.B", "failed constructor must not be provided")
+	})
+
 	t.Run("parent shares values with children", func(t *testing.T) {
 		type (
 			T1 struct{ s string }
@@ -174,6 +271,157 @@ func TestScopedOperations(t *testing.T) {
 	})
 }
 
+func TestScopeResolutionOrder(t *testing.T) {
+	t.Parallel()
+
+	type Config struct{ name string }
+
+	t.Run("child provider shadows parent cache", func(t *testing.T) {
+		root := digtest.New(t)
+		root.RequireProvide(func() *Config { return &Config{name: "root"} })
+		root.RequireInvoke(func(c *Config) {
+			assert.Equal(t, "root", c.name)
+		})
+
+		child := root.Scope("child")
+		child.RequireProvide(func() *Config { return &Config{name: "child"} })
+
+		child.RequireInvoke(func(c *Config) {
+			assert.Equal(t, "child", c.name)
+		})
+		root.RequireInvoke(func(c *Config) {
+			assert.Equal(t, "root", c.name, "parent must keep its own value")
+		})
+	})
+
+	t.Run("parent provider with child cache", func(t *testing.T) {
+		root := digtest.New(t)
+		root.RequireProvide(func() *Config { return &Config{name: "root"} })
+
+		child := root.Scope("child")
+		child.RequireProvide(func() *Config { return &Config{name: "child"} })
+		child.RequireInvoke(func(c *Config) {
+			assert.Equal(t, "child", c.name)
+		})
+
+		root.RequireInvoke(func(c *Config) {
+			assert.Equal(t, "root", c.name, "child cache must not leak to parent")
+		})
+		child.RequireInvoke(func(c *Config) {
+			assert.Equal(t, "child", c.name)
+		})
+	})
+
+	t.Run("parent cache is shared without a child provider", func(t *testing.T) {
+		root := digtest.New(t)
+
+		var calls int
+		root.RequireProvide(func() *Config {
+			calls++
+			return &Config{name: "root"}
+		})
+		root.RequireInvoke(func(*Config) {})
+
+		child := root.Scope("child")
+		child.RequireInvoke(func(c *Config) {
+			assert.Equal(t, "root", c.name)
+		})
+		assert.Equal(t, 1, calls, "constructor must be called once")
+	})
+
+	t.Run("groups are merged", func(t *testing.T) {
+		type params struct {
+			dig.In
+
+			Names []string `group:"names"`
+		}
+
+		root := digtest.New(t)
+		root.RequireProvide(func() string { return "root" }, dig.Group("names"))
+		root.RequireInvoke(func(p params) {
+			assert.ElementsMatch(t, []string{"root"}, p.Names)
+		})
+
+		child := root.Scope("child")
+		child.RequireProvide(func() string { return "child" }, dig.Group("names"))
+		child.RequireInvoke(func(p params) {
+			assert.ElementsMatch(t, []string{"root", "child"}, p.Names)
+		})
+		root.RequireInvoke(func(p params) {
+			assert.ElementsMatch(t, []string{"root"}, p.Names)
+		})
+	})
+}
+
+func TestScopeMemoization(t *testing.T) {
+	t.Parallel()
+
+	type Config struct{ name string }
+	type Server struct{ cfg *Config }
+
+	t.Run("root constructor is called once for all children", func(t *testing.T) {
+		// Scope tree:
+		//        root  <-- Provide(func() *Config)
+		//       /    \
+		//      a      b
+		//      |      |
+		//     gc1    gc2
+		root := digtest.New(t)
+		a, b := root.Scope("a"), root.Scope("b")
+		gc1, gc2 := a.Scope("gc1"), b.Scope("gc2")
+
+		var calls int
+		root.RequireProvide(func() *Config {
+			calls++
+			return &Config{name: "root"}
+		})
+
+		var got []*Config
+		for _, s := range []*digtest.Scope{gc1, a, gc2, b} {
+			s.RequireInvoke(func(c *Config) { got = append(got, c) })
+		}
+		root.RequireInvoke(func(c *Config) { got = append(got, c) })
+
+		assert.Equal(t, 1, calls, "constructor must be called exactly once")
+		for _, c := range got {
+			assert.True(t, c == got[0], "all scopes must share the same value")
+		}
+	})
+
+	t.Run("sibling constructors are called separately", func(t *testing.T) {
+		root := digtest.New(t)
+		a, b := root.Scope("a"), root.Scope("b")
+
+		var calls int
+		newConfig := func(name string) func() *Config {
+			return func() *Config {
+				calls++
+				return &Config{name: name}
+			}
+		}
+		a.RequireProvide(newConfig("a"))
+		b.RequireProvide(newConfig("b"))
+
+		a.RequireInvoke(func(c *Config) { assert.Equal(t, "a", c.name) })
+		b.RequireInvoke(func(c *Config) { assert.Equal(t, "b", c.name) })
+		a.RequireInvoke(func(c *Config) { assert.Equal(t, "a", c.name) })
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("root constructor does not capture child dependencies", func(t *testing.T) {
+		root := digtest.New(t)
+		a, b := root.Scope("a"), root.Scope("b")
+
+		root.RequireProvide(func(c *Config) *Server { return &Server{cfg: c} })
+		a.RequireProvide(func() *Config { return &Config{name: "a"} })
+		b.RequireProvide(func() *Config { return &Config{name: "b"} })
+
+		err := a.Invoke(func(*Server) {})
+		require.Error(t, err, "root constructor must resolve its dependencies from the root")
+		assert.Contains(t, err.Error(), "missing type: *dig_test.Config")
+	})
+}
+
 func TestScopeFailures(t *testing.T) {
 	t.Parallel()
 
@@ -317,6 +565,51 @@ func TestScopeFailures(t *testing.T) {
 
 		gc.RequireInvoke(func(a *A) {})
 	})
+	t.Run("PrivateTo a scope outside the subtree", func(t *testing.T) {
+		type A struct{}
+
+		root := dig.New()
+		child1 := root.Scope("child 1")
+		child2 := root.Scope("child 2")
+
+		err := child1.Provide(func() *A { return &A{} }, dig.PrivateTo(child2))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			`cannot provide to scope "child 2" privately: it is not "child 1" or one of its descendants`)
+		assert.Error(t, child2.Invoke(func(a *A) {}))
+	})
+
+	t.Run("PrivateTo with Export", func(t *testing.T) {
+		root := dig.New()
+		child := root.Scope("child")
+
+		err := child.Provide(func() int { return 0 }, dig.PrivateTo(child), dig.Export(true))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot use dig.Export with dig.PrivateTo")
+	})
+
+	t.Run("ResolveFrom a scope outside the subtree", func(t *testing.T) {
+		type A struct{}
+
+		root := dig.New()
+		child1 := root.Scope("child 1")
+		child2 := root.Scope("child 2")
+
+		err := child1.Provide(func() *A { return &A{} }, dig.ResolveFrom(child2))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			`cannot resolve from scope "child 2": it is not "child 1" or one of its descendants`)
+		assert.Error(t, child1.Invoke(func(a *A) {}))
+	})
+
+	t.Run("ResolveFrom with Export", func(t *testing.T) {
+		root := dig.New()
+		child := root.Scope("child")
+
+		err := child.Provide(func() int { return 0 }, dig.ResolveFrom(child), dig.Export(true))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot use dig.Export with dig.ResolveFrom")
+	})
 }
 
 func TestScopeValueGroups(t *testing.T) {
@@ -397,3 +690,95 @@ func TestScopeValueGroups(t *testing.T) {
 		child.RequireInvoke(func(T1) {})
 	})
 }
+
+func TestScopeExportedGroups(t *testing.T) {
+	t.Parallel()
+
+	type PluginConfig struct{ name string }
+	type Codec string
+
+	type codecs struct {
+		dig.In
+
+		Codecs []Codec `group:"codecs"`
+	}
+
+	newPlugin := func(c *digtest.Container, name string) *digtest.Scope {
+		plugin := c.Scope(name)
+		plugin.RequireProvide(func() *PluginConfig { return &PluginConfig{name: name} })
+		return plugin
+	}
+
+	t.Run("exported values are built in their scope", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() Codec { return "json" }, dig.Group("codecs"))
+		for _, name := range []string{"proto", "yaml"} {
+			newPlugin(c, name).RequireProvide(func(cfg *PluginConfig) Codec {
+				return Codec(cfg.name)
+			}, dig.Group("codecs"), dig.Export(true))
+		}
+
+		c.RequireInvoke(func(p codecs) {
+			assert.ElementsMatch(t, []Codec{"json", "proto", "yaml"}, p.Codecs)
+		})
+	})
+
+	t.Run("unexported values are local", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() Codec { return "json" }, dig.Group("codecs"))
+		plugin := newPlugin(c, "proto")
+		plugin.RequireProvide(func(cfg *PluginConfig) Codec {
+			return Codec(cfg.name)
+		}, dig.Group("codecs"))
+
+		c.RequireInvoke(func(p codecs) {
+			assert.Equal(t, []Codec{"json"}, p.Codecs)
+		})
+		plugin.RequireInvoke(func(p codecs) {
+			assert.ElementsMatch(t, []Codec{"json", "proto"}, p.Codecs)
+		})
+	})
+}
+
+func TestScopeTree(t *testing.T) {
+	t.Parallel()
+
+	type A struct{}
+	type B struct{}
+	type C struct{}
+
+	c := digtest.New(t)
+	c.RequireProvide(func() *A { return &A{} })
+	child := c.Scope("child")
+	child.RequireProvide(func() *B { return &B{} })
+	child.RequireProvide(func() *C { return &C{} })
+	grandchild := child.Scope("grandchild")
+	grandchild.RequireProvide(func() int { return 0 }, dig.Export(true))
+	c.Scope("sibling")
+
+	assert.Equal(t, &dig.ScopeNode{
+		Providers: 2,
+		Children: []*dig.ScopeNode{
+			{
+				Name:      "child",
+				Providers: 2,
+				Children: []*dig.ScopeNode{
+					{Name: "grandchild"},
+				},
+			},
+			{Name: "sibling"},
+		},
+	}, c.ScopeTree())
+
+	assert.Equal(t, &dig.ScopeNode{
+		Name:      "child",
+		Providers: 2,
+		Children: []*dig.ScopeNode{
+			{Name: "grandchild"},
+		},
+	}, child.ScopeTree())
+}
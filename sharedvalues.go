@@ -0,0 +1,237 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"go.uber.org/dig/internal/digreflect"
+)
+
+// CopySharedValues is an [Option] that gives every function that depends
+// on a slice or a map its own copy of it.
+//
+// The Container builds each value once and passes the same value to all
+// functions that depend on it, so by default these functions share the
+// backing storage of slices and maps, and one of them modifying the value
+// changes it for the others. With this option, each function instead
+// receives a shallow copy: the elements of the slice, or the keys and
+// values of the map, are not copied themselves. This applies to the
+// elements of value groups as well.
+func CopySharedValues() Option {
+	return copySharedValuesOption{}
+}
+
+type copySharedValuesOption struct{}
+
+func (copySharedValuesOption) String() string {
+	return "CopySharedValues()"
+}
+
+func (copySharedValuesOption) applyOption(c *Container) {
+	c.scope.copySharedValues = true
+}
+
+// DetectSharedMutations is an [Option] that reports functions that
+// modify slices or maps that they share with other functions.
+//
+// With this option, the Container hashes the contents of every slice or
+// map that it passes to a function, including the elements of value
+// groups, the first time it does so. Before passing the same value to
+// another function, it hashes the value again and fails that function's
+// arguments with an error naming the functions that received it earlier
+// if the contents changed.
+//
+// This is intended to help track down such bugs and makes functions
+// slower to call, so it should not be enabled in production. Only direct
+// modifications are detected; changes to values that the slice or map
+// points to are not. It has no effect with the CopySharedValues option,
+// since functions never share slices or maps then.
+func DetectSharedMutations() Option {
+	return detectSharedMutationsOption{}
+}
+
+type detectSharedMutationsOption struct{}
+
+func (detectSharedMutationsOption) String() string {
+	return "DetectSharedMutations()"
+}
+
+func (detectSharedMutationsOption) applyOption(c *Container) {
+	c.scope.detectSharedMutations = true
+}
+
+func (s *Scope) copiesSharedValues() bool {
+	return s.rootScope().copySharedValues
+}
+
+// shallowCopy returns a copy of v if it is a slice or a map, and v
+// otherwise.
+func shallowCopy(v reflect.Value) reflect.Value {
+	switch {
+	case v.Kind() == reflect.Slice && !v.IsNil():
+		cp := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(cp, v)
+		return cp
+	case v.Kind() == reflect.Map && !v.IsNil():
+		cp := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			cp.SetMapIndex(iter.Key(), iter.Value())
+		}
+		return cp
+	}
+	return v
+}
+
+// copyShared returns a copy of the value group items, built by this param,
+// where each element is a shallow copy of the original.
+func (pt paramGroupedSlice) copyShared(items reflect.Value) reflect.Value {
+	result := reflect.MakeSlice(items.Type(), items.Len(), items.Len())
+	for i := 0; i < items.Len(); i++ {
+		item := result.Index(i)
+		item.Set(items.Index(i))
+		if pt.EntryType != nil {
+			item = item.FieldByName("Value")
+		}
+		item.Set(shallowCopy(item))
+	}
+	return result
+}
+
+// sharedIdentity identifies the backing storage of a slice or a map.
+type sharedIdentity struct {
+	t   reflect.Type
+	ptr uintptr
+	len int
+}
+
+// sharedDelivery records the contents of a slice or a map when it was
+// first passed to a function, and the functions that received it since.
+type sharedDelivery struct {
+	key       key
+	hash      [sha256.Size]byte
+	consumers []*digreflect.Func
+}
+
+// checkSharedMutations verifies that none of the slices or maps in args,
+// which were built for the given params, changed since they were passed to
+// other functions, and records that they were passed to consumer.
+func (s *Scope) checkSharedMutations(consumer *digreflect.Func, pl paramList, args []reflect.Value) error {
+	root := s.rootScope()
+	if !root.detectSharedMutations || root.copySharedValues {
+		return nil
+	}
+
+	var err error
+	for i, p := range pl.Params {
+		walkShared(p, args[i], func(k key, v reflect.Value) {
+			if err == nil {
+				err = root.checkShared(consumer, k, v)
+			}
+		})
+	}
+	return err
+}
+
+// walkShared calls f with the slices and maps in v, which was built by the
+// given param.
+func walkShared(p param, v reflect.Value, f func(key, reflect.Value)) {
+	switch p := p.(type) {
+	case paramSingle:
+		f(key{t: p.Type, name: p.Name}, p.unwrap(v))
+	case paramObject:
+		for _, field := range p.Fields {
+			walkShared(field.Param, v.Field(field.FieldIndex), f)
+		}
+	case paramGroupedSlice:
+		k := key{t: p.Type.Elem(), group: p.Group}
+		for i := 0; i < v.Len(); i++ {
+			item := v.Index(i)
+			if p.EntryType != nil {
+				item = item.FieldByName("Value")
+			}
+			f(k, item)
+		}
+	}
+}
+
+func (s *Scope) checkShared(consumer *digreflect.Func, k key, v reflect.Value) error {
+	if (v.Kind() != reflect.Slice && v.Kind() != reflect.Map) || v.IsNil() || !v.CanInterface() {
+		return nil
+	}
+
+	// Slices that share a backing array but differ in length are
+	// different values, but maps are identified by their pointer alone
+	// since they are modified in place.
+	id := sharedIdentity{t: v.Type(), ptr: v.Pointer()}
+	if v.Kind() == reflect.Slice {
+		id.len = v.Len()
+	}
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%#v", v.Interface())))
+
+	defer s.lock()()
+	d, ok := s.sharedDeliveries[id]
+	if !ok {
+		if s.sharedDeliveries == nil {
+			s.sharedDeliveries = make(map[sharedIdentity]*sharedDelivery)
+		}
+		s.sharedDeliveries[id] = &sharedDelivery{
+			key:       k,
+			hash:      hash,
+			consumers: []*digreflect.Func{consumer},
+		}
+		return nil
+	}
+	if d.hash != hash {
+		return errSharedValueMutated{Key: d.key, Consumers: d.consumers}
+	}
+	d.consumers = append(d.consumers, consumer)
+	return nil
+}
+
+// errSharedValueMutated is returned by the DetectSharedMutations option
+// when a slice or a map changed after it was passed to other functions.
+type errSharedValueMutated struct {
+	Key       key
+	Consumers []*digreflect.Func
+}
+
+var _ digError = errSharedValueMutated{}
+
+func (e errSharedValueMutated) Error() string { return fmt.Sprint(e) }
+
+func (e errSharedValueMutated) writeMessage(w io.Writer, verb string) {
+	consumers := make([]string, len(e.Consumers))
+	for i, f := range e.Consumers {
+		consumers[i] = fmt.Sprintf(verb, f)
+	}
+	fmt.Fprintf(w, "%v was modified after it was passed to %v",
+		e.Key, strings.Join(consumers, ", "))
+}
+
+func (e errSharedValueMutated) Format(w fmt.State, c rune) {
+	formatError(e, w, c)
+}
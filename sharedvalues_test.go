@@ -0,0 +1,214 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestCopySharedValues(t *testing.T) {
+	t.Parallel()
+
+	type Handler func()
+
+	t.Run("shared by default", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() []string { return []string{"a", "b"} })
+		c.RequireInvoke(func(s []string) { s[0] = "x" })
+		c.RequireInvoke(func(s []string) {
+			assert.Equal(t, []string{"x", "b"}, s)
+		})
+	})
+
+	t.Run("slice", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.CopySharedValues())
+		c.RequireProvide(func() []string { return []string{"a", "b"} })
+		c.RequireInvoke(func(s []string) { s[0] = "x" })
+		c.RequireInvoke(func(s []string) {
+			assert.Equal(t, []string{"a", "b"}, s)
+		})
+	})
+
+	t.Run("map", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.CopySharedValues())
+		c.RequireProvide(func() map[string]Handler {
+			return map[string]Handler{"a": func() {}}
+		})
+		c.RequireInvoke(func(m map[string]Handler) {
+			delete(m, "a")
+			m["b"] = func() {}
+		})
+		c.RequireInvoke(func(m map[string]Handler) {
+			assert.Len(t, m, 1)
+			assert.Contains(t, m, "a")
+		})
+	})
+
+	t.Run("nil values stay nil", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.CopySharedValues())
+		c.RequireProvide(func() []string { return nil })
+		c.RequireProvide(func() map[string]int { return nil })
+		c.RequireInvoke(func(s []string, m map[string]int) {
+			assert.Nil(t, s)
+			assert.Nil(t, m)
+		})
+	})
+
+	t.Run("decorated value", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.CopySharedValues())
+		c.RequireProvide(func() []string { return []string{"a"} })
+		c.RequireDecorate(func(s []string) []string { return append(s, "b") })
+		c.RequireInvoke(func(s []string) { s[0] = "x" })
+		c.RequireInvoke(func(s []string) {
+			assert.Equal(t, []string{"a", "b"}, s)
+		})
+	})
+
+	t.Run("value group elements", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			Lists [][]string `group:"lists"`
+		}
+
+		c := digtest.New(t, dig.CopySharedValues())
+		c.RequireProvide(func() []string { return []string{"a"} }, dig.Group("lists"))
+		c.RequireInvoke(func(p params) {
+			require.Len(t, p.Lists, 1)
+			p.Lists[0][0] = "x"
+		})
+		c.RequireInvoke(func(p params) {
+			assert.Equal(t, [][]string{{"a"}}, p.Lists)
+		})
+	})
+
+	t.Run("value group entries", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			Lists []dig.GroupValue[[]string] `group:"lists"`
+		}
+
+		c := digtest.New(t, dig.CopySharedValues())
+		c.RequireProvide(func() []string { return []string{"a"} }, dig.Group("lists"))
+		c.RequireInvoke(func(p params) {
+			require.Len(t, p.Lists, 1)
+			p.Lists[0].Value[0] = "x"
+		})
+		c.RequireInvoke(func(p params) {
+			require.Len(t, p.Lists, 1)
+			assert.Equal(t, []string{"a"}, p.Lists[0].Value)
+		})
+	})
+}
+
+func TestDetectSharedMutations(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unmodified", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.DetectSharedMutations())
+		c.RequireProvide(func() []string { return []string{"a"} })
+		c.RequireInvoke(func([]string) {})
+		c.RequireInvoke(func([]string) {})
+	})
+
+	t.Run("modified slice", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.DetectSharedMutations())
+		c.RequireProvide(func() []string { return []string{"a"} })
+		c.RequireInvoke(func([]string) {})
+		c.RequireInvoke(func(s []string) { s[0] = "x" })
+
+		err := c.Invoke(func([]string) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "[]string was modified after it was passed to")
+		assert.Contains(t, err.Error(), "TestDetectSharedMutations.func2.2")
+		assert.Contains(t, err.Error(), "TestDetectSharedMutations.func2.3")
+	})
+
+	t.Run("modified map by constructor", func(t *testing.T) {
+		t.Parallel()
+
+		type Consumer struct{}
+
+		c := digtest.New(t, dig.DetectSharedMutations())
+		c.RequireProvide(func() map[string]int { return map[string]int{"a": 1} })
+		c.RequireProvide(func(m map[string]int) *Consumer {
+			m["b"] = 2
+			return &Consumer{}
+		})
+		c.RequireInvoke(func(*Consumer) {})
+
+		err := c.Invoke(func(map[string]int) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "map[string]int was modified after it was passed to")
+		assert.Contains(t, err.Error(), "TestDetectSharedMutations.func3.2")
+	})
+
+	t.Run("modified value group element", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			Lists [][]string `group:"lists"`
+		}
+
+		c := digtest.New(t, dig.DetectSharedMutations())
+		c.RequireProvide(func() []string { return []string{"a"} }, dig.Group("lists"))
+		c.RequireInvoke(func(p params) { p.Lists[0][0] = "x" })
+
+		err := c.Invoke(func(params) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `[]string[group="lists"] was modified`)
+	})
+
+	t.Run("disabled by CopySharedValues", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.DetectSharedMutations(), dig.CopySharedValues())
+		c.RequireProvide(func() []string { return []string{"a"} })
+		c.RequireInvoke(func(s []string) { s[0] = "x" })
+		c.RequireInvoke(func([]string) {})
+	})
+}
@@ -0,0 +1,254 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// StrictSliceGroups is an [Option] that makes Provide fail if a slice type
+// would be both provided directly and consumed from a value group. See
+// SliceGroupConflicts for details. By default, such conflicts are allowed.
+func StrictSliceGroups() Option {
+	return strictSliceGroupsOption{}
+}
+
+type strictSliceGroupsOption struct{}
+
+func (strictSliceGroupsOption) String() string {
+	return "StrictSliceGroups()"
+}
+
+func (strictSliceGroupsOption) applyOption(c *Container) {
+	c.scope.strictSliceGroups = true
+}
+
+// SliceGroupConflict is a slice type that is both provided directly and
+// consumed from value groups of its element type. A function that depends
+// on the slice type receives the provided slice, while a function that
+// depends on it with a group tag receives the values of the group, and the
+// two are easily mistaken for one another.
+type SliceGroupConflict struct {
+	// Type is the slice type.
+	Type reflect.Type
+
+	// SliceProviders are the constructors that provide Type directly.
+	SliceProviders []string
+
+	// Groups are the names of the value groups of the element type of
+	// Type that are provided or consumed.
+	Groups []string
+
+	// SliceConsumers are the constructors and decorators that depend on
+	// the provided slice.
+	SliceConsumers []string
+
+	// GroupConsumers are the constructors and decorators that depend on
+	// one of the Groups.
+	GroupConsumers []string
+}
+
+func (c SliceGroupConflict) String() string {
+	groups := make([]string, len(c.Groups))
+	for i, g := range c.Groups {
+		groups[i] = fmt.Sprintf("%q", g)
+	}
+	return fmt.Sprintf("%v is provided by %v, but is also the type of value groups %v: "+
+		"the provided slice is read by %v, and the value groups are read by %v",
+		c.Type, joinOrNone(c.SliceProviders), strings.Join(groups, ", "),
+		joinOrNone(c.SliceConsumers), joinOrNone(c.GroupConsumers))
+}
+
+func joinOrNone(ss []string) string {
+	if len(ss) == 0 {
+		return "none"
+	}
+	return strings.Join(ss, "; ")
+}
+
+// SliceGroupConflicts reports the slice types that are both provided to
+// the Container, or to any of its Scopes, without a name, and consumed from
+// value groups of their element type, along with the functions that read
+// each of them. Functions passed to Invoke are not known in advance, so
+// they are not reported as consumers.
+//
+// Use the StrictSliceGroups option to reject such conflicts at Provide
+// time.
+func (c *Container) SliceGroupConflicts() []SliceGroupConflict {
+	return c.scope.SliceGroupConflicts()
+}
+
+// SliceGroupConflicts reports the slice types that are both provided to
+// this Scope, or to any of its descendants, and consumed from value groups.
+// See Container.SliceGroupConflicts for details.
+func (s *Scope) SliceGroupConflicts() []SliceGroupConflict {
+	idx := newSliceGroupIndex(s.appendSubscopes(nil))
+	return idx.conflicts()
+}
+
+// checkSliceGroups fails if the given constructor, which provides values
+// with the given keys, introduces a conflict reported by
+// SliceGroupConflicts, either as a provider or as a consumer.
+func (s *Scope) checkSliceGroups(n *constructorNode, keys map[key]struct{}) error {
+	idx := newSliceGroupIndex(s.rootScope().appendSubscopes(nil))
+	known := make(map[reflect.Type]struct{})
+	for _, c := range idx.conflicts() {
+		known[c.Type] = struct{}{}
+	}
+
+	for k := range keys {
+		idx.addProvider(k, n)
+	}
+	idx.addConsumer(fmt.Sprint(n.location), n.paramList.Params)
+	for _, c := range idx.conflicts() {
+		if _, ok := known[c.Type]; !ok {
+			return newErrInvalidInput(fmt.Sprintf("%v: name the slice or use a different type", c), nil)
+		}
+	}
+	return nil
+}
+
+// sliceGroupIndex collects the slice types and the value groups that are
+// provided and consumed in a set of Scopes.
+type sliceGroupIndex struct {
+	// Providers and consumers of unnamed slices, by slice type.
+	sliceProviders map[reflect.Type][]string
+	sliceConsumers map[reflect.Type][]string
+
+	// Names of value groups and their consumers, by slice type.
+	groups         map[reflect.Type]map[string]struct{}
+	groupConsumers map[reflect.Type][]string
+}
+
+func newSliceGroupIndex(scopes []*Scope) *sliceGroupIndex {
+	idx := &sliceGroupIndex{
+		sliceProviders: make(map[reflect.Type][]string),
+		sliceConsumers: make(map[reflect.Type][]string),
+		groups:         make(map[reflect.Type]map[string]struct{}),
+		groupConsumers: make(map[reflect.Type][]string),
+	}
+	for _, scope := range scopes {
+		for k, ps := range scope.providers {
+			for _, p := range ps {
+				idx.addProvider(k, p)
+			}
+		}
+		for _, n := range scope.nodes {
+			idx.addConsumer(fmt.Sprint(n.Location()), n.ParamList().Params)
+		}
+		seen := make(map[*decoratorNode]struct{})
+		for _, d := range scope.decorators {
+			if _, ok := seen[d]; ok {
+				continue
+			}
+			seen[d] = struct{}{}
+			idx.addConsumer(fmt.Sprint(d.location), d.params.Params)
+		}
+	}
+	return idx
+}
+
+func (idx *sliceGroupIndex) addGroup(t reflect.Type, group string) {
+	if idx.groups[t] == nil {
+		idx.groups[t] = make(map[string]struct{})
+	}
+	idx.groups[t][group] = struct{}{}
+}
+
+// addProvider records that p provides the value with the given key.
+func (idx *sliceGroupIndex) addProvider(k key, p provider) {
+	switch {
+	case k.group != "":
+		idx.addGroup(reflect.SliceOf(k.t), k.group)
+	case k.name == "" && k.t.Kind() == reflect.Slice:
+		idx.sliceProviders[k.t] = append(idx.sliceProviders[k.t], fmt.Sprint(p.Location()))
+	}
+}
+
+// addConsumer records the slices and value groups that the given params of
+// the given function depend on.
+func (idx *sliceGroupIndex) addConsumer(consumer string, params []param) {
+	for _, p := range params {
+		switch p := p.(type) {
+		case paramSingle:
+			if p.Name == "" && p.Type.Kind() == reflect.Slice {
+				idx.sliceConsumers[p.Type] = appendUnique(idx.sliceConsumers[p.Type], consumer)
+			}
+		case paramDynamic:
+			idx.addConsumer(consumer, []param{p.Value})
+		case paramGroupedSlice:
+			idx.addGroup(p.Type, p.Group)
+			idx.groupConsumers[p.Type] = appendUnique(idx.groupConsumers[p.Type], consumer)
+		case paramObject:
+			for _, f := range p.Fields {
+				idx.addConsumer(consumer, []param{f.Param})
+			}
+		}
+	}
+}
+
+func appendUnique(ss []string, s string) []string {
+	for _, x := range ss {
+		if x == s {
+			return ss
+		}
+	}
+	return append(ss, s)
+}
+
+// conflicts returns the conflicts in this index, sorted by type.
+func (idx *sliceGroupIndex) conflicts() []SliceGroupConflict {
+	var conflicts []SliceGroupConflict
+	for t, providers := range idx.sliceProviders {
+		groups := idx.groups[t]
+		if len(groups) == 0 {
+			continue
+		}
+
+		c := SliceGroupConflict{
+			Type:           t,
+			SliceProviders: sortedCopy(providers),
+			SliceConsumers: sortedCopy(idx.sliceConsumers[t]),
+			GroupConsumers: sortedCopy(idx.groupConsumers[t]),
+		}
+		for g := range groups {
+			c.Groups = append(c.Groups, g)
+		}
+		sort.Strings(c.Groups)
+		conflicts = append(conflicts, c)
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].Type.String() < conflicts[j].Type.String()
+	})
+	return conflicts
+}
+
+func sortedCopy(ss []string) []string {
+	if len(ss) == 0 {
+		return nil
+	}
+	ss = append([]string(nil), ss...)
+	sort.Strings(ss)
+	return ss
+}
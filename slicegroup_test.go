@@ -0,0 +1,181 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+type sliceGroupRoute string
+
+type sliceGroupRoutes struct {
+	dig.In
+
+	Routes []sliceGroupRoute `group:"routes"`
+}
+
+type sliceGroupServer struct{}
+
+func newSliceGroupRoutes() []sliceGroupRoute { return []sliceGroupRoute{"/"} }
+
+func newSliceGroupRoute() sliceGroupRoute { return "/health" }
+
+func newSliceGroupServer(sliceGroupRoutes) *sliceGroupServer { return &sliceGroupServer{} }
+
+func newSliceGroupMux([]sliceGroupRoute) *int { return new(int) }
+
+func TestSliceGroupConflicts(t *testing.T) {
+	t.Parallel()
+
+	t.Run("conflict", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(newSliceGroupRoutes)
+		c.RequireProvide(newSliceGroupRoute, dig.Group("routes"))
+		c.RequireProvide(newSliceGroupServer)
+		c.RequireProvide(newSliceGroupMux)
+
+		conflicts := c.SliceGroupConflicts()
+		require.Len(t, conflicts, 1)
+		conflict := conflicts[0]
+		assert.Equal(t, reflect.TypeOf([]sliceGroupRoute(nil)), conflict.Type)
+		assert.Equal(t, []string{"routes"}, conflict.Groups)
+		require.Len(t, conflict.SliceProviders, 1)
+		assert.Contains(t, conflict.SliceProviders[0], "newSliceGroupRoutes")
+		require.Len(t, conflict.SliceConsumers, 1)
+		assert.Contains(t, conflict.SliceConsumers[0], "newSliceGroupMux")
+		require.Len(t, conflict.GroupConsumers, 1)
+		assert.Contains(t, conflict.GroupConsumers[0], "newSliceGroupServer")
+
+		assert.Regexp(t,
+			`^\[\]dig_test.sliceGroupRoute is provided by "go.uber.org/dig_test".newSliceGroupRoutes \(\S+\), `+
+				`but is also the type of value groups "routes": `+
+				`the provided slice is read by "go.uber.org/dig_test".newSliceGroupMux \(\S+\), `+
+				`and the value groups are read by "go.uber.org/dig_test".newSliceGroupServer \(\S+\)$`,
+			conflict.String())
+	})
+
+	t.Run("group without providers", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(newSliceGroupRoutes)
+		c.RequireProvide(newSliceGroupServer)
+
+		conflicts := c.SliceGroupConflicts()
+		require.Len(t, conflicts, 1)
+		assert.Empty(t, conflicts[0].SliceConsumers)
+		assert.Contains(t, conflicts[0].String(), "the provided slice is read by none")
+	})
+
+	t.Run("named slice", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(newSliceGroupRoutes, dig.Name("static"))
+		c.RequireProvide(newSliceGroupRoute, dig.Group("routes"))
+		c.RequireProvide(newSliceGroupServer)
+
+		assert.Empty(t, c.SliceGroupConflicts())
+	})
+
+	t.Run("no group", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(newSliceGroupRoutes)
+		c.RequireProvide(newSliceGroupMux)
+
+		assert.Empty(t, c.SliceGroupConflicts())
+	})
+
+	t.Run("scopes", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(newSliceGroupRoute, dig.Group("routes"))
+		s := c.Scope("child")
+		s.RequireProvide(newSliceGroupRoutes)
+
+		assert.Len(t, c.SliceGroupConflicts(), 1)
+		assert.Len(t, s.SliceGroupConflicts(), 0,
+			"only constructors provided to the child Scope are considered")
+	})
+}
+
+func TestStrictSliceGroups(t *testing.T) {
+	t.Parallel()
+
+	t.Run("slice after group", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.StrictSliceGroups())
+		c.RequireProvide(newSliceGroupRoute, dig.Group("routes"))
+
+		err := c.Provide(newSliceGroupRoutes)
+		require.Error(t, err)
+		dig.AssertErrorMatches(t, err,
+			`cannot provide function "go.uber.org/dig_test".newSliceGroupRoutes`,
+			`slicegroup_test.go:\d+`,
+			`\[\]dig_test.sliceGroupRoute is provided by "go.uber.org/dig_test".newSliceGroupRoutes \(\S+\), but is also the type of value groups "routes": .+: name the slice or use a different type`)
+	})
+
+	t.Run("group after slice", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.StrictSliceGroups())
+		c.RequireProvide(newSliceGroupRoutes)
+
+		err := c.Provide(newSliceGroupRoute, dig.Group("routes"))
+		require.Error(t, err)
+		assert.ErrorContains(t, err, `but is also the type of value groups "routes"`)
+	})
+
+	t.Run("group consumer after slice", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.StrictSliceGroups())
+		c.RequireProvide(newSliceGroupRoutes)
+
+		err := c.Provide(newSliceGroupServer)
+		require.Error(t, err)
+		assert.ErrorContains(t, err, `newSliceGroupServer`)
+
+		// The failed constructor was not provided.
+		assert.Empty(t, c.SliceGroupConflicts())
+	})
+
+	t.Run("named slice", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.StrictSliceGroups())
+		c.RequireProvide(newSliceGroupRoute, dig.Group("routes"))
+		c.RequireProvide(newSliceGroupRoutes, dig.Name("static"))
+		c.RequireProvide(newSliceGroupServer)
+	})
+}
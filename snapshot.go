@@ -0,0 +1,330 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"go.uber.org/dig/internal/digreflect"
+	"go.uber.org/dig/internal/dot"
+)
+
+// Snapshot is the state of a Container and its Scopes at a point in time.
+// It holds only strings, numbers, and booleans, so it may be kept after the
+// Container changes, and marshaled to JSON to render the Container on a
+// debug page. Use WriteDOT to render it with Graphviz.
+type Snapshot struct {
+	// Root is the Scope of the Container itself.
+	Root ScopeSnapshot `json:"root"`
+}
+
+// ScopeSnapshot is the state of a single Scope in a Snapshot.
+type ScopeSnapshot struct {
+	// Name of the Scope. The name of the root Scope is empty.
+	Name string `json:"name"`
+
+	// Constructors provided to the Scope, in the order they were provided,
+	// followed by those provided to the Scope with the Export option.
+	Providers []ProviderSnapshot `json:"providers,omitempty"`
+
+	// Value groups that received values in the Scope, or that constructors
+	// provided to the Scope add values to.
+	Groups []GroupSnapshot `json:"groups,omitempty"`
+
+	// Child Scopes of the Scope, in the order they were created.
+	Children []ScopeSnapshot `json:"children,omitempty"`
+}
+
+// ProviderSnapshot is the state of a constructor in a Snapshot.
+type ProviderSnapshot struct {
+	// ID of the constructor, as reported by ProvideInfo.
+	ID ID `json:"id"`
+
+	// Package, name, file, and line of the constructor.
+	Package  string `json:"package"`
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+
+	// Values that the constructor depends on and produces.
+	Inputs  []KeySnapshot `json:"inputs,omitempty"`
+	Outputs []KeySnapshot `json:"outputs,omitempty"`
+
+	// Called reports whether the constructor was already called.
+	Called bool `json:"called"`
+
+	// Exported reports whether the constructor was provided with the
+	// Export option. Its values are held by the root Scope, and are
+	// visible to all Scopes of the Container.
+	Exported bool `json:"exported,omitempty"`
+
+	// Error that the constructor reported to the value group of the
+	// ReportErrorsToGroup option, if it failed.
+	Error string `json:"error,omitempty"`
+}
+
+// KeySnapshot identifies a value that a constructor depends on or produces
+// in a Snapshot.
+type KeySnapshot struct {
+	// Type of the value. For value groups, this is the type of the values
+	// in the group.
+	Type string `json:"type"`
+
+	// Name or Group of the value, if any. At most one of these is set.
+	Name  string `json:"name,omitempty"`
+	Group string `json:"group,omitempty"`
+
+	// Optional reports whether a dependency is optional.
+	Optional bool `json:"optional,omitempty"`
+
+	// Cached reports whether a value that is not part of a value group was
+	// already built and cached in the Scope.
+	Cached bool `json:"cached,omitempty"`
+}
+
+func (k KeySnapshot) String() string {
+	switch {
+	case k.Name != "":
+		return fmt.Sprintf("%v[name=%q]", k.Type, k.Name)
+	case k.Group != "":
+		return fmt.Sprintf("%v[group=%q]", k.Type, k.Group)
+	}
+	return k.Type
+}
+
+// GroupSnapshot is the state of a value group in a Snapshot.
+type GroupSnapshot struct {
+	// Type of the values in the group and name of the group.
+	Type  string `json:"type"`
+	Group string `json:"group"`
+
+	// Number of values in the group.
+	Values int `json:"values"`
+
+	// IDs of the constructors that contributed the values, in the order
+	// they were added. Values of unknown provenance are not listed.
+	Providers []ID `json:"providers,omitempty"`
+
+	// IDs of the constructors that contributed nothing to the group
+	// because they produced nil values. See OmitNilFromGroup.
+	Omitted []ID `json:"omitted,omitempty"`
+
+	// IDs of the constructors provided to the Scope that add values to the
+	// group, whether or not they were called. Compare with Providers to
+	// see how much of the group was already built.
+	Registered []ID `json:"registered,omitempty"`
+}
+
+// DebugSnapshot returns the state of the Container c and all its Scopes:
+// the constructors provided to them, the values they depend on and
+// produce, which of these were already built, and the values of each value
+// group.
+//
+// Taking a snapshot never calls constructors, and is safe to do while
+// other goroutines call Invoke.
+func DebugSnapshot(c *Container) *Snapshot {
+	s := c.scope
+	defer s.lock()()
+
+	ids := make(map[*digreflect.Func]ID)
+	exported := make(map[*Scope][]*constructorNode)
+	for _, scope := range s.appendSubscopes(nil) {
+		for _, n := range scope.nodes {
+			ids[n.location] = ID(n.id)
+			if n.provideOpts.Exported {
+				exported[n.origS] = append(exported[n.origS], n)
+			}
+		}
+	}
+	return &Snapshot{Root: s.snapshot(ids, exported)}
+}
+
+// snapshot captures the state of this Scope and its descendants. ids maps
+// the locations of constructors to their IDs, and exported maps Scopes to
+// the constructors provided to them with the Export option. The caller
+// must hold the lock.
+func (s *Scope) snapshot(ids map[*digreflect.Func]ID, exported map[*Scope][]*constructorNode) ScopeSnapshot {
+	ss := ScopeSnapshot{Name: s.name}
+	for _, n := range s.nodes {
+		if !n.provideOpts.Exported {
+			ss.Providers = append(ss.Providers, s.snapshotProvider(n))
+		}
+	}
+	for _, n := range exported[s] {
+		p := n.s.snapshotProvider(n)
+		p.Exported = true
+		ss.Providers = append(ss.Providers, p)
+	}
+
+	groups := make([]key, 0, len(s.groups))
+	for k := range s.groups {
+		groups = append(groups, k)
+	}
+	for k, ms := range s.groupOmissions {
+		if _, ok := s.groups[k]; !ok && len(ms) > 0 {
+			groups = append(groups, k)
+		}
+	}
+	for k, ns := range s.providers {
+		_, hasValues := s.groups[k]
+		_, hasOmissions := s.groupOmissions[k]
+		if k.group != "" && !hasValues && !hasOmissions && len(ns) > 0 {
+			groups = append(groups, k)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].String() < groups[j].String()
+	})
+	for _, k := range groups {
+		g := GroupSnapshot{
+			Type:   k.t.String(),
+			Group:  k.group,
+			Values: len(s.groups[k]),
+		}
+		for _, m := range s.groupMembers[k] {
+			if id, ok := ids[m.Source]; ok {
+				g.Providers = append(g.Providers, id)
+			}
+		}
+		for _, m := range s.groupOmissions[k] {
+			if id, ok := ids[m.Source]; ok {
+				g.Omitted = append(g.Omitted, id)
+			}
+		}
+		for _, n := range s.providers[k] {
+			g.Registered = append(g.Registered, ID(n.id))
+		}
+		ss.Groups = append(ss.Groups, g)
+	}
+
+	for _, child := range s.childScopes {
+		ss.Children = append(ss.Children, child.snapshot(ids, exported))
+	}
+	return ss
+}
+
+func (s *Scope) snapshotProvider(n *constructorNode) ProviderSnapshot {
+	p := ProviderSnapshot{
+		ID:       ID(n.id),
+		Package:  n.location.Package,
+		Function: n.location.Name,
+		File:     n.location.File,
+		Line:     n.location.Line,
+	}
+	err, called := s.calledCtors[n]
+	p.Called = called
+	if err != nil {
+		p.Error = err.Error()
+	}
+
+	for _, param := range n.paramList.DotParam() {
+		p.Inputs = append(p.Inputs, snapshotKey(param.Node, param.Optional))
+	}
+	for _, r := range n.resultList.DotResult() {
+		k := snapshotKey(r.Node, false)
+		if r.Group == "" {
+			_, k.Cached = s.values[key{t: r.Type, name: r.Name}]
+		}
+		p.Outputs = append(p.Outputs, k)
+	}
+	return p
+}
+
+func snapshotKey(n *dot.Node, optional bool) KeySnapshot {
+	t := n.Type
+	if n.Group != "" && t.Kind() == reflect.Slice {
+		// Params of value groups are slices of the values in the group.
+		t = t.Elem()
+	}
+	return KeySnapshot{
+		Type:     t.String(),
+		Name:     n.Name,
+		Group:    n.Group,
+		Optional: optional,
+	}
+}
+
+// WriteDOT writes the Snapshot to w as a graph in the DOT format. Each
+// Scope is drawn as a cluster of its constructors, with edges from the
+// values they produce to the constructors, and from the constructors to
+// the values they depend on. Values that were already built are filled,
+// and dependencies that are optional are dashed. The values of exported
+// constructors are drawn outside the cluster of their Scope, since they
+// are visible to all Scopes.
+func (s *Snapshot) WriteDOT(w io.Writer) error {
+	dw := snapshotDOTWriter{}
+	dw.b.WriteString("digraph {\n\trankdir=RL;\n")
+	dw.writeScope(&s.Root)
+	dw.b.Write(dw.exported.Bytes())
+	dw.b.WriteString("}\n")
+	_, err := w.Write(dw.b.Bytes())
+	return err
+}
+
+// snapshotDOTWriter writes a Snapshot in the DOT format, numbering clusters
+// and constructors in the order they are written.
+type snapshotDOTWriter struct {
+	b        bytes.Buffer
+	clusters int
+	ctors    int
+
+	// Values of exported constructors, written after all clusters.
+	exported bytes.Buffer
+}
+
+func (dw *snapshotDOTWriter) writeScope(ss *ScopeSnapshot) {
+	b := &dw.b
+	fmt.Fprintf(b, "\tsubgraph cluster_%d {\n", dw.clusters)
+	dw.clusters++
+	fmt.Fprintf(b, "\t\tlabel = %v;\n", strconv.Quote(ss.Name))
+	for _, p := range ss.Providers {
+		ctor := dw.ctors
+		dw.ctors++
+		fmt.Fprintf(b, "\t\tconstructor_%d [shape=plaintext label=%v];\n",
+			ctor, strconv.Quote(p.Package+"."+p.Function))
+		outs, indent := b, "\t\t"
+		if p.Exported {
+			outs, indent = &dw.exported, "\t"
+		}
+		for _, out := range p.Outputs {
+			if out.Cached {
+				fmt.Fprintf(outs, "%v%v [style=filled];\n", indent, strconv.Quote(out.String()))
+			}
+			fmt.Fprintf(outs, "%v%v -> constructor_%d;\n", indent, strconv.Quote(out.String()), ctor)
+		}
+		for _, in := range p.Inputs {
+			attrs := ""
+			if in.Optional {
+				attrs = " [style=dashed]"
+			}
+			fmt.Fprintf(b, "\t\tconstructor_%d -> %v%v;\n", ctor, strconv.Quote(in.String()), attrs)
+		}
+	}
+	for i := range ss.Children {
+		dw.writeScope(&ss.Children[i])
+	}
+	b.WriteString("\t}\n")
+}
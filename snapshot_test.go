@@ -0,0 +1,236 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestDebugSnapshot(t *testing.T) {
+	t.Parallel()
+
+	type Config struct{}
+	type Server struct{}
+	type Handler string
+	type Metrics struct{}
+
+	newContainer := func(t *testing.T) *digtest.Container {
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{} })
+		c.RequireProvide(func(*Config, struct {
+			dig.In
+
+			Handlers []Handler `group:"handlers"`
+			Metrics  *Metrics  `optional:"true"`
+		}) *Server {
+			return &Server{}
+		})
+		c.RequireProvide(func() Handler { return "users" }, dig.Group("handlers"))
+		c.RequireProvide(func() (*Metrics, error) {
+			return nil, errors.New("great sadness")
+		}, dig.ReportErrorsToGroup("errors"))
+		return c
+	}
+
+	t.Run("does not call constructors", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config {
+			t.Fatal("must not be called")
+			return nil
+		})
+
+		snap := dig.DebugSnapshot(c.Container)
+		require.Len(t, snap.Root.Providers, 1)
+		p := snap.Root.Providers[0]
+		assert.False(t, p.Called)
+		assert.Equal(t, "go.uber.org/dig_test", p.Package)
+		assert.Contains(t, p.Function, "TestDebugSnapshot")
+		assert.NotEmpty(t, p.File)
+		assert.NotZero(t, p.Line)
+		assert.Equal(t, []dig.KeySnapshot{{Type: "*dig_test.Config"}}, p.Outputs)
+	})
+
+	t.Run("reports state", func(t *testing.T) {
+		t.Parallel()
+
+		c := newContainer(t)
+		c.RequireInvoke(func(*Server) {})
+		child := c.Scope("child")
+		child.RequireProvide(func() Handler { return "admin" }, dig.Group("handlers"))
+
+		snap := dig.DebugSnapshot(c.Container)
+		root := snap.Root
+		assert.Empty(t, root.Name)
+		require.Len(t, root.Providers, 4)
+
+		config, server, handler, metrics := root.Providers[0], root.Providers[1], root.Providers[2], root.Providers[3]
+		assert.True(t, config.Called)
+		assert.Equal(t, []dig.KeySnapshot{{Type: "*dig_test.Config", Cached: true}}, config.Outputs)
+
+		assert.True(t, server.Called)
+		assert.Equal(t, []dig.KeySnapshot{
+			{Type: "*dig_test.Config"},
+			{Type: "dig_test.Handler", Group: "handlers"},
+			{Type: "*dig_test.Metrics", Optional: true},
+		}, server.Inputs)
+
+		assert.True(t, handler.Called)
+		assert.Equal(t, []dig.KeySnapshot{{Type: "dig_test.Handler", Group: "handlers"}}, handler.Outputs)
+
+		assert.True(t, metrics.Called)
+		assert.Contains(t, metrics.Error, "great sadness")
+		assert.Equal(t, []dig.KeySnapshot{{Type: "*dig_test.Metrics"}}, metrics.Outputs)
+
+		assert.Equal(t, []dig.GroupSnapshot{
+			{
+				Type:       "dig_test.Handler",
+				Group:      "handlers",
+				Values:     1,
+				Providers:  []dig.ID{handler.ID},
+				Registered: []dig.ID{handler.ID},
+			},
+			{
+				Type:       "error",
+				Group:      "errors",
+				Values:     1,
+				Providers:  []dig.ID{metrics.ID},
+				Registered: []dig.ID{metrics.ID},
+			},
+		}, root.Groups)
+
+		require.Len(t, root.Children, 1)
+		assert.Equal(t, "child", root.Children[0].Name)
+		require.Len(t, root.Children[0].Providers, 1)
+		childHandler := root.Children[0].Providers[0]
+		assert.False(t, childHandler.Called)
+		assert.Equal(t, []dig.GroupSnapshot{
+			{
+				Type:       "dig_test.Handler",
+				Group:      "handlers",
+				Registered: []dig.ID{childHandler.ID},
+			},
+		}, root.Children[0].Groups, "groups that were not built yet must be reported")
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		t.Parallel()
+
+		c := newContainer(t)
+		c.RequireInvoke(func(*Config) {})
+		snap := dig.DebugSnapshot(c.Container)
+
+		b, err := json.Marshal(snap)
+		require.NoError(t, err)
+		assert.Contains(t, string(b), `"outputs":[{"type":"*dig_test.Config","cached":true}]`)
+
+		var got dig.Snapshot
+		require.NoError(t, json.Unmarshal(b, &got))
+		assert.Equal(t, *snap, got)
+	})
+
+	t.Run("DOT", func(t *testing.T) {
+		t.Parallel()
+
+		c := newContainer(t)
+		c.RequireInvoke(func(*Config) {})
+		c.Scope("child")
+
+		var buf bytes.Buffer
+		require.NoError(t, dig.DebugSnapshot(c.Container).WriteDOT(&buf))
+		out := buf.String()
+		assert.Contains(t, out, "digraph {\n\trankdir=RL;\n\tsubgraph cluster_0 {\n\t\tlabel = \"\";\n")
+		assert.Contains(t, out, "\t\t\"*dig_test.Config\" [style=filled];\n\t\t\"*dig_test.Config\" -> constructor_0;\n")
+		assert.Contains(t, out, "\t\tconstructor_1 -> \"*dig_test.Config\";\n")
+		assert.Contains(t, out, "\t\tconstructor_1 -> \"dig_test.Handler[group=\\\"handlers\\\"]\";\n")
+		assert.Contains(t, out, "\t\tconstructor_1 -> \"*dig_test.Metrics\" [style=dashed];\n")
+		assert.Contains(t, out, "\tsubgraph cluster_1 {\n\t\tlabel = \"child\";\n\t}\n\t}\n}\n")
+	})
+
+	t.Run("exported constructors", func(t *testing.T) {
+		t.Parallel()
+
+		c := newContainer(t)
+		child := c.Scope("plugin")
+		child.RequireProvide(func(*Config) Handler { return "plugin" },
+			dig.Group("handlers"), dig.Export(true))
+		c.RequireInvoke(func(*Server) {})
+
+		snap := dig.DebugSnapshot(c.Container)
+		require.Len(t, snap.Root.Providers, 4, "exported constructor must not be listed in the root")
+		for _, p := range snap.Root.Providers {
+			assert.False(t, p.Exported)
+		}
+
+		require.Len(t, snap.Root.Children, 1)
+		plugin := snap.Root.Children[0]
+		require.Len(t, plugin.Providers, 1)
+		exported := plugin.Providers[0]
+		assert.True(t, exported.Exported)
+		assert.True(t, exported.Called)
+
+		assert.Equal(t, dig.GroupSnapshot{
+			Type:       "dig_test.Handler",
+			Group:      "handlers",
+			Values:     2,
+			Providers:  []dig.ID{snap.Root.Providers[2].ID, exported.ID},
+			Registered: []dig.ID{snap.Root.Providers[2].ID, exported.ID},
+		}, snap.Root.Groups[0], "exported values must be held by the root")
+
+		var buf bytes.Buffer
+		require.NoError(t, snap.WriteDOT(&buf))
+		out := buf.String()
+		assert.Contains(t, out, "\tsubgraph cluster_1 {\n\t\tlabel = \"plugin\";\n\t\tconstructor_4 ")
+		assert.Contains(t, out,
+			"\t\tconstructor_4 -> \"*dig_test.Config\";\n\t}\n\t}\n"+
+				"\t\"dig_test.Handler[group=\\\"handlers\\\"]\" -> constructor_4;\n}\n",
+			"values of exported constructors must be drawn outside the cluster")
+	})
+
+	t.Run("concurrent with Invoke", func(t *testing.T) {
+		t.Parallel()
+
+		c := newContainer(t)
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, c.Invoke(func(*Server) {}))
+			}()
+			go func() {
+				defer wg.Done()
+				assert.NotNil(t, dig.DebugSnapshot(c.Container))
+			}()
+		}
+		wg.Wait()
+	})
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// LenientTags is an [Option] that allows struct tag keys on the fields of
+// dig.In structs that look like misspellings of the keys dig reads.
+//
+// By default, a key like `nmae:"ro"` or `groups:"handlers"` fails Provide
+// and Invoke: reflect ignores it, so the field would silently be resolved
+// without a name or group. Keys used by common libraries, like json and
+// yaml, are always allowed.
+func LenientTags() Option {
+	return lenientTagsOption{}
+}
+
+type lenientTagsOption struct{}
+
+func (lenientTagsOption) String() string {
+	return "LenientTags()"
+}
+
+func (lenientTagsOption) applyOption(c *Container) {
+	c.scope.tags.Lenient = true
+}
+
+// _foreignTagKeys are struct tag keys of other libraries that are never
+// reported as misspellings, even if they are close to a key dig reads.
+var _foreignTagKeys = map[string]struct{}{
+	"binding":      {},
+	"bson":         {},
+	"db":           {},
+	"default":      {},
+	"env":          {},
+	"form":         {},
+	"header":       {},
+	"json":         {},
+	"mapstructure": {},
+	"msgpack":      {},
+	"protobuf":     {},
+	"query":        {},
+	"toml":         {},
+	"validate":     {},
+	"xml":          {},
+	"yaml":         {},
+}
+
+// known returns the struct tag keys that dig reads from dig.In fields,
+// along with the group options that are commonly mistaken for keys.
+func (tk tagKeys) known() []string {
+	return []string{
+		tk.name(),
+		_namesTag,
+		tk.group(),
+		_optionalTag,
+		_ignoreUnexportedTag,
+		_fromCtxTag,
+		_dynamicTag,
+		_labelsTag,
+		"flatten",
+		"soft",
+	}
+}
+
+// checkTagTypos returns an error if the field has a struct tag key that is
+// a close misspelling of one of the keys dig reads.
+func checkTagTypos(f reflect.StructField, tags tagKeys) error {
+	if tags.Lenient {
+		return nil
+	}
+
+	known := tags.known()
+	for _, k := range structTagKeys(f.Tag) {
+		if _, ok := _foreignTagKeys[k]; ok || containsString(known, k) {
+			continue
+		}
+		if k == _nameTag || k == _groupTag {
+			// The default keys are not read if WithNameTag or
+			// WithGroupTag changed them, so they may be used for
+			// other purposes.
+			continue
+		}
+		for _, want := range known {
+			if isTagTypo(k, want) {
+				return newErrInvalidInput(fmt.Sprintf(
+					"unknown tag %q on field %v, did you mean %q? "+
+						"use the dig.LenientTags option if this is intended", k, f.Name, want), nil)
+			}
+		}
+	}
+	return nil
+}
+
+func containsString(ss []string, s string) bool {
+	for _, o := range ss {
+		if o == s {
+			return true
+		}
+	}
+	return false
+}
+
+// isTagTypo reports whether got is close enough to want to be a
+// misspelling of it. Short keys allow a single edit, longer ones two.
+func isTagTypo(got, want string) bool {
+	max := 1
+	if len(want) > 5 {
+		max = 2
+	}
+	d := editDistance(got, want)
+	return d > 0 && d <= max
+}
+
+// editDistance returns the optimal string alignment distance between a and
+// b: the number of insertions, deletions, substitutions, and transpositions
+// of adjacent bytes needed to turn a into b.
+func editDistance(a, b string) int {
+	// d[i][j] is the distance between a[:i] and b[:j].
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func minInt(first int, rest ...int) int {
+	m := first
+	for _, n := range rest {
+		if n < m {
+			m = n
+		}
+	}
+	return m
+}
+
+// structTagKeys returns the keys of the conventional key:"value" pairs in
+// tag, in order. Parsing stops at the first malformed pair, as it does for
+// reflect.StructTag.Lookup.
+func structTagKeys(tag reflect.StructTag) []string {
+	var keys []string
+	for tag != "" {
+		// Skip leading space.
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+
+		// Scan to colon. A space, a quote or a control character is a
+		// syntax error.
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			break
+		}
+		key := string(tag[:i])
+		tag = tag[i+1:]
+
+		// Scan quoted string to find the end of the value.
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		tag = tag[i+1:]
+
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructTagKeys(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		give reflect.StructTag
+		want []string
+	}{
+		{give: "", want: nil},
+		{give: `name:"foo"`, want: []string{"name"}},
+		{give: `name:"foo"  json:"bar,omitempty"`, want: []string{"name", "json"}},
+		{give: `name:"a \"quoted\" value" group:"g"`, want: []string{"name", "group"}},
+		{give: `name:"foo" broken`, want: []string{"name"}},
+		{give: `name:"unterminated`, want: nil},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, structTagKeys(tt.give), "keys of %q", tt.give)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"name", "name", 0},
+		{"nmae", "name", 1},
+		{"nam", "name", 1},
+		{"groups", "group", 1},
+		{"optinal", "optional", 1},
+		{"optioanl", "optional", 1},
+		{"", "soft", 4},
+		{"json", "name", 4},
+		{"yaml", "name", 2},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, editDistance(tt.a, tt.b), "distance(%q, %q)", tt.a, tt.b)
+		assert.Equal(t, tt.want, editDistance(tt.b, tt.a), "distance(%q, %q)", tt.b, tt.a)
+	}
+}
+
+func TestCheckTagTypos(t *testing.T) {
+	t.Parallel()
+
+	t.Run("typos", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			give reflect.StructTag
+			tags tagKeys
+			want string
+		}{
+			{give: `nmae:"ro"`, want: "name"},
+			{give: `Name:"ro"`, want: "name"},
+			{give: `groups:"handlers"`, want: "group"},
+			{give: `gruop:"handlers"`, want: "group"},
+			{give: `optinal:"true"`, want: "optional"},
+			{give: `ignore-unexproted:"true"`, want: "ignore-unexported"},
+			{give: `fromctxx:"id"`, want: "fromctx"},
+			{give: `flaten:"true"`, want: "flatten"},
+			{give: `json:"x" sotf:"true"`, want: "soft"},
+			{give: `dig-nmae:"ro"`, tags: tagKeys{Name: "dig-name"}, want: "dig-name"},
+		}
+
+		for _, tt := range tests {
+			f := reflect.StructField{Name: "Field", Tag: tt.give}
+			err := checkTagTypos(f, tt.tags)
+			require.Error(t, err, "tag %q", tt.give)
+			assert.Contains(t, err.Error(), "did you mean "+`"`+tt.want+`"`, "tag %q", tt.give)
+			assert.Contains(t, err.Error(), "on field Field")
+		}
+	})
+
+	t.Run("no false positives", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			give reflect.StructTag
+			tags tagKeys
+		}{
+			{give: `name:"ro" optional:"true"`},
+			{give: `group:"handlers,flatten,soft"`},
+			{give: `names:"*"`},
+			{give: `json:"name" yaml:"name" xml:"name" toml:"name"`},
+			{give: `env:"NAME" db:"name" form:"name" default:"x"`},
+			{give: `mapstructure:"group" validate:"required"`},
+			{give: `description:"the name of the group"`},
+			{give: `name:"unrelated" dig-name:"ro"`, tags: tagKeys{Name: "dig-name"}},
+			{give: `group:"unrelated" dig-group:"g"`, tags: tagKeys{Group: "dig-group"}},
+			{give: `nmae:"ro"`, tags: tagKeys{Lenient: true}},
+		}
+
+		for _, tt := range tests {
+			f := reflect.StructField{Name: "Field", Tag: tt.give}
+			assert.NoError(t, checkTagTypos(f, tt.tags), "tag %q", tt.give)
+		}
+	})
+
+	t.Run("invoke", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			In
+
+			Name string `nmae:"ro"`
+		}
+
+		c := New()
+		require.NoError(t, c.Provide(func() string { return "" }))
+		err := c.Invoke(func(params) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `bad field "Name" of dig.params`)
+		assert.Contains(t, err.Error(), `unknown tag "nmae" on field Name, did you mean "name"?`)
+
+		c = New(LenientTags())
+		require.NoError(t, c.Provide(func() string { return "" }))
+		assert.NoError(t, c.Invoke(func(params) {}))
+	})
+
+	t.Run("dig.In embed", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			In `ignore-unexproted:"true"`
+		}
+
+		err := New().Invoke(func(params) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `did you mean "ignore-unexported"?`)
+	})
+}
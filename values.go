@@ -0,0 +1,106 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ExportValues returns the values that the Container already built, keyed
+// by their types, so that they may be passed to ImportValues of another
+// Container. If a value was decorated, its decorated value is returned.
+//
+// Only values without a name are exported. Named values, value groups, and
+// values built in child Scopes are not.
+func (c *Container) ExportValues() map[reflect.Type]reflect.Value {
+	s := c.scope
+	defer s.lock()()
+
+	values := make(map[reflect.Type]reflect.Value)
+	for k, v := range s.values {
+		if k.name == "" {
+			values[k.t] = v
+		}
+	}
+	for k, v := range s.decoratedValues {
+		if k.name == "" {
+			values[k.t] = v
+		}
+	}
+	return values
+}
+
+// ImportValues adds values to the Container as if its constructors had
+// already built them, usually values that were returned by ExportValues of
+// another Container. Constructors for these types are not called, and
+// functions may depend on these types even if the Container has no
+// constructor for them.
+//
+//	values := parent.ExportValues()
+//	c := dig.New()
+//	err := c.ImportValues(values)
+//
+// Each value must be assignable to its type. Values are imported without a
+// name, so they do not satisfy dependencies on named values or value
+// groups. Values that the Container already built are not replaced: if
+// any of the types was already built, no values are imported and an error
+// is returned.
+func (c *Container) ImportValues(values map[reflect.Type]reflect.Value) error {
+	s := c.scope
+
+	types := make([]reflect.Type, 0, len(values))
+	for t := range values {
+		types = append(types, t)
+	}
+	sort.Sort(byTypeName(types))
+
+	imported := make(map[key]reflect.Value, len(values))
+	for _, t := range types {
+		v := values[t]
+		switch {
+		case t == nil:
+			return newErrInvalidInput("cannot import a value without a type", nil)
+		case !v.IsValid():
+			return newErrInvalidInput(fmt.Sprintf("cannot import %v: the reflect.Value is invalid", t), nil)
+		case !v.Type().AssignableTo(t):
+			return newErrInvalidInput(fmt.Sprintf("cannot import %v: a value of type %v is not assignable to it", t, v.Type()), nil)
+		case IsIn(t) || IsOut(t) || isError(t):
+			return newErrInvalidInput(fmt.Sprintf("cannot import %v: only single values may be imported", t), nil)
+		}
+
+		k := key{t: t}
+		if _, ok := s.getValue(k.name, k.t); ok {
+			return newErrInvalidInput(fmt.Sprintf("cannot import %v: a value of this type was already built", t), nil)
+		}
+
+		iv := reflect.New(t).Elem()
+		iv.Set(v)
+		imported[k] = iv
+	}
+
+	defer s.lock()()
+	for k, v := range imported {
+		s.values[k] = v
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestExportImportValues(t *testing.T) {
+	t.Parallel()
+
+	type Config struct{ Name string }
+	type Logger struct{ Name string }
+
+	t.Run("round trip", func(t *testing.T) {
+		t.Parallel()
+
+		src := digtest.New(t)
+		src.RequireProvide(func() *Config { return &Config{Name: "loaded"} })
+		src.RequireProvide(func() *Logger { return &Logger{Name: "unused"} })
+		src.RequireProvide(func() *Logger { return &Logger{Name: "named"} }, dig.Name("named"))
+		src.RequireProvide(func() int { return 1 }, dig.Group("ints"))
+		src.RequireInvoke(func(*Config, struct {
+			dig.In
+
+			Named *Logger `name:"named"`
+			Ints  []int   `group:"ints"`
+		}) {
+		})
+
+		values := src.ExportValues()
+		assert.Len(t, values, 1, "only unnamed values that were built are exported")
+
+		dst := digtest.New(t)
+		require.NoError(t, dst.ImportValues(values))
+		dst.RequireInvoke(func(cfg *Config) {
+			assert.Equal(t, &Config{Name: "loaded"}, cfg)
+		})
+	})
+
+	t.Run("skips constructors", func(t *testing.T) {
+		t.Parallel()
+
+		dst := digtest.New(t)
+		dst.RequireProvide(func() *Config {
+			t.Fatal("must not be called")
+			return nil
+		})
+		require.NoError(t, dst.ImportValues(map[reflect.Type]reflect.Value{
+			reflect.TypeOf(&Config{}): reflect.ValueOf(&Config{Name: "imported"}),
+		}))
+		dst.RequireInvoke(func(cfg *Config) {
+			assert.Equal(t, "imported", cfg.Name)
+		})
+	})
+
+	t.Run("decorated values", func(t *testing.T) {
+		t.Parallel()
+
+		src := digtest.New(t)
+		src.RequireProvide(func() *Config { return &Config{Name: "loaded"} })
+		src.RequireDecorate(func(cfg *Config) *Config { return &Config{Name: cfg.Name + "!"} })
+		src.RequireInvoke(func(*Config) {})
+
+		values := src.ExportValues()
+		assert.Equal(t, &Config{Name: "loaded!"}, values[reflect.TypeOf(&Config{})].Interface())
+	})
+
+	t.Run("interfaces", func(t *testing.T) {
+		t.Parallel()
+
+		type Namer interface{}
+
+		dst := digtest.New(t)
+		require.NoError(t, dst.ImportValues(map[reflect.Type]reflect.Value{
+			reflect.TypeOf((*Namer)(nil)).Elem(): reflect.ValueOf(&Config{Name: "impl"}),
+		}))
+		dst.RequireInvoke(func(n Namer) {
+			assert.Equal(t, &Config{Name: "impl"}, n)
+		})
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		t.Parallel()
+
+		dst := digtest.New(t)
+		dst.RequireProvide(func() *Logger { return &Logger{} })
+		dst.RequireInvoke(func(*Logger) {})
+
+		tests := []struct {
+			desc    string
+			give    map[reflect.Type]reflect.Value
+			wantErr string
+		}{
+			{
+				desc:    "invalid reflect.Value",
+				give:    map[reflect.Type]reflect.Value{reflect.TypeOf(&Config{}): {}},
+				wantErr: "cannot import *dig_test.Config: the reflect.Value is invalid",
+			},
+			{
+				desc:    "not assignable",
+				give:    map[reflect.Type]reflect.Value{reflect.TypeOf(&Config{}): reflect.ValueOf(&Logger{})},
+				wantErr: "cannot import *dig_test.Config: a value of type *dig_test.Logger is not assignable to it",
+			},
+			{
+				desc: "already built",
+				give: map[reflect.Type]reflect.Value{
+					reflect.TypeOf(&Config{}): reflect.ValueOf(&Config{}),
+					reflect.TypeOf(&Logger{}): reflect.ValueOf(&Logger{}),
+				},
+				wantErr: "cannot import *dig_test.Logger: a value of this type was already built",
+			},
+		}
+
+		for _, tt := range tests {
+			err := dst.ImportValues(tt.give)
+			require.Error(t, err, tt.desc)
+			assert.Contains(t, err.Error(), tt.wantErr, tt.desc)
+		}
+
+		// Nothing was imported by the failed calls.
+		assert.Error(t, dst.Invoke(func(*Config) {}))
+	})
+}
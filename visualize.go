@@ -25,6 +25,7 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 	"text/template"
 
 	"go.uber.org/dig/internal/dot"
@@ -36,7 +37,11 @@ type VisualizeOption interface {
 }
 
 type visualizeOptions struct {
-	VisualizeError error
+	VisualizeError   error
+	VisualizeMissing bool
+	Legend           bool
+	SourceLinks      bool
+	URLTemplate      string
 }
 
 // VisualizeError includes a visualization of the given error in the output of
@@ -62,7 +67,116 @@ func (o visualizeErrorOption) applyVisualizeOption(opt *visualizeOptions) {
 	opt.VisualizeError = o.err
 }
 
+// VisualizeMissing restricts the output of Visualize to the dependencies
+// that cannot be satisfied. Constructors with required parameters that
+// nothing provides are shown along with the missing values, in red, and the
+// constructors that depend on them, directly or indirectly.
+//
+//	if missing := c.MissingDependencies(); len(missing) > 0 {
+//	  dig.Visualize(c, w, dig.VisualizeMissing())
+//	}
+//
+// This is useful while a container is only partially wired, when the full
+// graph hides the parts that still need work. The graph is empty if nothing
+// is missing.
+func VisualizeMissing() VisualizeOption {
+	return visualizeMissingOption{}
+}
+
+type visualizeMissingOption struct{}
+
+func (visualizeMissingOption) String() string {
+	return "VisualizeMissing()"
+}
+
+func (visualizeMissingOption) applyVisualizeOption(opt *visualizeOptions) {
+	opt.VisualizeMissing = true
+}
+
+// VisualizeLegend includes a legend in the output of Visualize that
+// explains how constructors, values, named values, value groups, optional
+// dependencies, and failures are drawn.
+func VisualizeLegend() VisualizeOption {
+	return visualizeLegendOption{}
+}
+
+type visualizeLegendOption struct{}
+
+func (visualizeLegendOption) String() string {
+	return "VisualizeLegend()"
+}
+
+func (visualizeLegendOption) applyVisualizeOption(opt *visualizeOptions) {
+	opt.Legend = true
+}
+
+// VisualizeSourceLinks sets the tooltip of each constructor in the output
+// of Visualize to its file and line. If urlTemplate is not empty, it is a
+// text/template that builds the URL of the constructor, for example to link
+// to a code browser. The template receives the File, Line, Package, and
+// Name of the constructor.
+//
+//	dig.Visualize(c, w, dig.VisualizeSourceLinks(
+//	  "https://code.example.com/{{.File}}#L{{.Line}}",
+//	))
+//
+// Viewers such as SVG renderers show tooltips on hover and follow URLs on
+// click.
+func VisualizeSourceLinks(urlTemplate string) VisualizeOption {
+	return visualizeSourceLinksOption{urlTemplate: urlTemplate}
+}
+
+type visualizeSourceLinksOption struct{ urlTemplate string }
+
+func (o visualizeSourceLinksOption) String() string {
+	return fmt.Sprintf("VisualizeSourceLinks(%q)", o.urlTemplate)
+}
+
+func (o visualizeSourceLinksOption) applyVisualizeOption(opt *visualizeOptions) {
+	opt.SourceLinks = true
+	opt.URLTemplate = o.urlTemplate
+}
+
+// addSourceLinks sets the tooltips and URLs of the constructors in the graph.
+func addSourceLinks(dg *dot.Graph, urlTemplate string) error {
+	var tmpl *template.Template
+	if urlTemplate != "" {
+		var err error
+		tmpl, err = template.New("URL").Parse(urlTemplate)
+		if err != nil {
+			return newErrInvalidInput("invalid dig.VisualizeSourceLinks template", err)
+		}
+	}
+
+	for _, c := range dg.Ctors {
+		c.Tooltip = fmt.Sprintf("%v:%d", c.File, c.Line)
+		if tmpl == nil {
+			continue
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, c); err != nil {
+			return newErrInvalidInput(fmt.Sprintf("cannot build URL of %v.%v", c.Package, c.Name), err)
+		}
+		c.URL = b.String()
+	}
+	return nil
+}
+
 func updateGraph(dg *dot.Graph, err error) error {
+	if !failGraph(dg, err) {
+		// If there are no errVisualizers included, we do not modify the graph.
+		return nil
+	}
+
+	// Remove non-error entries from the graph for readability.
+	dg.PruneSuccess()
+
+	return nil
+}
+
+// failGraph marks the nodes of the graph that failed according to the given
+// error. It reports whether the error had any information to visualize.
+func failGraph(dg *dot.Graph, err error) bool {
 	var errs []errVisualizer
 	// Unwrap error to find the root cause.
 	for {
@@ -76,20 +190,12 @@ func updateGraph(dg *dot.Graph, err error) error {
 		err = e
 	}
 
-	// If there are no errVisualizers included, we do not modify the graph.
-	if len(errs) == 0 {
-		return nil
-	}
-
 	// We iterate in reverse because the last element is the root cause.
 	for i := len(errs) - 1; i >= 0; i-- {
 		errs[i].updateGraph(dg)
 	}
 
-	// Remove non-error entries from the graph for readability.
-	dg.PruneSuccess()
-
-	return nil
+	return len(errs) > 0
 }
 
 var _graphTmpl = template.Must(
@@ -100,6 +206,8 @@ var _graphTmpl = template.Must(
 		Parse(`digraph {
 	rankdir=RL;
 	graph [compound=true];
+	{{if .ShowLegend}}{{.Legend}}
+	{{end -}}
 	{{range $g := .Groups}}
 		{{- quote .String}} [{{.Attributes}}];
 		{{range .Results}}
@@ -111,7 +219,7 @@ var _graphTmpl = template.Must(
 			{{ with .Package }}label = {{ quote .}};
 			{{ end -}}
 
-			constructor_{{$index}} [shape=plaintext label={{quote .Name}}];
+			constructor_{{$index}} [shape=plaintext label={{quote .Name}}{{range $k, $v := .Tags}} {{quote (printf "tag:%s" $k)}}={{quote $v}}{{end}}{{with .Tooltip}} tooltip={{quote .}}{{end}}{{with .URL}} URL={{quote .}}{{end}}];
 			{{with .ErrorType}}color={{.Color}};{{end}}
 			{{range .Results}}
 				{{- quote .String}} [{{.Attributes}}];
@@ -148,6 +256,20 @@ func Visualize(c *Container, w io.Writer, opts ...VisualizeOption) error {
 		}
 	}
 
+	if options.VisualizeMissing {
+		c.scope.failMissing(dg)
+		dg.FailConsumers()
+		dg.PruneSuccess()
+	}
+
+	if options.SourceLinks {
+		if err := addSourceLinks(dg, options.URLTemplate); err != nil {
+			return err
+		}
+	}
+
+	dg.ShowLegend = options.Legend
+	dg.Sort()
 	return _graphTmpl.Execute(w, dg)
 }
 
@@ -188,5 +310,6 @@ func newDotCtor(n *constructorNode) *dot.Ctor {
 		Package: n.location.Package,
 		File:    n.location.File,
 		Line:    n.location.Line,
+		Tags:    n.Tags(),
 	}
 }
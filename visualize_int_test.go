@@ -21,12 +21,15 @@
 package dig
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig/internal/digreflect"
 	"go.uber.org/dig/internal/dot"
 )
 
@@ -99,3 +102,44 @@ func TestCanVisualizeError(t *testing.T) {
 		})
 	}
 }
+
+func TestVisualizeSourceLinks(t *testing.T) {
+	type t1 struct{}
+	type t2 struct{}
+
+	// Locations are fixed so that the output does not depend on where
+	// the tests run.
+	newContainer := func(t *testing.T) *Container {
+		c := New()
+		require.NoError(t, c.Provide(func() t1 { return t1{} }, provideLocationOption{
+			loc: &digreflect.Func{Package: "example.com/a", Name: "NewT1", File: "a/t1.go", Line: 12},
+		}))
+		require.NoError(t, c.Provide(func(t1) t2 { return t2{} }, provideLocationOption{
+			loc: &digreflect.Func{Package: "example.com/b", Name: "NewT2", File: "b/t2.go", Line: 34},
+		}))
+		return c
+	}
+
+	t.Run("tooltips", func(t *testing.T) {
+		VerifyVisualization(t, "source_tooltips", newContainer(t), VisualizeSourceLinks(""))
+	})
+
+	t.Run("URLs", func(t *testing.T) {
+		VerifyVisualization(t, "source_links", newContainer(t),
+			VisualizeSourceLinks("https://code.example.com/{{.Package}}/{{.File}}#L{{.Line}}"))
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		var b bytes.Buffer
+		err := Visualize(newContainer(t), &b, VisualizeSourceLinks("{{.File"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid dig.VisualizeSourceLinks template")
+	})
+
+	t.Run("template fails", func(t *testing.T) {
+		var b bytes.Buffer
+		err := Visualize(newContainer(t), &b, VisualizeSourceLinks("{{.Unknown}}"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot build URL of example.com/a.NewT1")
+	})
+}
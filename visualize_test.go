@@ -30,6 +30,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/dig"
 	"go.uber.org/dig/internal/digtest"
 	"go.uber.org/dig/internal/dot"
@@ -582,6 +583,116 @@ func TestVisualize(t *testing.T) {
 
 		dig.VerifyVisualization(t, "missingDep", c.Container, dig.VisualizeError(err))
 	})
+
+	t.Run("only missing dependencies", func(t *testing.T) {
+		type t5 struct{}
+		type in struct {
+			dig.In
+
+			T4 []t4 `group:"t4s"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() t1 { return t1{} })
+		c.RequireProvide(func(t1, t2) t3 { return t3{} })
+		c.RequireProvide(func(t3) t4 { return t4{} }, dig.Group("t4s"))
+		c.RequireProvide(func(in) t5 { return t5{} })
+		c.RequireProvide(func(t1) (string, error) { return "", nil })
+
+		dig.VerifyVisualization(t, "missingOnly", c.Container, dig.VisualizeMissing())
+	})
+
+	t.Run("only missing dependencies with nothing missing", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(func() t1 { return t1{} })
+		c.RequireProvide(func(t1) t2 { return t2{} })
+
+		dig.VerifyVisualization(t, "empty", c.Container, dig.VisualizeMissing())
+	})
+
+	t.Run("tags", func(t *testing.T) {
+		c := digtest.New(t)
+
+		c.RequireProvide(func() t1 { return t1{} },
+			dig.Tags(map[string]string{"team": "payments", "tier": "1"}))
+		c.RequireProvide(func(t1) t2 { return t2{} })
+		dig.VerifyVisualization(t, "tags", c.Container)
+	})
+
+	t.Run("legend", func(t *testing.T) {
+		c := digtest.New(t)
+
+		c.Provide(func() (t1, t2) { return t1{}, t2{} })
+		c.Provide(func(A t1, B t2) (t3, t4) { return t3{}, t4{} })
+		dig.VerifyVisualization(t, "legend", c.Container, dig.VisualizeLegend())
+	})
+}
+
+func TestVisualizeStable(t *testing.T) {
+	t.Parallel()
+
+	type t1 struct{}
+	type t2 struct{}
+	type t3 struct{}
+
+	type in struct {
+		dig.In
+
+		B t2
+		A t1
+	}
+
+	newT1 := func() t1 { return t1{} }
+	newT2 := func() t2 { return t2{} }
+	newT3 := func(in) t3 { return t3{} }
+
+	render := func(ctors ...interface{}) string {
+		c := digtest.New(t)
+		for _, ctor := range ctors {
+			c.RequireProvide(ctor)
+		}
+
+		var b bytes.Buffer
+		require.NoError(t, dig.Visualize(c.Container, &b))
+		return b.String()
+	}
+
+	t.Run("same container rendered twice", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(newT1)
+		c.RequireProvide(newT2)
+		c.RequireProvide(newT3)
+
+		var first, second bytes.Buffer
+		require.NoError(t, dig.Visualize(c.Container, &first))
+		require.NoError(t, dig.Visualize(c.Container, &second))
+		assert.Equal(t, first.String(), second.String())
+	})
+
+	t.Run("independent of provide order", func(t *testing.T) {
+		want := render(newT1, newT2, newT3)
+		assert.Equal(t, want, render(newT3, newT2, newT1))
+		assert.Equal(t, want, render(newT2, newT3, newT1))
+	})
+}
+
+func TestVisualizeLegendString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "VisualizeLegend()", fmt.Sprint(dig.VisualizeLegend()))
+}
+
+func TestVisualizeSourceLinksString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, `VisualizeSourceLinks("https://example.com/{{.File}}")`,
+		fmt.Sprint(dig.VisualizeSourceLinks("https://example.com/{{.File}}")))
+}
+
+func TestVisualizeMissingString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "VisualizeMissing()", fmt.Sprint(dig.VisualizeMissing()))
 }
 
 func TestVisualizeErrorString(t *testing.T) {
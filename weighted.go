@@ -0,0 +1,160 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// Weight is a ProvideOption that sets the weight of all values that a
+// constructor provides to value groups. Consumers may request a single
+// value of a value group, selected at random with a probability
+// proportional to its weight, with the "select=weighted" option of the
+// group tag on a field that is not a slice.
+//
+//	c.Provide(NewPrimary, dig.Group("backends"), dig.Weight(9))
+//	c.Provide(NewCanary, dig.Group("backends"), dig.Weight(1))
+//
+//	type Params struct {
+//	  dig.In
+//
+//	  Backend Backend `group:"backends,select=weighted"`
+//	}
+//
+// A value is selected every time a function that depends on it is called.
+// Values whose constructors were provided without this option have a
+// weight of 1. Values produced by decorators have no weight, so consumers
+// cannot select from value groups that were decorated. The weight must be
+// positive.
+func Weight(weight int) ProvideOption {
+	return provideWeightOption(weight)
+}
+
+type provideWeightOption int
+
+func (o provideWeightOption) String() string {
+	return fmt.Sprintf("Weight(%d)", int(o))
+}
+
+func (o provideWeightOption) applyProvideOption(opts *provideOptions) {
+	w := int(o)
+	opts.Weight = &w
+}
+
+// _defaultWeight is the weight of values whose constructors were provided
+// without the Weight option.
+const _defaultWeight = 1
+
+func weightOrDefault(w *int) int {
+	if w == nil {
+		return _defaultWeight
+	}
+	return *w
+}
+
+// weight returns the weight of this member. Values that were not provided
+// by a constructor have the default weight.
+func (m groupMember) weight() int {
+	if m.Weight == 0 {
+		return _defaultWeight
+	}
+	return m.Weight
+}
+
+func (s *Scope) randIntn(n int) int {
+	defer s.lock()()
+	return s.rand.Intn(n)
+}
+
+// buildWeighted builds the value group of this param and selects one of its
+// values according to their weights.
+func (pt paramGroupedSlice) buildWeighted(c containerStore) (reflect.Value, error) {
+	if err := pt.callGroupDecorators(c); err != nil {
+		return _noValue, err
+	}
+	if _, ok := pt.getDecoratedValues(c); ok {
+		return _noValue, newErrInvalidInput(fmt.Sprintf(
+			"cannot consume %v: decorated values have no weight", pt), nil)
+	}
+
+	if !pt.Soft {
+		if _, err := pt.callGroupProviders(c); err != nil {
+			return _noValue, err
+		}
+	}
+	recordConsumed(c, pt.Type)
+
+	var (
+		entries []groupEntry
+		total   int
+	)
+	for _, c := range c.storesToRoot() {
+		for _, e := range c.getValueGroupEntries(pt.Group, pt.Type.Elem()) {
+			if !pt.Filter.matches(e.groupMember) {
+				continue
+			}
+			entries = append(entries, e)
+			total += e.weight()
+		}
+	}
+	if len(entries) == 0 {
+		return _noValue, errEmptyGroupSelection{Key: key{group: pt.Group, t: pt.Type.Elem()}}
+	}
+
+	n := c.randIntn(total)
+	e := entries[len(entries)-1]
+	for _, candidate := range entries {
+		if n -= candidate.weight(); n < 0 {
+			e = candidate
+			break
+		}
+	}
+
+	v := e.Value
+	if c.copiesSharedValues() {
+		v = shallowCopy(v)
+	}
+	if pt.EntryType != nil {
+		e.Value = v
+		return newGroupValue(pt.EntryType, e), nil
+	}
+	return v, nil
+}
+
+// errEmptyGroupSelection is returned when a single value is requested from a
+// value group that has no values to select from.
+type errEmptyGroupSelection struct {
+	Key key
+}
+
+var _ digError = errEmptyGroupSelection{}
+
+func (e errEmptyGroupSelection) Error() string { return fmt.Sprint(e) }
+
+func (e errEmptyGroupSelection) writeMessage(w io.Writer, _ string) {
+	fmt.Fprintf(w, "cannot select a value from %v: the value group has no values", e.Key)
+}
+
+func (e errEmptyGroupSelection) Format(w fmt.State, c rune) {
+	formatError(e, w, c)
+}
@@ -0,0 +1,183 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/dig/internal/digtest"
+)
+
+func TestWeightedGroupSelection(t *testing.T) {
+	t.Parallel()
+
+	type Backend string
+
+	type params struct {
+		dig.In
+
+		Backend Backend `group:"backends,select=weighted"`
+	}
+
+	t.Run("selects by weight", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.SetRand(rand.New(rand.NewSource(0))))
+		c.RequireProvide(func() Backend { return "primary" }, dig.Group("backends"), dig.Weight(9))
+		c.RequireProvide(func() Backend { return "canary" }, dig.Group("backends"))
+
+		counts := make(map[Backend]int)
+		for i := 0; i < 1000; i++ {
+			c.RequireInvoke(func(p params) { counts[p.Backend]++ })
+		}
+		assert.Len(t, counts, 2)
+		assert.Greater(t, counts["primary"], 800)
+		assert.Greater(t, counts["canary"], 50)
+	})
+
+	t.Run("single member", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		c := digtest.New(t)
+		c.RequireProvide(func() Backend {
+			calls++
+			return "primary"
+		}, dig.Group("backends"))
+		c.RequireInvoke(func(p params) {
+			assert.Equal(t, Backend("primary"), p.Backend)
+		})
+		c.RequireInvoke(func(p params) {
+			assert.Equal(t, Backend("primary"), p.Backend)
+		})
+		assert.Equal(t, 1, calls, "constructor must be called once")
+	})
+
+	t.Run("GroupValue entries", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() Backend { return "primary" }, dig.Group("backends"))
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Backend dig.GroupValue[Backend] `group:"backends,select=weighted"`
+		}) {
+			assert.Equal(t, Backend("primary"), p.Backend.Value)
+			assert.Contains(t, p.Backend.Function, "TestWeightedGroupSelection")
+		})
+	})
+
+	t.Run("filtered by tag and labels", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() Backend { return "primary" },
+			dig.Group("backends"), dig.GroupTag("eu"), dig.GroupLabels("stable"))
+		c.RequireProvide(func() Backend { return "canary" },
+			dig.Group("backends"), dig.GroupTag("eu"), dig.Weight(100))
+		c.RequireProvide(func() Backend { return "us" },
+			dig.Group("backends"), dig.GroupLabels("stable"), dig.Weight(100))
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Backend Backend `group:"backends,tag=eu,select=weighted" labels:"stable"`
+		}) {
+			assert.Equal(t, Backend("primary"), p.Backend)
+		})
+	})
+
+	t.Run("param tags", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() Backend { return "primary" }, dig.Group("backends"))
+		c.RequireProvide(func(b Backend) string { return string(b) },
+			dig.ParamTags(`group:"backends,select=weighted"`))
+		c.RequireInvoke(func(s string) {
+			assert.Equal(t, "primary", s)
+		})
+	})
+
+	t.Run("empty group", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Invoke(func(params) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			`cannot select a value from dig_test.Backend[group="backends"]: the value group has no values`)
+	})
+
+	t.Run("decorated groups cannot be selected from", func(t *testing.T) {
+		t.Parallel()
+
+		type decorated struct {
+			dig.Out
+
+			Backends []Backend `group:"backends"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() Backend { return "primary" }, dig.Group("backends"))
+		c.RequireDecorate(func(p struct {
+			dig.In
+
+			Backends []Backend `group:"backends"`
+		}) decorated {
+			return decorated{Backends: p.Backends}
+		})
+
+		err := c.Invoke(func(params) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			`cannot consume dig_test.Backend[group="backends", select=weighted]: decorated values have no weight`)
+	})
+
+	t.Run("invalid weight", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Provide(func() Backend { panic("must not be called") }, dig.Group("backends"), dig.Weight(0))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid dig.Weight(0): weights must be positive")
+	})
+
+	t.Run("results cannot select", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Provide(func() struct {
+			dig.Out
+
+			Backend Backend `group:"backends,select=weighted"`
+		} {
+			panic("must not be called")
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			`cannot use select with result value groups: select was used with group "backends"`)
+	})
+}